@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TaskFile represents a batch of commands sharing one secrets collection
+type TaskFile struct {
+	Parallel        bool   `yaml:"parallel,omitempty"`          // Run all tasks concurrently (default: sequential)
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty"` // Keep running remaining tasks after a failure (default: fail fast)
+	Tasks           []Task `yaml:"tasks"`
+}
+
+// Task represents a single command in a batch
+type Task struct {
+	Name    string   `yaml:"name,omitempty"` // Optional display name (defaults to the command)
+	Command []string `yaml:"command"`        // Command and arguments
+}
+
+// DisplayName returns the task name, falling back to the command
+func (t Task) DisplayName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return fmt.Sprintf("%v", t.Command)
+}
+
+// LoadTaskFile reads and validates a batch task file
+func LoadTaskFile(path string) (*TaskFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task file: %w", err)
+	}
+
+	var taskFile TaskFile
+	if err := yaml.Unmarshal(data, &taskFile); err != nil {
+		return nil, fmt.Errorf("failed to parse task file: %w", err)
+	}
+
+	if len(taskFile.Tasks) == 0 {
+		return nil, fmt.Errorf("task file must contain at least one task")
+	}
+	for i, task := range taskFile.Tasks {
+		if len(task.Command) == 0 {
+			return nil, fmt.Errorf("tasks[%d].command is required", i)
+		}
+	}
+
+	return &taskFile, nil
+}
+
+// TaskResult holds the outcome of a single task
+type TaskResult struct {
+	Task     Task
+	ExitCode int   // Exit code of the task (-1 if it was not run)
+	Err      error // Error starting or waiting for the task, excluding non-zero exits
+}
+
+// RunAll collects secrets once and executes every task in the batch with the shared environment.
+// It returns the per-task results and the aggregated exit code: 0 if all tasks succeeded,
+// otherwise the exit code of the first task to fail. Unless ContinueOnError is set, the first
+// failure stops the batch: remaining sequential tasks are not started and parallel tasks are killed.
+func (r *Runner) RunAll(ctx context.Context, providerIDs []string, taskFile *TaskFile) ([]TaskResult, int, error) {
+	// Collect secrets once for all tasks
+	envSecrets, err := r.collector.Collect(ctx, providerIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to collect secrets: %w", err)
+	}
+	env, owned := r.buildEnv(envSecrets)
+	// Every task has been started or skipped when RunAll returns
+	defer owned.Zero()
+
+	results := make([]TaskResult, len(taskFile.Tasks))
+	for i, task := range taskFile.Tasks {
+		results[i] = TaskResult{Task: task, ExitCode: -1}
+	}
+
+	// Cancelling taskCtx kills tasks that are still running (fail-fast in parallel mode)
+	taskCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Track running children so signals are forwarded to all of them
+	var mu sync.Mutex
+	running := make(map[*exec.Cmd]struct{})
+	exitCode := 0
+
+	sigChan := make(chan os.Signal, 1)
+	registerSignals(sigChan)
+	go func() {
+		for sig := range sigChan {
+			mu.Lock()
+			for cmd := range running {
+				if cmd.Process != nil {
+					_ = cmd.Process.Signal(sig)
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		signal.Stop(sigChan)
+		close(sigChan)
+	}()
+
+	runTask := func(i int) {
+		cmd := r.newCommand(taskCtx, env, taskFile.Tasks[i].Command)
+
+		mu.Lock()
+		if err := cmd.Start(); err != nil {
+			results[i].ExitCode = 1
+			results[i].Err = fmt.Errorf("failed to start task '%s': %w", taskFile.Tasks[i].DisplayName(), err)
+		} else {
+			running[cmd] = struct{}{}
+		}
+		mu.Unlock()
+
+		if results[i].Err == nil {
+			waitErr := cmd.Wait()
+
+			mu.Lock()
+			delete(running, cmd)
+			mu.Unlock()
+
+			results[i].ExitCode = 0
+			if waitErr != nil {
+				if exitError, ok := waitErr.(*exec.ExitError); ok {
+					results[i].ExitCode = exitError.ExitCode()
+					// Terminated by a signal: report a generic failure
+					if results[i].ExitCode < 0 {
+						results[i].ExitCode = 1
+					}
+				} else {
+					results[i].ExitCode = 1
+					results[i].Err = waitErr
+				}
+			}
+		}
+
+		if results[i].ExitCode != 0 {
+			mu.Lock()
+			if exitCode == 0 {
+				exitCode = results[i].ExitCode
+			}
+			mu.Unlock()
+			if !taskFile.ContinueOnError {
+				cancel()
+			}
+		}
+	}
+
+	if taskFile.Parallel {
+		var wg sync.WaitGroup
+		for i := range taskFile.Tasks {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				runTask(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range taskFile.Tasks {
+			runTask(i)
+			if results[i].ExitCode != 0 && !taskFile.ContinueOnError {
+				break
+			}
+		}
+	}
+
+	return results, exitCode, nil
+}
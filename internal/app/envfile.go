@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/dirathea/sstart/internal/provider/dotenv"
+)
+
+// EnvFileVar is the environment variable holding the path of the dotenv file written with WithEnvFile
+const EnvFileVar = "SSTART_ENV_FILE"
+
+// writeEnvFile writes envSecrets as KEY=value lines sorted by key to a new temporary file
+// readable only by the current user, and returns its path
+func writeEnvFile(envSecrets map[string]string) (string, error) {
+	keys := make([]string, 0, len(envSecrets))
+	for key := range envSecrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file := &dotenv.File{}
+	for _, key := range keys {
+		if err := file.Set(key, envSecrets[key]); err != nil {
+			return "", fmt.Errorf("failed to write env file: %w", err)
+		}
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	f, err := os.CreateTemp("", "sstart-*.env")
+	if err != nil {
+		return "", fmt.Errorf("failed to create env file: %w", err)
+	}
+	if _, err := file.WriteTo(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// prepareEnvFile writes the env file of a subprocess when WithEnvFile is set, and adds its path to env.
+// The returned function removes the file, and must be called once the subprocess has exited.
+func (r *Runner) prepareEnvFile(env []string, envSecrets map[string]string) ([]string, func(), error) {
+	if !r.envFile {
+		return env, func() {}, nil
+	}
+	path, err := writeEnvFile(envSecrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env = append(env, fmt.Sprintf("%s=%s", EnvFileVar, path))
+	if r.sortEnv {
+		env = sortEnv(env)
+	}
+	return env, func() {
+		_ = os.Remove(path)
+	}, nil
+}
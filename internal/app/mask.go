@@ -0,0 +1,134 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"sort"
+	"sync"
+)
+
+// DefaultMaskMinLength is the shortest secret value masked in the subprocess output by default,
+// so that common short values like "1" or "true" are not masked everywhere
+const DefaultMaskMinLength = 4
+
+// maskReplacement replaces every secret value found in the subprocess output
+const maskReplacement = "****"
+
+// maskFlushSize is the amount of buffered output after which a line without a newline is flushed anyway
+const maskFlushSize = 64 * 1024
+
+// maskingWriter writes to out with every occurrence of a secret value replaced by maskReplacement.
+// Output is written line by line. The end of a line is held back while it may be the start of a
+// secret value (one containing a newline), so values split across writes or lines are still masked.
+type maskingWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	values [][]byte // Longest first, so a value containing another one is masked whole
+	buf    []byte
+}
+
+// newMaskingWriter returns a maskingWriter masking the values of envSecrets at least minLength bytes long
+func newMaskingWriter(out io.Writer, envSecrets map[string]string, minLength int) *maskingWriter {
+	seen := make(map[string]bool, len(envSecrets))
+	w := &maskingWriter{out: out}
+	for _, value := range envSecrets {
+		if len(value) == 0 || len(value) < minLength || seen[value] {
+			continue
+		}
+		seen[value] = true
+		w.values = append(w.values, []byte(value))
+	}
+	sort.Slice(w.values, func(i, j int) bool {
+		return len(w.values[i]) > len(w.values[j])
+	})
+	return w
+}
+
+// Write buffers p and writes the complete lines buffered so far, masked
+func (w *maskingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	end := bytes.LastIndexByte(w.buf, '\n') + 1
+	if len(w.buf) >= maskFlushSize {
+		end = len(w.buf)
+	}
+	if end == 0 {
+		return len(p), nil
+	}
+	if err := w.writeMasked(end, true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the remaining buffered output, masked
+func (w *maskingWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeMasked(len(w.buf), false)
+}
+
+// writeMasked writes the buffered output up to end with secret values masked, and keeps the rest buffered.
+// A value starting before end is masked whole, even if it ends after end. With holdBack, output is
+// kept buffered from the first position where the buffer ends with the start of a secret value.
+func (w *maskingWriter) writeMasked(end int, holdBack bool) error {
+	var masked bytes.Buffer
+	i, plain := 0, 0
+scan:
+	for i < len(w.buf) {
+		for _, value := range w.values {
+			if bytes.HasPrefix(w.buf[i:], value) {
+				masked.Write(w.buf[plain:i])
+				masked.WriteString(maskReplacement)
+				i += len(value)
+				plain = i
+				continue scan
+			}
+		}
+		if i >= end {
+			break
+		}
+		if holdBack && w.startsValue(w.buf[i:]) {
+			break
+		}
+		i++
+	}
+	masked.Write(w.buf[plain:i])
+	w.buf = append(w.buf[:0], w.buf[i:]...)
+
+	if masked.Len() == 0 {
+		return nil
+	}
+	_, err := w.out.Write(masked.Bytes())
+	return err
+}
+
+// startsValue reports whether tail is a proper prefix of a secret value, which more output may complete
+func (w *maskingWriter) startsValue(tail []byte) bool {
+	for _, value := range w.values {
+		if len(tail) < len(value) && bytes.HasPrefix(value, tail) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskOutput routes the stdout and stderr of cmd through writers masking the values of envSecrets.
+// The returned function writes the output still buffered, and must be called once cmd has been
+// waited for. Without WithMaskOutput, cmd is left unchanged.
+func (r *Runner) maskOutput(cmd *exec.Cmd, envSecrets map[string]string) func() {
+	if !r.maskOutputs {
+		return func() {}
+	}
+	stdout := newMaskingWriter(cmd.Stdout, envSecrets, r.maskMinLength)
+	stderr := newMaskingWriter(cmd.Stderr, envSecrets, r.maskMinLength)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return func() {
+		_ = stdout.Flush()
+		_ = stderr.Flush()
+	}
+}
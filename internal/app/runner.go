@@ -6,22 +6,106 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"sort"
+	"strings"
 
+	"github.com/dirathea/sstart/internal/logger"
+	"github.com/dirathea/sstart/internal/secmem"
 	"github.com/dirathea/sstart/internal/secrets"
 )
 
 // Runner executes subprocesses with injected secrets
 type Runner struct {
-	collector *secrets.Collector
-	inherit   bool
+	collector   *secrets.Collector
+	inherit     bool
+	preserveEnv []string
+	sortEnv     bool
+
+	detectSecretsInArgs bool
+	failOnWarn          bool
+	zeroEnv             bool
+	maskOutputs         bool
+	maskMinLength       int
+	envFile             bool
+}
+
+// ExitError reports that the subprocess exited with a non-zero exit code.
+// Callers should exit with the same code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+// RunnerOption configures a Runner
+type RunnerOption func(*Runner)
+
+// WithPreserveEnv passes the named variables from the current environment through
+// to the subprocess even when inheritance is disabled. Collected secrets take precedence.
+func WithPreserveEnv(names []string) RunnerOption {
+	return func(r *Runner) {
+		r.preserveEnv = names
+	}
+}
+
+// WithSortEnv controls whether the subprocess environment is deduplicated and sorted.
+// When enabled (the default), each key appears once with its last value and keys are sorted.
+func WithSortEnv(sortEnv bool) RunnerOption {
+	return func(r *Runner) {
+		r.sortEnv = sortEnv
+	}
+}
+
+// WithDetectSecretsInArgs checks the command arguments for collected secret values before
+// starting the subprocess, since arguments are visible in process listings.
+// A match is reported as a warning, or as an error when failOnWarn is set.
+func WithDetectSecretsInArgs(detect, failOnWarn bool) RunnerOption {
+	return func(r *Runner) {
+		r.detectSecretsInArgs = detect
+		r.failOnWarn = failOnWarn
+	}
+}
+
+// WithZeroEnv zeroes the memory holding the secret entries of the subprocess environment once
+// the subprocess has started (or, for batches, once every task has finished), so sstart does
+// not keep a copy of them for the lifetime of the command
+func WithZeroEnv(zeroEnv bool) RunnerOption {
+	return func(r *Runner) {
+		r.zeroEnv = zeroEnv
+	}
+}
+
+// WithMaskOutput replaces every collected secret value at least minLength bytes long with ****
+// in the stdout and stderr of the subprocess. Output is then passed through line by line.
+func WithMaskOutput(maskOutput bool, minLength int) RunnerOption {
+	return func(r *Runner) {
+		r.maskOutputs = maskOutput
+		r.maskMinLength = minLength
+	}
+}
+
+// WithEnvFile also writes the collected secrets to a temporary dotenv file readable only by the
+// current user, for tools that read their configuration from a file. The subprocess receives its
+// path in SSTART_ENV_FILE, and the file is removed once the subprocess has exited.
+func WithEnvFile(envFile bool) RunnerOption {
+	return func(r *Runner) {
+		r.envFile = envFile
+	}
 }
 
 // NewRunner creates a new runner instance
-func NewRunner(collector *secrets.Collector, inherit bool) *Runner {
-	return &Runner{
+func NewRunner(collector *secrets.Collector, inherit bool, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		collector: collector,
 		inherit:   inherit,
+		sortEnv:   true,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // Run executes a command with injected secrets
@@ -32,32 +116,34 @@ func (r *Runner) Run(ctx context.Context, providerIDs []string, command []string
 		return fmt.Errorf("failed to collect secrets: %w", err)
 	}
 
-	// Prepare environment
-	env := os.Environ()
-	if !r.inherit {
-		env = make([]string, 0)
-	}
-
-	// Merge secrets into environment
-	for key, value := range envSecrets {
-		env = append(env, fmt.Sprintf("%s=%s", key, value))
-	}
-
 	// Prepare command
 	if len(command) == 0 {
 		return fmt.Errorf("no command specified")
 	}
 
-	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
-	cmd.Env = env
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	// Set up process group so subprocess runs in its own process group (Unix only)
-	setProcessGroup(cmd)
+	if r.detectSecretsInArgs {
+		if err := r.checkArgs(command, envSecrets); err != nil {
+			return err
+		}
+	}
+
+	env, owned := r.buildEnv(envSecrets)
+	env, removeEnvFile, err := r.prepareEnvFile(env, envSecrets)
+	if err != nil {
+		owned.Zero()
+		return err
+	}
+	// Signals are forwarded to the subprocess rather than stopping sstart, so the file
+	// is removed here once the subprocess has exited, however it was stopped
+	defer removeEnvFile()
+	cmd := r.newCommand(ctx, env, command)
+	flushOutput := r.maskOutput(cmd, envSecrets)
 
 	// Start the command
-	if err := cmd.Start(); err != nil {
+	err = cmd.Start()
+	// The environment has been copied to the subprocess
+	owned.Zero()
+	if err != nil {
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 
@@ -78,20 +164,117 @@ func (r *Runner) Run(ctx context.Context, providerIDs []string, command []string
 
 	// Wait for command to complete
 	waitErr := cmd.Wait()
+	flushOutput()
 
 	// Stop forwarding signals
 	signal.Stop(sigChan)
 	close(sigChan)
 
+	return commandResult(waitErr)
+}
+
+// commandResult converts the error from waiting for the subprocess into the error returned by Run
+func commandResult(waitErr error) error {
 	if waitErr != nil {
 		// Get exit code if available (cross-platform compatible)
 		if exitError, ok := waitErr.(*exec.ExitError); ok {
 			// ExitCode() method is available on all platforms (Go 1.12+)
-			os.Exit(exitError.ExitCode())
-			return nil
+			return &ExitError{Code: exitError.ExitCode()}
 		}
 		return waitErr
 	}
 
 	return nil
 }
+
+// checkArgs reports collected secret values found in the command arguments
+func (r *Runner) checkArgs(command []string, envSecrets map[string]string) error {
+	keys := secrets.FindSecretsInArgs(command, envSecrets)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	hint := fmt.Sprintf("reference them from the environment instead, e.g. sh -c 'cmd \"$%s\"'", keys[0])
+	if r.failOnWarn {
+		return fmt.Errorf("command arguments contain the value of secret(s) %s; %s", strings.Join(keys, ", "), hint)
+	}
+	logger.Warnf("Command arguments contain the value of secret(s) %s, which can leak via process listings; %s", strings.Join(keys, ", "), hint)
+	return nil
+}
+
+// buildEnv prepares the subprocess environment from the inherited environment and collected secrets.
+// With WithZeroEnv, the secret entries are held in memory owned by sstart and returned for zeroing.
+func (r *Runner) buildEnv(envSecrets map[string]string) ([]string, secmem.Values) {
+	env := os.Environ()
+	if !r.inherit {
+		env = make([]string, 0)
+		// Pass through explicitly preserved variables, below collected secrets
+		for _, name := range r.preserveEnv {
+			if _, isSecret := envSecrets[name]; isSecret {
+				continue
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, fmt.Sprintf("%s=%s", name, value))
+			}
+		}
+	}
+
+	// Merge secrets into environment
+	var owned secmem.Values
+	for key, value := range envSecrets {
+		if r.zeroEnv {
+			entry := secmem.New(key, "=", value)
+			owned = append(owned, entry)
+			env = append(env, entry.UnsafeString())
+			continue
+		}
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	if r.sortEnv {
+		env = sortEnv(env)
+	}
+
+	return env, owned
+}
+
+// sortEnv returns env with each key exactly once, keeping the last value, sorted by key
+func sortEnv(env []string) []string {
+	// Entries are kept whole rather than rebuilt, so no new copies of secret values are made
+	entries := make(map[string]string, len(env))
+	for _, entry := range env {
+		// Windows uses keys starting with '=' (e.g. "=C:=C:\\"), so the separator is searched after the first byte
+		if len(entry) < 2 {
+			continue
+		}
+		sep := strings.Index(entry[1:], "=")
+		if sep < 0 {
+			continue
+		}
+		entries[entry[:sep+1]] = entry
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, entries[key])
+	}
+	return sorted
+}
+
+// newCommand prepares a subprocess wired to the current stdio with the given environment
+func (r *Runner) newCommand(ctx context.Context, env []string, command []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Set up process group so subprocess runs in its own process group (Unix only)
+	setProcessGroup(cmd)
+	return cmd
+}
@@ -16,6 +16,14 @@ func setProcessGroup(cmd *exec.Cmd) {
 	}
 }
 
+// stopProcess asks the subprocess and the processes it started to terminate
+func stopProcess(cmd *exec.Cmd) {
+	// The subprocess leads its own process group, so signal the whole group
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM); err != nil {
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+	}
+}
+
 // registerSignals registers signals for Unix systems
 func registerSignals(sigChan chan os.Signal) {
 	// Register for interrupt and terminate signals
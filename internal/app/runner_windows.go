@@ -13,6 +13,11 @@ func setProcessGroup(cmd *exec.Cmd) {
 	// No-op on Windows
 }
 
+// stopProcess terminates the subprocess (Windows has no SIGTERM)
+func stopProcess(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
+
 // registerSignals registers signals for Windows systems
 func registerSignals(sigChan chan os.Signal) {
 	// On Windows, only os.Interrupt (Ctrl+C) is available
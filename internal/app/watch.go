@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dirathea/sstart/internal/logger"
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is the default window in which change events are coalesced into one re-collection
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// debounceMaxWaitWindows bounds how many debounce windows a continuous stream of triggers may delay a
+// re-collection, so triggers arriving faster than the window (e.g. an interval below it) still cause one
+const debounceMaxWaitWindows = 4
+
+// watchStopTimeout is how long a command being restarted may take to exit before it is killed
+const watchStopTimeout = 10 * time.Second
+
+// WatchOptions configures watch mode
+type WatchOptions struct {
+	Files    []string      // Local files whose changes trigger a re-collection
+	Interval time.Duration // Re-collect periodically, for providers without local files (0 disables)
+	Debounce time.Duration // Coalesce triggers arriving within this window into one re-collection
+	// Only restart when one of these keys changes, is added or is removed (default: any key)
+	RestartOn []string
+}
+
+// Watch executes a command with injected secrets and keeps it supervised: whenever a watched file
+// changes or the interval elapses, secrets are collected again and the command is restarted if they
+// changed (only the keys in RestartOn, if set). Bursts of triggers within the debounce window result in a single re-collection.
+// A burst lasting longer than a few windows still re-collects every few windows rather than only after it ends.
+// Watch returns when the command exits on its own, with the same result as Run.
+func (r *Runner) Watch(ctx context.Context, providerIDs []string, command []string, opts WatchOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	envSecrets, err := r.collector.Collect(ctx, providerIDs)
+	if err != nil {
+		return fmt.Errorf("failed to collect secrets: %w", err)
+	}
+
+	if len(command) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+
+	if r.detectSecretsInArgs {
+		if err := r.checkArgs(command, envSecrets); err != nil {
+			return err
+		}
+	}
+
+	triggers := make(chan struct{}, 1)
+	watcher, err := watchFiles(opts.Files, triggers)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if len(opts.Files) == 0 && opts.Interval <= 0 {
+		logger.Warnf("Watch mode has no local files to watch; use --watch-interval to re-collect periodically")
+	}
+	if opts.Interval > 0 {
+		go tick(ctx, opts.Interval, triggers)
+	}
+	reloads := debounce(ctx, triggers, opts.Debounce)
+
+	var mu sync.Mutex
+	cmd, done, err := r.startWatched(ctx, envSecrets, command)
+	if err != nil {
+		return err
+	}
+
+	// Forward signals to whichever command is currently running
+	sigChan := make(chan os.Signal, 1)
+	registerSignals(sigChan)
+	go func() {
+		for sig := range sigChan {
+			mu.Lock()
+			if cmd.Process != nil {
+				_ = cmd.Process.Signal(sig)
+			}
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		signal.Stop(sigChan)
+		close(sigChan)
+	}()
+
+	fingerprint := secrets.Fingerprint(restartSecrets(envSecrets, opts.RestartOn))
+	for {
+		select {
+		case waitErr := <-done:
+			return commandResult(waitErr)
+		case <-reloads:
+			newSecrets, err := r.collector.Collect(ctx, providerIDs)
+			if err != nil {
+				logger.Warnf("Failed to collect secrets, keeping the current command running: %v", err)
+				continue
+			}
+			newFingerprint := secrets.Fingerprint(restartSecrets(newSecrets, opts.RestartOn))
+			if newFingerprint == fingerprint {
+				continue
+			}
+			fingerprint = newFingerprint
+
+			fmt.Fprintln(os.Stderr, "Secrets changed, restarting command")
+			stopWatched(cmd, done)
+
+			newCmd, newDone, err := r.startWatched(ctx, newSecrets, command)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			cmd, done = newCmd, newDone
+			mu.Unlock()
+		}
+	}
+}
+
+// restartSecrets returns the secrets whose changes restart the command: those listed in keys, or all of them
+func restartSecrets(envSecrets map[string]string, keys []string) map[string]string {
+	if len(keys) == 0 {
+		return envSecrets
+	}
+	watched := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := envSecrets[key]; ok {
+			watched[key] = value
+		}
+	}
+	return watched
+}
+
+// startWatched starts the command with the given secrets and returns a channel receiving its wait result
+func (r *Runner) startWatched(ctx context.Context, envSecrets map[string]string, command []string) (*exec.Cmd, <-chan error, error) {
+	env, owned := r.buildEnv(envSecrets)
+	env, removeEnvFile, err := r.prepareEnvFile(env, envSecrets)
+	if err != nil {
+		owned.Zero()
+		return nil, nil, err
+	}
+	cmd := r.newCommand(ctx, env, command)
+	flushOutput := r.maskOutput(cmd, envSecrets)
+	err = cmd.Start()
+	// The environment has been copied to the subprocess
+	owned.Zero()
+	if err != nil {
+		removeEnvFile()
+		return nil, nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		flushOutput()
+		// Each start gets its own file, removed before the exit is reported
+		removeEnvFile()
+		done <- err
+	}()
+	return cmd, done, nil
+}
+
+// stopWatched terminates a running command, killing it if it does not exit in time
+func stopWatched(cmd *exec.Cmd, done <-chan error) {
+	stopProcess(cmd)
+	select {
+	case <-done:
+	case <-time.After(watchStopTimeout):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
+
+// fileWatcher reports changes to a set of files
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+}
+
+// watchFiles sends to triggers whenever one of the files is written, created, renamed or removed.
+// The parent directories are watched, so files replaced by editors keep being watched.
+func watchFiles(files []string, triggers chan<- struct{}) (*fileWatcher, error) {
+	if len(files) == 0 {
+		return &fileWatcher{}, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	watched := make(map[string]bool, len(files))
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			path = filepath.Clean(file)
+		}
+		watched[path] = true
+		dirs[filepath.Dir(path)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch directory '%s': %w", dir, err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !watched[filepath.Clean(event.Name)] || event.Op == fsnotify.Chmod {
+					continue
+				}
+				notify(triggers)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("File watcher error: %v", err)
+			}
+		}
+	}()
+
+	return &fileWatcher{watcher: watcher}, nil
+}
+
+// Close stops watching
+func (w *fileWatcher) Close() {
+	if w.watcher != nil {
+		_ = w.watcher.Close()
+	}
+}
+
+// tick sends to triggers every interval until ctx is done
+func tick(ctx context.Context, interval time.Duration, triggers chan<- struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notify(triggers)
+		}
+	}
+}
+
+// debounce emits one value on the returned channel once no value has arrived on in for the window,
+// or at the latest debounceMaxWaitWindows windows after the first value of a burst
+func debounce(ctx context.Context, in <-chan struct{}, window time.Duration) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	go func() {
+		var timer, maxTimer *time.Timer
+		var fire, maxFire <-chan time.Time
+		stop := func() {
+			if timer != nil {
+				timer.Stop()
+			}
+			if maxTimer != nil {
+				maxTimer.Stop()
+			}
+			fire, maxFire = nil, nil
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-in:
+				// Every new event restarts the window, but not the maximum wait of the burst
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.NewTimer(window)
+				fire = timer.C
+				if maxFire == nil {
+					maxTimer = time.NewTimer(debounceMaxWaitWindows * window)
+					maxFire = maxTimer.C
+				}
+			case <-fire:
+				stop()
+				notify(out)
+			case <-maxFire:
+				stop()
+				notify(out)
+			}
+		}
+	}()
+	return out
+}
+
+// notify sends to ch without blocking; a pending value already stands for the new one
+func notify(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -6,12 +6,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
 	"time"
 
-	"github.com/zalando/go-keyring"
+	"github.com/dirathea/sstart/internal/keyring"
 )
 
 const (
@@ -19,13 +20,27 @@ const (
 	KeyringService = "sstart-cache"
 	// DefaultTTL is the default cache TTL (5 minutes)
 	DefaultTTL = 5 * time.Minute
+	// Backend is the name of the storage backend used by the cache
+	Backend = "keyring"
 )
 
 // CachedSecrets represents cached secrets with metadata
 type CachedSecrets struct {
-	Secrets   map[string]string `json:"secrets"`
-	ExpiresAt time.Time         `json:"expires_at"`
-	CachedAt  time.Time         `json:"cached_at"`
+	Secrets    map[string]string `json:"secrets"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	CachedAt   time.Time         `json:"cached_at"`
+	ProviderID string            `json:"provider_id,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+}
+
+// Entry describes a cached provider result without its secret values
+type Entry struct {
+	Key        string
+	ProviderID string
+	Kind       string
+	KeyCount   int
+	CachedAt   time.Time
+	ExpiresAt  time.Time
 }
 
 // CacheStore represents the entire cache storage
@@ -37,7 +52,10 @@ type CacheStore struct {
 type Cache struct {
 	ttl             time.Duration
 	keyringDisabled bool
+	keyringErr      error // Set when the keyring did not respond, e.g. because it is locked
 	keyringOnce     sync.Once
+	// Serializes read-modify-write updates of the keyring store, e.g. from providers fetched concurrently
+	mu sync.Mutex
 }
 
 // Option is a functional option for configuring the Cache
@@ -83,6 +101,13 @@ func GenerateCacheKey(providerID string, kind string, config map[string]interfac
 	return hex.EncodeToString(hash[:])
 }
 
+// CustomCacheKey returns the cache key for a user-defined provider 'cache_key'.
+// It is hashed with a prefix so it can never collide with a generated key.
+func CustomCacheKey(key string) string {
+	hash := sha256.Sum256([]byte("cache_key:" + key))
+	return hex.EncodeToString(hash[:])
+}
+
 // sortedConfigString creates a deterministic string representation of config
 func sortedConfigString(config map[string]interface{}) string {
 	if config == nil {
@@ -112,6 +137,9 @@ func sortedConfigString(config map[string]interface{}) string {
 
 // Get retrieves cached secrets for a provider if they exist and are not expired
 func (c *Cache) Get(cacheKey string) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.isKeyringAvailable() {
 		return nil, false
 	}
@@ -140,6 +168,15 @@ func (c *Cache) Get(cacheKey string) (map[string]string, bool) {
 // Set stores secrets in the cache with the configured TTL.
 // If keyring is not available, this is a no-op (returns nil).
 func (c *Cache) Set(cacheKey string, secrets map[string]string) error {
+	return c.SetProvider(cacheKey, "", "", secrets)
+}
+
+// SetProvider stores secrets like Set, recording the provider id and kind
+// so the entry can be listed and cleared by provider
+func (c *Cache) SetProvider(cacheKey, providerID, kind string, secrets map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.isKeyringAvailable() {
 		// Silently skip caching when keyring is not available
 		return nil
@@ -158,9 +195,11 @@ func (c *Cache) Set(cacheKey string, secrets map[string]string) error {
 
 	now := time.Now()
 	store.Providers[cacheKey] = &CachedSecrets{
-		Secrets:   secrets,
-		CachedAt:  now,
-		ExpiresAt: now.Add(c.ttl),
+		Secrets:    secrets,
+		CachedAt:   now,
+		ExpiresAt:  now.Add(c.ttl),
+		ProviderID: providerID,
+		Kind:       kind,
 	}
 
 	return c.saveStore(store)
@@ -168,11 +207,15 @@ func (c *Cache) Set(cacheKey string, secrets map[string]string) error {
 
 // Clear removes all cached secrets
 func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.isKeyringAvailable() {
 		return nil
 	}
 
 	if err := keyring.Delete(KeyringService, "cache"); err != nil && err != keyring.ErrNotFound {
+		c.keyringFailed(err)
 		return fmt.Errorf("failed to remove cache from keyring: %w", err)
 	}
 
@@ -181,6 +224,9 @@ func (c *Cache) Clear() error {
 
 // ClearProvider removes cached secrets for a specific provider
 func (c *Cache) ClearProvider(cacheKey string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.isKeyringAvailable() {
 		return nil
 	}
@@ -194,8 +240,76 @@ func (c *Cache) ClearProvider(cacheKey string) error {
 	return c.saveStore(store)
 }
 
+// ClearProviderID removes all cached secrets recorded for the given provider id
+// and returns the number of removed entries
+func (c *Cache) ClearProviderID(providerID string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.isKeyringAvailable() {
+		return 0, nil
+	}
+
+	store := c.loadStore()
+	if store == nil {
+		return 0, nil
+	}
+
+	removed := 0
+	for key, cached := range store.Providers {
+		if cached != nil && cached.ProviderID == providerID {
+			delete(store.Providers, key)
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, c.saveStore(store)
+}
+
+// Entries lists the cached entries, including expired ones, sorted by provider id.
+// Secret values are not included.
+func (c *Cache) Entries() []Entry {
+	if !c.isKeyringAvailable() {
+		return nil
+	}
+
+	store := c.loadStore()
+	if store == nil {
+		return nil
+	}
+
+	entries := make([]Entry, 0, len(store.Providers))
+	for key, cached := range store.Providers {
+		if cached == nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:        key,
+			ProviderID: cached.ProviderID,
+			Kind:       cached.Kind,
+			KeyCount:   len(cached.Secrets),
+			CachedAt:   cached.CachedAt,
+			ExpiresAt:  cached.ExpiresAt,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ProviderID != entries[j].ProviderID {
+			return entries[i].ProviderID < entries[j].ProviderID
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
 // CleanExpired removes all expired cache entries
 func (c *Cache) CleanExpired() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.isKeyringAvailable() {
 		return nil
 	}
@@ -227,16 +341,27 @@ func (c *Cache) isKeyringAvailable() bool {
 		_, err := keyring.Get(KeyringService, "test-availability")
 		if err != nil && err != keyring.ErrNotFound {
 			c.keyringDisabled = true
+			c.keyringFailed(err)
 		}
 	})
 
 	return !c.keyringDisabled
 }
 
+// keyringFailed disables the cache after the keyring did not respond, so later operations
+// fetch from providers instead of waiting for it again
+func (c *Cache) keyringFailed(err error) {
+	if errors.Is(err, keyring.ErrTimeout) {
+		c.keyringErr = err
+		c.keyringDisabled = true
+	}
+}
+
 // loadStore loads the cache store from keyring
 func (c *Cache) loadStore() *CacheStore {
 	data, err := keyring.Get(KeyringService, "cache")
 	if err != nil {
+		c.keyringFailed(err)
 		return nil
 	}
 
@@ -258,6 +383,7 @@ func (c *Cache) saveStore(store *CacheStore) error {
 	}
 
 	if err := keyring.Set(KeyringService, "cache", string(data)); err != nil {
+		c.keyringFailed(err)
 		return fmt.Errorf("failed to save cache to keyring: %w", err)
 	}
 
@@ -299,3 +425,9 @@ func (c *Cache) Stats() (total int, valid int, expired int) {
 func (c *Cache) IsAvailable() bool {
 	return c.isKeyringAvailable()
 }
+
+// Err returns why the cache backend became unavailable when the keyring did not respond,
+// e.g. because it is locked, or nil
+func (c *Cache) Err() error {
+	return c.keyringErr
+}
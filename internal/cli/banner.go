@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/logger"
+	"github.com/dirathea/sstart/internal/oidc"
+	"golang.org/x/term"
+)
+
+// shouldPrintBanner reports whether the run banner is shown: always with --verbose,
+// and for interactive terminals unless --quiet is set
+func shouldPrintBanner() bool {
+	if logger.Enabled(logger.LevelInfo) {
+		return true
+	}
+	return logger.Enabled(logger.LevelWarn) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// printBanner writes a short summary of the run: the config file, the providers that will be
+// collected, the inherit mode and the SSO identity, if any. It never contains secret values.
+func printBanner(w io.Writer, cfg *config.Config, providerIDs []string) {
+	ids := make([]string, 0, len(cfg.Providers))
+	if len(providerIDs) > 0 {
+		for _, id := range providerIDs {
+			ids = append(ids, cfg.ResolveProviderID(id))
+		}
+	} else {
+		for _, p := range cfg.Providers {
+			ids = append(ids, p.ID)
+		}
+	}
+
+	source := configPath
+	if source == "-" {
+		source = "(stdin)"
+	}
+
+	fmt.Fprintln(w, "sstart run")
+	fmt.Fprintf(w, "  config:    %s\n", source)
+	fmt.Fprintf(w, "  providers: %d (%s)\n", len(ids), strings.Join(ids, ", "))
+	fmt.Fprintf(w, "  inherit:   %t\n", cfg.Inherit)
+	if identity := bannerIdentity(cfg); identity != "" {
+		fmt.Fprintf(w, "  sso:       %s\n", identity)
+	}
+}
+
+// bannerIdentity returns the masked SSO identity from the stored ID token, or "" without SSO
+func bannerIdentity(cfg *config.Config) string {
+	if cfg.SSO == nil || cfg.SSO.OIDC == nil {
+		return ""
+	}
+	client, err := oidc.NewClient(cfg.SSO.OIDC, oidc.WithTokenStorage(oidc.StorageBackend(cfg.SSO.TokenStorage)))
+	if err != nil {
+		return "(unavailable)"
+	}
+	identity, err := client.Whoami()
+	if err != nil {
+		return "(not authenticated)"
+	}
+
+	name := identity.Email
+	if name == "" {
+		name = identity.PreferredUsername
+	}
+	if name == "" {
+		name = identity.Subject
+	}
+	name = maskIdentity(name)
+	if identity.IsExpired() {
+		name += " (expired)"
+	}
+	return name
+}
+
+// maskIdentity keeps the first character of an identity and the domain of an email address,
+// e.g. "alice@example.com" becomes "a***@example.com"
+func maskIdentity(name string) string {
+	if name == "" {
+		return "(unknown)"
+	}
+	local, domain, isEmail := strings.Cut(name, "@")
+	masked := "***"
+	if first, size := utf8.DecodeRuneInString(local); size > 0 {
+		masked = string(first) + masked
+	}
+	if isEmail {
+		masked += "@" + domain
+	}
+	return masked
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dirathea/sstart/internal/cache"
+	"github.com/dirathea/sstart/internal/keyring"
+	"github.com/spf13/cobra"
+)
+
+var cacheClearProvider string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear cached secrets",
+	Long:  `Inspect and clear secrets cached by providers when 'cache' is enabled in the configuration.`,
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List cached entries without revealing values",
+	Long: `List the cached provider entries with their provider id, kind, number of keys,
+age and remaining TTL. Secret values are never shown.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := cache.New()
+		out := cmd.OutOrStdout()
+
+		if !c.IsAvailable() {
+			printCacheUnavailable(out, c)
+			return nil
+		}
+
+		entries := c.Entries()
+		fmt.Fprintf(out, "Backend: %s\n", cache.Backend)
+		if len(entries) == 0 {
+			fmt.Fprintln(out, "No cached entries")
+			return nil
+		}
+
+		now := time.Now()
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tKIND\tKEYS\tAGE\tTTL REMAINING")
+		for _, entry := range entries {
+			providerID, kind := entry.ProviderID, entry.Kind
+			if providerID == "" {
+				providerID = "(unknown)"
+			}
+			if kind == "" {
+				kind = "(unknown)"
+			}
+			remaining := "expired"
+			if entry.ExpiresAt.After(now) {
+				remaining = entry.ExpiresAt.Sub(now).Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", providerID, kind, entry.KeyCount, now.Sub(entry.CachedAt).Round(time.Second), remaining)
+		}
+		return w.Flush()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached secrets",
+	Long: `Remove all cached secrets, or only the entries of one provider with --provider.
+
+Example:
+  sstart cache clear
+  sstart cache clear --provider aws-prod`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := cache.New()
+		out := cmd.OutOrStdout()
+
+		if !c.IsAvailable() {
+			printCacheUnavailable(out, c)
+			return nil
+		}
+
+		if cacheClearProvider == "" {
+			if err := c.Clear(); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, "Cleared all cached secrets")
+			return nil
+		}
+
+		removed, err := c.ClearProviderID(cacheClearProvider)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Cleared %d cached entries for provider '%s'\n", removed, cacheClearProvider)
+		return nil
+	},
+}
+
+func init() {
+	cacheClearCmd.Flags().StringVar(&cacheClearProvider, "provider", "", "Only clear the entries of this provider ID")
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// printCacheUnavailable reports that the cache backend is not available, and why when the keyring did not respond
+func printCacheUnavailable(out io.Writer, c *cache.Cache) {
+	if err := c.Err(); err != nil {
+		fmt.Fprintf(out, "Cache backend '%s' is not available: %v (raise %s to wait longer)\n", cache.Backend, err, keyring.TimeoutEnvVar)
+		return
+	}
+	fmt.Fprintf(out, "Cache backend '%s' is not available\n", cache.Backend)
+}
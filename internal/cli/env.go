@@ -2,53 +2,74 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 
-	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/jsonout"
+	"github.com/dirathea/sstart/internal/provider/dotenv"
 	"github.com/dirathea/sstart/internal/secrets"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-var envFormat string
+var (
+	envFormat   string
+	envContract string
+)
 
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "Export secrets in environment variable format",
 	Long: `Export secrets in a format suitable for --env-file or shell export.
 
+With --contract, a JSON file listing the exported key names and their inferred
+types (string, number, bool or json) is written as well, without any values,
+so downstream services can validate they received the expected shape.
+
 Example:
   docker run --env-file <(sstart env) alpine sh
-  eval "$(sstart env)"`,
+  eval "$(sstart env)"
+  sstart env --format json --contract contract.json > secrets.json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
+		if err := rejectAuditStdout(cmd); err != nil {
+			return err
+		}
+
 		// Load configuration
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		// Collect secrets
-		collector := secrets.NewCollector(cfg)
-		envProviders := providers
-		if len(envProviders) == 0 {
-			envProviders = nil // Use all providers
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
 		}
-		envSecrets, err := collector.Collect(ctx, envProviders)
+
+		// Collect secrets
+		collector := secrets.NewCollector(cfg, readOnlyCollectorOptions()...)
+		defer collector.Close()
+		envSecrets, err := collector.Collect(ctx, selectedProviders)
 		if err != nil {
 			return fmt.Errorf("failed to collect secrets: %w", err)
 		}
 
+		if envContract != "" {
+			if err := secrets.WriteContract(envContract, envSecrets, prettyJSON(nil)); err != nil {
+				return err
+			}
+		}
+
 		// Export in requested format
 		switch envFormat {
 		case "json":
-			jsonBytes, err := json.MarshalIndent(envSecrets, "", "  ")
-			if err != nil {
-				return fmt.Errorf("failed to marshal JSON: %w", err)
-			}
-			fmt.Println(string(jsonBytes))
+			return writeSecretsJSON(cmd.OutOrStdout(), envSecrets, prettyJSON(cmd.OutOrStdout()))
 		case "yaml":
 			for key, value := range envSecrets {
 				fmt.Printf("%s: %s\n", key, escapeYAML(value))
@@ -63,6 +84,47 @@ Example:
 	},
 }
 
+// writeSecretsJSON writes secrets as a JSON object, indented when pretty is set. Values are written
+// exactly as collected: only JSON string escaping is applied, and HTML characters are not escaped.
+func writeSecretsJSON(w io.Writer, secrets map[string]string, pretty bool) error {
+	if err := jsonout.Encode(w, secrets, pretty); err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return nil
+}
+
+// prettyJSON reports whether JSON written to w is indented: as set by --pretty or --compact,
+// otherwise only when w is a terminal. Pass nil for a file.
+func prettyJSON(w io.Writer) bool {
+	if jsonPretty {
+		return true
+	}
+	if jsonCompact {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// writeSecretsDotenv writes secrets as KEY=value lines sorted by key, quoted so the file can be
+// read back by dotenv parsers and sourced by a POSIX shell
+func writeSecretsDotenv(w io.Writer, secrets map[string]string) error {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file := &dotenv.File{}
+	for _, key := range keys {
+		if err := file.Set(key, secrets[key]); err != nil {
+			return fmt.Errorf("failed to write dotenv file: %w", err)
+		}
+	}
+	_, err := file.WriteTo(w)
+	return err
+}
+
 func escapeShell(s string) string {
 	// Escape single quotes by ending the quoted string, escaping the quote, and restarting
 	s = strings.ReplaceAll(s, "'", "'\"'\"'")
@@ -83,6 +145,7 @@ func escapeYAML(s string) string {
 
 func init() {
 	envCmd.Flags().StringVar(&envFormat, "format", "shell", "Output format: shell, json, or yaml")
-	envCmd.Flags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated list of provider IDs to use (default: all providers)")
+	envCmd.Flags().StringVar(&envContract, "contract", "", "Also write the key names and inferred types (no values) as JSON to this file")
+	envCmd.Flags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
 	rootCmd.AddCommand(envCmd)
 }
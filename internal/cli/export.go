@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOut    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print collected secrets as a JSON object or dotenv file",
+	Long: `Collect secrets and print them to stdout as a single JSON object, without
+starting a process, so other tools can use sstart as a secret source.
+
+Values are emitted exactly as collected, with JSON string escaping only (no shell
+quoting). The output is the same as 'sstart env --format json'.
+
+With --format dotenv, one KEY=value line is written per secret, sorted by key.
+Values are quoted so the file can be read back by dotenv parsers and sourced by
+a POSIX shell. With --out, the output is written to a file with 0600 permissions
+instead of stdout.
+
+Example:
+  sstart export > secrets.json
+  sstart export --providers vault-prod | jq -r .DB_PASSWORD
+  sstart export --format dotenv --out .env.generated`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if exportFormat != "json" && exportFormat != "dotenv" {
+			return fmt.Errorf("unsupported format '%s' (supported: json, dotenv)", exportFormat)
+		}
+		if exportOut == "" {
+			if err := rejectAuditStdout(cmd); err != nil {
+				return err
+			}
+		}
+
+		// Load configuration
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
+		// Collect secrets
+		collector := secrets.NewCollector(cfg, collectorOptions()...)
+		defer collector.Close()
+		exportSecrets, err := collector.Collect(ctx, selectedProviders)
+		if err != nil {
+			return fmt.Errorf("failed to collect secrets: %w", err)
+		}
+
+		// Render fully before writing, so a failure never leaves a partial file behind
+		var buf bytes.Buffer
+		if exportFormat == "dotenv" {
+			err = writeSecretsDotenv(&buf, exportSecrets)
+		} else {
+			var out io.Writer
+			if exportOut == "" {
+				out = cmd.OutOrStdout()
+			}
+			err = writeSecretsJSON(&buf, exportSecrets, prettyJSON(out))
+		}
+		if err != nil {
+			return err
+		}
+
+		if exportOut != "" {
+			if err := os.WriteFile(exportOut, buf.Bytes(), 0600); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+			return nil
+		}
+		_, err = cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "Output format: json or dotenv")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "Write the output to this file (0600) instead of stdout")
+	exportCmd.Flags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fingerprintBaseline        string
+	fingerprintFailIfChanged   bool
+	fingerprintFailIfUnchanged bool
+	fingerprintWriteBaseline   bool
+)
+
+var fingerprintCmd = &cobra.Command{
+	Use:   "fingerprint",
+	Short: "Print a fingerprint of the collected secrets",
+	Long: `Print a SHA-256 fingerprint of the collected secret set. The fingerprint changes
+whenever a key is added, removed or a value changes, and reveals no secret values.
+
+With --baseline, the fingerprint is compared to the one stored in a baseline file,
+so CI can gate deployments on whether secrets changed. A missing baseline file
+counts as changed. --write-baseline stores the current fingerprint after the
+comparison, so a later run passes once the change has been approved.
+
+Example:
+  sstart fingerprint
+  sstart fingerprint --baseline .sstart.fingerprint --fail-if-changed
+  sstart fingerprint --baseline .sstart.fingerprint --write-baseline`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if fingerprintFailIfChanged && fingerprintFailIfUnchanged {
+			return fmt.Errorf("--fail-if-changed and --fail-if-unchanged cannot be used together")
+		}
+		if fingerprintBaseline == "" && (fingerprintFailIfChanged || fingerprintFailIfUnchanged || fingerprintWriteBaseline) {
+			return fmt.Errorf("--baseline is required with --fail-if-changed, --fail-if-unchanged and --write-baseline")
+		}
+		if err := rejectAuditStdout(cmd); err != nil {
+			return err
+		}
+
+		// Load configuration
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
+		// Collect secrets
+		collector := secrets.NewCollector(cfg, readOnlyCollectorOptions()...)
+		defer collector.Close()
+		envSecrets, err := collector.Collect(ctx, selectedProviders)
+		if err != nil {
+			return fmt.Errorf("failed to collect secrets: %w", err)
+		}
+
+		fingerprint := secrets.Fingerprint(envSecrets)
+		fmt.Println(fingerprint)
+
+		if fingerprintBaseline == "" {
+			return nil
+		}
+
+		baseline, err := secrets.ReadBaseline(fingerprintBaseline)
+		if err != nil {
+			return err
+		}
+		changed := baseline != fingerprint
+		if changed {
+			fmt.Fprintf(os.Stderr, "Secrets changed relative to baseline %s\n", fingerprintBaseline)
+		} else {
+			fmt.Fprintf(os.Stderr, "Secrets unchanged relative to baseline %s\n", fingerprintBaseline)
+		}
+
+		if fingerprintWriteBaseline {
+			if err := secrets.WriteBaseline(fingerprintBaseline, fingerprint); err != nil {
+				return err
+			}
+		}
+
+		if changed && fingerprintFailIfChanged {
+			return fmt.Errorf("secrets changed relative to baseline %s", fingerprintBaseline)
+		}
+		if !changed && fingerprintFailIfUnchanged {
+			return fmt.Errorf("secrets unchanged relative to baseline %s", fingerprintBaseline)
+		}
+		return nil
+	},
+}
+
+func init() {
+	fingerprintCmd.Flags().StringVar(&fingerprintBaseline, "baseline", "", "Path to a baseline fingerprint file to compare against")
+	fingerprintCmd.Flags().BoolVar(&fingerprintFailIfChanged, "fail-if-changed", false, "Exit with an error if the fingerprint differs from the baseline")
+	fingerprintCmd.Flags().BoolVar(&fingerprintFailIfUnchanged, "fail-if-unchanged", false, "Exit with an error if the fingerprint matches the baseline")
+	fingerprintCmd.Flags().BoolVar(&fingerprintWriteBaseline, "write-baseline", false, "Write the current fingerprint to the baseline file")
+	rootCmd.AddCommand(fingerprintCmd)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getRaw     bool
+	getNewline bool
+	getBase64  bool
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the value of a single secret",
+	Long: `Print the unmasked value of a single collected secret, for use in scripts.
+
+By default the value is written as-is, without a trailing newline (--raw).
+Use --newline to append one, and --base64 to emit the value base64-encoded
+for safe transport of binary or multiline values.
+
+Example:
+  DB_PASSWORD="$(sstart get DB_PASSWORD)"
+  sstart get TLS_KEY --base64 --newline`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		key := args[0]
+
+		if getNewline && getRaw && cmd.Flags().Changed("raw") {
+			return fmt.Errorf("--raw and --newline cannot be used together")
+		}
+		if err := rejectAuditStdout(cmd); err != nil {
+			return err
+		}
+
+		// Load configuration
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
+		// Collect secrets
+		collector := secrets.NewCollector(cfg, readOnlyCollectorOptions()...)
+		defer collector.Close()
+		envSecrets, err := collector.Collect(ctx, selectedProviders)
+		if err != nil {
+			return fmt.Errorf("failed to collect secrets: %w", err)
+		}
+
+		value, ok := envSecrets[key]
+		if !ok {
+			return fmt.Errorf("secret '%s' not found", key)
+		}
+
+		if getBase64 {
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		if getNewline || !getRaw {
+			value += "\n"
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), value)
+		return err
+	},
+}
+
+func init() {
+	getCmd.Flags().BoolVar(&getRaw, "raw", true, "Print the value without a trailing newline (default)")
+	getCmd.Flags().BoolVar(&getNewline, "newline", false, "Append a trailing newline to the value")
+	getCmd.Flags().BoolVar(&getBase64, "base64", false, "Print the value base64-encoded")
+	rootCmd.AddCommand(getCmd)
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginIdentity  string
+	logoutIdentity string
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Authenticate with SSO and store the tokens",
+	Long: `Run the SSO login flow and store the tokens, so that later commands use them without logging in.
+
+The interactive flow is selected with --auth-flow. When a client secret is available
+(SSTART_SSO_SECRET or the keyring), the non-interactive client credentials flow is used instead.
+On success, the token storage backend and the token expiry are printed.
+
+Example:
+  sstart login
+  sstart login --auth-flow device
+  sstart login --identity cluster-b`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if err := oidc.ValidateAuthFlow(oidc.AuthFlow(authFlow)); err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		client, err := newSSOClient(cfg, loginIdentity)
+		if err != nil {
+			return err
+		}
+		if oidc.StorageBackend(cfg.SSO.TokenStorage) == oidc.StorageBackendMemory {
+			return fmt.Errorf("token storage 'memory' does not keep tokens after sstart exits; use auto, keyring or file to log in")
+		}
+
+		var result *oidc.AuthResult
+		if client.HasClientCredentials() {
+			result, err = client.LoginWithClientCredentials(ctx)
+		} else {
+			result, err = client.LoginWithFlow(ctx, oidc.AuthFlow(authFlow))
+		}
+		if err != nil {
+			return fmt.Errorf("SSO login failed: %w", err)
+		}
+
+		// Login only warns when the tokens cannot be saved, but storing them is the point here
+		if !client.TokensExist() {
+			if err := client.StorageError(); err != nil {
+				return fmt.Errorf("SSO login succeeded but the tokens could not be stored: %w", err)
+			}
+			return fmt.Errorf("SSO login succeeded but the tokens could not be stored (token storage: %s)", tokenStorageName(cfg))
+		}
+
+		printField := func(name, value string) {
+			if value != "" {
+				fmt.Printf("%-10s %s\n", name+":", value)
+			}
+		}
+		fmt.Println("Logged in.")
+		if loginIdentity != "" {
+			printField("Identity", loginIdentity)
+		}
+		printField("Issuer", cfg.SSO.Identity(loginIdentity).Issuer)
+		if result.UserInfo != nil {
+			printField("Email", result.UserInfo.Email)
+			printField("Username", result.UserInfo.PreferredUsername)
+		}
+		storage := string(client.GetStorageBackend())
+		if client.GetStorageBackend() == oidc.StorageBackendFile {
+			storage += " (" + client.GetTokenPath() + ")"
+		}
+		printField("Storage", storage)
+		if result.Tokens != nil && !result.Tokens.Expiry.IsZero() {
+			printField("Expires", result.Tokens.Expiry.Local().Format(time.RFC1123))
+		}
+		return nil
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored SSO tokens",
+	Long: `Remove the SSO tokens stored by a login from the keyring and the token file.
+
+Secrets already cached with 'cache' stay cached; remove them with 'sstart cache clear'.
+
+Example:
+  sstart logout
+  sstart logout --identity cluster-b`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		client, err := newSSOClient(cfg, logoutIdentity)
+		if err != nil {
+			return err
+		}
+
+		if !client.TokensExist() {
+			fmt.Println("Not logged in.")
+			return nil
+		}
+		if err := client.ClearTokens(); err != nil {
+			return fmt.Errorf("failed to remove SSO tokens: %w", err)
+		}
+		fmt.Println("Logged out.")
+		return nil
+	},
+}
+
+// newSSOClient creates the SSO client of the named identity of the config (the default identity when empty)
+func newSSOClient(cfg *config.Config, identity string) (*oidc.Client, error) {
+	if cfg.SSO == nil || cfg.SSO.Identity(identity) == nil {
+		if identity == "" || identity == config.DefaultSSOIdentity {
+			return nil, fmt.Errorf("sso.oidc configuration not found in config file")
+		}
+		return nil, fmt.Errorf("sso identity '%s' not found in config file", identity)
+	}
+
+	client, err := oidc.NewClient(cfg.SSO.Identity(identity), oidc.WithTokenStorage(oidc.StorageBackend(cfg.SSO.TokenStorage)), oidc.WithIdentity(identity))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSO client: %w", err)
+	}
+	return client, nil
+}
+
+// tokenStorageName returns the configured token storage, naming the default
+func tokenStorageName(cfg *config.Config) string {
+	if cfg.SSO.TokenStorage == "" {
+		return string(oidc.StorageBackendAuto)
+	}
+	return cfg.SSO.TokenStorage
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginIdentity, "identity", "", "Name of the SSO identity to log in with, a key of sso.oidc (default: the default identity)")
+	logoutCmd.Flags().StringVar(&logoutIdentity, "identity", "", "Name of the SSO identity to log out from, a key of sso.oidc (default: the default identity)")
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -7,7 +7,6 @@ import (
 	"os/signal"
 	"syscall"
 
-	"github.com/dirathea/sstart/internal/config"
 	"github.com/dirathea/sstart/internal/mcp"
 	"github.com/dirathea/sstart/internal/secrets"
 	"github.com/spf13/cobra"
@@ -41,6 +40,10 @@ Example configuration (.sstart.yml):
       - id: filesystem
         command: npx
         args: ["@modelcontextprotocol/server-filesystem", "/allowed/path"]
+      - id: remote
+        url: https://mcp.example.com/mcp
+        headers:
+          Authorization: "Bearer {{.API_TOKEN}}"
 
 Example usage in Claude Desktop config:
   {
@@ -52,6 +55,11 @@ Example usage in Claude Desktop config:
     }
   }`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// The proxy talks to the client over stdout
+		if err := rejectAuditStdout(cmd); err != nil {
+			return err
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
@@ -64,19 +72,26 @@ Example usage in Claude Desktop config:
 		}()
 
 		// Load configuration
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
 		// Validate MCP configuration is present
 		if !cfg.HasMCP() {
 			return fmt.Errorf("mcp configuration not found in config file")
 		}
 
 		// Collect secrets from providers
-		collector := secrets.NewCollector(cfg, secrets.WithForceAuth(forceAuth))
-		collectedSecrets, err := collector.Collect(ctx, providers)
+		collector := secrets.NewCollector(cfg, collectorOptions()...)
+		defer collector.Close()
+		collectedSecrets, err := collector.Collect(ctx, selectedProviders)
 		if err != nil {
 			return fmt.Errorf("failed to collect secrets: %w", err)
 		}
@@ -85,9 +100,16 @@ Example usage in Claude Desktop config:
 		serverConfigs := make([]mcp.ServerConfig, 0, len(cfg.MCP.Servers))
 		for _, s := range cfg.MCP.Servers {
 			serverConfig := mcp.ServerConfig{
-				ID:      s.ID,
-				Command: s.Command,
-				Args:    s.Args,
+				ID:              s.ID,
+				Command:         s.Command,
+				Args:            s.Args,
+				URL:             s.URL,
+				Headers:         s.Headers,
+				Secrets:         s.Secrets,
+				ShutdownTimeout: s.ShutdownTimeout,
+				Restart:         s.Restart,
+				MaxRestarts:     s.MaxRestarts,
+				RestartBackoff:  s.RestartBackoff,
 			}
 			serverConfigs = append(serverConfigs, serverConfig)
 		}
@@ -100,6 +122,9 @@ Example usage in Claude Desktop config:
 
 		// Create and run the proxy
 		proxy := mcp.NewProxy(manager, transport, GetVersion())
+		if cfg.MCP.NamespaceSeparator != "" {
+			proxy.SetNamespaceSeparator(cfg.MCP.NamespaceSeparator)
+		}
 
 		// Run proxy (blocks until context is cancelled or EOF)
 		err = proxy.Run(ctx)
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check that provider backends are reachable, without fetching secrets",
+	Long: `Probe every selected provider and report its status and latency, without fetching
+secret values: Vault reads sys/health, 1Password lists the vaults of the service account,
+AWS Secrets Manager describes the secret and dotenv opens its file. Other provider kinds
+are reported as unsupported. SSO authentication runs first when configured, as providers
+may need its tokens.
+
+The command fails if any probe fails.
+
+Example:
+  sstart ping
+  sstart ping --providers vault-prod,aws-prod`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Load configuration
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
+		collector := secrets.NewCollector(cfg, collectorOptions()...)
+		defer collector.Close()
+		results, err := collector.Ping(ctx, selectedProviders)
+		if err != nil {
+			return err
+		}
+		if err := writePingResults(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, result := range results {
+			if result.Status == secrets.ProbeFailed {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d providers failed the probe", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pingCmd)
+}
+
+// writePingResults writes one line per probed provider with its status, latency and what was checked,
+// or the error of a failed probe
+func writePingResults(out io.Writer, results []secrets.ProbeResult) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tKIND\tSTATUS\tLATENCY\tDETAIL")
+	for _, result := range results {
+		latency, detail := "-", result.Detail
+		if result.Status != secrets.ProbeUnsupported {
+			latency = result.Latency.Round(time.Millisecond).String()
+		}
+		if result.Err != nil {
+			detail = result.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Provider, result.Kind, result.Status, latency, detail)
+	}
+	return w.Flush()
+}
@@ -2,7 +2,11 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	_ "github.com/dirathea/sstart/internal/provider/aws"
 	_ "github.com/dirathea/sstart/internal/provider/bitwarden"
@@ -10,11 +14,18 @@ import (
 	_ "github.com/dirathea/sstart/internal/provider/dotenv"
 	_ "github.com/dirathea/sstart/internal/provider/gcsm"
 	_ "github.com/dirathea/sstart/internal/provider/infisical"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
 	_ "github.com/dirathea/sstart/internal/provider/onepassword"
+	_ "github.com/dirathea/sstart/internal/provider/prompt"
+	_ "github.com/dirathea/sstart/internal/provider/sqlite"
 	_ "github.com/dirathea/sstart/internal/provider/template"
 	_ "github.com/dirathea/sstart/internal/provider/vault"
+	_ "github.com/dirathea/sstart/internal/transform/gcpkms"
 	"github.com/dirathea/sstart/internal/app"
 	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/logger"
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/dirathea/sstart/internal/secmem"
 	"github.com/dirathea/sstart/internal/secrets"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +35,29 @@ var (
 	verbose    bool
 	providers  []string
 	forceAuth  bool
+	requireSSO bool
+	authFlow   string
+	strictKeys bool
+	quiet      bool
+	noDump     bool
+
+	providersFromEnv string
+	tokenStorage     string
+	maxExecProviders int
+	concurrency      int
+	providerTimeout  time.Duration
+	collectTimeout   time.Duration
+	auditStdout      bool
+	traceFile        string
+	explainCollision bool
+
+	configFormat string
+	jsonPretty   bool
+	jsonCompact  bool
+
+	expandJSON    bool
+	noExpandJSON  bool
+	expandJSONSet bool
 )
 
 var rootCmd = &cobra.Command{
@@ -48,27 +82,180 @@ Examples:
 		ctx := context.Background()
 
 		// Load configuration
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
+		}
+
 		// Create collector and runner
-		collector := secrets.NewCollector(cfg, secrets.WithForceAuth(forceAuth))
-		runner := app.NewRunner(collector, cfg.Inherit)
+		collector := secrets.NewCollector(cfg, collectorOptions()...)
+		runner := app.NewRunner(collector, cfg.Inherit, app.WithZeroEnv(noDump))
+		defer collector.Close()
+
+		if shouldPrintBanner() {
+			printBanner(os.Stderr, cfg, selectedProviders)
+		}
 
 		// Run the command
-		return runner.Run(ctx, providers, args)
+		return silenceExitError(cmd, runner.Run(ctx, selectedProviders, args))
 	},
 }
 
+// loadConfig loads the configuration from --config, honoring --config-format
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadWithFormat(configPath, configFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	// --token-storage takes precedence over sso.token_storage
+	if tokenStorage != "" {
+		if err := oidc.ValidateStorageBackend(oidc.StorageBackend(tokenStorage)); err != nil {
+			return nil, err
+		}
+		if cfg.SSO != nil {
+			cfg.SSO.TokenStorage = tokenStorage
+		}
+	}
+	return cfg, nil
+}
+
+// DefaultProvidersEnv is the environment variable read for provider selectors when --providers is not set
+const DefaultProvidersEnv = "SSTART_PROVIDERS"
+
+// selectProviders returns the provider ids selected by a --providers flag value or, when the flag is not set,
+// by the comma-separated selectors in the environment variable named by --providers-from-env.
+// It returns nil to collect every provider.
+func selectProviders(cfg *config.Config, flagValue []string) ([]string, error) {
+	selectors := flagValue
+	if len(selectors) == 0 && providersFromEnv != "" {
+		for _, selector := range strings.Split(os.Getenv(providersFromEnv), ",") {
+			if selector = strings.TrimSpace(selector); selector != "" {
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	return cfg.SelectProviders(selectors)
+}
+
+// collectorOptions returns the collector options derived from global flags
+func collectorOptions() []secrets.CollectorOption {
+	opts := []secrets.CollectorOption{
+		secrets.WithForceAuth(forceAuth),
+		secrets.WithRequireSSO(requireSSO),
+		secrets.WithAuthFlow(oidc.AuthFlow(authFlow)),
+		secrets.WithStrictKeys(strictKeys),
+		secrets.WithMaxExecProviders(maxExecProviders),
+		secrets.WithConcurrency(concurrency),
+		secrets.WithTimeout(providerTimeout),
+		secrets.WithCollectionTimeout(collectTimeout),
+	}
+	if traceFile != "" {
+		opts = append(opts, secrets.WithTraceFile(traceFile))
+	}
+	if explainCollision {
+		opts = append(opts, secrets.WithExplainCollisions(os.Stderr))
+	}
+	if auditStdout {
+		opts = append(opts, secrets.WithEventHandler(secrets.JSONEventWriter(os.Stdout)))
+	}
+	if noExpandJSON {
+		opts = append(opts, secrets.WithExpandJSON(false))
+	} else if expandJSONSet {
+		opts = append(opts, secrets.WithExpandJSON(expandJSON))
+	}
+	return opts
+}
+
+// readOnlyCollectorOptions returns the collector options of commands that only inspect secrets, such as
+// show or run --dry-run: providers render their files without writing them
+func readOnlyCollectorOptions() []secrets.CollectorOption {
+	return append(collectorOptions(), secrets.WithFileWrites(false))
+}
+
+// rejectAuditStdout fails commands that write their own output to stdout, which the events of
+// --audit-stdout would corrupt
+func rejectAuditStdout(cmd *cobra.Command) error {
+	if auditStdout {
+		return fmt.Errorf("--audit-stdout cannot be used with '%s', which writes its output to stdout", cmd.Name())
+	}
+	return nil
+}
+
+// silenceExitError keeps cobra from printing a subprocess exit code as an error with usage
+func silenceExitError(cmd *cobra.Command, err error) error {
+	var exitErr *app.ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// exitWithCode returns an error that makes Execute exit with the given code
+func exitWithCode(cmd *cobra.Command, code int) error {
+	return silenceExitError(cmd, &app.ExitError{Code: code})
+}
+
+// Execute runs the root command, prints the warning summary and propagates subprocess exit codes
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+
+	logger.PrintSummary()
+
+	var exitErr *app.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+	return err
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", ".sstart.yml", "Path to configuration file")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.PersistentFlags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated list of provider IDs to use (default: all providers)")
+	cobra.OnInitialize(func() {
+		if quiet {
+			logger.SetLevel(logger.LevelQuiet)
+		} else if verbose {
+			logger.SetLevel(logger.LevelInfo)
+		}
+		expandJSONSet = rootCmd.PersistentFlags().Changed("expand-json")
+		if noDump {
+			if err := secmem.DisableCoreDumps(); err != nil {
+				logger.Warnf("Failed to disable core dumps: %v", err)
+			}
+		}
+	})
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", ".sstart.yml", "Path to configuration file (use - to read from stdin)")
+	rootCmd.PersistentFlags().StringVar(&configFormat, "config-format", "", "Configuration format: yaml or json (default: detected from the file extension)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output, including the run banner")
+	rootCmd.PersistentFlags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
+	rootCmd.PersistentFlags().StringVar(&providersFromEnv, "providers-from-env", DefaultProvidersEnv, "Environment variable holding provider selectors, used when --providers is not set (empty to ignore the environment)")
 	rootCmd.PersistentFlags().BoolVar(&forceAuth, "force-auth", false, "Force re-authentication, ignoring cached SSO tokens")
+	rootCmd.PersistentFlags().BoolVar(&requireSSO, "require-sso", false, "Fail before fetching secrets unless a valid or refreshable SSO token is stored")
+	rootCmd.PersistentFlags().StringVar(&authFlow, "auth-flow", string(oidc.AuthFlowAuto), "Interactive SSO login flow: browser, device (enter a code on another device) or auto (device when no browser can be opened)")
+	rootCmd.PersistentFlags().StringVar(&tokenStorage, "token-storage", "", "Where SSO tokens are stored: auto (keyring, falling back to a file), keyring, file or memory (never persisted) (overrides sso.token_storage)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress warnings and the warning summary")
+	rootCmd.PersistentFlags().BoolVar(&noDump, "no-dump", false, "Disable core dumps of sstart and zero its copy of the command environment once the command has started (best-effort)")
+	rootCmd.PersistentFlags().BoolVar(&strictKeys, "strict-keys", false, "Fail when a provider returns source keys not listed in its 'keys' mapping")
+	rootCmd.PersistentFlags().IntVar(&maxExecProviders, "max-exec-providers", secrets.DefaultMaxExecProviders, "Maximum number of process-spawning providers (bitwarden, 1password_cli) fetching at once (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", secrets.DefaultConcurrency, "Maximum number of providers fetching at once (0 for no limit, 1 to fetch one at a time)")
+	rootCmd.PersistentFlags().DurationVar(&providerTimeout, "provider-timeout", 0, "Maximum duration of each provider fetch, for providers without a 'timeout' (0 for no timeout)")
+	rootCmd.PersistentFlags().DurationVar(&collectTimeout, "collection-timeout", 0, "Maximum duration of fetching all providers, failing before the command is started (0 for no timeout)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Write the provider of every collected key (no values) to this file, for diffing between runs")
+	rootCmd.PersistentFlags().BoolVar(&explainCollision, "explain-collision", false, "Report keys supplied by several providers (masked values, which one was kept and why) and collisions that failed a provider to stderr")
+	rootCmd.PersistentFlags().BoolVar(&auditStdout, "audit-stdout", false, "Stream provider access events (provider, key names, outcome; never values) to stdout as JSON lines (not with commands writing their output to stdout, such as env or get)")
+	rootCmd.PersistentFlags().BoolVar(&expandJSON, "expand-json", true, "Expand JSON secrets into one key per field, unless a provider sets expand_json (overrides the config)")
+	rootCmd.PersistentFlags().BoolVar(&noExpandJSON, "no-expand-json", false, "Load JSON secrets as a single value, unless a provider sets expand_json (same as --expand-json=false)")
+	rootCmd.PersistentFlags().BoolVar(&jsonPretty, "pretty", false, "Indent JSON outputs (default: only when writing to a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&jsonCompact, "compact", false, "Write JSON outputs on a single line")
+	rootCmd.MarkFlagsMutuallyExclusive("expand-json", "no-expand-json")
+	rootCmd.MarkFlagsMutuallyExclusive("pretty", "compact")
 }
@@ -3,15 +3,36 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/dirathea/sstart/internal/app"
-	"github.com/dirathea/sstart/internal/config"
 	"github.com/dirathea/sstart/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
 var (
-	runProviders []string
+	runProviders   []string
+	runPreserveEnv []string
+	runSortEnv     bool
+
+	runDetectSecretsInArgs bool
+	runFailOnWarn          bool
+
+	runWatch         bool
+	runWatchInterval time.Duration
+	runWatchDebounce time.Duration
+	runRestartOn     []string
+	runPrewarm       bool
+
+	runDryRun bool
+
+	runMaskOutput    bool
+	runMaskMinLength int
+
+	runEnvFile bool
 )
 
 var runCmd = &cobra.Command{
@@ -19,29 +40,164 @@ var runCmd = &cobra.Command{
 	Short: "Run a command with injected secrets",
 	Long: `Run a command with secrets automatically injected from configured providers.
 
+With --watch, secrets are collected again whenever a local file read by a provider
+(e.g. a dotenv file) changes, and the command is restarted if the secrets changed.
+--watch-interval also re-collects periodically, for remote providers. Changes arriving
+within --watch-debounce of each other are coalesced into a single restart; changes that
+keep arriving still re-collect at least every 4 debounce windows.
+--prewarm sets up provider clients and logins (SSO, Vault, AWS, 1Password) before the
+first collection and keeps them for every reload.
+
+With --dry-run, secrets are collected as usual but no command is started: the environment
+variables that would be set are listed, sorted, with their source provider, the length of
+their value and a short fingerprint of it. Values are never printed.
+
+With --mask-output, every collected secret value in the command's stdout and stderr is
+replaced with ****, including values split across writes. Output is then passed through
+line by line, and values shorter than --mask-min-length are left as is.
+
+With --env-file, the secrets are also written to a temporary dotenv file readable only by
+the current user, for tools that read their configuration from a file. The command finds
+its path in SSTART_ENV_FILE; the file is removed when the command exits, including when
+it is stopped by a signal forwarded by sstart.
+
+In a terminal, a short banner (config file, providers, inherit mode, SSO identity) is
+printed to stderr before the command starts; --quiet hides it and --verbose always shows it.
+
 Example:
   sstart run -- node index.js
-  sstart run --providers aws-prod,dotenv-dev -- node index.js`,
-	Args: cobra.MinimumNArgs(1),
+  sstart run --providers aws-prod,dotenv-dev -- node index.js
+  sstart run --preserve-env PATH --preserve-env HOME -- node index.js
+  sstart run --watch --watch-interval 5m -- node index.js
+  sstart run --mask-output -- ./deploy.sh
+  sstart run --env-file -- sh -c 'dotenv-linter "$SSTART_ENV_FILE"'
+  sstart run --dry-run`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if runDryRun {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
+		watch := runWatch || runWatchInterval > 0
+		if !watch && cmd.Flags().Changed("watch-debounce") {
+			return fmt.Errorf("--watch-debounce requires --watch or --watch-interval")
+		}
+		if !watch && len(runRestartOn) > 0 {
+			return fmt.Errorf("--restart-on requires --watch or --watch-interval")
+		}
+		if !watch && runPrewarm {
+			return fmt.Errorf("--prewarm requires --watch or --watch-interval")
+		}
+		if watch && runDryRun {
+			return fmt.Errorf("--dry-run cannot be used with --watch or --watch-interval")
+		}
+		if !runMaskOutput && cmd.Flags().Changed("mask-min-length") {
+			return fmt.Errorf("--mask-min-length requires --mask-output")
+		}
+		if runMaskMinLength < 1 {
+			return fmt.Errorf("--mask-min-length must be at least 1")
+		}
+		if runWatchInterval < 0 || runWatchDebounce < 0 {
+			return fmt.Errorf("--watch-interval and --watch-debounce must not be negative")
+		}
+
 		// Load configuration
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		// Create collector and runner
-		collector := secrets.NewCollector(cfg, secrets.WithForceAuth(forceAuth))
-		runner := app.NewRunner(collector, cfg.Inherit)
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, runProviders)
+		if err != nil {
+			return err
+		}
+
+		// Create collector and runner; a dry run leaves the files rendered by providers untouched
+		opts := collectorOptions()
+		if runDryRun {
+			opts = readOnlyCollectorOptions()
+		}
+		collector := secrets.NewCollector(cfg, opts...)
+		runner := app.NewRunner(collector, cfg.Inherit,
+			app.WithPreserveEnv(runPreserveEnv),
+			app.WithSortEnv(runSortEnv),
+			app.WithDetectSecretsInArgs(runDetectSecretsInArgs, runFailOnWarn),
+			app.WithZeroEnv(noDump),
+			app.WithMaskOutput(runMaskOutput, runMaskMinLength),
+			app.WithEnvFile(runEnvFile),
+		)
+		defer collector.Close()
+
+		if runDryRun {
+			envSecrets, err := collector.Collect(ctx, selectedProviders)
+			if err != nil {
+				return fmt.Errorf("failed to collect secrets: %w", err)
+			}
+			return writeDryRun(os.Stdout, envSecrets, collector.Provenance())
+		}
+
+		if shouldPrintBanner() {
+			printBanner(os.Stderr, cfg, selectedProviders)
+		}
+
+		if watch {
+			if runPrewarm {
+				if err := collector.Prewarm(ctx, selectedProviders); err != nil {
+					return err
+				}
+			}
+			files, err := collector.SourceFiles(selectedProviders)
+			if err != nil {
+				return err
+			}
+			return silenceExitError(cmd, runner.Watch(ctx, selectedProviders, args, app.WatchOptions{
+				Files:     files,
+				Interval:  runWatchInterval,
+				Debounce:  runWatchDebounce,
+				RestartOn: runRestartOn,
+			}))
+		}
 
 		// Run the command
-		return runner.Run(ctx, runProviders, args)
+		return silenceExitError(cmd, runner.Run(ctx, selectedProviders, args))
 	},
 }
 
 func init() {
-	runCmd.Flags().StringSliceVar(&runProviders, "providers", []string{}, "Comma-separated list of provider IDs to use (default: all providers)")
+	runCmd.Flags().StringSliceVar(&runProviders, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
+	runCmd.Flags().StringArrayVar(&runPreserveEnv, "preserve-env", []string{}, "Environment variable to pass through when 'inherit' is false (repeatable)")
+	runCmd.Flags().BoolVar(&runSortEnv, "sort-env", true, "Pass the subprocess environment with each key once (last value wins), sorted by key")
+	runCmd.Flags().BoolVar(&runDetectSecretsInArgs, "detect-secrets-in-args", false, "Warn when the command arguments contain a collected secret value")
+	runCmd.Flags().BoolVar(&runFailOnWarn, "fail-on-warn", false, "Fail instead of warning when --detect-secrets-in-args finds a secret")
+	runCmd.Flags().BoolVar(&runWatch, "watch", false, "Re-collect secrets when a provider's local file changes and restart the command if they changed")
+	runCmd.Flags().DurationVar(&runWatchInterval, "watch-interval", 0, "Also re-collect secrets periodically at this interval (implies --watch)")
+	runCmd.Flags().DurationVar(&runWatchDebounce, "watch-debounce", app.DefaultWatchDebounce, "Coalesce changes arriving within this window into a single re-collection")
+	runCmd.Flags().StringArrayVar(&runRestartOn, "restart-on", []string{}, "Only restart the command when this secret key changes, in watch mode (repeatable; default: any key)")
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Collect secrets and list the environment variables that would be set (provider, value length and fingerprint, never values) without running the command")
+	runCmd.Flags().BoolVar(&runMaskOutput, "mask-output", false, "Replace collected secret values with **** in the command's stdout and stderr (output is passed through line by line)")
+	runCmd.Flags().IntVar(&runMaskMinLength, "mask-min-length", app.DefaultMaskMinLength, "Shortest secret value replaced by --mask-output, so short common values are not masked")
+	runCmd.Flags().BoolVar(&runEnvFile, "env-file", false, "Also write the secrets to a temporary dotenv file (0600) whose path is passed in SSTART_ENV_FILE, removed when the command exits")
+	runCmd.Flags().BoolVar(&runPrewarm, "prewarm", false, "Set up provider clients and logins before the first collection and keep them across reloads, in watch mode")
 	rootCmd.AddCommand(runCmd)
 }
+
+// writeDryRun lists the collected keys sorted by name, with the provider each one came from
+// and the length and fingerprint of its value
+func writeDryRun(out io.Writer, envSecrets map[string]string, provenance []secrets.KeyProvenance) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tPROVIDER\tLENGTH\tFINGERPRINT")
+	// Provenance is sorted by key and covers every collected key
+	for _, entry := range provenance {
+		source := entry.Provider
+		if source == "" {
+			source = "(post-processor)"
+		}
+		value := envSecrets[entry.Key]
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", entry.Key, source, len(value), secrets.ValueFingerprint(value))
+	}
+	return w.Flush()
+}
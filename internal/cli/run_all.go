@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dirathea/sstart/internal/app"
+	"github.com/dirathea/sstart/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runAllFile            string
+	runAllProviders       []string
+	runAllParallel        bool
+	runAllContinueOnError bool
+)
+
+var runAllCmd = &cobra.Command{
+	Use:   "run-all --file <tasks.yml>",
+	Short: "Run multiple commands sharing one secrets collection",
+	Long: `Collect secrets once and run every task from a task file with the shared environment.
+Providers are only called once, no matter how many tasks run.
+
+By default tasks run sequentially and the batch stops at the first failure.
+The exit code is 0 if all tasks succeed, otherwise the exit code of the first failing task.
+
+Example tasks.yml:
+  parallel: false
+  continue_on_error: false
+  tasks:
+    - name: migrate
+      command: ["./bin/migrate", "up"]
+    - name: seed
+      command: ["./bin/seed"]
+
+Example:
+  sstart run-all --file tasks.yml
+  sstart run-all --file tasks.yml --parallel --continue-on-error`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		taskFile, err := app.LoadTaskFile(runAllFile)
+		if err != nil {
+			return err
+		}
+
+		// Flags override the task file settings when set
+		if cmd.Flags().Changed("parallel") {
+			taskFile.Parallel = runAllParallel
+		}
+		if cmd.Flags().Changed("continue-on-error") {
+			taskFile.ContinueOnError = runAllContinueOnError
+		}
+
+		// Load configuration
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, runAllProviders)
+		if err != nil {
+			return err
+		}
+
+		// Create collector and runner
+		collector := secrets.NewCollector(cfg, collectorOptions()...)
+		runner := app.NewRunner(collector, cfg.Inherit, app.WithZeroEnv(noDump))
+		defer collector.Close()
+
+		results, exitCode, err := runner.RunAll(ctx, selectedProviders, taskFile)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			switch {
+			case result.Err != nil:
+				fmt.Fprintf(os.Stderr, "task '%s' failed: %v\n", result.Task.DisplayName(), result.Err)
+			case result.ExitCode < 0:
+				fmt.Fprintf(os.Stderr, "task '%s' skipped\n", result.Task.DisplayName())
+			case result.ExitCode > 0:
+				fmt.Fprintf(os.Stderr, "task '%s' failed with exit code %d\n", result.Task.DisplayName(), result.ExitCode)
+			}
+		}
+
+		if exitCode != 0 {
+			return exitWithCode(cmd, exitCode)
+		}
+		return nil
+	},
+}
+
+func init() {
+	runAllCmd.Flags().StringVarP(&runAllFile, "file", "f", "", "Path to the task file")
+	runAllCmd.Flags().StringSliceVar(&runAllProviders, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
+	runAllCmd.Flags().BoolVar(&runAllParallel, "parallel", false, "Run tasks concurrently (overrides the task file)")
+	runAllCmd.Flags().BoolVar(&runAllContinueOnError, "continue-on-error", false, "Keep running remaining tasks after a failure (overrides the task file)")
+	_ = runAllCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(runAllCmd)
+}
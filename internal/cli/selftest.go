@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// selfTestConfigs holds a representative configuration for every built-in provider kind
+var selfTestConfigs = map[string]map[string]interface{}{
+	"1password":            {"ref": "op://Private/Database/password"},
+	"1password_cli":        {"ref": "op://Private/Database/password"},
+	"aws_secretsmanager":   {"secret_id": "myapp/production", "region": "us-east-1"},
+	"azure_keyvault":       {"vault_url": "https://myvault.vault.azure.net/", "secret_name": "myapp"},
+	"bitwarden":            {"item_id": "0a1b2c3d-0000-0000-0000-000000000000", "format": "fields"},
+	"bitwarden_sm":         {"organization_id": "org-id", "project_id": "project-id"},
+	"doppler":              {"project": "myapp", "config": "prd"},
+	"dotenv":               {"path": ".env"},
+	"gcloud_secretmanager": {"project_id": "my-project", "secret_id": "myapp"},
+	"infisical":            {"project_id": "project-id", "environment": "prod", "path": "/"},
+	"mock":                 {"values": map[string]interface{}{"KEY": "value"}, "delay": "10ms"},
+	"prompt":               {"key": "API_KEY", "message": "Enter API key: "},
+	"sqlite":               {"path": "secrets.db", "table": "secrets"},
+	"template":             {"templates": map[string]interface{}{"DSN": "{{.db.host}}"}},
+	"vault":                {"path": "myapp/config", "mount": "secret", "kv_version": 2},
+}
+
+var selfTestCmd = &cobra.Command{
+	Use:   "self-test",
+	Short: "Check that every registered provider parses a representative configuration",
+	Long: `Instantiate every registered provider and validate a representative configuration,
+without reading files or contacting any backend. Use it to verify a release build:
+it fails if a provider is missing a representative configuration, rejects it, or panics.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		kinds := provider.List()
+		sort.Strings(kinds)
+
+		failed := 0
+		for _, kind := range kinds {
+			config, ok := selfTestConfigs[kind]
+			var err error
+			if !ok {
+				err = fmt.Errorf("no representative configuration for provider '%s'", kind)
+			} else {
+				err = provider.Validate(kind, config)
+			}
+			if err != nil {
+				failed++
+				fmt.Fprintf(out, "FAIL  %s: %v\n", kind, err)
+				continue
+			}
+			fmt.Fprintf(out, "ok    %s\n", kind)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("self-test failed for %d of %d providers", failed, len(kinds))
+		}
+		fmt.Fprintf(out, "All %d providers passed\n", len(kinds))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfTestCmd)
+}
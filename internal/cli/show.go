@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/dirathea/sstart/internal/config"
 	"github.com/dirathea/sstart/internal/secrets"
 	"github.com/spf13/cobra"
 )
@@ -18,18 +17,21 @@ Only the first 2 and last 2 characters are shown.`,
 		ctx := context.Background()
 
 		// Load configuration
-		cfg, err := config.Load(configPath)
+		cfg, err := loadConfig()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		// Collect secrets
-		collector := secrets.NewCollector(cfg, secrets.WithForceAuth(forceAuth))
-		showProviders := providers
-		if len(showProviders) == 0 {
-			showProviders = nil // Use all providers
+		// Resolve provider selectors from --providers or the environment
+		selectedProviders, err := selectProviders(cfg, providers)
+		if err != nil {
+			return err
 		}
-		envSecrets, err := collector.Collect(ctx, showProviders)
+
+		// Collect secrets
+		collector := secrets.NewCollector(cfg, readOnlyCollectorOptions()...)
+		defer collector.Close()
+		envSecrets, err := collector.Collect(ctx, selectedProviders)
 		if err != nil {
 			return fmt.Errorf("failed to collect secrets: %w", err)
 		}
@@ -45,6 +47,6 @@ Only the first 2 and last 2 characters are shown.`,
 }
 
 func init() {
-	showCmd.Flags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated list of provider IDs to use (default: all providers)")
+	showCmd.Flags().StringSliceVar(&providers, "providers", []string{}, "Comma-separated provider IDs, aliases, globs (aws-*) or kind:<kind> selectors to use (default: all providers)")
 	rootCmd.AddCommand(showCmd)
 }
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/spf13/cobra"
+)
+
+var ssoDeleteSecret bool
+
+var ssoCmd = &cobra.Command{
+	Use:   "sso",
+	Short: "Manage SSO settings",
+	Long:  `Manage SSO settings for the OIDC configuration in the config file.`,
+}
+
+var ssoSetSecretCmd = &cobra.Command{
+	Use:   "set-secret",
+	Short: "Store the SSO client secret in the system keyring",
+	Long: `Store the OIDC client secret in the system keyring, keyed by the issuer and client ID
+from the config file. The secret is read from stdin.
+
+When SSTART_SSO_SECRET is not set, sstart reads the client secret from the keyring.
+The environment variable always takes precedence.
+
+Example:
+  sstart sso set-secret
+  printf '%s' "$CLIENT_SECRET" | sstart sso set-secret
+  sstart sso set-secret --delete`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.SSO == nil || cfg.SSO.OIDC == nil {
+			return fmt.Errorf("sso.oidc configuration not found in config file")
+		}
+		issuer := cfg.SSO.OIDC.Issuer
+		clientID := cfg.SSO.OIDC.ClientID
+
+		if ssoDeleteSecret {
+			if err := oidc.DeleteClientSecret(issuer, clientID); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Removed client secret for %s (%s) from keyring\n", clientID, issuer)
+			return nil
+		}
+
+		if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "Client secret: ")
+		}
+		secret, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read client secret: %w", err)
+		}
+		secret = strings.TrimRight(secret, "\r\n")
+
+		if err := oidc.SaveClientSecret(issuer, clientID, secret); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Stored client secret for %s (%s) in keyring\n", clientID, issuer)
+		return nil
+	},
+}
+
+func init() {
+	ssoSetSecretCmd.Flags().BoolVar(&ssoDeleteSecret, "delete", false, "Remove the stored client secret instead of setting it")
+	ssoCmd.AddCommand(ssoSetSecretCmd)
+	rootCmd.AddCommand(ssoCmd)
+}
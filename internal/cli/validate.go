@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration without contacting any backend",
+	Long: `Check the configuration statically: it must parse, every provider kind must be
+built into sstart, every provider must accept its options, and the providers listed
+in 'uses' must exist. No secret is fetched, no file is read by the providers and no
+backend is contacted, so it fits a pre-commit hook.
+
+Each provider is listed as ok or FAIL with its problems. The command fails if the
+configuration cannot be loaded or any provider has a problem.
+
+Example:
+  sstart validate
+  sstart --config deploy/.sstart.yml validate`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		failed := writeValidateResults(cmd.OutOrStdout(), cfg)
+		if failed > 0 {
+			return fmt.Errorf("validation failed for %d of %d providers", failed, len(cfg.Providers))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// writeValidateResults writes one line per provider, or one line per problem of a provider that
+// fails validation, and returns the number of providers that failed
+func writeValidateResults(out io.Writer, cfg *config.Config) int {
+	failed := 0
+	for i := range cfg.Providers {
+		providerCfg := &cfg.Providers[i]
+		problems := validateProvider(cfg, providerCfg)
+		if len(problems) > 0 {
+			failed++
+			for _, problem := range problems {
+				fmt.Fprintf(out, "FAIL  %s (%s): %v\n", providerCfg.ID, providerCfg.Kind, problem)
+			}
+			continue
+		}
+		fmt.Fprintf(out, "ok    %s (%s)\n", providerCfg.ID, providerCfg.Kind)
+	}
+	if failed == 0 {
+		fmt.Fprintf(out, "Configuration is valid (%d providers)\n", len(cfg.Providers))
+	}
+	return failed
+}
+
+// validateProvider returns every problem found in a provider's configuration without fetching secrets
+func validateProvider(cfg *config.Config, providerCfg *config.ProviderConfig) []error {
+	var problems []error
+
+	p, err := provider.New(providerCfg.Kind)
+	if err != nil {
+		problems = append(problems, err)
+	} else if _, ok := p.(provider.ConfigValidator); ok {
+		if err := provider.Validate(providerCfg.Kind, providerCfg.Config); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, name := range providerCfg.Uses {
+		id := cfg.ResolveProviderID(name)
+		if id == providerCfg.ID {
+			problems = append(problems, fmt.Errorf("'uses' references the provider itself"))
+			continue
+		}
+		if _, err := cfg.GetProvider(id); err != nil {
+			problems = append(problems, fmt.Errorf("'uses' references unknown provider '%s'", name))
+		}
+	}
+	return problems
+}
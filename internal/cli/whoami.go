@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the active SSO identity",
+	Long: `Show the identity from the stored SSO ID token.
+
+The ID token is decoded without verifying its signature, for display purposes only.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.SSO == nil || cfg.SSO.OIDC == nil {
+			return fmt.Errorf("sso.oidc configuration not found in config file")
+		}
+
+		client, err := oidc.NewClient(cfg.SSO.OIDC, oidc.WithTokenStorage(oidc.StorageBackend(cfg.SSO.TokenStorage)))
+		if err != nil {
+			return fmt.Errorf("failed to create SSO client: %w", err)
+		}
+
+		identity, err := client.Whoami()
+		if err != nil {
+			return fmt.Errorf("no active SSO identity: %w", err)
+		}
+
+		printField := func(name, value string) {
+			if value != "" {
+				fmt.Printf("%-10s %s\n", name+":", value)
+			}
+		}
+		printField("Subject", identity.Subject)
+		printField("Email", identity.Email)
+		printField("Username", identity.PreferredUsername)
+		printField("Issuer", identity.Issuer)
+		if !identity.Expiry.IsZero() {
+			printField("Expires", identity.Expiry.Local().Format(time.RFC1123))
+		}
+
+		if identity.IsExpired() {
+			fmt.Println("\nThe ID token has expired. Re-authenticate with 'sstart login'.")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
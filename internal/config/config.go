@@ -1,35 +1,223 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
 
+	"github.com/dirathea/sstart/internal/provider"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the main configuration structure
 type Config struct {
-	Inherit   bool             `yaml:"inherit"` // Whether to inherit system environment variables (default: true)
-	Providers []ProviderConfig `yaml:"providers"`
-	SSO       *SSOConfig       `yaml:"sso,omitempty"`   // SSO configuration
-	Cache     *CacheConfig     `yaml:"cache,omitempty"` // Cache configuration
-	MCP       *MCPConfig       `yaml:"mcp,omitempty"`   // MCP proxy configuration
+	Inherit       bool             `yaml:"inherit"`                  // Whether to inherit system environment variables (default: true)
+	UppercaseKeys bool             `yaml:"uppercase_keys,omitempty"` // Whether to uppercase all final secret key names (default: false)
+	DenyKeys      []string         `yaml:"deny_keys"`                // Keys that providers may never set (default: DefaultDenyKeys, [] disables)
+	ExpandJSON    *bool            `yaml:"expand_json,omitempty"`    // Default for providers' expand_json: expand JSON secrets into one key per field (default: true)
+	Providers     []ProviderConfig `yaml:"providers"`
+	SSO           *SSOConfig       `yaml:"sso,omitempty"`   // SSO configuration
+	Cache         *CacheConfig     `yaml:"cache,omitempty"` // Cache configuration
+	MCP           *MCPConfig       `yaml:"mcp,omitempty"`   // MCP proxy configuration
+	// Limits on provider retries across the whole collection
+	RetryBudget *RetryBudgetConfig `yaml:"retry_budget,omitempty"`
+	// How values for the same key from several providers are combined, by target key (default: override)
+	MergeStrategy map[string]MergeStrategyConfig `yaml:"merge_strategy,omitempty"`
+	// Providers preferred for specific keys, highest priority first, regardless of declaration order
+	SourcePriority map[string][]string `yaml:"source_priority,omitempty"`
+	// Remote registry of shared provider definitions
+	Registry *RegistryConfig `yaml:"registry,omitempty"`
+	// Registry providers added before the configured providers, by id
+	UseFromRegistry []string `yaml:"use_from_registry,omitempty"`
+}
+
+const (
+	// MergeOverride replaces an earlier value with the later one (default)
+	MergeOverride = "override"
+	// MergeConcat joins the values with the separator
+	MergeConcat = "concat"
+	// MergeUnique joins the list items of all values with the separator, dropping duplicates
+	MergeUnique = "unique"
+
+	// DefaultMergeSeparator separates list items for the concat and unique strategies
+	DefaultMergeSeparator = ","
+)
+
+// MergeStrategyConfig controls how a key contributed by several providers is combined.
+// It is written either as a strategy name or as a mapping with 'strategy' and 'separator'.
+type MergeStrategyConfig struct {
+	Strategy  string `yaml:"strategy"`            // override (default), concat or unique
+	Separator string `yaml:"separator,omitempty"` // List separator for concat and unique (default: ",")
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to accept a plain strategy name
+func (m *MergeStrategyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var strategy string
+	if err := unmarshal(&strategy); err != nil {
+		type rawMergeStrategyConfig MergeStrategyConfig
+		var raw rawMergeStrategyConfig
+		if err := unmarshal(&raw); err != nil {
+			return err
+		}
+		*m = MergeStrategyConfig(raw)
+	} else {
+		m.Strategy = strategy
+	}
+
+	switch m.Strategy {
+	case "":
+		m.Strategy = MergeOverride
+	case MergeOverride, MergeConcat, MergeUnique:
+	default:
+		return fmt.Errorf("invalid merge strategy '%s' (supported: override, concat, unique)", m.Strategy)
+	}
+	if m.Separator == "" {
+		m.Separator = DefaultMergeSeparator
+	}
+	return nil
+}
+
+// DefaultDenyKeys are the keys dropped from collected secrets when 'deny_keys' is not configured.
+// They control how the child process resolves executables and loads shared libraries.
+var DefaultDenyKeys = []string{
+	"PATH",
+	"LD_PRELOAD",
+	"LD_LIBRARY_PATH",
+	"LD_AUDIT",
+	"DYLD_INSERT_LIBRARIES",
+	"DYLD_LIBRARY_PATH",
+}
+
+// RetryBudgetConfig represents a budget of retries shared by all providers during one collection.
+// Once either limit is reached, remaining retries are skipped and failing providers fail immediately.
+type RetryBudgetConfig struct {
+	MaxRetries int           `yaml:"max_retries,omitempty"` // Maximum total number of retries (0: unlimited)
+	MaxWait    time.Duration `yaml:"max_wait,omitempty"`    // Maximum total time spent waiting between retries (0: unlimited)
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to handle max_wait as duration string
+func (r *RetryBudgetConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawRetryBudgetConfig struct {
+		MaxRetries int    `yaml:"max_retries,omitempty"`
+		MaxWait    string `yaml:"max_wait,omitempty"`
+	}
+
+	var raw rawRetryBudgetConfig
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	if raw.MaxRetries < 0 {
+		return fmt.Errorf("retry_budget.max_retries must not be negative, got %d", raw.MaxRetries)
+	}
+	r.MaxRetries = raw.MaxRetries
+
+	// Parse max_wait if provided
+	if raw.MaxWait != "" {
+		maxWait, err := time.ParseDuration(raw.MaxWait)
+		if err != nil {
+			return fmt.Errorf("invalid retry_budget.max_wait format '%s': %w", raw.MaxWait, err)
+		}
+		if maxWait <= 0 {
+			return fmt.Errorf("retry_budget.max_wait must be positive, got '%s'", raw.MaxWait)
+		}
+		r.MaxWait = maxWait
+	}
+
+	return nil
 }
 
 // MCPConfig represents the MCP proxy configuration
 type MCPConfig struct {
 	Servers []MCPServerConfig `yaml:"servers"` // List of downstream MCP servers
+	// Separator between server IDs and tool, resource and prompt names (default: "/")
+	NamespaceSeparator string `yaml:"namespace_separator,omitempty"`
 }
 
 // MCPServerConfig represents a single downstream MCP server configuration
 type MCPServerConfig struct {
-	ID      string   `yaml:"id"`             // Unique identifier for the server (used for namespacing)
-	Command string   `yaml:"command"`        // Command to execute
-	Args    []string `yaml:"args,omitempty"` // Command arguments
-	Env     EnvVars  `yaml:"env,omitempty"`  // Additional environment variables
-	// Future: Secrets []string `yaml:"secrets,omitempty"` // Optional: filter which provider secrets to inject
+	ID      string            `yaml:"id"`                // Unique identifier for the server (used for namespacing)
+	Command string            `yaml:"command,omitempty"` // Command to execute
+	Args    []string          `yaml:"args,omitempty"`    // Command arguments
+	Env     EnvVars           `yaml:"env,omitempty"`     // Additional environment variables
+	URL     string            `yaml:"url,omitempty"`     // URL of a remote (HTTP/SSE) MCP server, used instead of command
+	Headers map[string]string `yaml:"headers,omitempty"` // HTTP headers for remote servers; values may reference secrets, e.g. "Bearer {{.API_TOKEN}}"
+	Secrets []string          `yaml:"secrets,omitempty"` // Keys of the collected secrets given to the server (default: all; [] gives none)
+	// How long the server process may take to exit on shutdown before it is killed (default: 5s)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout,omitempty"`
+	// Whether the server process is restarted when it exits unexpectedly: never (default) or on-failure
+	Restart string `yaml:"restart,omitempty"`
+	// Maximum number of restarts with on-failure (default: 3)
+	MaxRestarts int `yaml:"max_restarts,omitempty"`
+	// Delay before the first restart, doubled for each further restart (default: 1s)
+	RestartBackoff time.Duration `yaml:"restart_backoff,omitempty"`
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to handle shutdown_timeout as duration string
+func (s *MCPServerConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawMCPServerConfig struct {
+		ID              string            `yaml:"id"`
+		Command         string            `yaml:"command,omitempty"`
+		Args            []string          `yaml:"args,omitempty"`
+		Env             EnvVars           `yaml:"env,omitempty"`
+		URL             string            `yaml:"url,omitempty"`
+		Headers         map[string]string `yaml:"headers,omitempty"`
+		Secrets         []string          `yaml:"secrets,omitempty"`
+		ShutdownTimeout string            `yaml:"shutdown_timeout,omitempty"`
+		Restart         string            `yaml:"restart,omitempty"`
+		MaxRestarts     int               `yaml:"max_restarts,omitempty"`
+		RestartBackoff  string            `yaml:"restart_backoff,omitempty"`
+	}
+
+	var raw rawMCPServerConfig
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	s.ID = raw.ID
+	s.Command = raw.Command
+	s.Args = raw.Args
+	s.Env = raw.Env
+	s.URL = raw.URL
+	s.Headers = raw.Headers
+	s.Secrets = raw.Secrets
+	s.Restart = raw.Restart
+	s.MaxRestarts = raw.MaxRestarts
+
+	// Parse shutdown_timeout if provided
+	if raw.ShutdownTimeout != "" {
+		timeout, err := time.ParseDuration(raw.ShutdownTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid shutdown_timeout format '%s' for mcp server '%s': %w", raw.ShutdownTimeout, raw.ID, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("shutdown_timeout for mcp server '%s' must be positive, got '%s'", raw.ID, raw.ShutdownTimeout)
+		}
+		s.ShutdownTimeout = timeout
+	}
+
+	// Parse restart_backoff if provided
+	if raw.RestartBackoff != "" {
+		backoff, err := time.ParseDuration(raw.RestartBackoff)
+		if err != nil {
+			return fmt.Errorf("invalid restart_backoff format '%s' for mcp server '%s': %w", raw.RestartBackoff, raw.ID, err)
+		}
+		if backoff <= 0 {
+			return fmt.Errorf("restart_backoff for mcp server '%s' must be positive, got '%s'", raw.ID, raw.RestartBackoff)
+		}
+		s.RestartBackoff = backoff
+	}
+
+	return nil
 }
 
 // CacheConfig represents cache configuration
@@ -67,9 +255,91 @@ func (c *CacheConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// DefaultSSOIdentity is the name of the SSO identity used by providers without an 'sso_ref'
+const DefaultSSOIdentity = "default"
+
+// TokenStorages are the valid values of 'sso.token_storage'; empty selects "auto"
+var TokenStorages = []string{"auto", "keyring", "file", "memory"}
+
+// ValidateTokenStorage returns an error for a token storage not listed in TokenStorages
+func ValidateTokenStorage(storage string) error {
+	if storage == "" {
+		return nil
+	}
+	for _, valid := range TokenStorages {
+		if storage == valid {
+			return nil
+		}
+	}
+	last := len(TokenStorages) - 1
+	return fmt.Errorf("must be %s or %s", strings.Join(TokenStorages[:last], ", "), TokenStorages[last])
+}
+
 // SSOConfig represents SSO configuration
 type SSOConfig struct {
-	OIDC *OIDCConfig `yaml:"oidc,omitempty"` // OIDC configuration
+	OIDC         *OIDCConfig `yaml:"oidc,omitempty"`          // OIDC configuration of the default identity
+	TokenStorage string      `yaml:"token_storage,omitempty"` // Where tokens are stored: auto (keyring, falling back to a file; default), keyring, file or memory
+	// Named identities, when 'oidc' is a map of names to OIDC configurations. The identity named
+	// DefaultSSOIdentity, if any, is also set as OIDC.
+	Identities map[string]*OIDCConfig `yaml:"-"`
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling to accept 'oidc' as either a single OIDC configuration
+// (the default identity) or a map of identity names to OIDC configurations
+func (s *SSOConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawSSOConfig struct {
+		OIDC         yaml.Node `yaml:"oidc,omitempty"`
+		TokenStorage string    `yaml:"token_storage,omitempty"`
+	}
+
+	var raw rawSSOConfig
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	s.TokenStorage = raw.TokenStorage
+
+	if raw.OIDC.Kind == 0 || raw.OIDC.Tag == "!!null" {
+		return nil
+	}
+	if !isIdentityMap(&raw.OIDC) {
+		s.OIDC = &OIDCConfig{}
+		return raw.OIDC.Decode(s.OIDC)
+	}
+
+	s.Identities = make(map[string]*OIDCConfig, len(raw.OIDC.Content)/2)
+	for i := 0; i+1 < len(raw.OIDC.Content); i += 2 {
+		name := raw.OIDC.Content[i].Value
+		identity := &OIDCConfig{}
+		if err := raw.OIDC.Content[i+1].Decode(identity); err != nil {
+			return fmt.Errorf("sso.oidc.%s: %w", name, err)
+		}
+		s.Identities[name] = identity
+	}
+	s.OIDC = s.Identities[DefaultSSOIdentity]
+	return nil
+}
+
+// isIdentityMap reports whether an 'sso.oidc' node maps identity names to OIDC configurations,
+// i.e. it is a non-empty mapping whose values are all mappings
+func isIdentityMap(node *yaml.Node) bool {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		return false
+	}
+	for i := 1; i < len(node.Content); i += 2 {
+		if node.Content[i].Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// Identity returns the OIDC configuration of the named SSO identity, the default identity for an
+// empty name, or nil if there is no such identity
+func (s *SSOConfig) Identity(name string) *OIDCConfig {
+	if name == "" || name == DefaultSSOIdentity {
+		return s.OIDC
+	}
+	return s.Identities[name]
 }
 
 // OIDCConfig represents OIDC configuration
@@ -81,6 +351,8 @@ type OIDCConfig struct {
 	RedirectURI  string   `yaml:"redirectUri,omitempty"`  // OIDC redirect URI (optional, can be auto-generated)
 	PKCE         *bool    `yaml:"pkce,omitempty"`         // Enable PKCE flow (optional, auto-enabled if clientSecret is empty)
 	ResponseMode string   `yaml:"responseMode,omitempty"` // OIDC response mode (optional)
+	Audience     string   `yaml:"audience,omitempty"`     // Audience of the issued tokens, sent as the 'audience' parameter (optional)
+	Resource     string   `yaml:"resource,omitempty"`     // RFC 8707 resource indicator: absolute URI of the target service (optional)
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling to handle scopes as either array or space-separated string
@@ -94,6 +366,8 @@ func (o *OIDCConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		RedirectURI  string      `yaml:"redirectUri,omitempty"`
 		PKCE         *bool       `yaml:"pkce,omitempty"`
 		ResponseMode string      `yaml:"responseMode,omitempty"`
+		Audience     string      `yaml:"audience,omitempty"`
+		Resource     string      `yaml:"resource,omitempty"`
 	}
 
 	var raw rawOIDCConfig
@@ -107,6 +381,16 @@ func (o *OIDCConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	o.RedirectURI = raw.RedirectURI
 	o.PKCE = raw.PKCE
 	o.ResponseMode = raw.ResponseMode
+	o.Audience = raw.Audience
+	o.Resource = raw.Resource
+
+	// RFC 8707 requires the resource to be an absolute URI without a fragment
+	if raw.Resource != "" {
+		resource, err := url.Parse(raw.Resource)
+		if err != nil || !resource.IsAbs() || resource.Fragment != "" {
+			return fmt.Errorf("invalid sso.oidc.resource '%s': must be an absolute URI without a fragment", raw.Resource)
+		}
+	}
 
 	// Handle scopes: can be string (space-separated) or []string
 	if raw.Scopes != nil {
@@ -142,11 +426,84 @@ func (o *OIDCConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 // configure multiple provider instances with the same 'kind' but different 'id' values.
 type ProviderConfig struct {
 	Kind   string                 `yaml:"kind"`
-	ID     string                 `yaml:"id,omitempty"`   // Optional: defaults to 'kind'. Required if multiple providers share the same kind
-	Config map[string]interface{} `yaml:"-"`              // Provider-specific configuration (e.g., path, region, endpoint, etc.)
-	Keys   map[string]string      `yaml:"keys,omitempty"` // Optional key mappings (source_key: target_key, or "==" to keep same name)
+	ID     string                 `yaml:"id,omitempty"`    // Optional: defaults to 'kind'. Required if multiple providers share the same kind
+	Alias  string                 `yaml:"alias,omitempty"` // Optional short name usable in 'uses' and template references instead of the id
+	Config map[string]interface{} `yaml:"-"`               // Provider-specific configuration (e.g., path, region, endpoint, etc.)
+	Keys   map[string]string      `yaml:"keys,omitempty"`  // Optional key mappings (source_key: target_key, "==" to keep same name, or "!" to exclude)
 	Env    EnvVars                `yaml:"env,omitempty"`
 	Uses   []string               `yaml:"uses,omitempty"` // Optional list of provider IDs to depend on
+	// Optional source keys that are never loaded; all other keys are loaded unless 'keys' maps some of them
+	ExcludeKeys []string `yaml:"exclude_keys,omitempty"`
+	// Optional template computing each key's final name from its name after 'keys', available as .key
+	KeysTemplate string `yaml:"keys_template,omitempty"`
+	// Optional list of provider IDs that must be collected before this provider, regardless of declaration order
+	DependsOn []string `yaml:"depends_on,omitempty"`
+	// Optional condition on a secret from another provider; the provider is skipped when it does not hold
+	Requires *RequiresConfig `yaml:"requires,omitempty"`
+	// Fail when the source returns keys that are not listed in 'keys' (only applies when 'keys' is set)
+	StrictKeys bool `yaml:"strict_keys,omitempty"`
+	// Match source keys against 'keys' ignoring case (an exact match takes precedence)
+	CaseInsensitiveKeys bool `yaml:"case_insensitive_keys,omitempty"`
+	// Fail when a provider listed in 'uses' was not collected (failed, skipped or not selected),
+	// instead of resolving its secrets as absent
+	StrictUses bool `yaml:"strict_uses,omitempty"`
+	// Number of times a failed fetch is retried (default: 0)
+	Retries int `yaml:"retries,omitempty"`
+	// Delay before the first retry, doubled for each further retry (default: 1s)
+	RetryDelay time.Duration `yaml:"retry_delay,omitempty"`
+	// Maximum duration of each fetch attempt (default: the collector's timeout, none if unset)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Optional explicit cache key replacing the hash of the provider configuration.
+	// Providers sharing a cache key share cached secrets and are fetched once per collection.
+	CacheKey string `yaml:"cache_key,omitempty"`
+	// Optional transform applied to the provider's values after they are fetched (e.g. gcp_kms_decrypt)
+	ValueTransform *ValueTransformConfig `yaml:"value_transform,omitempty"`
+	// Optional key under which all of the provider's secrets are loaded as a single JSON object,
+	// instead of one key per secret
+	AsJSONKey string `yaml:"as_json_key,omitempty"`
+	// Optional labels attached to the provider's collection events (e.g. team: payments), for per-team dashboards
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Whether a failure of the provider fails the collection (default: true). When false, a failing
+	// provider is warned about and contributes no keys.
+	Required *bool `yaml:"required,omitempty"`
+	// Optional name of the SSO identity (a key of 'sso.oidc') whose tokens the provider receives
+	// (default: the default identity)
+	SSORef string `yaml:"sso_ref,omitempty"`
+}
+
+// IsRequired returns whether a failure of the provider fails the collection
+func (p *ProviderConfig) IsRequired() bool {
+	return p.Required == nil || *p.Required
+}
+
+// ValueTransformConfig represents a value transform applied to a provider's secrets
+type ValueTransformConfig struct {
+	Type   string                 `yaml:"type"`           // Name of the registered transform (required)
+	Keys   []string               `yaml:"keys,omitempty"` // Keys whose values are transformed (default: all keys of the provider)
+	Config map[string]interface{} `yaml:"-"`              // Transform-specific configuration (e.g., key_name, location, etc.)
+}
+
+// DefaultRetryDelay is the delay before the first retry when 'retry_delay' is not set
+const DefaultRetryDelay = time.Second
+
+// RequiresConfig represents a condition that gates whether a provider is collected.
+// The condition references a key from another provider's collected secrets.
+type RequiresConfig struct {
+	Provider string `yaml:"provider"`         // ID of the provider holding the key
+	Key      string `yaml:"key"`              // Key to inspect in that provider's secrets
+	Equals   string `yaml:"equals,omitempty"` // Expected value (optional: if empty, the key only needs a non-empty value)
+}
+
+// Matches reports whether the condition holds for the given provider secrets
+func (r *RequiresConfig) Matches(secrets map[string]string) bool {
+	value, ok := secrets[r.Key]
+	if !ok {
+		return false
+	}
+	if r.Equals == "" {
+		return value != ""
+	}
+	return value == r.Equals
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling to capture provider-specific fields
@@ -168,6 +525,11 @@ func (p *ProviderConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 		delete(raw, "id")
 	}
 
+	if alias, ok := raw["alias"].(string); ok {
+		p.Alias = alias
+		delete(raw, "alias")
+	}
+
 	if keys, ok := raw["keys"].(map[string]interface{}); ok {
 		p.Keys = make(map[string]string)
 		for k, v := range keys {
@@ -188,6 +550,24 @@ func (p *ProviderConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 		delete(raw, "env")
 	}
 
+	if excludeKeys, ok := raw["exclude_keys"].([]interface{}); ok {
+		p.ExcludeKeys = make([]string, 0, len(excludeKeys))
+		for _, v := range excludeKeys {
+			if str, ok := v.(string); ok {
+				p.ExcludeKeys = append(p.ExcludeKeys, str)
+			}
+		}
+		delete(raw, "exclude_keys")
+	}
+
+	if keysTemplate, ok := raw["keys_template"].(string); ok {
+		if _, err := template.New("keys_template").Funcs(provider.TemplateFuncs()).Parse(keysTemplate); err != nil {
+			return fmt.Errorf("invalid keys_template: %w", err)
+		}
+		p.KeysTemplate = keysTemplate
+		delete(raw, "keys_template")
+	}
+
 	if uses, ok := raw["uses"].([]interface{}); ok {
 		p.Uses = make([]string, 0, len(uses))
 		for _, v := range uses {
@@ -198,6 +578,160 @@ func (p *ProviderConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 		delete(raw, "uses")
 	}
 
+	if dependsOn, ok := raw["depends_on"].([]interface{}); ok {
+		p.DependsOn = make([]string, 0, len(dependsOn))
+		for _, v := range dependsOn {
+			if str, ok := v.(string); ok {
+				p.DependsOn = append(p.DependsOn, str)
+			}
+		}
+		delete(raw, "depends_on")
+	}
+
+	if strictKeys, ok := raw["strict_keys"].(bool); ok {
+		p.StrictKeys = strictKeys
+		delete(raw, "strict_keys")
+	}
+
+	if caseInsensitive, ok := raw["case_insensitive_keys"].(bool); ok {
+		p.CaseInsensitiveKeys = caseInsensitive
+		delete(raw, "case_insensitive_keys")
+	}
+
+	if strictUses, ok := raw["strict_uses"].(bool); ok {
+		p.StrictUses = strictUses
+		delete(raw, "strict_uses")
+	}
+
+	if required, ok := raw["required"]; ok {
+		b, ok := required.(bool)
+		if !ok {
+			return fmt.Errorf("invalid required '%v': must be true or false", required)
+		}
+		p.Required = &b
+		delete(raw, "required")
+	}
+
+	if retries, ok := raw["retries"]; ok {
+		n, ok := retries.(int)
+		if !ok || n < 0 {
+			return fmt.Errorf("invalid retries '%v': must be a non-negative integer", retries)
+		}
+		p.Retries = n
+		delete(raw, "retries")
+	}
+
+	if retryDelay, ok := raw["retry_delay"].(string); ok {
+		delay, err := time.ParseDuration(retryDelay)
+		if err != nil {
+			return fmt.Errorf("invalid retry_delay format '%s': %w", retryDelay, err)
+		}
+		if delay <= 0 {
+			return fmt.Errorf("retry_delay must be positive, got '%s'", retryDelay)
+		}
+		p.RetryDelay = delay
+		delete(raw, "retry_delay")
+	}
+
+	if timeout, ok := raw["timeout"].(string); ok {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout format '%s': %w", timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got '%s'", timeout)
+		}
+		p.Timeout = d
+		delete(raw, "timeout")
+	}
+
+	if cacheKey, ok := raw["cache_key"]; ok {
+		str, ok := cacheKey.(string)
+		if !ok || str == "" {
+			return fmt.Errorf("invalid cache_key '%v': must be a non-empty string", cacheKey)
+		}
+		p.CacheKey = str
+		delete(raw, "cache_key")
+	}
+
+	if ssoRef, ok := raw["sso_ref"]; ok {
+		str, ok := ssoRef.(string)
+		if !ok || str == "" {
+			return fmt.Errorf("invalid sso_ref '%v': must be a non-empty string", ssoRef)
+		}
+		p.SSORef = str
+		delete(raw, "sso_ref")
+	}
+
+	if asJSONKey, ok := raw["as_json_key"]; ok {
+		str, ok := asJSONKey.(string)
+		if !ok || str == "" {
+			return fmt.Errorf("invalid as_json_key '%v': must be a non-empty string", asJSONKey)
+		}
+		p.AsJSONKey = str
+		delete(raw, "as_json_key")
+	}
+
+	if labels, ok := raw["labels"]; ok {
+		labelsRaw, ok := labels.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid labels: must be a mapping of label names to values")
+		}
+		p.Labels = make(map[string]string, len(labelsRaw))
+		for k, v := range labelsRaw {
+			switch v.(type) {
+			case map[string]interface{}, []interface{}, nil:
+				return fmt.Errorf("invalid label '%s': value must be a string, number or boolean", k)
+			}
+			p.Labels[k] = fmt.Sprintf("%v", v)
+		}
+		delete(raw, "labels")
+	}
+
+	if valueTransform, ok := raw["value_transform"]; ok {
+		transformRaw, ok := valueTransform.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value_transform: must be a mapping")
+		}
+		transformType, ok := transformRaw["type"].(string)
+		if !ok || transformType == "" {
+			return fmt.Errorf("value_transform requires a 'type' field")
+		}
+		p.ValueTransform = &ValueTransformConfig{Type: transformType, Config: make(map[string]interface{})}
+		for k, v := range transformRaw {
+			switch k {
+			case "type":
+			case "keys":
+				keys, ok := v.([]interface{})
+				if !ok {
+					return fmt.Errorf("value_transform.keys must be a list of key names")
+				}
+				for _, key := range keys {
+					if str, ok := key.(string); ok {
+						p.ValueTransform.Keys = append(p.ValueTransform.Keys, str)
+					}
+				}
+			default:
+				p.ValueTransform.Config[k] = v
+			}
+		}
+		delete(raw, "value_transform")
+	}
+
+	if requires, ok := raw["requires"].(map[string]interface{}); ok {
+		p.Requires = &RequiresConfig{}
+		if v, ok := requires["provider"].(string); ok {
+			p.Requires.Provider = v
+		}
+		if v, ok := requires["key"].(string); ok {
+			p.Requires.Key = v
+		}
+		if v, ok := requires["equals"]; ok && v != nil {
+			p.Requires.Equals = fmt.Sprintf("%v", v)
+		}
+		delete(raw, "requires")
+	}
+
 	// Everything else goes into Config
 	p.Config = raw
 	if p.Config == nil {
@@ -210,13 +744,83 @@ func (p *ProviderConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 // EnvVars represents environment variable overrides
 type EnvVars map[string]string
 
-// Load reads and parses the configuration file
+// Supported configuration formats
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// StdinPath is the config path that reads the configuration from stdin
+const StdinPath = "-"
+
+// Load reads and parses the configuration file, detecting the format from the file extension
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	return LoadWithFormat(path, "")
+}
+
+// LoadWithFormat reads and parses the configuration from path ("-" for stdin) in the given format.
+// An empty format is detected from the file extension, defaulting to YAML.
+func LoadWithFormat(path, format string) (*Config, error) {
+	var data []byte
+	var err error
+	if path == StdinPath {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	if format == "" {
+		format = DetectFormat(path)
+	}
+
+	data, err = toYAML(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge the configuration over the files it includes
+	data, err = resolveIncludes(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(data)
+}
+
+// toYAML returns configuration data in the given format as YAML
+func toYAML(data []byte, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return data, nil
+	case FormatJSON:
+		// Parse as strict JSON for accurate errors, then hand the document to the YAML decoder
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file as JSON: %w", err)
+		}
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert JSON config: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s' (supported: yaml, json)", format)
+	}
+}
+
+// DetectFormat returns the configuration format implied by a path's extension, defaulting to YAML
+func DetectFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// parse parses and validates YAML configuration data
+func parse(data []byte) (*Config, error) {
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -229,9 +833,18 @@ func Load(path string) (*Config, error) {
 		if _, explicitlySet := raw["inherit"]; !explicitlySet {
 			config.Inherit = true
 		}
+		if _, explicitlySet := raw["deny_keys"]; !explicitlySet {
+			config.DenyKeys = append([]string(nil), DefaultDenyKeys...)
+		}
 	} else {
 		// If we can't parse the raw YAML, default to true
 		config.Inherit = true
+		config.DenyKeys = append([]string(nil), DefaultDenyKeys...)
+	}
+
+	// Pull in shared provider definitions, so they are validated like configured providers
+	if err := config.useFromRegistry(); err != nil {
+		return nil, err
 	}
 
 	if config.Providers == nil {
@@ -251,6 +864,12 @@ func Load(path string) (*Config, error) {
 			provider.Config = make(map[string]interface{})
 		}
 
+		// Expand environment variables, so one configuration can serve several environments
+		expandConfig(provider.Config)
+		if provider.ValueTransform != nil {
+			expandConfig(provider.ValueTransform.Config)
+		}
+
 		kindCounts[provider.Kind]++
 	}
 
@@ -282,17 +901,108 @@ func Load(path string) (*Config, error) {
 		}
 	}
 
+	// Reject options the provider kinds do not accept, before they are silently ignored
+	for i := range config.Providers {
+		if err := validateProviderFields(&config.Providers[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate aliases: they share a namespace with ids and must be unique
+	aliasOwners := make(map[string]string)
+	for i := range config.Providers {
+		provider := &config.Providers[i]
+		if provider.Alias == "" {
+			continue
+		}
+		if idCounts[provider.Alias] > 0 && provider.Alias != provider.ID {
+			return nil, fmt.Errorf("provider '%s': alias '%s' conflicts with an existing provider id", provider.ID, provider.Alias)
+		}
+		if owner, exists := aliasOwners[provider.Alias]; exists {
+			return nil, fmt.Errorf("duplicate provider alias '%s' used by '%s' and '%s'", provider.Alias, owner, provider.ID)
+		}
+		aliasOwners[provider.Alias] = provider.ID
+	}
+
+	// Validate provider conditions now that all IDs are known
+	for i := range config.Providers {
+		provider := &config.Providers[i]
+		if provider.Requires == nil {
+			continue
+		}
+		if provider.Requires.Provider == "" {
+			return nil, fmt.Errorf("provider '%s': requires.provider is required", provider.ID)
+		}
+		if provider.Requires.Key == "" {
+			return nil, fmt.Errorf("provider '%s': requires.key is required", provider.ID)
+		}
+		provider.Requires.Provider = config.ResolveProviderID(provider.Requires.Provider)
+		if provider.Requires.Provider == provider.ID {
+			return nil, fmt.Errorf("provider '%s': requires cannot reference itself", provider.ID)
+		}
+		if idCounts[provider.Requires.Provider] == 0 {
+			return nil, fmt.Errorf("provider '%s': requires references unknown provider '%s'", provider.ID, provider.Requires.Provider)
+		}
+	}
+
+	// Validate explicit dependencies and resolve aliases to ids
+	for i := range config.Providers {
+		provider := &config.Providers[i]
+		for j, name := range provider.DependsOn {
+			id := config.ResolveProviderID(name)
+			if id == provider.ID {
+				return nil, fmt.Errorf("provider '%s': depends_on cannot reference itself", provider.ID)
+			}
+			if idCounts[id] == 0 {
+				return nil, fmt.Errorf("provider '%s': depends_on references unknown provider '%s'", provider.ID, name)
+			}
+			provider.DependsOn[j] = id
+		}
+	}
+	if cycle := findDependsOnCycle(config.Providers); cycle != nil {
+		return nil, fmt.Errorf("depends_on forms a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	// Validate source priorities and resolve aliases to ids
+	for key, providerIDs := range config.SourcePriority {
+		for i, name := range providerIDs {
+			id := config.ResolveProviderID(name)
+			if idCounts[id] == 0 {
+				return nil, fmt.Errorf("source_priority for key '%s' references unknown provider '%s'", key, name)
+			}
+			providerIDs[i] = id
+		}
+	}
+
 	// Validate SSO configuration if present
-	if config.SSO != nil && config.SSO.OIDC != nil {
-		oidc := config.SSO.OIDC
-		if oidc.ClientID == "" {
-			return nil, fmt.Errorf("sso.oidc.clientId is required")
+	if config.SSO != nil {
+		if err := ValidateTokenStorage(config.SSO.TokenStorage); err != nil {
+			return nil, fmt.Errorf("invalid sso.token_storage '%s': %w", config.SSO.TokenStorage, err)
 		}
-		if oidc.Issuer == "" {
-			return nil, fmt.Errorf("sso.oidc.issuer is required")
+	}
+	if config.SSO != nil && config.SSO.Identities != nil {
+		names := make([]string, 0, len(config.SSO.Identities))
+		for name := range config.SSO.Identities {
+			names = append(names, name)
 		}
-		if len(oidc.Scopes) == 0 {
-			return nil, fmt.Errorf("sso.oidc.scopes is required and must contain at least one scope")
+		sort.Strings(names)
+		for _, name := range names {
+			if !isIdentityName(name) {
+				return nil, fmt.Errorf("invalid sso.oidc identity name '%s': use letters, digits, '-' and '_'", name)
+			}
+			if err := validateOIDCConfig(config.SSO.Identities[name], "sso.oidc."+name); err != nil {
+				return nil, err
+			}
+		}
+	} else if config.SSO != nil && config.SSO.OIDC != nil {
+		if err := validateOIDCConfig(config.SSO.OIDC, "sso.oidc"); err != nil {
+			return nil, err
+		}
+	}
+	for i := range config.Providers {
+		provider := &config.Providers[i]
+		if provider.SSORef != "" && (config.SSO == nil || config.SSO.Identity(provider.SSORef) == nil) {
+			return nil, fmt.Errorf("provider '%s': sso_ref references unknown sso identity '%s'", provider.ID, provider.SSORef)
 		}
 	}
 
@@ -306,11 +1016,91 @@ func Load(path string) (*Config, error) {
 	return &config, nil
 }
 
+// validateOIDCConfig checks the required fields of an OIDC configuration found at path
+func validateOIDCConfig(oidc *OIDCConfig, path string) error {
+	if oidc.ClientID == "" {
+		return fmt.Errorf("%s.clientId is required", path)
+	}
+	if oidc.Issuer == "" {
+		return fmt.Errorf("%s.issuer is required", path)
+	}
+	if len(oidc.Scopes) == 0 {
+		return fmt.Errorf("%s.scopes is required and must contain at least one scope", path)
+	}
+	return nil
+}
+
+// isIdentityName reports whether name is a valid SSO identity name: letters, digits, '-' and '_',
+// so that it can name token files and keyring entries
+func isIdentityName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r != '-' && r != '_' && (r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// findDependsOnCycle returns the provider ids forming a 'depends_on' cycle, starting and ending
+// with the same id, or nil if there is none
+func findDependsOnCycle(providers []ProviderConfig) []string {
+	dependsOn := make(map[string][]string, len(providers))
+	for _, provider := range providers {
+		dependsOn[provider.ID] = provider.DependsOn
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(providers))
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			for i, onPath := range path {
+				if onPath == id {
+					return append(append([]string(nil), path[i:]...), id)
+				}
+			}
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range dependsOn[id] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, provider := range providers {
+		if cycle := visit(provider.ID); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
 // validateMCPConfig validates the MCP proxy configuration
 func validateMCPConfig(mcp *MCPConfig) error {
 	if len(mcp.Servers) == 0 {
 		return fmt.Errorf("mcp.servers must contain at least one server")
 	}
+	// Escaped server IDs only consist of other characters, '%' and hex digits, so they never contain the separator
+	if strings.ContainsFunc(mcp.NamespaceSeparator, func(r rune) bool {
+		return r == '%' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) {
+		return fmt.Errorf("mcp.namespace_separator must not contain letters, digits or '%%', got '%s'", mcp.NamespaceSeparator)
+	}
 
 	// Track server IDs to check for duplicates
 	serverIDs := make(map[string]int)
@@ -319,8 +1109,34 @@ func validateMCPConfig(mcp *MCPConfig) error {
 		if server.ID == "" {
 			return fmt.Errorf("mcp.servers[%d].id is required", i)
 		}
-		if server.Command == "" {
-			return fmt.Errorf("mcp.servers[%d].command is required", i)
+		if server.Command == "" && server.URL == "" {
+			return fmt.Errorf("mcp.servers[%d].command or mcp.servers[%d].url is required", i, i)
+		}
+		if server.Command != "" && server.URL != "" {
+			return fmt.Errorf("mcp.servers[%d] cannot set both command and url", i)
+		}
+		if len(server.Headers) > 0 && server.URL == "" {
+			return fmt.Errorf("mcp.servers[%d].headers requires url", i)
+		}
+		for _, key := range server.Secrets {
+			if key == "" {
+				return fmt.Errorf("mcp.servers[%d].secrets cannot contain an empty key", i)
+			}
+		}
+		switch server.Restart {
+		case "", "never":
+			if server.MaxRestarts != 0 || server.RestartBackoff != 0 {
+				return fmt.Errorf("mcp.servers[%d].max_restarts and restart_backoff require restart: on-failure", i)
+			}
+		case "on-failure":
+			if server.URL != "" {
+				return fmt.Errorf("mcp.servers[%d].restart requires command", i)
+			}
+			if server.MaxRestarts < 0 {
+				return fmt.Errorf("mcp.servers[%d].max_restarts must not be negative, got %d", i, server.MaxRestarts)
+			}
+		default:
+			return fmt.Errorf("mcp.servers[%d].restart must be 'never' or 'on-failure', got '%s'", i, server.Restart)
 		}
 
 		// Check for duplicate IDs
@@ -343,6 +1159,72 @@ func (c *Config) GetProvider(id string) (*ProviderConfig, error) {
 	return nil, fmt.Errorf("provider '%s' not found", id)
 }
 
+// ResolveProviderID returns the provider id for a name that may be either an id or an alias.
+// Names that match neither are returned unchanged.
+func (c *Config) ResolveProviderID(name string) string {
+	for i := range c.Providers {
+		if c.Providers[i].ID == name {
+			return name
+		}
+	}
+	for i := range c.Providers {
+		if c.Providers[i].Alias != "" && c.Providers[i].Alias == name {
+			return c.Providers[i].ID
+		}
+	}
+	return name
+}
+
+// SelectProviders expands provider selectors into provider ids. A selector is a provider id or alias,
+// a glob pattern matched against ids and aliases (e.g. 'aws-*'), or 'kind:<pattern>' matched against
+// provider kinds (e.g. 'kind:vault'). Patterns expand to their providers in declaration order, and
+// every provider is selected at most once. Names that are not patterns are returned unchanged when
+// they match no provider, so that collection reports them as not found.
+func (c *Config) SelectProviders(selectors []string) ([]string, error) {
+	var ids []string
+	selected := make(map[string]bool)
+	add := func(id string) {
+		if !selected[id] {
+			selected[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, selector := range selectors {
+		kindPattern, byKind := strings.CutPrefix(selector, "kind:")
+		if !byKind && !strings.ContainsAny(selector, "*?[") {
+			add(c.ResolveProviderID(selector))
+			continue
+		}
+
+		matched := false
+		for i := range c.Providers {
+			providerCfg := &c.Providers[i]
+			var ok bool
+			var err error
+			if byKind {
+				ok, err = path.Match(kindPattern, providerCfg.Kind)
+			} else {
+				ok, err = path.Match(selector, providerCfg.ID)
+				if err == nil && !ok && providerCfg.Alias != "" {
+					ok, err = path.Match(selector, providerCfg.Alias)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid provider selector '%s': %w", selector, err)
+			}
+			if ok {
+				matched = true
+				add(providerCfg.ID)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("provider selector '%s' matches no provider", selector)
+		}
+	}
+	return ids, nil
+}
+
 // IsCacheEnabled returns whether caching is enabled globally
 func (c *Config) IsCacheEnabled() bool {
 	return c.Cache != nil && c.Cache.Enabled
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// ExpandEnv expands environment variables in s: $VAR and ${VAR} are replaced by the value of VAR
+// (empty when unset), ${VAR:-default} by the value of VAR or default when VAR is unset or empty,
+// and $$ by a single $.
+func ExpandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if variable, fallback, found := strings.Cut(name, ":-"); found {
+			if value := os.Getenv(variable); value != "" {
+				return value
+			}
+			return fallback
+		}
+		return os.Getenv(name)
+	})
+}
+
+// expandConfig expands environment variables in the string values of a provider or transform
+// configuration, including strings nested in mappings and lists
+func expandConfig(config map[string]interface{}) {
+	for key, value := range config {
+		config[key] = expandValue(value)
+	}
+}
+
+// expandValue expands environment variables in a configuration value
+func expandValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return ExpandEnv(v)
+	case map[string]interface{}:
+		expandConfig(v)
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = expandValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
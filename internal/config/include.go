@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IncludeKey is the top-level key listing the configuration files a configuration builds on
+const IncludeKey = "include"
+
+// resolveIncludes loads the files listed in the 'include' of a YAML document and merges the document
+// over them: included files are merged in order, each over the previous ones, and the including
+// document over all of them. Mappings are merged key by key, 'providers' lists by provider id, and
+// any other value of the including document replaces the included one. Include paths are relative
+// to the including file (the working directory for stdin), and includes may be nested.
+// A document without 'include' is returned unchanged.
+func resolveIncludes(data []byte, path string) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc[IncludeKey] == nil {
+		// Parsing errors are reported by parse
+		return data, nil
+	}
+
+	var stack []string
+	if path != StdinPath {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		stack = []string{absPath}
+	}
+
+	merged, err := mergeIncludes(doc, includeDir(path), stack)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(merged)
+}
+
+// includeDir returns the directory include paths of the configuration at path are relative to
+func includeDir(path string) string {
+	if path == StdinPath {
+		return "."
+	}
+	return filepath.Dir(path)
+}
+
+// mergeIncludes returns doc merged over the files in its 'include', which are resolved against dir.
+// stack holds the absolute paths of the files being included, to detect cycles.
+func mergeIncludes(doc map[string]interface{}, dir string, stack []string) (map[string]interface{}, error) {
+	includes, err := includePaths(doc[IncludeKey])
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, IncludeKey)
+
+	merged := map[string]interface{}{}
+	for _, include := range includes {
+		includePath := include
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(dir, includePath)
+		}
+		absPath, err := filepath.Abs(includePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve include '%s': %w", include, err)
+		}
+		for i, visited := range stack {
+			if visited == absPath {
+				cycle := append(append([]string(nil), stack[i:]...), absPath)
+				return nil, fmt.Errorf("config include cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		included, err := loadIncluded(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load include '%s': %w", include, err)
+		}
+		included, err = mergeIncludes(included, filepath.Dir(absPath), append(stack, absPath))
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeDocuments(merged, included)
+	}
+	return mergeDocuments(merged, doc), nil
+}
+
+// includePaths returns the paths of an 'include' value: a path or a list of paths
+func includePaths(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			path, ok := item.(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("invalid include '%v': must be a file path", item)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("invalid include: must be a file path or a list of file paths")
+	}
+}
+
+// loadIncluded reads an included configuration file as a document, detecting its format from the extension
+func loadIncluded(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err = toYAML(data, DetectFormat(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return doc, nil
+}
+
+// mergeDocuments merges the top-level keys of override over base, merging 'providers' by id
+func mergeDocuments(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		if key == "providers" {
+			merged[key] = mergeProviders(merged[key], value)
+			continue
+		}
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// mergeValues merges mappings key by key; any other override value replaces base
+func mergeValues(base, override interface{}) interface{} {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	overrideMap, overrideIsMap := override.(map[string]interface{})
+	if !baseIsMap || !overrideIsMap {
+		return override
+	}
+	merged := make(map[string]interface{}, len(baseMap)+len(overrideMap))
+	for key, value := range baseMap {
+		merged[key] = value
+	}
+	for key, value := range overrideMap {
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// mergeProviders merges two provider lists by provider id (the kind when there is no id): a provider
+// of override is merged over the base provider with the same id in its place, and other providers
+// of override are appended
+func mergeProviders(base, override interface{}) interface{} {
+	baseList, baseIsList := base.([]interface{})
+	overrideList, overrideIsList := override.([]interface{})
+	if !baseIsList || !overrideIsList {
+		return override
+	}
+
+	merged := append([]interface{}(nil), baseList...)
+	index := make(map[string]int, len(merged))
+	for i, provider := range merged {
+		if id := providerDocumentID(provider); id != "" {
+			index[id] = i
+		}
+	}
+	for _, provider := range overrideList {
+		id := providerDocumentID(provider)
+		if i, found := index[id]; found && id != "" {
+			merged[i] = mergeValues(merged[i], provider)
+			continue
+		}
+		if id != "" {
+			index[id] = len(merged)
+		}
+		merged = append(merged, provider)
+	}
+	return merged
+}
+
+// providerDocumentID returns the id of a provider document, defaulting to its kind
+func providerDocumentID(provider interface{}) string {
+	fields, ok := provider.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if id, ok := fields["id"].(string); ok && id != "" {
+		return id
+	}
+	kind, _ := fields["kind"].(string)
+	return kind
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// registryTimeout bounds the request fetching provider definitions from a registry
+const registryTimeout = 30 * time.Second
+
+// RegistryConfig represents a remote registry of shared provider definitions.
+// The registry URL returns a YAML or JSON document with a 'providers' list, in the same format
+// as the configuration file. Registry providers are named by their id.
+type RegistryConfig struct {
+	URL     string            `yaml:"url"`               // URL of the registry document (required)
+	Headers map[string]string `yaml:"headers,omitempty"` // HTTP headers sent to the registry; environment variables in values are expanded
+}
+
+// registryDocument is the document served by a registry
+type registryDocument struct {
+	Providers []ProviderConfig `yaml:"providers"`
+}
+
+// useFromRegistry adds the registry providers named in 'use_from_registry' to the configuration,
+// before its own providers and in the listed order. A configured provider with the same id
+// replaces the registry provider.
+func (c *Config) useFromRegistry() error {
+	if len(c.UseFromRegistry) == 0 {
+		return nil
+	}
+	if c.Registry == nil || c.Registry.URL == "" {
+		return fmt.Errorf("use_from_registry requires registry.url")
+	}
+
+	registryProviders, err := fetchRegistry(c.Registry)
+	if err != nil {
+		return fmt.Errorf("failed to load providers from registry '%s': %w", c.Registry.URL, err)
+	}
+
+	byName := make(map[string]ProviderConfig, len(registryProviders))
+	for i, provider := range registryProviders {
+		if provider.ID == "" {
+			return fmt.Errorf("registry '%s': provider at index %d is missing required field 'id'", c.Registry.URL, i)
+		}
+		byName[provider.ID] = provider
+	}
+
+	configured := make(map[string]bool, len(c.Providers))
+	for _, provider := range c.Providers {
+		configured[provider.ID] = true
+	}
+
+	providers := make([]ProviderConfig, 0, len(c.UseFromRegistry)+len(c.Providers))
+	used := make(map[string]bool, len(c.UseFromRegistry))
+	for _, name := range c.UseFromRegistry {
+		provider, found := byName[name]
+		if !found {
+			return fmt.Errorf("use_from_registry references unknown provider '%s' (registry '%s')", name, c.Registry.URL)
+		}
+		if used[name] || configured[name] {
+			continue
+		}
+		used[name] = true
+		providers = append(providers, provider)
+	}
+	c.Providers = append(providers, c.Providers...)
+	return nil
+}
+
+// fetchRegistry downloads and parses the provider definitions of a registry
+func fetchRegistry(registry *RegistryConfig) ([]ProviderConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registry.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry url: %w", err)
+	}
+	req.Header.Set("Accept", "application/yaml, application/json")
+	for name, value := range registry.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	// JSON documents are valid YAML
+	var doc registryDocument
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse registry document: %w", err)
+	}
+	return doc.Providers, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// providerFields are the options shared by all providers, parsed by ProviderConfig.UnmarshalYAML
+var providerFields = []string{
+	"kind", "id", "alias", "keys", "env", "exclude_keys", "keys_template", "uses", "depends_on",
+	"strict_keys", "case_insensitive_keys", "strict_uses", "required", "retries", "retry_delay",
+	"timeout", "cache_key", "as_json_key", "labels", "value_transform", "requires", "sso_ref",
+}
+
+// validateProviderFields rejects the options of a provider that its kind does not accept, naming the
+// closest valid option. Providers whose kind is not registered or does not declare its options are
+// not checked.
+func validateProviderFields(p *ProviderConfig) error {
+	fields, ok := provider.Fields(p.Kind)
+	if !ok {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = true
+	}
+
+	unknown := make([]string, 0)
+	for field := range p.Config {
+		if !allowed[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	field := unknown[0]
+	if suggestion := closestField(field, append(append([]string(nil), fields...), providerFields...)); suggestion != "" {
+		return fmt.Errorf("provider '%s': unknown field '%s' for kind '%s' (did you mean '%s'?)", p.ID, field, p.Kind, suggestion)
+	}
+	valid := append([]string(nil), fields...)
+	sort.Strings(valid)
+	return fmt.Errorf("provider '%s': unknown field '%s' for kind '%s' (valid fields: %s)", p.ID, field, p.Kind, strings.Join(valid, ", "))
+}
+
+// closestField returns the candidate closest to field, ignoring case, or "" when none is close
+// enough to be a likely typo
+func closestField(field string, candidates []string) string {
+	field = strings.ToLower(field)
+	best, bestDistance := "", min(len(field)/3+1, 3)+1
+	for _, candidate := range candidates {
+		if distance := editDistance(field, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
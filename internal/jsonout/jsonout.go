@@ -0,0 +1,18 @@
+// Package jsonout writes the JSON outputs of sstart, either indented for people or on a single line for tools
+package jsonout
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Encode writes v as JSON followed by a newline, indented by two spaces when pretty is set.
+// HTML characters are not escaped, so string values are written exactly as given.
+func Encode(w io.Writer, v interface{}, pretty bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
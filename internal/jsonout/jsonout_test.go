@@ -0,0 +1,31 @@
+package jsonout
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	value := map[string]string{"A": "1", "B": "<tag>&"}
+
+	tests := []struct {
+		name   string
+		pretty bool
+		want   string
+	}{
+		{name: "pretty", pretty: true, want: "{\n  \"A\": \"1\",\n  \"B\": \"<tag>&\"\n}\n"},
+		{name: "compact", pretty: false, want: "{\"A\":\"1\",\"B\":\"<tag>&\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, value, tt.pretty); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package keyring wraps the system keyring with a timeout. On some systems the keyring is locked
+// or shows an unlock prompt, which would make every operation hang; with the timeout, operations
+// fail with ErrTimeout instead, so callers can fall back or report an actionable error.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// DefaultTimeout is the default time to wait for the system keyring to answer an operation
+	DefaultTimeout = 10 * time.Second
+	// TimeoutEnvVar is the environment variable overriding DefaultTimeout, as a duration (e.g. "30s")
+	TimeoutEnvVar = "SSTART_KEYRING_TIMEOUT"
+)
+
+var (
+	// ErrNotFound is returned when the requested key does not exist in the keyring
+	ErrNotFound = keyring.ErrNotFound
+	// ErrTimeout is returned when the keyring does not answer within the timeout
+	ErrTimeout = errors.New("system keyring did not respond")
+)
+
+// Backend is a keyring implementation
+type Backend interface {
+	Get(service, user string) (string, error)
+	Set(service, user, password string) error
+	Delete(service, user string) error
+}
+
+// systemBackend is the system keyring (or the go-keyring mock, when initialized)
+type systemBackend struct{}
+
+func (systemBackend) Get(service, user string) (string, error) {
+	return keyring.Get(service, user)
+}
+
+func (systemBackend) Set(service, user, password string) error {
+	return keyring.Set(service, user, password)
+}
+
+func (systemBackend) Delete(service, user string) error {
+	return keyring.Delete(service, user)
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend = systemBackend{}
+)
+
+// SetBackend replaces the keyring implementation, e.g. with a slow one in tests,
+// and returns a function restoring the previous one
+func SetBackend(b Backend) (restore func()) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	previous := backend
+	backend = b
+	return func() {
+		backendMu.Lock()
+		defer backendMu.Unlock()
+		backend = previous
+	}
+}
+
+// Timeout returns the time to wait for the keyring: the duration in SSTART_KEYRING_TIMEOUT,
+// or DefaultTimeout when it is unset or not a positive duration
+func Timeout() time.Duration {
+	if value := os.Getenv(TimeoutEnvVar); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return DefaultTimeout
+}
+
+// Get returns the secret stored for a service and user
+func Get(service, user string) (string, error) {
+	var secret string
+	err := withTimeout(func(b Backend) error {
+		var err error
+		secret, err = b.Get(service, user)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Set stores a secret for a service and user
+func Set(service, user, password string) error {
+	return withTimeout(func(b Backend) error {
+		return b.Set(service, user, password)
+	})
+}
+
+// Delete removes the secret stored for a service and user
+func Delete(service, user string) error {
+	return withTimeout(func(b Backend) error {
+		return b.Delete(service, user)
+	})
+}
+
+// withTimeout runs a keyring operation, giving up after Timeout. An operation that timed out keeps
+// running in the background, as the keyring API cannot be cancelled.
+func withTimeout(op func(Backend) error) error {
+	backendMu.RLock()
+	b := backend
+	backendMu.RUnlock()
+
+	timeout := Timeout()
+	done := make(chan error, 1)
+	go func() {
+		done <- op(b)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w within %s, it may be locked or waiting for an unlock prompt", ErrTimeout, timeout)
+	}
+}
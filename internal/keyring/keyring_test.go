@@ -0,0 +1,88 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// slowBackend answers every operation after a delay
+type slowBackend struct {
+	delay time.Duration
+}
+
+func (b slowBackend) Get(service, user string) (string, error) {
+	time.Sleep(b.delay)
+	return "", keyring.ErrNotFound
+}
+
+func (b slowBackend) Set(service, user, password string) error {
+	time.Sleep(b.delay)
+	return nil
+}
+
+func (b slowBackend) Delete(service, user string) error {
+	time.Sleep(b.delay)
+	return nil
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: DefaultTimeout},
+		{value: "30s", want: 30 * time.Second},
+		{value: "not-a-duration", want: DefaultTimeout},
+		{value: "-1s", want: DefaultTimeout},
+	}
+	for _, tt := range tests {
+		t.Setenv(TimeoutEnvVar, tt.value)
+		if got := Timeout(); got != tt.want {
+			t.Errorf("Timeout() with %s=%q = %s, want %s", TimeoutEnvVar, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOperations_SlowBackend(t *testing.T) {
+	t.Setenv(TimeoutEnvVar, "50ms")
+	t.Cleanup(SetBackend(slowBackend{delay: 2 * time.Second}))
+
+	operations := map[string]func() error{
+		"get": func() error {
+			_, err := Get("sstart-test", "user")
+			return err
+		},
+		"set":    func() error { return Set("sstart-test", "user", "secret") },
+		"delete": func() error { return Delete("sstart-test", "user") },
+	}
+	for name, op := range operations {
+		started := time.Now()
+		err := op()
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("%s: expected ErrTimeout, got %v", name, err)
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("%s: expected to give up after the timeout, took %s", name, elapsed)
+		}
+	}
+}
+
+func TestOperations_Backend(t *testing.T) {
+	keyring.MockInit()
+
+	if err := Set("sstart-test", "user", "secret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := Get("sstart-test", "user"); err != nil || got != "secret" {
+		t.Errorf("Get() = %q, %v, want %q", got, err, "secret")
+	}
+	if err := Delete("sstart-test", "user"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := Get("sstart-test", "user"); err != ErrNotFound {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package logger provides a small leveled logger for sstart's diagnostic output.
+// Warnings are recorded so a consolidated summary can be printed at the end of a run.
+// Repeated warnings are recorded once, and at most MaxWarnings distinct ones are kept, so
+// long-running modes (watch, MCP) do not accumulate them without bound.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// MaxWarnings is the maximum number of distinct warnings recorded for the summary; later ones are only counted
+const MaxWarnings = 100
+
+// Level is a logging level
+type Level int
+
+const (
+	// LevelDebug enables all messages
+	LevelDebug Level = iota
+	// LevelInfo enables informational messages and warnings
+	LevelInfo
+	// LevelWarn enables warnings only (default)
+	LevelWarn
+	// LevelQuiet disables all messages; warnings are still recorded
+	LevelQuiet
+)
+
+// Logger writes leveled messages and records warnings
+type Logger struct {
+	mu       sync.Mutex
+	out      io.Writer
+	level    Level
+	warnings []string       // Distinct warnings, in the order they were first recorded
+	repeats  map[string]int // Number of times each recorded warning was issued
+	dropped  int            // Number of warnings issued after MaxWarnings distinct ones were recorded
+}
+
+// New creates a logger writing to out at the given level
+func New(out io.Writer, level Level) *Logger {
+	return &Logger{out: out, level: level}
+}
+
+// std is the process-wide logger used by the package-level functions
+var std = New(os.Stderr, LevelWarn)
+
+// Default returns the process-wide logger
+func Default() *Logger {
+	return std
+}
+
+// SetLevel sets the minimum level of messages written
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// SetOutput sets the writer messages are written to
+func (l *Logger) SetOutput(out io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = out
+}
+
+// Enabled reports whether messages of the given level are written
+func (l *Logger) Enabled(level Level) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return level >= l.level
+}
+
+// logf writes a message if the level is enabled
+func (l *Logger) logf(level Level, prefix, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level {
+		return
+	}
+	fmt.Fprintf(l.out, prefix+format+"\n", args...)
+}
+
+// Debugf writes a debug message
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, "DEBUG: ", format, args...)
+}
+
+// Infof writes an informational message
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, "INFO: ", format, args...)
+}
+
+// Warnf writes a warning and records it for the summary
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.record(fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+	l.logf(LevelWarn, "WARN: ", format, args...)
+}
+
+// record adds a warning for the summary, counting repeats of already recorded ones
+func (l *Logger) record(warning string) {
+	if _, seen := l.repeats[warning]; seen {
+		l.repeats[warning]++
+		return
+	}
+	if len(l.warnings) >= MaxWarnings {
+		l.dropped++
+		return
+	}
+	if l.repeats == nil {
+		l.repeats = make(map[string]int)
+	}
+	l.warnings = append(l.warnings, warning)
+	l.repeats[warning] = 1
+}
+
+// Warnings returns the distinct warnings recorded so far
+func (l *Logger) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warnings...)
+}
+
+// Reset clears the recorded warnings
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = nil
+	l.repeats = nil
+	l.dropped = 0
+}
+
+// PrintSummary writes the count and list of recorded warnings, each once with the number of times it was issued.
+// Nothing is written if there are no warnings or the logger is quiet.
+func (l *Logger) PrintSummary() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.warnings) == 0 || l.level >= LevelQuiet {
+		return
+	}
+
+	total := l.dropped
+	for _, warning := range l.warnings {
+		total += l.repeats[warning]
+	}
+	noun := "warnings"
+	if total == 1 {
+		noun = "warning"
+	}
+	fmt.Fprintf(l.out, "sstart: %d %s:\n", total, noun)
+	for _, warning := range l.warnings {
+		if n := l.repeats[warning]; n > 1 {
+			fmt.Fprintf(l.out, "  - %s (%d times)\n", warning, n)
+		} else {
+			fmt.Fprintf(l.out, "  - %s\n", warning)
+		}
+	}
+	if l.dropped > 0 {
+		fmt.Fprintf(l.out, "  - ... and %d more\n", l.dropped)
+	}
+}
+
+// SetLevel sets the level of the process-wide logger
+func SetLevel(level Level) {
+	std.SetLevel(level)
+}
+
+// Enabled reports whether the process-wide logger writes messages of the given level
+func Enabled(level Level) bool {
+	return std.Enabled(level)
+}
+
+// Debugf writes a debug message to the process-wide logger
+func Debugf(format string, args ...interface{}) {
+	std.Debugf(format, args...)
+}
+
+// Infof writes an informational message to the process-wide logger
+func Infof(format string, args ...interface{}) {
+	std.Infof(format, args...)
+}
+
+// Warnf writes a warning to the process-wide logger and records it for the summary
+func Warnf(format string, args ...interface{}) {
+	std.Warnf(format, args...)
+}
+
+// PrintSummary writes the warning summary of the process-wide logger
+func PrintSummary() {
+	std.PrintSummary()
+}
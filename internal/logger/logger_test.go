@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelWarn)
+
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 3)
+
+	if got := buf.String(); got != "WARN: warn 3\n" {
+		t.Errorf("output = %q, want only the warning", got)
+	}
+
+	buf.Reset()
+	l.SetLevel(LevelDebug)
+	l.Debugf("debug")
+	l.Infof("info")
+	if got := buf.String(); got != "DEBUG: debug\nINFO: info\n" {
+		t.Errorf("output = %q, want debug and info messages", got)
+	}
+}
+
+func TestLogger_Enabled(t *testing.T) {
+	l := New(&bytes.Buffer{}, LevelWarn)
+	if l.Enabled(LevelInfo) || !l.Enabled(LevelWarn) {
+		t.Errorf("expected only warnings to be enabled at LevelWarn")
+	}
+
+	l.SetLevel(LevelQuiet)
+	if l.Enabled(LevelWarn) {
+		t.Errorf("expected warnings to be disabled at LevelQuiet")
+	}
+}
+
+func TestLogger_Summary(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelWarn)
+
+	l.PrintSummary()
+	if buf.Len() != 0 {
+		t.Errorf("expected no summary without warnings, got %q", buf.String())
+	}
+
+	l.Warnf("Secret from provider '%s' is not JSON format", "aws")
+	l.Warnf("second warning")
+	buf.Reset()
+	l.PrintSummary()
+
+	summary := buf.String()
+	if !strings.HasPrefix(summary, "sstart: 2 warnings:\n") {
+		t.Errorf("summary should start with the count, got %q", summary)
+	}
+	if !strings.Contains(summary, "  - Secret from provider 'aws' is not JSON format\n") || !strings.Contains(summary, "  - second warning\n") {
+		t.Errorf("summary should list all warnings, got %q", summary)
+	}
+}
+
+func TestLogger_SummaryRepeatsAndCap(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelWarn)
+
+	for i := 0; i < 3; i++ {
+		l.Warnf("Failed to collect secrets, keeping the current command running")
+	}
+	for i := 0; i < MaxWarnings+5; i++ {
+		l.Warnf("warning %d", i)
+	}
+	if warnings := l.Warnings(); len(warnings) != MaxWarnings {
+		t.Errorf("expected %d recorded warnings, got %d", MaxWarnings, len(warnings))
+	}
+
+	buf.Reset()
+	l.PrintSummary()
+	summary := buf.String()
+	if !strings.HasPrefix(summary, "sstart: 108 warnings:\n") {
+		t.Errorf("summary should count every warning issued, got %q", summary[:strings.Index(summary, "\n")+1])
+	}
+	if strings.Count(summary, "keeping the current command running") != 1 || !strings.Contains(summary, "keeping the current command running (3 times)\n") {
+		t.Errorf("summary should list a repeated warning once with its count, got %q", summary)
+	}
+	if strings.Contains(summary, fmt.Sprintf("warning %d\n", MaxWarnings-1)) || !strings.HasSuffix(summary, "  - ... and 6 more\n") {
+		t.Errorf("summary should stop at %d distinct warnings, got %q", MaxWarnings, summary)
+	}
+
+	l.Reset()
+	if warnings := l.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings after Reset, got %v", warnings)
+	}
+}
+
+func TestLogger_Quiet(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelQuiet)
+
+	l.Warnf("hidden")
+	l.PrintSummary()
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output when quiet, got %q", buf.String())
+	}
+	if warnings := l.Warnings(); len(warnings) != 1 || warnings[0] != "hidden" {
+		t.Errorf("expected warning to be recorded, got %v", warnings)
+	}
+}
@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	// SessionIDHeader is the header used by remote MCP servers to track a session
+	SessionIDHeader = "Mcp-Session-Id"
+)
+
+// HTTPTransport implements the MCP streamable HTTP transport for remote servers.
+// Every outgoing message is POSTed to the server URL. Responses are either a single
+// JSON body or a text/event-stream of JSON-RPC messages, and are queued for ReadMessage.
+type HTTPTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	incoming  chan *JSONRPCMessage
+	sessionID string
+	sessionMu sync.RWMutex
+	closeOnce sync.Once
+}
+
+// NewHTTPTransport creates a new HTTP transport for the given URL.
+// The headers are sent with every request (e.g., Authorization).
+func NewHTTPTransport(url string, headers map[string]string) *HTTPTransport {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &HTTPTransport{
+		url:      url,
+		headers:  headers,
+		client:   &http.Client{},
+		ctx:      ctx,
+		cancel:   cancel,
+		incoming: make(chan *JSONRPCMessage, 16),
+	}
+}
+
+// ReadMessage returns the next message received from the server.
+// It returns io.EOF once the transport is closed.
+func (t *HTTPTransport) ReadMessage() (*JSONRPCMessage, error) {
+	select {
+	case msg := <-t.incoming:
+		return msg, nil
+	case <-t.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// WriteMessage POSTs a JSON-RPC message to the server and queues any messages in the response
+func (t *HTTPTransport) WriteMessage(msg *JSONRPCMessage) error {
+	if t.ctx.Err() != nil {
+		return fmt.Errorf("transport is closed")
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	t.sessionMu.RLock()
+	if t.sessionID != "" {
+		req.Header.Set(SessionIDHeader, t.sessionID)
+	}
+	t.sessionMu.RUnlock()
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if sessionID := resp.Header.Get(SessionIDHeader); sessionID != "" {
+		t.sessionMu.Lock()
+		t.sessionID = sessionID
+		t.sessionMu.Unlock()
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	// Notifications and responses are acknowledged without a body
+	if resp.StatusCode == http.StatusAccepted || resp.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return t.readEventStream(resp.Body)
+	}
+	return t.readJSONBody(resp.Body)
+}
+
+// readJSONBody queues a single message or a batch of messages from a JSON body
+func (t *HTTPTransport) readJSONBody(body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	if data[0] == '[' {
+		var msgs []*JSONRPCMessage
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		for _, msg := range msgs {
+			t.enqueue(msg)
+		}
+		return nil
+	}
+
+	var msg JSONRPCMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	t.enqueue(&msg)
+	return nil
+}
+
+// readEventStream queues every message event from a server-sent events stream
+func (t *HTTPTransport) readEventStream(body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		payload := strings.Join(data, "\n")
+		data = data[:0]
+
+		var msg JSONRPCMessage
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			return fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		t.enqueue(&msg)
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Other fields (event, id, retry) and comments are ignored
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return dispatch()
+}
+
+// enqueue hands a received message to ReadMessage unless the transport is closed
+func (t *HTTPTransport) enqueue(msg *JSONRPCMessage) {
+	select {
+	case t.incoming <- msg:
+	case <-t.ctx.Done():
+	}
+}
+
+// Close cancels in-flight requests and unblocks ReadMessage
+func (t *HTTPTransport) Close() error {
+	t.closeOnce.Do(t.cancel)
+	return nil
+}
+
+// RenderHeaders renders header values as Go templates over the collected secrets,
+// so a header like "Bearer {{.API_TOKEN}}" receives the value of API_TOKEN
+func RenderHeaders(headers map[string]string, secrets map[string]string) (map[string]string, error) {
+	rendered := make(map[string]string, len(headers))
+	for name, value := range headers {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse header '%s': %w", name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, secrets); err != nil {
+			return nil, fmt.Errorf("failed to render header '%s': %w", name, err)
+		}
+		rendered[name] = buf.String()
+	}
+	return rendered, nil
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRemoteMCPServer starts an MCP-like HTTP server that requires the given Authorization header.
+// initialize is answered with a JSON body and tools/list with an event stream.
+func newRemoteMCPServer(t *testing.T, wantAuth string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != wantAuth {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var msg JSONRPCMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch msg.Method {
+		case MethodInitialize:
+			resp, _ := NewJSONRPCResponse(msg.ID.Value(), InitializeResult{
+				ProtocolVersion: MCPProtocolVersion,
+				Capabilities:    &ServerCapabilities{Tools: &ToolCapabilities{}},
+				ServerInfo:      &Implementation{Name: "remote", Version: "1.0.0"},
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(SessionIDHeader, "session-1")
+			json.NewEncoder(w).Encode(resp)
+		case MethodToolsList:
+			if r.Header.Get(SessionIDHeader) != "session-1" {
+				http.Error(w, "missing session", http.StatusBadRequest)
+				return
+			}
+			resp, _ := NewJSONRPCResponse(msg.ID.Value(), ToolsListResult{
+				Tools: []Tool{{Name: "search", Description: "Search things"}},
+			})
+			data, _ := json.Marshal(resp)
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+}
+
+func TestProxy_AggregatesRemoteHTTPServer(t *testing.T) {
+	server := newRemoteMCPServer(t, "Bearer remote-token")
+	defer server.Close()
+
+	configs := []ServerConfig{
+		{
+			ID:      "remote",
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer {{.API_TOKEN}}"},
+		},
+	}
+	manager := NewServerManager(configs, map[string]string{"API_TOKEN": "remote-token"}, false)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	}, "\n") + "\n"
+	var output bytes.Buffer
+
+	proxy := NewProxy(manager, NewStdioTransport(strings.NewReader(input), &output), "test")
+	if err := proxy.Run(context.Background()); err != nil {
+		t.Fatalf("proxy.Run() error: %v", err)
+	}
+	defer proxy.Stop()
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %s", len(lines), output.String())
+	}
+
+	var resp JSONRPCMessage
+	if err := json.Unmarshal([]byte(lines[1]), &resp); err != nil {
+		t.Fatalf("failed to unmarshal tools/list response: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("tools/list returned error: %s", resp.Error.Message)
+	}
+
+	var result ToolsListResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("failed to unmarshal tools list: %v", err)
+	}
+	if len(result.Tools) != 1 || result.Tools[0].Name != "remote/search" {
+		t.Errorf("expected tool 'remote/search', got %+v", result.Tools)
+	}
+}
+
+func TestHTTPTransport_Unauthorized(t *testing.T) {
+	server := newRemoteMCPServer(t, "Bearer remote-token")
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.URL, map[string]string{"Authorization": "Bearer wrong"})
+	defer transport.Close()
+
+	msg, _ := NewJSONRPCRequest(1, MethodToolsList, nil)
+	err := transport.WriteMessage(msg)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected 401 error, got %v", err)
+	}
+}
+
+func TestRenderHeaders(t *testing.T) {
+	secrets := map[string]string{"API_TOKEN": "abc"}
+
+	headers, err := RenderHeaders(map[string]string{
+		"Authorization": "Bearer {{.API_TOKEN}}",
+		"X-Static":      "static",
+	}, secrets)
+	if err != nil {
+		t.Fatalf("RenderHeaders() error: %v", err)
+	}
+	if headers["Authorization"] != "Bearer abc" {
+		t.Errorf("Authorization = %q, want %q", headers["Authorization"], "Bearer abc")
+	}
+	if headers["X-Static"] != "static" {
+		t.Errorf("X-Static = %q, want %q", headers["X-Static"], "static")
+	}
+
+	if _, err := RenderHeaders(map[string]string{"Authorization": "Bearer {{.MISSING}}"}, secrets); err == nil {
+		t.Error("expected error for missing secret, got nil")
+	}
+}
@@ -42,6 +42,9 @@ const (
 	MethodPing                   = "ping"
 	MethodCancelled              = "notifications/cancelled"
 	MethodProgress               = "notifications/progress"
+	MethodToolsListChanged       = "notifications/tools/list_changed"
+	MethodResourcesListChanged   = "notifications/resources/list_changed"
+	MethodPromptsListChanged     = "notifications/prompts/list_changed"
 )
 
 // Re-export SDK types for use in our implementation
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
 )
 
 const (
-	// NamespaceSeparator is used to prefix primitive names with server ID
+	// NamespaceSeparator is the default separator used to prefix primitive names with server ID
 	NamespaceSeparator = "/"
 )
 
@@ -25,28 +26,97 @@ type Proxy struct {
 	// Proxy info
 	proxyInfo Implementation
 
+	// Separator between server ID and primitive names (see SetNamespaceSeparator)
+	separator string
+
 	// Client info (received during initialization)
 	clientInfo         *Implementation
 	clientCapabilities *ClientCapabilities
 
-	// Aggregated primitives cache
-	toolsCache             []Tool
-	resourcesCache         []Resource
-	resourceTemplatesCache []ResourceTemplate
-	promptsCache           []Prompt
-	cacheOnce              sync.Once
-	cacheMu                sync.RWMutex
+	// Aggregated primitives cache, invalidated by the list_changed notifications of downstream servers
+	toolsCache             primitiveCache[Tool]
+	resourcesCache         primitiveCache[Resource]
+	resourceTemplatesCache primitiveCache[ResourceTemplate]
+	promptsCache           primitiveCache[Prompt]
+	cacheMu                sync.Mutex // Serializes aggregations, so concurrent list requests query servers once
+}
+
+// primitiveCache holds an aggregated list of primitives. Its mutex is never held while querying servers,
+// so a notification can invalidate it while an aggregation waits for a server.
+type primitiveCache[T any] struct {
+	mu         sync.Mutex
+	items      []T
+	valid      bool
+	generation uint64 // Incremented by invalidate, so an aggregation that raced it is not stored
+}
+
+// get returns the cached items, whether they are valid, and the generation to pass to store
+func (c *primitiveCache[T]) get() ([]T, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items, c.valid, c.generation
+}
+
+// store caches items aggregated since get returned generation, unless the cache was invalidated meanwhile
+func (c *primitiveCache[T]) store(items []T, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.items, c.valid = items, true
+	}
+}
+
+// invalidate drops the cached items, so the next list request queries the servers again
+func (c *primitiveCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items, c.valid = nil, false
+	c.generation++
 }
 
 // NewProxy creates a new MCP proxy
 func NewProxy(manager *ServerManager, transport Transport, version string) *Proxy {
-	return &Proxy{
+	p := &Proxy{
 		manager:   manager,
 		transport: transport,
 		proxyInfo: Implementation{
 			Name:    "sstart-mcp-proxy",
 			Version: version,
 		},
+		separator: NamespaceSeparator,
+	}
+	manager.SetNotificationHandler(p.handleServerNotification)
+	return p
+}
+
+// SetNamespaceSeparator sets the separator between server ID and primitive names (default:
+// NamespaceSeparator), e.g. "__" for clients that reject "/" in tool names. It must not be empty
+// or contain letters, digits or '%', so that escaped server IDs never contain it.
+func (p *Proxy) SetNamespaceSeparator(separator string) {
+	p.separator = separator
+}
+
+// handleServerNotification invalidates the cached primitives a downstream server reports as changed
+// and forwards the notification to the client, which lists them again
+func (p *Proxy) handleServerNotification(serverID string, msg *JSONRPCMessage) {
+	switch msg.Method {
+	case MethodToolsListChanged:
+		p.toolsCache.invalidate()
+	case MethodResourcesListChanged:
+		p.resourcesCache.invalidate()
+		p.resourceTemplatesCache.invalidate()
+	case MethodPromptsListChanged:
+		p.promptsCache.invalidate()
+	default:
+		return
+	}
+
+	notification, err := NewJSONRPCNotification(msg.Method, nil)
+	if err != nil {
+		return
+	}
+	if err := p.transport.WriteMessage(notification); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to forward %s from server '%s': %v\n", msg.Method, serverID, err)
 	}
 }
 
@@ -146,9 +216,9 @@ func (p *Proxy) handleInitialize(msg *JSONRPCMessage) (*JSONRPCMessage, error) {
 	result := InitializeResult{
 		ProtocolVersion: MCPProtocolVersion,
 		Capabilities: &ServerCapabilities{
-			Tools:     &ToolCapabilities{ListChanged: false},
-			Resources: &ResourceCapabilities{Subscribe: false, ListChanged: false},
-			Prompts:   &PromptCapabilities{ListChanged: false},
+			Tools:     &ToolCapabilities{ListChanged: true},
+			Resources: &ResourceCapabilities{Subscribe: false, ListChanged: true},
+			Prompts:   &PromptCapabilities{ListChanged: true},
 		},
 		ServerInfo:   &p.proxyInfo,
 		Instructions: "sstart MCP proxy - aggregates multiple MCP servers with secret injection",
@@ -349,15 +419,19 @@ func (p *Proxy) handlePromptsGet(msg *JSONRPCMessage) (*JSONRPCMessage, error) {
 	return resp, nil
 }
 
-// parseNamespacedName parses a namespaced name (serverID/name) into its components
+// parseNamespacedName parses a namespaced name (serverID/name) into its components. As escaped
+// server IDs never contain the separator, the name may contain it.
 func (p *Proxy) parseNamespacedName(namespacedName string) (serverID, name string, err error) {
-	idx := strings.Index(namespacedName, NamespaceSeparator)
+	idx := strings.Index(namespacedName, p.separator)
 	if idx == -1 {
 		return "", "", fmt.Errorf("invalid namespaced name '%s': missing server ID prefix", namespacedName)
 	}
 
-	serverID = namespacedName[:idx]
-	name = namespacedName[idx+1:]
+	serverID, err = url.PathUnescape(namespacedName[:idx])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid namespaced name '%s': %w", namespacedName, err)
+	}
+	name = namespacedName[idx+len(p.separator):]
 
 	if serverID == "" {
 		return "", "", fmt.Errorf("invalid namespaced name '%s': empty server ID", namespacedName)
@@ -369,9 +443,24 @@ func (p *Proxy) parseNamespacedName(namespacedName string) (serverID, name strin
 	return serverID, name, nil
 }
 
-// namespaceName prefixes a name with the server ID
+// namespaceName prefixes a name with the escaped server ID
 func (p *Proxy) namespaceName(serverID, name string) string {
-	return serverID + NamespaceSeparator + name
+	return escapeServerID(serverID, p.separator) + p.separator + name
+}
+
+// escapeServerID percent-encodes '%' and the characters of the separator in a server ID, so the
+// escaped ID never contains the separator. Other IDs are left unchanged.
+func escapeServerID(serverID, separator string) string {
+	var b strings.Builder
+	for i := 0; i < len(serverID); i++ {
+		c := serverID[i]
+		if c == '%' || strings.IndexByte(separator, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
 }
 
 // ensureServerInitialized ensures the server is started and initialized
@@ -399,23 +488,32 @@ func (p *Proxy) getAggregatedTools() ([]Tool, error) {
 	p.cacheMu.Lock()
 	defer p.cacheMu.Unlock()
 
+	cached, valid, generation := p.toolsCache.get()
+	if valid {
+		return cached, nil
+	}
+
 	var allTools []Tool
+	complete := true
 
 	for _, serverID := range p.manager.Servers() {
 		server, err := p.manager.GetOrStartServer(p.ctx, serverID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to start server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		if err := p.ensureServerInitialized(server); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to initialize server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		tools, err := server.FetchTools(p.ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch tools from server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
@@ -430,6 +528,10 @@ func (p *Proxy) getAggregatedTools() ([]Tool, error) {
 		}
 	}
 
+	// Servers that failed are queried again on the next request
+	if complete {
+		p.toolsCache.store(allTools, generation)
+	}
 	return allTools, nil
 }
 
@@ -438,23 +540,32 @@ func (p *Proxy) getAggregatedResources() ([]Resource, error) {
 	p.cacheMu.Lock()
 	defer p.cacheMu.Unlock()
 
+	cached, valid, generation := p.resourcesCache.get()
+	if valid {
+		return cached, nil
+	}
+
 	var allResources []Resource
+	complete := true
 
 	for _, serverID := range p.manager.Servers() {
 		server, err := p.manager.GetOrStartServer(p.ctx, serverID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to start server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		if err := p.ensureServerInitialized(server); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to initialize server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		resources, err := server.FetchResources(p.ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch resources from server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
@@ -470,6 +581,10 @@ func (p *Proxy) getAggregatedResources() ([]Resource, error) {
 		}
 	}
 
+	// Servers that failed are queried again on the next request
+	if complete {
+		p.resourcesCache.store(allResources, generation)
+	}
 	return allResources, nil
 }
 
@@ -478,23 +593,32 @@ func (p *Proxy) getAggregatedResourceTemplates() ([]ResourceTemplate, error) {
 	p.cacheMu.Lock()
 	defer p.cacheMu.Unlock()
 
+	cached, valid, generation := p.resourceTemplatesCache.get()
+	if valid {
+		return cached, nil
+	}
+
 	var allTemplates []ResourceTemplate
+	complete := true
 
 	for _, serverID := range p.manager.Servers() {
 		server, err := p.manager.GetOrStartServer(p.ctx, serverID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to start server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		if err := p.ensureServerInitialized(server); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to initialize server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		templates, err := server.FetchResourceTemplates(p.ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch resource templates from server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
@@ -510,6 +634,10 @@ func (p *Proxy) getAggregatedResourceTemplates() ([]ResourceTemplate, error) {
 		}
 	}
 
+	// Servers that failed are queried again on the next request
+	if complete {
+		p.resourceTemplatesCache.store(allTemplates, generation)
+	}
 	return allTemplates, nil
 }
 
@@ -518,23 +646,32 @@ func (p *Proxy) getAggregatedPrompts() ([]Prompt, error) {
 	p.cacheMu.Lock()
 	defer p.cacheMu.Unlock()
 
+	cached, valid, generation := p.promptsCache.get()
+	if valid {
+		return cached, nil
+	}
+
 	var allPrompts []Prompt
+	complete := true
 
 	for _, serverID := range p.manager.Servers() {
 		server, err := p.manager.GetOrStartServer(p.ctx, serverID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to start server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		if err := p.ensureServerInitialized(server); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to initialize server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
 		prompts, err := server.FetchPrompts(p.ctx)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch prompts from server '%s': %v\n", serverID, err)
+			complete = false
 			continue
 		}
 
@@ -549,5 +686,9 @@ func (p *Proxy) getAggregatedPrompts() ([]Prompt, error) {
 		}
 	}
 
+	// Servers that failed are queried again on the next request
+	if complete {
+		p.promptsCache.store(allPrompts, generation)
+	}
 	return allPrompts, nil
 }
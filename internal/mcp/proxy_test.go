@@ -0,0 +1,285 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newChangingMCPServer starts an MCP-like HTTP server whose tools change on the first tools/call:
+// the call's response is preceded by a notifications/tools/list_changed in the same batch.
+// It counts the tools/list requests it receives.
+func newChangingMCPServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var listCalls atomic.Int32
+	var changed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg JSONRPCMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch msg.Method {
+		case MethodInitialize:
+			resp, _ := NewJSONRPCResponse(msg.ID.Value(), InitializeResult{
+				ProtocolVersion: MCPProtocolVersion,
+				Capabilities:    &ServerCapabilities{Tools: &ToolCapabilities{ListChanged: true}},
+				ServerInfo:      &Implementation{Name: "changing", Version: "1.0.0"},
+			})
+			json.NewEncoder(w).Encode(resp)
+		case MethodToolsList:
+			listCalls.Add(1)
+			tools := []Tool{{Name: "search"}}
+			if changed.Load() {
+				tools = append(tools, Tool{Name: "fetch"})
+			}
+			resp, _ := NewJSONRPCResponse(msg.ID.Value(), ToolsListResult{Tools: tools})
+			json.NewEncoder(w).Encode(resp)
+		case MethodToolsCall:
+			changed.Store(true)
+			notification, _ := NewJSONRPCNotification(MethodToolsListChanged, nil)
+			resp, _ := NewJSONRPCResponse(msg.ID.Value(), map[string]any{"content": []any{}})
+			json.NewEncoder(w).Encode([]*JSONRPCMessage{notification, resp})
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	return server, &listCalls
+}
+
+func TestProxy_CachesToolsUntilListChanged(t *testing.T) {
+	server, listCalls := newChangingMCPServer(t)
+	defer server.Close()
+
+	manager := NewServerManager([]ServerConfig{{ID: "remote", URL: server.URL}}, nil, false)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"remote/search"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/list"}`,
+	}, "\n") + "\n"
+	var output bytes.Buffer
+
+	proxy := NewProxy(manager, NewStdioTransport(strings.NewReader(input), &output), "test")
+	if err := proxy.Run(context.Background()); err != nil {
+		t.Fatalf("proxy.Run() error: %v", err)
+	}
+	defer proxy.Stop()
+
+	if got := listCalls.Load(); got != 2 {
+		t.Errorf("expected 2 downstream tools/list requests (cached, then refreshed after list_changed), got %d", got)
+	}
+
+	toolCounts := make(map[int64]int)
+	notified := false
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var msg JSONRPCMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("failed to unmarshal proxy output %q: %v", line, err)
+		}
+		if msg.Method == MethodToolsListChanged {
+			notified = true
+			continue
+		}
+		switch id := normalizeID(msg.ID.Value()).(int64); id {
+		case 1:
+			var result InitializeResult
+			json.Unmarshal(msg.Result, &result)
+			if result.Capabilities == nil || result.Capabilities.Tools == nil || !result.Capabilities.Tools.ListChanged {
+				t.Errorf("expected the proxy to advertise tools.listChanged, got %s", msg.Result)
+			}
+		case 2, 3, 5:
+			var result ToolsListResult
+			if err := json.Unmarshal(msg.Result, &result); err != nil {
+				t.Fatalf("failed to unmarshal tools list: %v", err)
+			}
+			toolCounts[id] = len(result.Tools)
+		}
+	}
+
+	if !notified {
+		t.Error("expected notifications/tools/list_changed to be forwarded to the client")
+	}
+	if toolCounts[2] != 1 || toolCounts[3] != 1 || toolCounts[5] != 2 {
+		t.Errorf("unexpected tool counts per tools/list response: %v", toolCounts)
+	}
+}
+
+func TestProxy_NamespacedNameRoundTrip(t *testing.T) {
+	tests := []struct {
+		separator string
+		serverID  string
+		name      string
+		want      string
+	}{
+		{separator: "/", serverID: "github", name: "search", want: "github/search"},
+		{separator: "/", serverID: "github", name: "repos/search", want: "github/repos/search"},
+		{separator: "/", serverID: "team/github", name: "repos/search", want: "team%2Fgithub/repos/search"},
+		{separator: "/", serverID: "100%", name: "file:///tmp/a", want: "100%25/file:///tmp/a"},
+		{separator: "__", serverID: "my_db", name: "run__query", want: "my%5Fdb__run__query"},
+		{separator: "__", serverID: "github", name: "search", want: "github__search"},
+		{separator: ".", serverID: "a.b", name: "c.d", want: "a%2Eb.c.d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.separator+tt.serverID+tt.name, func(t *testing.T) {
+			proxy := NewProxy(NewServerManager(nil, nil, false), NewStdioTransport(strings.NewReader(""), &bytes.Buffer{}), "test")
+			proxy.SetNamespaceSeparator(tt.separator)
+
+			namespaced := proxy.namespaceName(tt.serverID, tt.name)
+			if namespaced != tt.want {
+				t.Errorf("namespaceName() = %q, want %q", namespaced, tt.want)
+			}
+
+			serverID, name, err := proxy.parseNamespacedName(namespaced)
+			if err != nil {
+				t.Fatalf("parseNamespacedName(%q) error: %v", namespaced, err)
+			}
+			if serverID != tt.serverID || name != tt.name {
+				t.Errorf("parseNamespacedName(%q) = (%q, %q), want (%q, %q)", namespaced, serverID, name, tt.serverID, tt.name)
+			}
+		})
+	}
+}
+
+func TestProxy_ParseNamespacedNameErrors(t *testing.T) {
+	proxy := NewProxy(NewServerManager(nil, nil, false), NewStdioTransport(strings.NewReader(""), &bytes.Buffer{}), "test")
+
+	for _, namespaced := range []string{"search", "/search", "github/", "bad%zz/search"} {
+		if _, _, err := proxy.parseNamespacedName(namespaced); err == nil {
+			t.Errorf("parseNamespacedName(%q) expected error, got nil", namespaced)
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use, as notifications are written from server goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// restartingServerScript is a stdio MCP server recording its runs in the file passed as $0. It offers a
+// second tool once it has been restarted.
+const restartingServerScript = `echo run >> "$0"
+runs=$(wc -l < "$0")
+while IFS= read -r line; do
+    id=$(echo "$line" | grep -o '"id":[0-9]*' | cut -d':' -f2)
+    case "$line" in
+        *'"method":"initialize"'*)
+            echo '{"jsonrpc":"2.0","id":'$id',"result":{"protocolVersion":"2024-11-05","capabilities":{"tools":{}},"serverInfo":{"name":"restarting","version":"1.0.0"}}}' ;;
+        *'"method":"tools/list"'*)
+            tools='{"name":"search"}'
+            if [ "$runs" -gt 1 ]; then tools="$tools"',{"name":"fetch"}'; fi
+            echo '{"jsonrpc":"2.0","id":'$id',"result":{"tools":['$tools']}}' ;;
+    esac
+done`
+
+func TestProxy_RestartedServerRefreshesTools(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+
+	manager := NewServerManager([]ServerConfig{{
+		ID:             "local",
+		Command:        "sh",
+		Args:           []string{"-c", restartingServerScript, runs},
+		Restart:        RestartOnFailure,
+		RestartBackoff: 10 * time.Millisecond,
+	}}, nil, true)
+
+	var output lockedBuffer
+	proxy := NewProxy(manager, NewStdioTransport(strings.NewReader(""), &output), "test")
+	proxy.ctx = context.Background()
+	defer proxy.Stop()
+
+	tools, err := proxy.getAggregatedTools()
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("expected 1 tool before the crash, got %v (error: %v)", tools, err)
+	}
+
+	server, _ := manager.GetServer("local")
+	server.cmd.Process.Kill()
+	waitForState(t, server, ServerStateCrashed)
+	waitForOutput(t, &output, MethodPromptsListChanged)
+	before := len(output.String())
+
+	if _, err := manager.GetOrStartServer(context.Background(), "local"); err != nil {
+		t.Fatalf("GetOrStartServer() error: %v", err)
+	}
+
+	tools, err = proxy.getAggregatedTools()
+	if err != nil || len(tools) != 2 {
+		t.Errorf("expected the restart to refresh the cached tools, got %v (error: %v)", tools, err)
+	}
+	restarted := output.String()[before:]
+	for _, method := range []string{MethodToolsListChanged, MethodResourcesListChanged, MethodPromptsListChanged} {
+		if !strings.Contains(restarted, `"method":"`+method+`"`) {
+			t.Errorf("expected %s to be sent to the client after the restart, got %s", method, restarted)
+		}
+	}
+}
+
+func TestProxy_CrashedServerDropsTools(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+
+	manager := NewServerManager([]ServerConfig{{
+		ID:      "local",
+		Command: "sh",
+		Args:    []string{"-c", restartingServerScript, runs},
+	}}, nil, true)
+
+	var output lockedBuffer
+	proxy := NewProxy(manager, NewStdioTransport(strings.NewReader(""), &output), "test")
+	proxy.ctx = context.Background()
+	defer proxy.Stop()
+
+	tools, err := proxy.getAggregatedTools()
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("expected 1 tool before the crash, got %v (error: %v)", tools, err)
+	}
+
+	server, _ := manager.GetServer("local")
+	server.cmd.Process.Kill()
+	waitForOutput(t, &output, MethodToolsListChanged)
+
+	tools, err = proxy.getAggregatedTools()
+	if err != nil || len(tools) != 0 {
+		t.Errorf("expected the crashed server's tools to be dropped, got %v (error: %v)", tools, err)
+	}
+}
+
+// waitForOutput waits until the proxy has sent a notification with the given method to the client
+func waitForOutput(t *testing.T, output *lockedBuffer, method string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(output.String(), `"method":"`+method+`"`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s, got %s", method, output.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
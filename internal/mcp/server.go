@@ -8,16 +8,45 @@ import (
 	"os/exec"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // ServerConfig represents the configuration for a downstream MCP server
 type ServerConfig struct {
-	ID      string   `yaml:"id"`
-	Command string   `yaml:"command"`
-	Args    []string `yaml:"args"`
-	// Future: Secrets []string `yaml:"secrets"` for selective injection
+	ID      string            `yaml:"id"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	URL     string            `yaml:"url"`     // Remote server URL (used instead of Command)
+	Headers map[string]string `yaml:"headers"` // HTTP headers for remote servers, rendered as templates over secrets
+	Secrets []string          `yaml:"secrets"` // Keys of the secrets given to the server (nil: all secrets)
+	// How long a stopping server process may take to exit before it is killed (default: DefaultShutdownTimeout)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// Whether a server process that exited unexpectedly is restarted: RestartNever (default) or RestartOnFailure
+	Restart string `yaml:"restart"`
+	// Maximum number of restarts with RestartOnFailure (default: DefaultMaxRestarts)
+	MaxRestarts int `yaml:"max_restarts"`
+	// Delay before the first restart, doubled for each further restart (default: DefaultRestartBackoff)
+	RestartBackoff time.Duration `yaml:"restart_backoff"`
 }
 
+const (
+	// DefaultShutdownTimeout is how long a stopping server process may take to exit before it is killed
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// DefaultMaxRestarts is the maximum number of restarts of a server with RestartOnFailure
+	DefaultMaxRestarts = 3
+	// DefaultRestartBackoff is the delay before the first restart of a server with RestartOnFailure
+	DefaultRestartBackoff = time.Second
+)
+
+// Restart policies for server processes that exit unexpectedly
+const (
+	// RestartNever leaves a crashed server stopped (default)
+	RestartNever = "never"
+	// RestartOnFailure restarts a crashed server on its next use, up to MaxRestarts times
+	RestartOnFailure = "on-failure"
+)
+
 // ServerState represents the current state of a server
 type ServerState int
 
@@ -27,19 +56,22 @@ const (
 	ServerStateRunning
 	ServerStateStopping
 	ServerStateError
+	ServerStateCrashed // The server process exited while running
 )
 
 // Server represents a downstream MCP server instance
 type Server struct {
 	config     ServerConfig
 	cmd        *exec.Cmd
-	transport  *PipeTransport
+	transport  Transport
 	state      atomic.Int32
 	stateMu    sync.RWMutex
 	startMu    sync.Mutex
 	secrets    map[string]string
 	inherit    bool
 	cancelFunc context.CancelFunc
+	exited     chan struct{} // Closed once the server process has exited
+	restarts   int           // Number of restarts after crashes, guarded by startMu
 
 	// Cached capabilities after initialization
 	capabilities *ServerCapabilities
@@ -57,18 +89,49 @@ type Server struct {
 	pendingRequests   map[interface{}]chan *JSONRPCMessage
 	pendingRequestsMu sync.Mutex
 	nextRequestID     atomic.Int64
+
+	// Handler of notifications sent by the server (set before the server starts)
+	notificationHandler func(serverID string, msg *JSONRPCMessage)
 }
 
-// NewServer creates a new server instance with the given configuration
+// NewServer creates a new server instance with the given configuration. The server only receives
+// the secrets listed in config.Secrets, or all secrets when it is nil.
 func NewServer(config ServerConfig, secrets map[string]string, inherit bool) *Server {
 	return &Server{
 		config:          config,
-		secrets:         secrets,
+		secrets:         scopeSecrets(config, secrets),
 		inherit:         inherit,
 		pendingRequests: make(map[interface{}]chan *JSONRPCMessage),
 	}
 }
 
+// SetNotificationHandler sets the function called with the notifications the server sends, such as
+// notifications/tools/list_changed. It is called from the goroutine reading the server's messages,
+// so it must not wait for responses of the server. After a restart, it is called with list_changed
+// notifications for all primitives, and likewise after a crash.
+func (s *Server) SetNotificationHandler(fn func(serverID string, msg *JSONRPCMessage)) {
+	s.notificationHandler = fn
+}
+
+// scopeSecrets returns the secrets listed in the server's configuration, warning about listed keys
+// that were not collected, or all secrets when the configuration lists none
+func scopeSecrets(config ServerConfig, secrets map[string]string) map[string]string {
+	if config.Secrets == nil {
+		return secrets
+	}
+
+	scoped := make(map[string]string, len(config.Secrets))
+	for _, key := range config.Secrets {
+		value, ok := secrets[key]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: secret '%s' for server '%s' was not collected\n", key, config.ID)
+			continue
+		}
+		scoped[key] = value
+	}
+	return scoped
+}
+
 // ID returns the server's ID
 func (s *Server) ID() string {
 	return s.config.ID
@@ -106,16 +169,32 @@ func (s *Server) Start(ctx context.Context) error {
 	s.startMu.Lock()
 	defer s.startMu.Unlock()
 
+	return s.start(ctx)
+}
+
+// start starts the server; the caller holds startMu
+func (s *Server) start(ctx context.Context) error {
 	if s.State() == ServerStateRunning {
 		return nil // Already running
 	}
 
 	s.state.Store(int32(ServerStateStarting))
 
-	// Create a cancellable context for this server
+	// A (re)started server must be initialized again
+	s.capabilities = nil
+	s.serverInfo = nil
+
+	// Create a cancellable context for this server, releasing the one of a crashed process
+	if s.cancelFunc != nil {
+		s.cancelFunc()
+	}
 	serverCtx, cancel := context.WithCancel(ctx)
 	s.cancelFunc = cancel
 
+	if s.config.URL != "" {
+		return s.startRemote(serverCtx)
+	}
+
 	// Create the command
 	s.cmd = exec.CommandContext(serverCtx, s.config.Command, s.config.Args...)
 	s.cmd.Env = s.buildEnv()
@@ -141,6 +220,7 @@ func (s *Server) Start(ctx context.Context) error {
 	s.transport = NewPipeTransport(stdin, stdout)
 
 	// Start the process
+	s.exited = make(chan struct{})
 	if err := s.cmd.Start(); err != nil {
 		s.transport.Close()
 		s.state.Store(int32(ServerStateError))
@@ -150,16 +230,34 @@ func (s *Server) Start(ctx context.Context) error {
 	s.state.Store(int32(ServerStateRunning))
 
 	// Start goroutine to read responses
-	go s.readResponses(serverCtx)
+	go s.readResponses(serverCtx, s.transport)
 
 	// Start goroutine to wait for process exit
-	go s.waitForExit()
+	go s.waitForExit(s.cmd, s.exited)
+
+	return nil
+}
+
+// startRemote connects to a remote MCP server over HTTP
+func (s *Server) startRemote(ctx context.Context) error {
+	headers, err := RenderHeaders(s.config.Headers, s.secrets)
+	if err != nil {
+		s.cancelFunc()
+		s.state.Store(int32(ServerStateError))
+		return err
+	}
+
+	s.transport = NewHTTPTransport(s.config.URL, headers)
+	s.state.Store(int32(ServerStateRunning))
+
+	go s.readResponses(ctx, s.transport)
 
 	return nil
 }
 
-// readResponses reads messages from the server and routes responses to waiting requests
-func (s *Server) readResponses(ctx context.Context) {
+// readResponses reads messages from the server and routes responses to waiting requests. The transport
+// is passed in, as a restart replaces it on the server.
+func (s *Server) readResponses(ctx context.Context, transport Transport) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -167,7 +265,7 @@ func (s *Server) readResponses(ctx context.Context) {
 		default:
 		}
 
-		msg, err := s.transport.ReadMessage()
+		msg, err := transport.ReadMessage()
 		if err != nil {
 			// Check if we're shutting down
 			if s.State() != ServerStateRunning {
@@ -188,9 +286,10 @@ func (s *Server) readResponses(ctx context.Context) {
 				delete(s.pendingRequests, normalizedID)
 			}
 			s.pendingRequestsMu.Unlock()
+		} else if msg.IsNotification() && s.notificationHandler != nil {
+			s.notificationHandler(s.config.ID, msg)
 		}
-		// Note: Server-initiated requests/notifications are not handled in this POC
-		// They would need to be forwarded to the proxy for handling
+		// Note: Server-initiated requests (e.g. sampling) are not handled
 	}
 }
 
@@ -211,11 +310,87 @@ func normalizeID(id interface{}) interface{} {
 	}
 }
 
-// waitForExit waits for the server process to exit
-func (s *Server) waitForExit() {
-	if s.cmd != nil && s.cmd.Process != nil {
-		s.cmd.Wait()
-		s.state.Store(int32(ServerStateStopped))
+// waitForExit waits for the server process to exit, then closes exited. The process and channel
+// are passed in, as a restart replaces them on the server.
+func (s *Server) waitForExit(cmd *exec.Cmd, exited chan struct{}) {
+	if cmd != nil && cmd.Process != nil {
+		err := cmd.Wait()
+
+		// A server still marked running was not stopped by us
+		if s.state.CompareAndSwap(int32(ServerStateRunning), int32(ServerStateCrashed)) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: server '%s' exited unexpectedly: %v\n", s.config.ID, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: server '%s' exited unexpectedly\n", s.config.ID)
+			}
+			// Its primitives are gone until it is restarted
+			s.notifyListsChanged()
+		} else {
+			s.state.Store(int32(ServerStateStopped))
+		}
+		close(exited)
+	}
+}
+
+// Restart starts a crashed server again according to its restart policy, waiting for the restart
+// backoff first. It fails once the server has been restarted MaxRestarts times.
+func (s *Server) Restart(ctx context.Context) error {
+	if s.config.Restart != RestartOnFailure {
+		return fmt.Errorf("server %s exited unexpectedly (set restart: %s to restart it)", s.config.ID, RestartOnFailure)
+	}
+
+	// Concurrent starts wait for the restart
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+
+	if s.State() != ServerStateCrashed {
+		// Restarted concurrently
+		return nil
+	}
+
+	maxRestarts := s.config.MaxRestarts
+	if maxRestarts <= 0 {
+		maxRestarts = DefaultMaxRestarts
+	}
+	if s.restarts >= maxRestarts {
+		return fmt.Errorf("server %s exited unexpectedly and was already restarted %d times", s.config.ID, s.restarts)
+	}
+
+	backoff := s.config.RestartBackoff
+	if backoff <= 0 {
+		backoff = DefaultRestartBackoff
+	}
+	backoff <<= s.restarts
+	s.restarts++
+	fmt.Fprintf(os.Stderr, "Restarting server '%s' in %s (attempt %d of %d)\n", s.config.ID, backoff, s.restarts, maxRestarts)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(backoff):
+	}
+
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+
+	// The restarted server may offer other primitives than before the crash
+	s.notifyListsChanged()
+	return nil
+}
+
+// notifyListsChanged passes list_changed notifications for all primitives to the notification handler,
+// as if the server had sent them
+func (s *Server) notifyListsChanged() {
+	if s.notificationHandler == nil {
+		return
+	}
+	for _, method := range []string{MethodToolsListChanged, MethodResourcesListChanged, MethodPromptsListChanged} {
+		notification, err := NewJSONRPCNotification(method, nil)
+		if err != nil {
+			continue
+		}
+		s.notificationHandler(s.config.ID, notification)
 	}
 }
 
@@ -227,26 +402,47 @@ func (s *Server) Stop() error {
 
 	s.state.Store(int32(ServerStateStopping))
 
-	// Cancel the context
-	if s.cancelFunc != nil {
-		s.cancelFunc()
-	}
-
-	// Close transport
+	// Close transport; a server process sees EOF on its stdin
 	if s.transport != nil {
 		s.transport.Close()
 	}
 
-	// Wait for process to exit (with timeout handled by context)
 	if s.cmd != nil && s.cmd.Process != nil {
-		s.cmd.Process.Kill()
-		s.cmd.Wait()
+		s.stopProcess()
+	}
+
+	// Cancel the context only once the process has exited, as it kills the process
+	if s.cancelFunc != nil {
+		s.cancelFunc()
 	}
 
 	s.state.Store(int32(ServerStateStopped))
 	return nil
 }
 
+// stopProcess interrupts the server process and waits for it to exit, killing it once the
+// shutdown timeout is exceeded
+func (s *Server) stopProcess() {
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	// Send interrupt signal to gracefully stop the process. Where interrupts are not supported
+	// (Windows), the process still sees EOF on its stdin.
+	s.cmd.Process.Signal(os.Interrupt)
+
+	select {
+	case <-s.exited:
+		// Process exited
+	case <-time.After(timeout):
+		// Timeout - force kill
+		fmt.Fprintf(os.Stderr, "Warning: server '%s' did not exit within %s, killing it\n", s.config.ID, timeout)
+		s.cmd.Process.Kill()
+		<-s.exited
+	}
+}
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response
 func (s *Server) SendRequest(ctx context.Context, method string, params interface{}) (*JSONRPCMessage, error) {
 	if s.State() != ServerStateRunning {
@@ -388,99 +584,91 @@ func (s *Server) ServerInfo() *Implementation {
 	return s.serverInfo
 }
 
-// FetchTools fetches the list of tools from the server
+// FetchTools fetches the list of tools from the server, following pagination
 func (s *Server) FetchTools(ctx context.Context) ([]Tool, error) {
 	if s.capabilities == nil || s.capabilities.Tools == nil {
 		return nil, nil // Server doesn't support tools
 	}
 
-	resp, err := s.SendRequest(ctx, MethodToolsList, &PaginatedRequest{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch tools: %w", err)
-	}
-
-	if resp.Error != nil {
-		return nil, fmt.Errorf("tools/list failed: %s", resp.Error.Message)
-	}
-
-	var result ToolsListResult
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tools list: %w", err)
-	}
-
-	// TODO: Handle pagination with NextCursor
-	return result.Tools, nil
+	return listAll(ctx, s, MethodToolsList, "tools", func(raw json.RawMessage) ([]Tool, *string, error) {
+		var result ToolsListResult
+		err := json.Unmarshal(raw, &result)
+		return result.Tools, result.NextCursor, err
+	})
 }
 
-// FetchResources fetches the list of resources from the server
+// FetchResources fetches the list of resources from the server, following pagination
 func (s *Server) FetchResources(ctx context.Context) ([]Resource, error) {
 	if s.capabilities == nil || s.capabilities.Resources == nil {
 		return nil, nil // Server doesn't support resources
 	}
 
-	resp, err := s.SendRequest(ctx, MethodResourcesList, &PaginatedRequest{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch resources: %w", err)
-	}
-
-	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/list failed: %s", resp.Error.Message)
-	}
-
-	var result ResourcesListResult
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resources list: %w", err)
-	}
-
-	// TODO: Handle pagination with NextCursor
-	return result.Resources, nil
+	return listAll(ctx, s, MethodResourcesList, "resources", func(raw json.RawMessage) ([]Resource, *string, error) {
+		var result ResourcesListResult
+		err := json.Unmarshal(raw, &result)
+		return result.Resources, result.NextCursor, err
+	})
 }
 
-// FetchResourceTemplates fetches the list of resource templates from the server
+// FetchResourceTemplates fetches the list of resource templates from the server, following pagination
 func (s *Server) FetchResourceTemplates(ctx context.Context) ([]ResourceTemplate, error) {
 	if s.capabilities == nil || s.capabilities.Resources == nil {
 		return nil, nil // Server doesn't support resources
 	}
 
-	resp, err := s.SendRequest(ctx, MethodResourcesTemplatesList, &PaginatedRequest{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch resource templates: %w", err)
-	}
-
-	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/templates/list failed: %s", resp.Error.Message)
-	}
-
-	var result ResourceTemplatesListResult
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resource templates list: %w", err)
-	}
-
-	return result.ResourceTemplates, nil
+	return listAll(ctx, s, MethodResourcesTemplatesList, "resource templates", func(raw json.RawMessage) ([]ResourceTemplate, *string, error) {
+		var result ResourceTemplatesListResult
+		err := json.Unmarshal(raw, &result)
+		return result.ResourceTemplates, result.NextCursor, err
+	})
 }
 
-// FetchPrompts fetches the list of prompts from the server
+// FetchPrompts fetches the list of prompts from the server, following pagination
 func (s *Server) FetchPrompts(ctx context.Context) ([]Prompt, error) {
 	if s.capabilities == nil || s.capabilities.Prompts == nil {
 		return nil, nil // Server doesn't support prompts
 	}
 
-	resp, err := s.SendRequest(ctx, MethodPromptsList, &PaginatedRequest{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch prompts: %w", err)
-	}
+	return listAll(ctx, s, MethodPromptsList, "prompts", func(raw json.RawMessage) ([]Prompt, *string, error) {
+		var result PromptsListResult
+		err := json.Unmarshal(raw, &result)
+		return result.Prompts, result.NextCursor, err
+	})
+}
 
-	if resp.Error != nil {
-		return nil, fmt.Errorf("prompts/list failed: %s", resp.Error.Message)
-	}
+// listAll sends a list request and follows nextCursor until the server returns no cursor, accumulating
+// the items of every page. decode returns the items and the next cursor of a page. A cursor the server
+// already returned ends the listing with a warning, so a misbehaving server cannot make it loop forever.
+func listAll[T any](ctx context.Context, s *Server, method, name string, decode func(json.RawMessage) ([]T, *string, error)) ([]T, error) {
+	var items []T
+	var cursor *string
+	seen := make(map[string]bool)
+	for {
+		resp, err := s.SendRequest(ctx, method, &PaginatedRequest{Cursor: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
 
-	var result PromptsListResult
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal prompts list: %w", err)
-	}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, resp.Error.Message)
+		}
+
+		page, next, err := decode(resp.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s list: %w", name, err)
+		}
+		items = append(items, page...)
 
-	// TODO: Handle pagination with NextCursor
-	return result.Prompts, nil
+		if next == nil || *next == "" {
+			return items, nil
+		}
+		if seen[*next] {
+			fmt.Fprintf(os.Stderr, "Warning: server '%s' returned the %s cursor '%s' twice, ignoring further pages\n", s.config.ID, method, *next)
+			return items, nil
+		}
+		seen[*next] = true
+		cursor = next
+	}
 }
 
 // ServerManager manages multiple downstream MCP servers
@@ -527,6 +715,14 @@ func (m *ServerManager) GetOrStartServer(ctx context.Context, id string) (*Serve
 		return server, nil
 	}
 
+	// Restart a crashed server, if its restart policy allows it
+	if server.State() == ServerStateCrashed {
+		if err := server.Restart(ctx); err != nil {
+			return nil, fmt.Errorf("failed to restart server '%s': %w", id, err)
+		}
+		return server, nil
+	}
+
 	// Start the server
 	if err := server.Start(ctx); err != nil {
 		return nil, fmt.Errorf("failed to start server '%s': %w", id, err)
@@ -535,6 +731,15 @@ func (m *ServerManager) GetOrStartServer(ctx context.Context, id string) (*Serve
 	return server, nil
 }
 
+// SetNotificationHandler sets the notification handler of every server (see Server.SetNotificationHandler)
+func (m *ServerManager) SetNotificationHandler(fn func(serverID string, msg *JSONRPCMessage)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, server := range m.servers {
+		server.SetNotificationHandler(fn)
+	}
+}
+
 // StartAll starts all configured servers
 func (m *ServerManager) StartAll(ctx context.Context) error {
 	m.mu.RLock()
@@ -558,12 +763,24 @@ func (m *ServerManager) StopAll() error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	var errs []error
+	// Stop servers concurrently, so their shutdown timeouts do not add up
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+	)
 	for id, server := range m.servers {
-		if err := server.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to stop server '%s': %w", id, err))
-		}
+		wg.Add(1)
+		go func(id string, server *Server) {
+			defer wg.Done()
+			if err := server.Stop(); err != nil {
+				errsMu.Lock()
+				errs = append(errs, fmt.Errorf("failed to stop server '%s': %w", id, err))
+				errsMu.Unlock()
+			}
+		}(id, server)
 	}
+	wg.Wait()
 
 	if len(errs) > 0 {
 		return fmt.Errorf("errors stopping servers: %v", errs)
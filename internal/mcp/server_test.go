@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPaginatedMCPServer starts an MCP-like HTTP server listing tools and prompts in pages.
+// pages maps a request cursor ("" for the first page) to the names on that page and the next cursor.
+func newPaginatedMCPServer(t *testing.T, pages map[string]struct {
+	names []string
+	next  string
+}) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg JSONRPCMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var params PaginatedRequest
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, &params)
+		}
+		cursor := ""
+		if params.Cursor != nil {
+			cursor = *params.Cursor
+		}
+
+		var result interface{}
+		switch msg.Method {
+		case MethodInitialize:
+			result = InitializeResult{
+				ProtocolVersion: MCPProtocolVersion,
+				Capabilities:    &ServerCapabilities{Tools: &ToolCapabilities{}, Prompts: &PromptCapabilities{}},
+				ServerInfo:      &Implementation{Name: "paginated", Version: "1.0.0"},
+			}
+		case MethodToolsList, MethodPromptsList:
+			cursors = append(cursors, msg.Method+":"+cursor)
+			page := pages[cursor]
+			var next *string
+			if page.next != "" {
+				next = &page.next
+			}
+			if msg.Method == MethodToolsList {
+				tools := make([]Tool, 0, len(page.names))
+				for _, name := range page.names {
+					tools = append(tools, Tool{Name: name})
+				}
+				result = ToolsListResult{Tools: tools, NextCursor: next}
+			} else {
+				prompts := make([]Prompt, 0, len(page.names))
+				for _, name := range page.names {
+					prompts = append(prompts, Prompt{Name: name})
+				}
+				result = PromptsListResult{Prompts: prompts, NextCursor: next}
+			}
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		resp, _ := NewJSONRPCResponse(msg.ID.Value(), result)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	return server, &cursors
+}
+
+// startPaginatedServer starts and initializes a Server connected to url
+func startPaginatedServer(t *testing.T, url string) *Server {
+	t.Helper()
+
+	server := NewServer(ServerConfig{ID: "paginated", URL: url}, nil, false)
+	ctx := context.Background()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	t.Cleanup(func() { server.Stop() })
+	if err := server.Initialize(ctx, Implementation{Name: "test", Version: "1.0"}, ClientCapabilities{}); err != nil {
+		t.Fatalf("Initialize() error: %v", err)
+	}
+	return server
+}
+
+func TestServer_FetchFollowsPagination(t *testing.T) {
+	httpServer, cursors := newPaginatedMCPServer(t, map[string]struct {
+		names []string
+		next  string
+	}{
+		"":       {names: []string{"a", "b"}, next: "page-2"},
+		"page-2": {names: []string{"c"}, next: "page-3"},
+		"page-3": {names: []string{"d"}},
+	})
+	defer httpServer.Close()
+	server := startPaginatedServer(t, httpServer.URL)
+
+	tools, err := server.FetchTools(context.Background())
+	if err != nil {
+		t.Fatalf("FetchTools() error: %v", err)
+	}
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "a,b,c,d" {
+		t.Errorf("FetchTools() = %s, want a,b,c,d", got)
+	}
+
+	prompts, err := server.FetchPrompts(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPrompts() error: %v", err)
+	}
+	if len(prompts) != 4 {
+		t.Errorf("FetchPrompts() returned %d prompts, want 4", len(prompts))
+	}
+
+	want := "tools/list:,tools/list:page-2,tools/list:page-3,prompts/list:,prompts/list:page-2,prompts/list:page-3"
+	if got := strings.Join(*cursors, ","); got != want {
+		t.Errorf("requested cursors = %s, want %s", got, want)
+	}
+}
+
+func TestServer_FetchStopsOnRepeatedCursor(t *testing.T) {
+	httpServer, cursors := newPaginatedMCPServer(t, map[string]struct {
+		names []string
+		next  string
+	}{
+		"":       {names: []string{"a"}, next: "page-2"},
+		"page-2": {names: []string{"b"}, next: "page-3"},
+		"page-3": {names: []string{"c"}, next: "page-2"},
+	})
+	defer httpServer.Close()
+	server := startPaginatedServer(t, httpServer.URL)
+
+	tools, err := server.FetchTools(context.Background())
+	if err != nil {
+		t.Fatalf("FetchTools() error: %v", err)
+	}
+	if len(tools) != 3 {
+		t.Errorf("FetchTools() returned %d tools, want the 3 tools listed before the cursor repeated", len(tools))
+	}
+	if len(*cursors) != 3 {
+		t.Errorf("expected 3 tools/list requests, got %v", *cursors)
+	}
+}
+
+func TestServer_BuildEnvScopesSecrets(t *testing.T) {
+	secrets := map[string]string{"GITHUB_TOKEN": "gh", "DB_PASSWORD": "db"}
+
+	tests := []struct {
+		name    string
+		scope   []string
+		wantEnv []string
+	}{
+		{name: "all secrets by default", scope: nil, wantEnv: []string{"DB_PASSWORD=db", "GITHUB_TOKEN=gh"}},
+		{name: "named subset", scope: []string{"GITHUB_TOKEN", "MISSING"}, wantEnv: []string{"GITHUB_TOKEN=gh"}},
+		{name: "no secrets", scope: []string{}, wantEnv: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(ServerConfig{ID: "test", Command: "true", Secrets: tt.scope}, secrets, false)
+
+			env := server.buildEnv()
+			sort.Strings(env)
+			if strings.Join(env, ",") != strings.Join(tt.wantEnv, ",") {
+				t.Errorf("buildEnv() = %v, want %v", env, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestServer_StopWaitsForGracefulExit(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "flushed")
+
+	// The process ignores interrupts and flushes its state once stdin is closed
+	server := NewServer(ServerConfig{
+		ID:      "graceful",
+		Command: "sh",
+		Args:    []string{"-c", `trap '' INT; touch "$0.started"; cat >/dev/null; sleep 0.2; echo done > "$0"`, marker},
+	}, nil, true)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	waitForFile(t, marker+".started")
+
+	if err := server.Stop(); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("expected server to flush state before exiting: %v", err)
+	}
+	if server.State() != ServerStateStopped {
+		t.Errorf("State() = %v, want %v", server.State(), ServerStateStopped)
+	}
+}
+
+func TestServer_StopKillsAfterShutdownTimeout(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "stubborn")
+
+	// The process ignores both interrupts and EOF on stdin
+	server := NewServer(ServerConfig{
+		ID:              "stubborn",
+		Command:         "sh",
+		Args:            []string{"-c", `trap '' INT; touch "$0.started"; exec sleep 30`, marker},
+		ShutdownTimeout: 100 * time.Millisecond,
+	}, nil, true)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	waitForFile(t, marker+".started")
+
+	start := time.Now()
+	if err := server.Stop(); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Stop() took %s, expected the process to be killed after the shutdown timeout", elapsed)
+	}
+	if server.State() != ServerStateStopped {
+		t.Errorf("State() = %v, want %v", server.State(), ServerStateStopped)
+	}
+}
+
+// waitForFile waits until the file at path exists, so a test process has installed its signal handlers
+func waitForFile(t *testing.T, path string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForState waits until the server reaches the given state
+func waitForState(t *testing.T, server *Server, state ServerState) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for state %v, got %v", state, server.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerManager_RestartsCrashedServer(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+
+	// The process records each run and crashes immediately
+	manager := NewServerManager([]ServerConfig{{
+		ID:             "crashy",
+		Command:        "sh",
+		Args:           []string{"-c", `echo run >> "$0"; exit 1`, runs},
+		Restart:        RestartOnFailure,
+		MaxRestarts:    2,
+		RestartBackoff: 10 * time.Millisecond,
+	}}, nil, true)
+	defer manager.StopAll()
+
+	server, err := manager.GetOrStartServer(context.Background(), "crashy")
+	if err != nil {
+		t.Fatalf("GetOrStartServer() error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		waitForState(t, server, ServerStateCrashed)
+		// A restarted server must be initialized again
+		server.capabilities = &ServerCapabilities{}
+
+		if _, err := manager.GetOrStartServer(context.Background(), "crashy"); err != nil {
+			t.Fatalf("restart %d: GetOrStartServer() error: %v", i+1, err)
+		}
+		if server.Capabilities() != nil {
+			t.Errorf("restart %d: expected capabilities to be reset", i+1)
+		}
+	}
+
+	waitForState(t, server, ServerStateCrashed)
+	_, err = manager.GetOrStartServer(context.Background(), "crashy")
+	if err == nil || !strings.Contains(err.Error(), "already restarted 2 times") {
+		t.Errorf("expected restart limit error, got %v", err)
+	}
+
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("failed to read runs: %v", err)
+	}
+	if got := strings.Count(string(data), "run"); got != 3 {
+		t.Errorf("expected 3 runs, got %d", got)
+	}
+}
+
+func TestServerManager_CrashedServerWithoutRestartPolicy(t *testing.T) {
+	manager := NewServerManager([]ServerConfig{{
+		ID:      "crashy",
+		Command: "sh",
+		Args:    []string{"-c", "exit 1"},
+	}}, nil, true)
+	defer manager.StopAll()
+
+	server, err := manager.GetOrStartServer(context.Background(), "crashy")
+	if err != nil {
+		t.Fatalf("GetOrStartServer() error: %v", err)
+	}
+	waitForState(t, server, ServerStateCrashed)
+
+	_, err = manager.GetOrStartServer(context.Background(), "crashy")
+	if err == nil || !strings.Contains(err.Error(), "exited unexpectedly") {
+		t.Errorf("expected crashed server error, got %v", err)
+	}
+}
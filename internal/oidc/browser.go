@@ -1,10 +1,26 @@
 package oidc
 
 import (
+	"os"
 	"os/exec"
 	"runtime"
 )
 
+// canOpenBrowser reports whether a local browser can likely be opened: not in an SSH session or
+// on CI and, on systems other than macOS and Windows, with an X11 or Wayland display
+func canOpenBrowser() bool {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" || os.Getenv("CI") != "" {
+		return false
+	}
+
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	default:
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+}
+
 // openBrowser attempts to open the given URL in the default browser
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
@@ -27,14 +27,19 @@ const (
 	DefaultCallbackPath = "/auth/sstart"
 	// DefaultTimeout is the default timeout for the authentication flow
 	DefaultTimeout = 5 * time.Minute
+	// RefreshSkew is how long before their expiry tokens are refreshed
+	RefreshSkew = time.Minute
 )
 
 // Client represents an OIDC client for SSO authentication
 type Client struct {
-	config    *config.OIDCConfig
-	provider  rp.RelyingParty
-	logger    *slog.Logger
-	tokenPath string
+	config       *config.OIDCConfig
+	provider     rp.RelyingParty
+	logger       *slog.Logger
+	tokenPath    string
+	tokenStorage StorageBackend // Where tokens are stored (default: StorageBackendAuto)
+	memoryTokens *Tokens        // Tokens of the StorageBackendMemory storage
+	identity     string         // Name of the SSO identity, empty for the default identity
 }
 
 // Tokens represents the OIDC tokens received after authentication
@@ -66,6 +71,16 @@ type AuthResult struct {
 // SSOSecretEnvVar is the environment variable name for the OIDC client secret
 const SSOSecretEnvVar = "SSTART_SSO_SECRET"
 
+// SecretEnvVar returns the environment variable holding the client secret of an SSO identity:
+// SSOSecretEnvVar for the default identity, SSTART_SSO_SECRET_<IDENTITY> for named identities
+// (upper-cased, '-' replaced by '_')
+func SecretEnvVar(identity string) string {
+	if identity == "" || identity == config.DefaultSSOIdentity {
+		return SSOSecretEnvVar
+	}
+	return SSOSecretEnvVar + "_" + strings.ToUpper(strings.ReplaceAll(identity, "-", "_"))
+}
+
 // oidcDiscoveryResponse represents the OIDC discovery document
 type oidcDiscoveryResponse struct {
 	TokenEndpoint string `json:"token_endpoint"`
@@ -82,8 +97,54 @@ type tokenResponse struct {
 	Scope        string `json:"scope,omitempty"`
 }
 
+// AuthFlow selects how an interactive login authenticates the user
+type AuthFlow string
+
+const (
+	// AuthFlowAuto uses the device flow when no browser can be opened, the browser flow otherwise (default)
+	AuthFlowAuto AuthFlow = "auto"
+	// AuthFlowBrowser opens a browser and receives the authorization code on a local callback server
+	AuthFlowBrowser AuthFlow = "browser"
+	// AuthFlowDevice uses the device authorization grant: the user enters a code on another device
+	AuthFlowDevice AuthFlow = "device"
+)
+
+// ValidateAuthFlow returns an error for unknown auth flows; empty selects AuthFlowAuto
+func ValidateAuthFlow(flow AuthFlow) error {
+	switch flow {
+	case "", AuthFlowAuto, AuthFlowBrowser, AuthFlowDevice:
+		return nil
+	default:
+		return fmt.Errorf("unknown SSO auth flow '%s' (valid flows: %s, %s, %s)", flow, AuthFlowAuto, AuthFlowBrowser, AuthFlowDevice)
+	}
+}
+
+// ClientOption is a functional option for configuring the Client
+type ClientOption func(*Client)
+
+// WithTokenStorage sets where tokens are stored: StorageBackendKeyring only in the keyring,
+// StorageBackendFile only in the token file, StorageBackendMemory only in memory, and anything
+// else in the keyring with the file as fallback
+func WithTokenStorage(backend StorageBackend) ClientOption {
+	return func(c *Client) {
+		c.tokenStorage = backend
+	}
+}
+
+// WithIdentity sets the name of the SSO identity the client authenticates, so that its tokens and client
+// secret are kept apart from those of other identities; empty or config.DefaultSSOIdentity selects the
+// default identity
+func WithIdentity(name string) ClientOption {
+	return func(c *Client) {
+		if name == config.DefaultSSOIdentity {
+			name = ""
+		}
+		c.identity = name
+	}
+}
+
 // NewClient creates a new OIDC client from the provided configuration
-func NewClient(cfg *config.OIDCConfig) (*Client, error) {
+func NewClient(cfg *config.OIDCConfig, opts ...ClientOption) (*Client, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("OIDC configuration is required")
 	}
@@ -100,9 +161,23 @@ func NewClient(cfg *config.OIDCConfig) (*Client, error) {
 		return nil, fmt.Errorf("at least one scope is required")
 	}
 
+	client := &Client{
+		config: cfg,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.tokenPath = getDefaultTokenPath(client.identity)
+
 	// Client secret must be provided via environment variable (not supported in YAML config)
-	if secret := os.Getenv(SSOSecretEnvVar); secret != "" {
+	// If the environment variable is absent, fall back to a secret stored in the system keyring,
+	// unless the keyring is not used (file or memory token storage)
+	if secret := os.Getenv(SecretEnvVar(client.identity)); secret != "" {
 		cfg.ClientSecret = secret
+	} else if client.keyringAllowed() {
+		if secret := LoadClientSecret(cfg.Issuer, cfg.ClientID); secret != "" {
+			cfg.ClientSecret = secret
+		}
 	}
 
 	logger := slog.New(
@@ -112,11 +187,7 @@ func NewClient(cfg *config.OIDCConfig) (*Client, error) {
 		}),
 	)
 
-	client := &Client{
-		config:    cfg,
-		logger:    logger,
-		tokenPath: getDefaultTokenPath(),
-	}
+	client.logger = logger
 
 	return client, nil
 }
@@ -189,6 +260,13 @@ func (c *Client) Login(ctx context.Context) (*AuthResult, error) {
 		urlOptions = append(urlOptions, rp.WithResponseModeURLParam(oidc.ResponseMode(c.config.ResponseMode)))
 	}
 
+	// The audience and resource are sent in both the authorization and the token request
+	audienceOptions := []rp.URLParamOpt{}
+	for key, values := range c.audienceParams() {
+		audienceOptions = append(audienceOptions, rp.WithURLParam(key, values[0]))
+	}
+	urlOptions = append(urlOptions, audienceOptions...)
+
 	// Register login handler
 	mux.Handle("/login", rp.AuthURLHandler(state, provider, urlOptions...))
 
@@ -225,7 +303,7 @@ func (c *Client) Login(ctx context.Context) (*AuthResult, error) {
 	}
 
 	// Register callback handler
-	mux.Handle(callbackPath, rp.CodeExchangeHandler(rp.UserinfoCallback(marshalUserinfo), provider))
+	mux.Handle(callbackPath, rp.CodeExchangeHandler(rp.UserinfoCallback(marshalUserinfo), provider, audienceOptions...))
 
 	// Create the HTTP server
 	server := &http.Server{
@@ -284,6 +362,90 @@ func (c *Client) Login(ctx context.Context) (*AuthResult, error) {
 	return result, nil
 }
 
+// LoginWithFlow logs in interactively with the given auth flow
+func (c *Client) LoginWithFlow(ctx context.Context, flow AuthFlow) (*AuthResult, error) {
+	if err := ValidateAuthFlow(flow); err != nil {
+		return nil, err
+	}
+
+	// Auto selects the device flow when no browser can be opened
+	if flow == AuthFlowDevice || (flow != AuthFlowBrowser && !canOpenBrowser()) {
+		return c.LoginWithDeviceCode(ctx)
+	}
+	return c.Login(ctx)
+}
+
+// LoginWithDeviceCode performs the OAuth2 device authorization grant (RFC 8628) for environments
+// without a browser: it prints a verification URL and user code, then polls the token endpoint
+// until the user has authenticated on another device
+func (c *Client) LoginWithDeviceCode(ctx context.Context) (*AuthResult, error) {
+	provider, err := c.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addAudience := httphelper.FormAuthorization(func(form url.Values) {
+		for key, values := range c.audienceParams() {
+			form[key] = values
+		}
+	})
+	authorization, err := rp.DeviceAuthorization(ctx, c.config.Scopes, provider, addAudience)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "\n🔐 To authenticate, visit: %s\n", authorization.VerificationURI)
+	fmt.Fprintf(os.Stderr, "   and enter the code: %s\n", authorization.UserCode)
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "   Or open: %s\n", authorization.VerificationURIComplete)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	// The device code expires; poll at most until then
+	timeout := DefaultTimeout
+	if authorization.ExpiresIn > 0 {
+		timeout = time.Duration(authorization.ExpiresIn) * time.Second
+	}
+	interval := 5 * time.Second
+	if authorization.Interval > 0 {
+		interval = time.Duration(authorization.Interval) * time.Second
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := rp.DeviceAccessToken(timeoutCtx, authorization.DeviceCode, interval, provider)
+	if err != nil {
+		if timeoutCtx.Err() != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("authentication timed out after %v", timeout)
+		}
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	expiry := time.Time{}
+	if resp.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	result := &AuthResult{
+		Tokens: &Tokens{
+			AccessToken:  resp.AccessToken,
+			RefreshToken: resp.RefreshToken,
+			IDToken:      resp.IDToken,
+			TokenType:    resp.TokenType,
+			Expiry:       expiry,
+		},
+	}
+
+	// Save tokens
+	if err := c.SaveTokens(result.Tokens); err != nil {
+		c.logger.Warn("failed to save tokens from device authorization", "error", err)
+	}
+
+	c.logger.Info("authentication successful")
+	return result, nil
+}
+
 // GetTokens loads and returns the stored tokens
 func (c *Client) GetTokens() (*Tokens, error) {
 	return c.LoadTokens()
@@ -316,6 +478,9 @@ func (c *Client) LoginWithClientCredentials(ctx context.Context) (*AuthResult, e
 	if len(c.config.Scopes) > 0 {
 		data.Set("scope", strings.Join(c.config.Scopes, " "))
 	}
+	for key, values := range c.audienceParams() {
+		data[key] = values
+	}
 
 	// Create the HTTP request
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(data.Encode()))
@@ -421,32 +586,13 @@ func (c *Client) RefreshTokens(ctx context.Context) (*Tokens, error) {
 		return nil, fmt.Errorf("no refresh token available")
 	}
 
-	// Initialize provider if not already done
-	if c.provider == nil {
-		redirectURI := fmt.Sprintf("http://localhost:%d%s", DefaultPort, DefaultCallbackPath)
-
-		key := []byte(uuid.New().String()[:16])
-		cookieHandler := httphelper.NewCookieHandler(key, key, httphelper.WithUnsecure())
-
-		httpClient := &http.Client{Timeout: time.Minute}
-
-		options := []rp.Option{
-			rp.WithCookieHandler(cookieHandler),
-			rp.WithVerifierOpts(rp.WithIssuedAtOffset(5 * time.Second)),
-			rp.WithHTTPClient(httpClient),
-			rp.WithLogger(c.logger),
-			rp.WithSigningAlgsFromDiscovery(),
-		}
-
-		provider, err := rp.NewRelyingPartyOIDC(ctx, c.config.Issuer, c.config.ClientID, c.config.ClientSecret, redirectURI, c.config.Scopes, options...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create OIDC provider: %w", err)
-		}
-		c.provider = provider
+	provider, err := c.relyingParty(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Refresh the tokens
-	newTokens, err := rp.RefreshTokens[*oidc.IDTokenClaims](ctx, c.provider, tokens.RefreshToken, "", "")
+	newTokens, err := rp.RefreshTokens[*oidc.IDTokenClaims](ctx, provider, tokens.RefreshToken, "", "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh tokens: %w", err)
 	}
@@ -458,6 +604,13 @@ func (c *Client) RefreshTokens(ctx context.Context) (*Tokens, error) {
 		TokenType:    newTokens.TokenType,
 		Expiry:       newTokens.Expiry,
 	}
+	// A refresh response may omit the refresh token (no rotation) and the ID token
+	if result.RefreshToken == "" {
+		result.RefreshToken = tokens.RefreshToken
+	}
+	if result.IDToken == "" {
+		result.IDToken = tokens.IDToken
+	}
 
 	// Save the new tokens
 	if err := c.SaveTokens(result); err != nil {
@@ -467,6 +620,91 @@ func (c *Client) RefreshTokens(ctx context.Context) (*Tokens, error) {
 	return result, nil
 }
 
+// RefreshIfExpired returns the stored tokens, first refreshing them with the refresh token grant
+// when the ID token or the access token expires within RefreshSkew. Refreshed tokens are saved.
+func (c *Client) RefreshIfExpired(ctx context.Context) (*Tokens, error) {
+	tokens, err := c.LoadTokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tokens: %w", err)
+	}
+
+	if !tokens.expiresBefore(time.Now().Add(RefreshSkew)) {
+		return tokens, nil
+	}
+
+	if tokens.RefreshToken == "" {
+		return nil, fmt.Errorf("tokens expired and no refresh token available")
+	}
+
+	refreshed, err := c.RefreshTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if refreshed.expiresBefore(time.Now()) {
+		return nil, fmt.Errorf("refreshed tokens are already expired")
+	}
+
+	return refreshed, nil
+}
+
+// expiresBefore reports whether the access token or the ID token expires before t, or
+// whether there is no access token
+func (t *Tokens) expiresBefore(deadline time.Time) bool {
+	if t.AccessToken == "" {
+		return true
+	}
+	if !t.Expiry.IsZero() && t.Expiry.Before(deadline) {
+		return true
+	}
+	if t.IDToken != "" {
+		if identity, err := DecodeIDToken(t.IDToken); err == nil && !identity.Expiry.IsZero() && identity.Expiry.Before(deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+// audienceParams returns the configured 'audience' and RFC 8707 'resource' request parameters
+func (c *Client) audienceParams() url.Values {
+	params := url.Values{}
+	if c.config.Audience != "" {
+		params.Set("audience", c.config.Audience)
+	}
+	if c.config.Resource != "" {
+		params.Set("resource", c.config.Resource)
+	}
+	return params
+}
+
+// relyingParty returns the OIDC provider, creating it from the discovery document if not already done
+func (c *Client) relyingParty(ctx context.Context) (rp.RelyingParty, error) {
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	redirectURI := fmt.Sprintf("http://localhost:%d%s", DefaultPort, DefaultCallbackPath)
+
+	key := []byte(uuid.New().String()[:16])
+	cookieHandler := httphelper.NewCookieHandler(key, key, httphelper.WithUnsecure())
+
+	httpClient := &http.Client{Timeout: time.Minute}
+
+	options := []rp.Option{
+		rp.WithCookieHandler(cookieHandler),
+		rp.WithVerifierOpts(rp.WithIssuedAtOffset(5 * time.Second)),
+		rp.WithHTTPClient(httpClient),
+		rp.WithLogger(c.logger),
+		rp.WithSigningAlgsFromDiscovery(),
+	}
+
+	provider, err := rp.NewRelyingPartyOIDC(ctx, c.config.Issuer, c.config.ClientID, c.config.ClientSecret, redirectURI, c.config.Scopes, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC provider: %w", err)
+	}
+	c.provider = provider
+	return provider, nil
+}
+
 // IsAuthenticated checks if valid tokens exist
 func (c *Client) IsAuthenticated() bool {
 	tokens, err := c.LoadTokens()
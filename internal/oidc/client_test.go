@@ -0,0 +1,352 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newRefreshIssuer starts an OIDC issuer whose token endpoint exchanges the refresh token
+// "refresh-1" for the access token "access-2", counting the token requests
+func newRefreshIssuer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 server.URL,
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+				"jwks_uri":               server.URL + "/keys",
+			})
+		case "/keys":
+			w.Write([]byte(`{"keys":[]}`))
+		case "/token":
+			requests.Add(1)
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"access-2","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// newFileClient creates a client for the issuer that stores its tokens in a temporary file
+func newFileClient(t *testing.T, issuer string) *Client {
+	t.Helper()
+	t.Setenv(SSOSecretEnvVar, "")
+
+	client, err := NewClient(&config.OIDCConfig{
+		ClientID: "sstart-cli",
+		Issuer:   issuer,
+		Scopes:   []string{"openid"},
+	}, WithTokenStorage(StorageBackendFile))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetTokenPath(filepath.Join(t.TempDir(), "tokens.json"))
+	return client
+}
+
+// idTokenExpiringAt returns an ID token, signed with a test key, expiring at the given time
+func idTokenExpiringAt(t *testing.T, expiry time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user-123",
+		"exp": expiry.Unix(),
+	}).SignedString([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestRefreshIfExpired_ValidTokens(t *testing.T) {
+	issuer, requests := newRefreshIssuer(t)
+	client := newFileClient(t, issuer.URL)
+
+	stored := &Tokens{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		IDToken:      idTokenExpiringAt(t, time.Now().Add(time.Hour)),
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	if err := client.SaveTokens(stored); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+
+	tokens, err := client.RefreshIfExpired(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshIfExpired() error = %v", err)
+	}
+	if tokens.AccessToken != "access-1" {
+		t.Errorf("AccessToken = %q, want %q", tokens.AccessToken, "access-1")
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected no token requests, got %d", requests.Load())
+	}
+}
+
+func TestRefreshIfExpired_RefreshesExpiringTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry time.Time
+	}{
+		{name: "expired", expiry: time.Now().Add(-time.Hour)},
+		{name: "within skew", expiry: time.Now().Add(RefreshSkew / 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, requests := newRefreshIssuer(t)
+			client := newFileClient(t, issuer.URL)
+
+			if err := client.SaveTokens(&Tokens{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: tt.expiry}); err != nil {
+				t.Fatalf("SaveTokens() error = %v", err)
+			}
+
+			tokens, err := client.RefreshIfExpired(context.Background())
+			if err != nil {
+				t.Fatalf("RefreshIfExpired() error = %v", err)
+			}
+			if tokens.AccessToken != "access-2" {
+				t.Errorf("AccessToken = %q, want %q", tokens.AccessToken, "access-2")
+			}
+			// The issuer did not rotate the refresh token
+			if tokens.RefreshToken != "refresh-1" {
+				t.Errorf("RefreshToken = %q, want %q", tokens.RefreshToken, "refresh-1")
+			}
+			if requests.Load() != 1 {
+				t.Errorf("expected 1 token request, got %d", requests.Load())
+			}
+
+			saved, err := client.LoadTokens()
+			if err != nil {
+				t.Fatalf("LoadTokens() error = %v", err)
+			}
+			if saved.AccessToken != "access-2" {
+				t.Errorf("saved AccessToken = %q, want %q", saved.AccessToken, "access-2")
+			}
+		})
+	}
+}
+
+func TestRefreshIfExpired_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  *Tokens
+		wantErr string
+	}{
+		{
+			name:    "no refresh token",
+			tokens:  &Tokens{AccessToken: "access-1", Expiry: time.Now().Add(-time.Hour)},
+			wantErr: "no refresh token available",
+		},
+		{
+			name:    "rejected refresh token",
+			tokens:  &Tokens{AccessToken: "access-1", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)},
+			wantErr: "failed to refresh tokens",
+		},
+		{
+			// The refresh response carries no new ID token
+			name: "expired ID token",
+			tokens: &Tokens{
+				AccessToken:  "access-1",
+				RefreshToken: "refresh-1",
+				IDToken:      idTokenExpiringAt(t, time.Now().Add(-time.Hour)),
+				Expiry:       time.Now().Add(time.Hour),
+			},
+			wantErr: "already expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, _ := newRefreshIssuer(t)
+			client := newFileClient(t, issuer.URL)
+
+			if err := client.SaveTokens(tt.tokens); err != nil {
+				t.Fatalf("SaveTokens() error = %v", err)
+			}
+
+			_, err := client.RefreshIfExpired(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RefreshIfExpired() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newDeviceIssuer starts an OIDC issuer supporting the device authorization grant. The token
+// endpoint reports the authorization as pending once before issuing tokens.
+func newDeviceIssuer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var polls atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                        server.URL,
+				"authorization_endpoint":        server.URL + "/authorize",
+				"token_endpoint":                server.URL + "/token",
+				"device_authorization_endpoint": server.URL + "/device",
+				"jwks_uri":                      server.URL + "/keys",
+			})
+		case "/keys":
+			w.Write([]byte(`{"keys":[]}`))
+		case "/device":
+			w.Write([]byte(`{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"https://auth.example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" || r.Form.Get("device_code") != "device-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			if polls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"device-access","refresh_token":"device-refresh","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoginWithFlow_Device(t *testing.T) {
+	issuer := newDeviceIssuer(t)
+	client := newFileClient(t, issuer.URL)
+
+	result, err := client.LoginWithFlow(context.Background(), AuthFlowDevice)
+	if err != nil {
+		t.Fatalf("LoginWithFlow() error = %v", err)
+	}
+	if result.Tokens.AccessToken != "device-access" || result.Tokens.RefreshToken != "device-refresh" {
+		t.Errorf("Tokens = %+v, want device tokens", result.Tokens)
+	}
+
+	saved, err := client.LoadTokens()
+	if err != nil {
+		t.Fatalf("LoadTokens() error = %v", err)
+	}
+	if saved.AccessToken != "device-access" {
+		t.Errorf("saved AccessToken = %q, want %q", saved.AccessToken, "device-access")
+	}
+}
+
+func TestLoginWithFlow_DeviceNotSupported(t *testing.T) {
+	// The refresh issuer has no device authorization endpoint
+	issuer, _ := newRefreshIssuer(t)
+	client := newFileClient(t, issuer.URL)
+
+	if _, err := client.LoginWithFlow(context.Background(), AuthFlowDevice); err == nil || !strings.Contains(err.Error(), "failed to start device authorization") {
+		t.Errorf("LoginWithFlow() error = %v, want device authorization error", err)
+	}
+}
+
+func TestLoginWithFlow_UnknownFlow(t *testing.T) {
+	client := newFileClient(t, "https://auth.example.com")
+
+	if _, err := client.LoginWithFlow(context.Background(), "popup"); err == nil || !strings.Contains(err.Error(), "unknown SSO auth flow 'popup'") {
+		t.Errorf("LoginWithFlow() error = %v, want unknown flow error", err)
+	}
+}
+
+func TestCanOpenBrowser_SSHSession(t *testing.T) {
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 52000 10.0.0.2 22")
+
+	if canOpenBrowser() {
+		t.Error("canOpenBrowser() = true in an SSH session, want false")
+	}
+}
+
+func TestAudienceParams_SentInTokenRequests(t *testing.T) {
+	forms := make(chan url.Values, 2)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                        server.URL,
+				"authorization_endpoint":        server.URL + "/authorize",
+				"token_endpoint":                server.URL + "/token",
+				"device_authorization_endpoint": server.URL + "/device",
+				"jwks_uri":                      server.URL + "/keys",
+			})
+		case "/keys":
+			w.Write([]byte(`{"keys":[]}`))
+		case "/device":
+			r.ParseForm()
+			forms <- r.PostForm
+			w.Write([]byte(`{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"https://auth.example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			r.ParseForm()
+			if r.PostForm.Get("grant_type") == "client_credentials" {
+				forms <- r.PostForm
+			}
+			w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newFileClient(t, server.URL)
+	client.config.Audience = "https://vault.example.com"
+	client.config.Resource = "https://vault.example.com/v1/auth/jwt"
+
+	assertAudience := func(t *testing.T, form url.Values) {
+		t.Helper()
+		if form.Get("audience") != "https://vault.example.com" {
+			t.Errorf("audience = %q, want %q", form.Get("audience"), "https://vault.example.com")
+		}
+		if form.Get("resource") != "https://vault.example.com/v1/auth/jwt" {
+			t.Errorf("resource = %q, want %q", form.Get("resource"), "https://vault.example.com/v1/auth/jwt")
+		}
+	}
+
+	t.Run("device", func(t *testing.T) {
+		if _, err := client.LoginWithDeviceCode(context.Background()); err != nil {
+			t.Fatalf("LoginWithDeviceCode() error = %v", err)
+		}
+		assertAudience(t, <-forms)
+	})
+
+	t.Run("client credentials", func(t *testing.T) {
+		client.config.ClientSecret = "secret"
+		if _, err := client.LoginWithClientCredentials(context.Background()); err != nil {
+			t.Fatalf("LoginWithClientCredentials() error = %v", err)
+		}
+		assertAudience(t, <-forms)
+	})
+}
@@ -0,0 +1,59 @@
+package oidc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity represents the identity claims carried by an ID token
+type Identity struct {
+	Subject           string
+	Email             string
+	PreferredUsername string
+	Issuer            string
+	Expiry            time.Time
+}
+
+// IsExpired reports whether the identity's token has expired
+func (i *Identity) IsExpired() bool {
+	return !i.Expiry.IsZero() && i.Expiry.Before(time.Now())
+}
+
+// DecodeIDToken decodes the identity claims of an ID token without verifying its signature.
+// It must only be used for display purposes, never for authorization decisions.
+func DecodeIDToken(idToken string) (*Identity, error) {
+	if idToken == "" {
+		return nil, fmt.Errorf("no ID token available")
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return nil, fmt.Errorf("failed to decode ID token: %w", err)
+	}
+
+	identity := &Identity{}
+	identity.Subject, _ = claims.GetSubject()
+	identity.Issuer, _ = claims.GetIssuer()
+	if email, ok := claims["email"].(string); ok {
+		identity.Email = email
+	}
+	if username, ok := claims["preferred_username"].(string); ok {
+		identity.PreferredUsername = username
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		identity.Expiry = exp.Time
+	}
+
+	return identity, nil
+}
+
+// Whoami loads the stored tokens and returns the identity from the ID token
+func (c *Client) Whoami() (*Identity, error) {
+	tokens, err := c.LoadTokens()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeIDToken(tokens.IDToken)
+}
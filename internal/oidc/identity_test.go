@@ -0,0 +1,75 @@
+package oidc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestDecodeIDToken(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":                "user-123",
+		"iss":                "https://auth.example.com",
+		"email":              "jane@example.com",
+		"preferred_username": "jane",
+		"exp":                expiry.Unix(),
+	})
+	idToken, err := token.SignedString([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	identity, err := DecodeIDToken(idToken)
+	if err != nil {
+		t.Fatalf("DecodeIDToken() error = %v", err)
+	}
+
+	if identity.Subject != "user-123" {
+		t.Errorf("Subject = %q, want %q", identity.Subject, "user-123")
+	}
+	if identity.Issuer != "https://auth.example.com" {
+		t.Errorf("Issuer = %q, want %q", identity.Issuer, "https://auth.example.com")
+	}
+	if identity.Email != "jane@example.com" {
+		t.Errorf("Email = %q, want %q", identity.Email, "jane@example.com")
+	}
+	if identity.PreferredUsername != "jane" {
+		t.Errorf("PreferredUsername = %q, want %q", identity.PreferredUsername, "jane")
+	}
+	if !identity.Expiry.Equal(expiry) {
+		t.Errorf("Expiry = %v, want %v", identity.Expiry, expiry)
+	}
+	if identity.IsExpired() {
+		t.Error("IsExpired() = true, want false")
+	}
+}
+
+func TestDecodeIDToken_Expired(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user-123",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	idToken, err := token.SignedString([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	// Expired tokens are still decoded so the identity can be displayed
+	identity, err := DecodeIDToken(idToken)
+	if err != nil {
+		t.Fatalf("DecodeIDToken() error = %v", err)
+	}
+	if !identity.IsExpired() {
+		t.Error("IsExpired() = false, want true")
+	}
+}
+
+func TestDecodeIDToken_Invalid(t *testing.T) {
+	for _, idToken := range []string{"", "not-a-jwt", "a.b.c"} {
+		if _, err := DecodeIDToken(idToken); err == nil {
+			t.Errorf("DecodeIDToken(%q) expected error, got none", idToken)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package oidc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/zalando/go-keyring"
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/keyring"
 )
 
 const (
@@ -18,40 +20,63 @@ const (
 	KeyringService = "sstart"
 	// KeyringUser is the user/account name used for keyring storage
 	KeyringUser = "sso-tokens"
+	// KeyringClientSecretPrefix is the prefix of the keyring account name used for client secrets
+	KeyringClientSecretPrefix = "sso-client-secret:"
 )
 
 // StorageBackend represents the type of storage being used
 type StorageBackend string
 
 const (
+	// StorageBackendAuto stores tokens in the system keyring, falling back to a file when it is not
+	// available (default)
+	StorageBackendAuto StorageBackend = "auto"
 	// StorageBackendKeyring indicates tokens are stored in the system keyring
 	StorageBackendKeyring StorageBackend = "keyring"
 	// StorageBackendFile indicates tokens are stored in a file
 	StorageBackendFile StorageBackend = "file"
+	// StorageBackendMemory indicates tokens are only kept in memory and never persisted
+	StorageBackendMemory StorageBackend = "memory"
 )
 
+// ValidateStorageBackend returns an error for unknown token storage backends; empty selects StorageBackendAuto.
+// The backends are listed in config.TokenStorages, which 'sso.token_storage' is validated against as well.
+func ValidateStorageBackend(backend StorageBackend) error {
+	if err := config.ValidateTokenStorage(string(backend)); err != nil {
+		return fmt.Errorf("invalid token storage '%s': %w", backend, err)
+	}
+	return nil
+}
+
 // storageState tracks which storage backend is being used
 type storageState struct {
 	backend         StorageBackend
 	keyringTested   bool
 	keyringDisabled bool
+	keyringErr      error // Set when the keyring did not respond, e.g. because it is locked
 }
 
 var storage = &storageState{}
 
-// getDefaultTokenPath returns the default path for storing tokens (file fallback)
-func getDefaultTokenPath() string {
+// getDefaultTokenPath returns the default path for storing the tokens of an SSO identity (file fallback):
+// TokenFileName for the default identity, tokens-<identity>.json for named identities
+func getDefaultTokenPath(identity string) string {
+	fileName := TokenFileName
+	if identity != "" {
+		fileName = "tokens-" + identity + ".json"
+	}
+
 	// Use XDG_CONFIG_HOME if set, otherwise use ~/.config
 	configHome := os.Getenv("XDG_CONFIG_HOME")
 	if configHome == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			// Fallback to current directory
-			return filepath.Join(".", ConfigDirName, TokenFileName)
+			return filepath.Join(".", ConfigDirName, fileName)
 		}
 		configHome = filepath.Join(homeDir, ".config")
 	}
-	return filepath.Join(configHome, ConfigDirName, TokenFileName)
+	return filepath.Join(configHome, ConfigDirName, fileName)
 }
 
 // isKeyringAvailable checks if keyring is available on this system
@@ -72,6 +97,7 @@ func isKeyringAvailable() bool {
 			return true
 		}
 		// Any other error means keyring is not available
+		keyringFailed(err)
 		storage.keyringDisabled = true
 		return false
 	}
@@ -80,6 +106,54 @@ func isKeyringAvailable() bool {
 	return true
 }
 
+// keyringFailed disables the keyring after it did not respond, so later operations
+// do not wait for it again
+func keyringFailed(err error) {
+	if errors.Is(err, keyring.ErrTimeout) {
+		storage.keyringErr = err
+		storage.keyringDisabled = true
+	}
+}
+
+// keyringUser returns the keyring account name holding the tokens of the client's SSO identity:
+// KeyringUser for the default identity, KeyringUser:<identity> for named identities
+func (c *Client) keyringUser() string {
+	if c.identity == "" {
+		return KeyringUser
+	}
+	return KeyringUser + ":" + c.identity
+}
+
+// keyringAllowed reports whether the token storage may use the keyring, which is the case
+// for the "auto" (default) and "keyring" storages
+func (c *Client) keyringAllowed() bool {
+	return c.tokenStorage == "" || c.tokenStorage == StorageBackendAuto || c.tokenStorage == StorageBackendKeyring
+}
+
+// useKeyring reports whether tokens are stored in the keyring, which is the case
+// when the token storage allows it and the keyring is available
+func (c *Client) useKeyring() bool {
+	return c.keyringAllowed() && isKeyringAvailable()
+}
+
+// errKeyringUnavailable is returned by the "keyring" token storage when the keyring is not available
+var errKeyringUnavailable = errors.New("the system keyring is not available; set 'sso.token_storage: file' or 'sso.token_storage: memory'")
+
+// StorageError returns an error when stored tokens cannot be read because the keyring did not respond,
+// e.g. because it is locked, and there is no token file to fall back to. A new login would leave later
+// runs waiting for the keyring all the same, so the error suggests how to avoid it.
+func (c *Client) StorageError() error {
+	if !c.keyringAllowed() || c.useKeyring() || storage.keyringErr == nil {
+		return nil
+	}
+	if c.tokenStorage != StorageBackendKeyring {
+		if _, err := os.Stat(c.tokenPath); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot read SSO tokens: %w; unlock the keyring, raise %s or set 'sso.token_storage: file'", storage.keyringErr, keyring.TimeoutEnvVar)
+}
+
 // SetTokenPath sets a custom path for storing tokens (file storage)
 func (c *Client) SetTokenPath(path string) {
 	c.tokenPath = path
@@ -95,12 +169,20 @@ func (c *Client) GetStorageBackend() StorageBackend {
 	return storage.backend
 }
 
-// SaveTokens saves the tokens, trying keyring first then falling back to file
+// SaveTokens saves the tokens in the configured token storage: by default in the keyring,
+// falling back to file
 func (c *Client) SaveTokens(tokens *Tokens) error {
 	if tokens == nil {
 		return fmt.Errorf("tokens cannot be nil")
 	}
 
+	if c.tokenStorage == StorageBackendMemory {
+		saved := *tokens
+		c.memoryTokens = &saved
+		storage.backend = StorageBackendMemory
+		return nil
+	}
+
 	// Marshal tokens to JSON
 	data, err := json.Marshal(tokens)
 	if err != nil {
@@ -108,8 +190,8 @@ func (c *Client) SaveTokens(tokens *Tokens) error {
 	}
 
 	// Try keyring first
-	if isKeyringAvailable() {
-		err := keyring.Set(KeyringService, KeyringUser, string(data))
+	if c.useKeyring() {
+		err := keyring.Set(KeyringService, c.keyringUser(), string(data))
 		if err == nil {
 			storage.backend = StorageBackendKeyring
 			// Clean up any old file storage
@@ -117,6 +199,12 @@ func (c *Client) SaveTokens(tokens *Tokens) error {
 			return nil
 		}
 		// Keyring failed, fall back to file
+		keyringFailed(err)
+		if c.tokenStorage == StorageBackendKeyring {
+			return fmt.Errorf("failed to store tokens in keyring: %w", err)
+		}
+	} else if c.tokenStorage == StorageBackendKeyring {
+		return errKeyringUnavailable
 	}
 
 	// Fall back to file storage
@@ -146,16 +234,26 @@ func (c *Client) saveTokensToFile(tokens *Tokens) error {
 	return nil
 }
 
-// LoadTokens loads the tokens, trying keyring first then falling back to file
+// LoadTokens loads the tokens from the configured token storage: by default from the keyring,
+// falling back to file
 func (c *Client) LoadTokens() (*Tokens, error) {
+	if c.tokenStorage == StorageBackendMemory {
+		if c.memoryTokens == nil {
+			return nil, fmt.Errorf("no tokens found (not authenticated)")
+		}
+		tokens := *c.memoryTokens
+		return &tokens, nil
+	}
+
 	// Try keyring first
-	if isKeyringAvailable() {
-		data, err := keyring.Get(KeyringService, KeyringUser)
+	if c.useKeyring() {
+		data, err := keyring.Get(KeyringService, c.keyringUser())
+		keyringFailed(err)
 		if err == nil {
 			var tokens Tokens
 			if err := json.Unmarshal([]byte(data), &tokens); err != nil {
 				// Invalid data in keyring, try to clean up and check file
-				_ = keyring.Delete(KeyringService, KeyringUser)
+				_ = keyring.Delete(KeyringService, c.keyringUser())
 			} else {
 				storage.backend = StorageBackendKeyring
 				return &tokens, nil
@@ -164,7 +262,16 @@ func (c *Client) LoadTokens() (*Tokens, error) {
 		// Keyring doesn't have tokens or failed, try file
 	}
 
-	// Fall back to file storage
+	// Fall back to file storage, unless the keyring holding the tokens did not respond
+	if err := c.StorageError(); err != nil {
+		return nil, err
+	}
+	if c.tokenStorage == StorageBackendKeyring {
+		if !c.useKeyring() {
+			return nil, errKeyringUnavailable
+		}
+		return nil, fmt.Errorf("no tokens found (not authenticated)")
+	}
 	return c.loadTokensFromFile()
 }
 
@@ -189,11 +296,16 @@ func (c *Client) loadTokensFromFile() (*Tokens, error) {
 
 // ClearTokens removes the stored tokens from both keyring and file
 func (c *Client) ClearTokens() error {
+	if c.tokenStorage == StorageBackendMemory {
+		c.memoryTokens = nil
+		return nil
+	}
+
 	var lastErr error
 
 	// Try to clear from keyring
-	if isKeyringAvailable() {
-		if err := keyring.Delete(KeyringService, KeyringUser); err != nil && err != keyring.ErrNotFound {
+	if c.useKeyring() {
+		if err := keyring.Delete(KeyringService, c.keyringUser()); err != nil && err != keyring.ErrNotFound {
 			lastErr = fmt.Errorf("failed to remove tokens from keyring: %w", err)
 		}
 	}
@@ -208,15 +320,65 @@ func (c *Client) ClearTokens() error {
 
 // TokensExist checks if tokens exist in either keyring or file
 func (c *Client) TokensExist() bool {
+	if c.tokenStorage == StorageBackendMemory {
+		return c.memoryTokens != nil
+	}
+
 	// Check keyring first
-	if isKeyringAvailable() {
-		_, err := keyring.Get(KeyringService, KeyringUser)
+	if c.useKeyring() {
+		_, err := keyring.Get(KeyringService, c.keyringUser())
 		if err == nil {
 			return true
 		}
+		keyringFailed(err)
 	}
 
 	// Check file
 	_, err := os.Stat(c.tokenPath)
 	return err == nil
 }
+
+// clientSecretKeyringUser returns the keyring account name for the client secret of an issuer and client ID
+func clientSecretKeyringUser(issuer, clientID string) string {
+	return KeyringClientSecretPrefix + issuer + "#" + clientID
+}
+
+// SaveClientSecret stores the OIDC client secret for an issuer and client ID in the system keyring
+func SaveClientSecret(issuer, clientID, secret string) error {
+	if secret == "" {
+		return fmt.Errorf("client secret cannot be empty")
+	}
+	if !isKeyringAvailable() {
+		return fmt.Errorf("system keyring is not available")
+	}
+	if err := keyring.Set(KeyringService, clientSecretKeyringUser(issuer, clientID), secret); err != nil {
+		keyringFailed(err)
+		return fmt.Errorf("failed to store client secret in keyring: %w", err)
+	}
+	return nil
+}
+
+// LoadClientSecret reads the OIDC client secret for an issuer and client ID from the system keyring.
+// It returns an empty string if the keyring is unavailable or no secret is stored.
+func LoadClientSecret(issuer, clientID string) string {
+	if !isKeyringAvailable() {
+		return ""
+	}
+	secret, err := keyring.Get(KeyringService, clientSecretKeyringUser(issuer, clientID))
+	if err != nil {
+		keyringFailed(err)
+		return ""
+	}
+	return secret
+}
+
+// DeleteClientSecret removes the OIDC client secret for an issuer and client ID from the system keyring
+func DeleteClientSecret(issuer, clientID string) error {
+	if !isKeyringAvailable() {
+		return nil
+	}
+	if err := keyring.Delete(KeyringService, clientSecretKeyringUser(issuer, clientID)); err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("failed to remove client secret from keyring: %w", err)
+	}
+	return nil
+}
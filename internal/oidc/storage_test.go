@@ -0,0 +1,321 @@
+package oidc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	sstartkeyring "github.com/dirathea/sstart/internal/keyring"
+	"github.com/zalando/go-keyring"
+)
+
+// useMockKeyring replaces the system keyring with an in-memory store for the duration of a test
+func useMockKeyring(t *testing.T) {
+	t.Helper()
+	keyring.MockInit()
+	previous := storage
+	storage = &storageState{}
+	t.Cleanup(func() {
+		storage = previous
+	})
+}
+
+func TestClientSecret_SaveLoadDelete(t *testing.T) {
+	useMockKeyring(t)
+
+	issuer := "https://auth.example.com"
+	clientID := "sstart-cli"
+
+	if got := LoadClientSecret(issuer, clientID); got != "" {
+		t.Fatalf("LoadClientSecret() before save = %q, want empty", got)
+	}
+
+	if err := SaveClientSecret(issuer, clientID, "s3cret"); err != nil {
+		t.Fatalf("SaveClientSecret() error = %v", err)
+	}
+
+	if got := LoadClientSecret(issuer, clientID); got != "s3cret" {
+		t.Errorf("LoadClientSecret() = %q, want %q", got, "s3cret")
+	}
+
+	// Secrets are keyed by issuer and client ID
+	if got := LoadClientSecret(issuer, "other-client"); got != "" {
+		t.Errorf("LoadClientSecret() for other client = %q, want empty", got)
+	}
+	if got := LoadClientSecret("https://other.example.com", clientID); got != "" {
+		t.Errorf("LoadClientSecret() for other issuer = %q, want empty", got)
+	}
+
+	if err := DeleteClientSecret(issuer, clientID); err != nil {
+		t.Fatalf("DeleteClientSecret() error = %v", err)
+	}
+	if got := LoadClientSecret(issuer, clientID); got != "" {
+		t.Errorf("LoadClientSecret() after delete = %q, want empty", got)
+	}
+}
+
+func TestClientSecret_SaveEmpty(t *testing.T) {
+	useMockKeyring(t)
+
+	if err := SaveClientSecret("https://auth.example.com", "sstart-cli", ""); err == nil {
+		t.Error("SaveClientSecret() with empty secret expected error, got none")
+	}
+}
+
+func TestNewClient_ClientSecretPrecedence(t *testing.T) {
+	useMockKeyring(t)
+
+	issuer := "https://auth.example.com"
+	clientID := "sstart-cli"
+	if err := SaveClientSecret(issuer, clientID, "from-keyring"); err != nil {
+		t.Fatalf("SaveClientSecret() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		envVar string
+		want   string
+	}{
+		{name: "keyring used when env var is absent", envVar: "", want: "from-keyring"},
+		{name: "env var takes precedence over keyring", envVar: "from-env", want: "from-env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(SSOSecretEnvVar, tt.envVar)
+
+			cfg := &config.OIDCConfig{
+				ClientID: clientID,
+				Issuer:   issuer,
+				Scopes:   []string{"openid"},
+			}
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if !client.HasClientCredentials() {
+				t.Error("HasClientCredentials() = false, want true")
+			}
+			if cfg.ClientSecret != tt.want {
+				t.Errorf("ClientSecret = %q, want %q", cfg.ClientSecret, tt.want)
+			}
+		})
+	}
+}
+
+// lockedKeyring never answers within a test's keyring timeout, like a locked keyring waiting for an unlock prompt
+type lockedKeyring struct{}
+
+func (lockedKeyring) Get(service, user string) (string, error) {
+	time.Sleep(5 * time.Second)
+	return "", keyring.ErrNotFound
+}
+
+func (lockedKeyring) Set(service, user, password string) error {
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+func (lockedKeyring) Delete(service, user string) error {
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+// useLockedKeyring replaces the system keyring with one that does not respond for the duration of a test
+func useLockedKeyring(t *testing.T) {
+	t.Helper()
+	useMockKeyring(t)
+	t.Setenv(sstartkeyring.TimeoutEnvVar, "100ms")
+	t.Cleanup(sstartkeyring.SetBackend(lockedKeyring{}))
+}
+
+func TestLoadTokens_LockedKeyring(t *testing.T) {
+	useLockedKeyring(t)
+
+	client := &Client{tokenPath: filepath.Join(t.TempDir(), TokenFileName)}
+
+	started := time.Now()
+	_, err := client.LoadTokens()
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("LoadTokens() took %s, want to give up after the keyring timeout", elapsed)
+	}
+	if !errors.Is(err, sstartkeyring.ErrTimeout) {
+		t.Fatalf("LoadTokens() error = %v, want a keyring timeout", err)
+	}
+	if !strings.Contains(err.Error(), "token_storage: file") {
+		t.Errorf("LoadTokens() error = %q, want it to suggest token_storage: file", err)
+	}
+
+	// The keyring is not waited for again
+	started = time.Now()
+	if client.TokensExist() {
+		t.Error("TokensExist() = true, want false")
+	}
+	if err := client.StorageError(); err == nil {
+		t.Error("StorageError() = nil, want the keyring timeout")
+	}
+	if elapsed := time.Since(started); elapsed >= 100*time.Millisecond {
+		t.Errorf("later operations took %s, want the keyring to be skipped", elapsed)
+	}
+
+	// Tokens saved meanwhile fall back to the token file and are read from there
+	if err := client.SaveTokens(&Tokens{AccessToken: "access"}); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+	tokens, err := client.LoadTokens()
+	if err != nil || tokens.AccessToken != "access" {
+		t.Errorf("LoadTokens() after save = %+v, %v, want the saved tokens", tokens, err)
+	}
+}
+
+func TestTokens_FileStorage(t *testing.T) {
+	useLockedKeyring(t)
+
+	client := &Client{tokenPath: filepath.Join(t.TempDir(), TokenFileName), tokenStorage: StorageBackendFile}
+
+	started := time.Now()
+	if _, err := client.LoadTokens(); err == nil || !strings.Contains(err.Error(), "no tokens found") {
+		t.Errorf("LoadTokens() error = %v, want no tokens found", err)
+	}
+	if err := client.SaveTokens(&Tokens{AccessToken: "access"}); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+	if _, err := os.Stat(client.tokenPath); err != nil {
+		t.Errorf("expected tokens in the token file: %v", err)
+	}
+	if !client.TokensExist() {
+		t.Error("TokensExist() = false, want true")
+	}
+	if err := client.ClearTokens(); err != nil {
+		t.Errorf("ClearTokens() error = %v", err)
+	}
+	if elapsed := time.Since(started); elapsed >= 100*time.Millisecond {
+		t.Errorf("file token storage took %s, want the keyring never to be used", elapsed)
+	}
+}
+
+func TestTokens_MemoryStorage(t *testing.T) {
+	useLockedKeyring(t)
+
+	client := &Client{tokenPath: filepath.Join(t.TempDir(), TokenFileName), tokenStorage: StorageBackendMemory}
+
+	started := time.Now()
+	if client.TokensExist() {
+		t.Error("TokensExist() before save = true, want false")
+	}
+	if err := client.SaveTokens(&Tokens{AccessToken: "access"}); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+	if _, err := os.Stat(client.tokenPath); !os.IsNotExist(err) {
+		t.Errorf("expected no token file, got stat error %v", err)
+	}
+	tokens, err := client.LoadTokens()
+	if err != nil || tokens.AccessToken != "access" {
+		t.Errorf("LoadTokens() = %+v, %v, want the saved tokens", tokens, err)
+	}
+	if got := client.GetStorageBackend(); got != StorageBackendMemory {
+		t.Errorf("GetStorageBackend() = %q, want %q", got, StorageBackendMemory)
+	}
+	if err := client.ClearTokens(); err != nil {
+		t.Errorf("ClearTokens() error = %v", err)
+	}
+	if client.TokensExist() {
+		t.Error("TokensExist() after clear = true, want false")
+	}
+	if elapsed := time.Since(started); elapsed >= 100*time.Millisecond {
+		t.Errorf("memory token storage took %s, want the keyring never to be used", elapsed)
+	}
+}
+
+func TestTokens_KeyringStorageDoesNotFallBack(t *testing.T) {
+	useLockedKeyring(t)
+
+	client := &Client{tokenPath: filepath.Join(t.TempDir(), TokenFileName), tokenStorage: StorageBackendKeyring}
+	if err := os.WriteFile(client.tokenPath, []byte(`{"access_token":"stale"}`), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	if _, err := client.LoadTokens(); !errors.Is(err, sstartkeyring.ErrTimeout) {
+		t.Errorf("LoadTokens() error = %v, want a keyring timeout instead of the token file", err)
+	}
+	if err := client.SaveTokens(&Tokens{AccessToken: "access"}); err == nil || !strings.Contains(err.Error(), "keyring is not available") {
+		t.Errorf("SaveTokens() error = %v, want the keyring to be unavailable", err)
+	}
+	data, err := os.ReadFile(client.tokenPath)
+	if err != nil || strings.Contains(string(data), `"access"`) {
+		t.Errorf("token file = %q, %v, want it untouched", data, err)
+	}
+}
+
+func TestValidateStorageBackend(t *testing.T) {
+	for _, backend := range []StorageBackend{"", StorageBackendAuto, StorageBackendKeyring, StorageBackendFile, StorageBackendMemory} {
+		if err := ValidateStorageBackend(backend); err != nil {
+			t.Errorf("ValidateStorageBackend(%q) error = %v", backend, err)
+		}
+	}
+	if err := ValidateStorageBackend("keychain"); err == nil || err.Error() != "invalid token storage 'keychain': must be auto, keyring, file or memory" {
+		t.Errorf("ValidateStorageBackend(\"keychain\") error = %v, want the valid backends listed", err)
+	}
+	// Every valid backend must have a StorageBackend constant
+	if len(config.TokenStorages) != 4 {
+		t.Errorf("config.TokenStorages = %v, want only the backends tested above", config.TokenStorages)
+	}
+}
+
+func TestNewClient_IdentitiesStoreTokensSeparately(t *testing.T) {
+	useMockKeyring(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(SSOSecretEnvVar, "default-secret")
+	t.Setenv(SecretEnvVar("cluster-b"), "cluster-b-secret")
+
+	newClient := func(identity string) *Client {
+		t.Helper()
+		client, err := NewClient(&config.OIDCConfig{ClientID: "sstart-cli", Issuer: "https://auth.example.com", Scopes: []string{"openid"}}, WithIdentity(identity))
+		if err != nil {
+			t.Fatalf("NewClient(%q) error = %v", identity, err)
+		}
+		return client
+	}
+	defaultClient := newClient(config.DefaultSSOIdentity)
+	namedClient := newClient("cluster-b")
+
+	if defaultClient.config.ClientSecret != "default-secret" || namedClient.config.ClientSecret != "cluster-b-secret" {
+		t.Errorf("client secrets = %q, %q, want each identity's own", defaultClient.config.ClientSecret, namedClient.config.ClientSecret)
+	}
+	if filepath.Base(defaultClient.GetTokenPath()) != TokenFileName || filepath.Base(namedClient.GetTokenPath()) != "tokens-cluster-b.json" {
+		t.Errorf("token paths = %q, %q, want separate token files", defaultClient.GetTokenPath(), namedClient.GetTokenPath())
+	}
+
+	if err := defaultClient.SaveTokens(&Tokens{AccessToken: "default-access"}); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+	if namedClient.TokensExist() {
+		t.Error("TokensExist() for cluster-b = true, want the default identity's tokens not to be shared")
+	}
+	if err := namedClient.SaveTokens(&Tokens{AccessToken: "cluster-b-access"}); err != nil {
+		t.Fatalf("SaveTokens() error = %v", err)
+	}
+	if tokens, err := defaultClient.LoadTokens(); err != nil || tokens.AccessToken != "default-access" {
+		t.Errorf("LoadTokens() for default = %+v, %v, want the default identity's tokens", tokens, err)
+	}
+	if tokens, err := namedClient.LoadTokens(); err != nil || tokens.AccessToken != "cluster-b-access" {
+		t.Errorf("LoadTokens() for cluster-b = %+v, %v, want the cluster-b identity's tokens", tokens, err)
+	}
+}
+
+func TestSecretEnvVar(t *testing.T) {
+	tests := map[string]string{
+		"":                        SSOSecretEnvVar,
+		config.DefaultSSOIdentity: SSOSecretEnvVar,
+		"cluster-b":               "SSTART_SSO_SECRET_CLUSTER_B",
+	}
+	for identity, want := range tests {
+		if got := SecretEnvVar(identity); got != want {
+			t.Errorf("SecretEnvVar(%q) = %q, want %q", identity, got, want)
+		}
+	}
+}
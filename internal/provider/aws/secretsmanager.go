@@ -2,27 +2,52 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
-	"strings"
+	"os"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/provider"
 )
 
+// Staging labels of secret versions during rotation
+const (
+	StageCurrent = "AWSCURRENT"
+	StagePending = "AWSPENDING"
+)
+
+// BinaryEncodingBase64 loads a binary secret as base64 instead of its decoded bytes
+const BinaryEncodingBase64 = "base64"
+
+// EndpointEnvVar sets the endpoint of every AWS provider that does not set 'endpoint'
+// (e.g., LocalStack for a fully emulated environment)
+const EndpointEnvVar = "SSTART_AWS_ENDPOINT"
+
 // SecretsManagerConfig represents the configuration for AWS Secrets Manager provider
 type SecretsManagerConfig struct {
 	// SecretID is the ARN or name of the secret in AWS Secrets Manager (required)
 	SecretID string `json:"secret_id" yaml:"secret_id"`
 	// Region is the AWS region where the secret is stored (optional)
 	Region string `json:"region,omitempty" yaml:"region,omitempty"`
-	// Endpoint is a custom endpoint URL for AWS Secrets Manager (optional, for local testing)
+	// Endpoint is a custom endpoint URL for AWS Secrets Manager (optional, for local testing, defaults to SSTART_AWS_ENDPOINT)
 	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// ExpandJSON controls whether a JSON secret is expanded into one key per field (optional, defaults to true)
+	ExpandJSON *bool `json:"expand_json,omitempty" yaml:"expand_json,omitempty"`
+	// PreferPendingOnRotation reads the AWSPENDING version while a rotation is in progress (optional, defaults to false)
+	PreferPendingOnRotation bool `json:"prefer_pending_on_rotation,omitempty" yaml:"prefer_pending_on_rotation,omitempty"`
+	// VersionStage is the staging label of the secret version to read, e.g. AWSPENDING (optional)
+	VersionStage string `json:"version_stage,omitempty" yaml:"version_stage,omitempty"`
+	// VersionID is the unique identifier of the secret version to read (optional, excludes VersionStage)
+	VersionID string `json:"version_id,omitempty" yaml:"version_id,omitempty"`
+	// BinaryEncoding is the encoding of a binary secret's value, "base64" or empty for the decoded bytes (optional)
+	BinaryEncoding string `json:"binary_encoding,omitempty" yaml:"binary_encoding,omitempty"`
 
 	// RoleArn is the ARN of the IAM role to assume using SSO JWT (optional)
 	// When set with SSO tokens, triggers AssumeRoleWithWebIdentity authentication
@@ -54,18 +79,18 @@ func (p *SecretsManagerProvider) Name() string {
 	return "aws_secretsmanager"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *SecretsManagerProvider) ConfigFields() []string {
+	return provider.StructFields(SecretsManagerConfig{})
+}
+
 // Fetch fetches secrets from AWS Secrets Manager
 func (p *SecretsManagerProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid aws_secretsmanager configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.SecretID == "" {
-		return nil, fmt.Errorf("aws_secretsmanager provider requires 'secret_id' field in configuration")
+		return nil, err
 	}
 
 	// Set region if provided
@@ -81,20 +106,50 @@ func (p *SecretsManagerProvider) Fetch(secretContext provider.SecretContext, map
 	input := &secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(cfg.SecretID),
 	}
+	switch {
+	case cfg.VersionID != "":
+		input.VersionId = aws.String(cfg.VersionID)
+	case cfg.VersionStage != "":
+		input.VersionStage = aws.String(cfg.VersionStage)
+	case cfg.PreferPendingOnRotation && p.rotationPending(ctx, mapID, cfg.SecretID):
+		logger.Warnf("Secret '%s' of provider '%s' is being rotated; reading its %s version", cfg.SecretID, mapID, StagePending)
+		input.VersionStage = aws.String(StagePending)
+	}
 
 	result, err := p.client.GetSecretValue(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch secret from AWS Secrets Manager: %w", err)
 	}
 
+	secretValue := aws.ToString(result.SecretString)
+
+	// Fall back to the binary value when the secret has no string value
+	if result.SecretString == nil && result.SecretBinary != nil {
+		secretValue = string(result.SecretBinary)
+		expandBinary := provider.ExpandJSON(cfg.ExpandJSON) && cfg.BinaryEncoding == "" && json.Valid(result.SecretBinary)
+		if !expandBinary {
+			if cfg.BinaryEncoding == BinaryEncodingBase64 {
+				secretValue = base64.StdEncoding.EncodeToString(result.SecretBinary)
+			}
+			logger.Warnf("Secret from provider '%s' is binary. Secret loaded to %s", mapID, provider.RawSecretKey(mapID))
+			return provider.MapRawSecret(mapID, secretValue, keys), nil
+		}
+		logger.Warnf("Secret from provider '%s' is binary. Secret loaded from its JSON content", mapID)
+	}
+
+	// Deliver the whole value as a single key when JSON expansion is disabled
+	if !provider.ExpandJSON(cfg.ExpandJSON) {
+		return provider.MapRawSecret(mapID, secretValue, keys), nil
+	}
+
 	// Parse the secret value (assuming JSON format)
 	var secretData map[string]interface{}
-	if err := json.Unmarshal([]byte(*result.SecretString), &secretData); err != nil {
+	if err := json.Unmarshal([]byte(secretValue), &secretData); err != nil {
 		// If not JSON, treat as a single value
-		secretKey := strings.ToUpper(strings.ReplaceAll(mapID, "-", "_")) + "_SECRET"
-		log.Printf("WARN: Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
+		secretKey := provider.RawSecretKey(mapID)
+		logger.Warnf("Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
 		return []provider.KeyValue{
-			{Key: secretKey, Value: *result.SecretString},
+			{Key: secretKey, Value: secretValue},
 		}, nil
 	}
 
@@ -128,6 +183,61 @@ func (p *SecretsManagerProvider) Fetch(secretContext provider.SecretContext, map
 	return kvs, nil
 }
 
+// Warm creates the AWS client, assuming the configured role if any, so the first fetch needs no handshake
+func (p *SecretsManagerProvider) Warm(secretContext provider.SecretContext, config map[string]interface{}) error {
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return err
+	}
+	if cfg.Region != "" {
+		p.region = cfg.Region
+	}
+	if err := p.ensureClient(secretContext.Ctx, cfg); err != nil {
+		return fmt.Errorf("failed to initialize AWS client: %w", err)
+	}
+	return nil
+}
+
+// Probe describes the configured secret, which reads its metadata but not its value
+func (p *SecretsManagerProvider) Probe(secretContext provider.SecretContext, config map[string]interface{}) (string, error) {
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Region != "" {
+		p.region = cfg.Region
+	}
+	if err := p.ensureClient(secretContext.Ctx, cfg); err != nil {
+		return "", fmt.Errorf("failed to initialize AWS client: %w", err)
+	}
+	result, err := p.client.DescribeSecret(secretContext.Ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(cfg.SecretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe secret '%s': %w", cfg.SecretID, err)
+	}
+	return fmt.Sprintf("secret '%s' found", aws.ToString(result.Name)), nil
+}
+
+// rotationPending reports whether the secret has an AWSPENDING version that is not yet current,
+// i.e. a rotation is in progress. If the secret cannot be described, the current version is used.
+func (p *SecretsManagerProvider) rotationPending(ctx context.Context, mapID, secretID string) bool {
+	result, err := p.client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		logger.Warnf("Failed to check the rotation state of secret '%s' of provider '%s', reading its %s version: %v", secretID, mapID, StageCurrent, err)
+		return false
+	}
+
+	for _, stages := range result.VersionIdsToStages {
+		if slices.Contains(stages, StagePending) && !slices.Contains(stages, StageCurrent) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *SecretsManagerProvider) ensureClient(ctx context.Context, cfg *SecretsManagerConfig) error {
 	if p.client != nil {
 		return nil
@@ -274,6 +384,35 @@ func (p *SecretsManagerProvider) assumeRoleWithJWT(ctx context.Context, cfg *Sec
 	}, nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *SecretsManagerProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the AWS Secrets Manager configuration
+func validateConfig(config map[string]interface{}) (*SecretsManagerConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aws_secretsmanager configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.SecretID == "" {
+		return nil, fmt.Errorf("aws_secretsmanager provider requires 'secret_id' field in configuration")
+	}
+
+	if cfg.VersionStage != "" && cfg.VersionID != "" {
+		return nil, fmt.Errorf("aws_secretsmanager provider accepts only one of 'version_stage' and 'version_id'")
+	}
+
+	if cfg.BinaryEncoding != "" && cfg.BinaryEncoding != BinaryEncodingBase64 {
+		return nil, fmt.Errorf("aws_secretsmanager provider 'binary_encoding' must be '%s', got '%s'", BinaryEncodingBase64, cfg.BinaryEncoding)
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to SecretsManagerConfig
 func parseConfig(config map[string]interface{}) (*SecretsManagerConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -287,6 +426,10 @@ func parseConfig(config map[string]interface{}) (*SecretsManagerConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(EndpointEnvVar)
+	}
+
 	// Extract SSO tokens from the config map (injected by the collector)
 	if accessToken, ok := config["_sso_access_token"].(string); ok {
 		cfg.SSOAccessToken = accessToken
@@ -2,12 +2,20 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
+	"github.com/dirathea/sstart/internal/provider"
 	"github.com/dirathea/sstart/internal/secrets"
 )
 
 func TestParseConfig(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "")
+
 	tests := []struct {
 		name         string
 		config       map[string]interface{}
@@ -377,6 +385,252 @@ func TestParseConfig_SSOTokensNotInJSON(t *testing.T) {
 	}
 }
 
+func TestParseConfig_EndpointFromEnv(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "http://localhost:4566")
+
+	cfg, err := parseConfig(map[string]interface{}{
+		"secret_id": "test-secret",
+	})
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.Endpoint != "http://localhost:4566" {
+		t.Errorf("Config.Endpoint = %v, want %v from %s", cfg.Endpoint, "http://localhost:4566", EndpointEnvVar)
+	}
+
+	// An explicit endpoint overrides the environment
+	cfg, err = parseConfig(map[string]interface{}{
+		"secret_id": "test-secret",
+		"endpoint":  "http://provider-endpoint:1234",
+	})
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.Endpoint != "http://provider-endpoint:1234" {
+		t.Errorf("Config.Endpoint = %v, want %v", cfg.Endpoint, "http://provider-endpoint:1234")
+	}
+}
+
+// newRotationStub serves DescribeSecret and GetSecretValue for a secret with the given version stages,
+// looking values up by version id when one is requested
+func newRotationStub(t *testing.T, stages map[string][]string, values map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct {
+			VersionId    string
+			VersionStage string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch r.Header.Get("X-Amz-Target") {
+		case "secretsmanager.DescribeSecret":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Name": "rotating", "VersionIdsToStages": stages})
+		case "secretsmanager.GetSecretValue":
+			stage := input.VersionStage
+			if input.VersionId != "" {
+				stage = input.VersionId
+			} else if stage == "" {
+				stage = StageCurrent
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Name": "rotating", "SecretString": values[stage]})
+		default:
+			http.Error(w, "unexpected operation", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSecretsManagerProvider_Fetch_PreferPendingOnRotation(t *testing.T) {
+	values := map[string]string{
+		StageCurrent: `{"DB_PASSWORD":"old-password"}`,
+		StagePending: `{"DB_PASSWORD":"new-password"}`,
+	}
+
+	tests := []struct {
+		name          string
+		stages        map[string][]string
+		preferPending bool
+		want          string
+	}{
+		{
+			name:          "rotation in progress",
+			stages:        map[string][]string{"v1": {StageCurrent}, "v2": {StagePending}},
+			preferPending: true,
+			want:          "new-password",
+		},
+		{
+			name:          "option disabled",
+			stages:        map[string][]string{"v1": {StageCurrent}, "v2": {StagePending}},
+			preferPending: false,
+			want:          "old-password",
+		},
+		{
+			name:          "rotation finished",
+			stages:        map[string][]string{"v1": {"AWSPREVIOUS"}, "v2": {StageCurrent, StagePending}},
+			preferPending: true,
+			want:          "old-password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRotationStub(t, tt.stages, values)
+			p := &SecretsManagerProvider{}
+			secretContext := secrets.NewEmptySecretContext(context.Background())
+			kvs, err := p.Fetch(secretContext, "aws", map[string]interface{}{
+				"secret_id":                  "rotating",
+				"region":                     "us-east-1",
+				"endpoint":                   server.URL,
+				"prefer_pending_on_rotation": tt.preferPending,
+			}, nil)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(kvs) != 1 || kvs[0] != (provider.KeyValue{Key: "DB_PASSWORD", Value: tt.want}) {
+				t.Errorf("Fetch() = %v, want DB_PASSWORD=%s", kvs, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretsManagerProvider_Fetch_PinnedVersion(t *testing.T) {
+	stages := map[string][]string{"v1": {StageCurrent}, "v2": {StagePending}}
+	values := map[string]string{
+		StageCurrent: `{"DB_PASSWORD":"old-password"}`,
+		StagePending: `{"DB_PASSWORD":"new-password"}`,
+		"v3":         `{"DB_PASSWORD":"pinned-password"}`,
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "version stage",
+			config: map[string]interface{}{"version_stage": StagePending},
+			want:   "new-password",
+		},
+		{
+			name:   "version id",
+			config: map[string]interface{}{"version_id": "v3"},
+			want:   "pinned-password",
+		},
+		{
+			name:   "pinned stage wins over rotation preference",
+			config: map[string]interface{}{"version_stage": StageCurrent, "prefer_pending_on_rotation": true},
+			want:   "old-password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRotationStub(t, stages, values)
+			config := map[string]interface{}{
+				"secret_id": "rotating",
+				"region":    "us-east-1",
+				"endpoint":  server.URL,
+			}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			p := &SecretsManagerProvider{}
+			kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "aws", config, nil)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(kvs) != 1 || kvs[0] != (provider.KeyValue{Key: "DB_PASSWORD", Value: tt.want}) {
+				t.Errorf("Fetch() = %v, want DB_PASSWORD=%s", kvs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_VersionStageAndID(t *testing.T) {
+	_, err := validateConfig(map[string]interface{}{"secret_id": "rotating", "version_stage": StagePending, "version_id": "v2"})
+	if err == nil || !containsSubstring(err.Error(), "only one of 'version_stage' and 'version_id'") {
+		t.Errorf("validateConfig() error = %v, want version conflict error", err)
+	}
+}
+
+func TestSecretsManagerProvider_Fetch_BinarySecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		binary string
+		config map[string]interface{}
+		keys   map[string]string
+		want   []provider.KeyValue
+	}{
+		{
+			name:   "raw bytes",
+			binary: "certificate-bytes",
+			want:   []provider.KeyValue{{Key: "AWS_SECRET", Value: "certificate-bytes"}},
+		},
+		{
+			name:   "base64 encoding",
+			binary: "certificate-bytes",
+			config: map[string]interface{}{"binary_encoding": "base64"},
+			want:   []provider.KeyValue{{Key: "AWS_SECRET", Value: base64.StdEncoding.EncodeToString([]byte("certificate-bytes"))}},
+		},
+		{
+			name:   "renamed raw bytes",
+			binary: "certificate-bytes",
+			keys:   map[string]string{"AWS_SECRET": "TLS_CERT"},
+			want:   []provider.KeyValue{{Key: "TLS_CERT", Value: "certificate-bytes"}},
+		},
+		{
+			name:   "JSON content with key mapping",
+			binary: `{"DB_PASSWORD":"binary-password","UNUSED":"x"}`,
+			keys:   map[string]string{"DB_PASSWORD": "PASSWORD"},
+			want:   []provider.KeyValue{{Key: "PASSWORD", Value: "binary-password"}},
+		},
+		{
+			name:   "JSON content with expansion disabled",
+			binary: `{"DB_PASSWORD":"binary-password"}`,
+			config: map[string]interface{}{"expand_json": false},
+			want:   []provider.KeyValue{{Key: "AWS_SECRET", Value: `{"DB_PASSWORD":"binary-password"}`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"Name": "binary", "SecretBinary": []byte(tt.binary)})
+			}))
+			defer server.Close()
+
+			config := map[string]interface{}{
+				"secret_id": "binary",
+				"region":    "us-east-1",
+				"endpoint":  server.URL,
+			}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			p := &SecretsManagerProvider{}
+			kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "aws", config, tt.keys)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if !reflect.DeepEqual(kvs, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", kvs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_BinaryEncoding(t *testing.T) {
+	_, err := validateConfig(map[string]interface{}{"secret_id": "binary", "binary_encoding": "hex"})
+	if err == nil || !containsSubstring(err.Error(), "'binary_encoding' must be 'base64'") {
+		t.Errorf("validateConfig() error = %v, want binary_encoding error", err)
+	}
+}
+
 // Helper function to check if a string contains a substring
 func containsSubstring(s, substr string) bool {
 	if len(substr) == 0 {
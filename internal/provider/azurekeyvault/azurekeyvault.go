@@ -5,13 +5,13 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/provider"
 )
 
@@ -24,6 +24,8 @@ type AzureKeyVaultConfig struct {
 	SecretName string `json:"secret_name" yaml:"secret_name"`
 	// Version is the secret version to fetch (optional, defaults to latest)
 	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// ExpandJSON controls whether a JSON secret is expanded into one key per field (optional, defaults to true)
+	ExpandJSON *bool `json:"expand_json,omitempty" yaml:"expand_json,omitempty"`
 }
 
 // AzureKeyVaultProvider implements the provider interface for Azure Key Vault
@@ -42,21 +44,18 @@ func (p *AzureKeyVaultProvider) Name() string {
 	return "azure_keyvault"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *AzureKeyVaultProvider) ConfigFields() []string {
+	return provider.StructFields(AzureKeyVaultConfig{})
+}
+
 // Fetch fetches secrets from Azure Key Vault
 func (p *AzureKeyVaultProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid azure_keyvault configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.VaultURL == "" {
-		return nil, fmt.Errorf("azure_keyvault provider requires 'vault_url' field in configuration")
-	}
-	if cfg.SecretName == "" {
-		return nil, fmt.Errorf("azure_keyvault provider requires 'secret_name' field in configuration")
+		return nil, err
 	}
 
 	if err := p.ensureClient(ctx, cfg.VaultURL); err != nil {
@@ -87,12 +86,17 @@ func (p *AzureKeyVaultProvider) Fetch(secretContext provider.SecretContext, mapI
 		return nil, fmt.Errorf("secret '%s' has no value", cfg.SecretName)
 	}
 
+	// Deliver the whole value as a single key when JSON expansion is disabled
+	if !provider.ExpandJSON(cfg.ExpandJSON) {
+		return provider.MapRawSecret(mapID, secretValue, keys), nil
+	}
+
 	// Try to parse as JSON first
 	var secretData map[string]interface{}
 	if err := json.Unmarshal([]byte(secretValue), &secretData); err != nil {
 		// If not JSON, treat as a single value
-		secretKey := strings.ToUpper(strings.ReplaceAll(mapID, "-", "_")) + "_SECRET"
-		log.Printf("WARN: Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
+		secretKey := provider.RawSecretKey(mapID)
+		logger.Warnf("Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
 		return []provider.KeyValue{
 			{Key: secretKey, Value: secretValue},
 		}, nil
@@ -180,6 +184,30 @@ func (p *AzureKeyVaultProvider) ensureClient(ctx context.Context, vaultURL strin
 	return nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *AzureKeyVaultProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the Azure Key Vault configuration
+func validateConfig(config map[string]interface{}) (*AzureKeyVaultConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure_keyvault configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.VaultURL == "" {
+		return nil, fmt.Errorf("azure_keyvault provider requires 'vault_url' field in configuration")
+	}
+	if cfg.SecretName == "" {
+		return nil, fmt.Errorf("azure_keyvault provider requires 'secret_name' field in configuration")
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to AzureKeyVaultConfig
 func parseConfig(config map[string]interface{}) (*AzureKeyVaultConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -22,8 +22,11 @@ type BitwardenConfig struct {
 	// ItemID is the ID of the item in Bitwarden vault (required)
 	// Can be found using: bw list items --search "item name" or via Bitwarden web vault
 	ItemID string `json:"item_id" yaml:"item_id"`
-	// Format specifies how to parse the secret: "note" (JSON), "fields" (key-value pairs), or "login" (username/password)
+	// Format specifies how to parse the secret: "note" (JSON), "fields" (key-value pairs), "login" (username/password),
+	// or "attachment" (the contents of the file attachment named by AttachmentName)
 	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// AttachmentName is the file name of the attachment loaded with the "attachment" format
+	AttachmentName string `json:"attachment_name,omitempty" yaml:"attachment_name,omitempty"`
 	// BWPath is the path to the Bitwarden CLI binary (optional, defaults to "bw" in PATH)
 	BWPath string `json:"bw_path,omitempty" yaml:"bw_path,omitempty"`
 	// ServerURL is the Bitwarden server URL (optional, defaults to https://vault.bitwarden.com)
@@ -37,13 +40,20 @@ type BitwardenConfig struct {
 
 // BitwardenItem represents a Bitwarden vault item structure from the REST API
 type BitwardenItem struct {
-	ID         string               `json:"id"`
-	Name       string               `json:"name"`
-	Type       int                  `json:"type"` // 1 = Login, 2 = Secure Note, etc.
-	Login      *BitwardenLogin      `json:"login,omitempty"`
-	SecureNote *BitwardenSecureNote `json:"secureNote,omitempty"`
-	Fields     []BitwardenField     `json:"fields,omitempty"`
-	Notes      string               `json:"notes,omitempty"`
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Type        int                   `json:"type"` // 1 = Login, 2 = Secure Note, etc.
+	Login       *BitwardenLogin       `json:"login,omitempty"`
+	SecureNote  *BitwardenSecureNote  `json:"secureNote,omitempty"`
+	Fields      []BitwardenField      `json:"fields,omitempty"`
+	Notes       string                `json:"notes,omitempty"`
+	Attachments []BitwardenAttachment `json:"attachments,omitempty"`
+}
+
+// BitwardenAttachment represents a file attached to a Bitwarden item
+type BitwardenAttachment struct {
+	ID       string `json:"id"`
+	FileName string `json:"fileName"`
 }
 
 // BitwardenLogin represents login credentials
@@ -69,20 +79,29 @@ type BitwardenField struct {
 
 // bwServeProcess manages the bw serve process
 type bwServeProcess struct {
-	cmd    *exec.Cmd
-	port   int
-	host   string
-	client *http.Client
-	apiURL string
+	cmd        *exec.Cmd
+	port       int
+	host       string
+	client     *http.Client
+	apiURL     string
+	bwPath     string
+	serverURL  string
+	sessionKey string // Session of the unlocked vault, shared by the fetches using the process
+	key        string // See bwServeKey
 }
 
 var (
-	bwServeInstance *bwServeProcess
-	bwServeMutex    sync.Mutex
+	// Running bw serve processes, one per configuration (see bwServeKey)
+	bwServeInstances = make(map[string]*bwServeProcess)
+	// Number of provider instances using the process of each configuration
+	bwServeUsers = make(map[string]int)
+	bwServeMutex sync.Mutex
 )
 
 // BitwardenProvider implements the provider interface for personal Bitwarden (using CLI REST API)
-type BitwardenProvider struct{}
+type BitwardenProvider struct {
+	serves map[string]bool // Configurations of the bw serve processes the provider uses, guarded by bwServeMutex
+}
 
 func init() {
 	provider.Register("bitwarden", func() provider.Provider {
@@ -95,91 +114,32 @@ func (p *BitwardenProvider) Name() string {
 	return "bitwarden"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *BitwardenProvider) ConfigFields() []string {
+	return provider.StructFields(BitwardenConfig{})
+}
+
+// SpawnsProcesses reports that the provider runs the bw CLI
+func (p *BitwardenProvider) SpawnsProcesses() bool {
+	return true
+}
+
 // Fetch fetches secrets from personal Bitwarden vault using REST API
 func (p *BitwardenProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("invalid bitwarden configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.ItemID == "" {
-		return nil, fmt.Errorf("bitwarden provider requires 'item_id' field in configuration")
-	}
-
-	// Validate format
-	format := strings.ToLower(cfg.Format)
-	if format != "" && format != "note" && format != "fields" && format != "both" && format != "login" {
-		return nil, fmt.Errorf("bitwarden provider 'format' must be either 'note', 'fields', 'both', or 'login' (got: %s)", cfg.Format)
-	}
-	if format == "" {
-		format = "both" // Default to both format
-	}
-
-	// Determine bw path
-	bwPath := cfg.BWPath
-	if bwPath == "" {
-		bwPath = "bw"
-	}
-
-	// Check if bw is available
-	if err := p.checkBWAvailable(ctx, bwPath); err != nil {
-		return nil, fmt.Errorf("bitwarden CLI not available: %w. Please install it from https://bitwarden.com/help/cli/", err)
-	}
-
-	// Set server URL if provided
-	if cfg.ServerURL != "" {
-		if err := p.setServerURL(ctx, bwPath, cfg.ServerURL); err != nil {
-			return nil, fmt.Errorf("failed to set Bitwarden server URL: %w", err)
-		}
-	}
-
-	// Check for API credentials
-	clientID := getEnv("BW_CLIENTID")
-	clientSecret := getEnv("BW_CLIENTSECRET")
-	if clientID == "" || clientSecret == "" {
-		return nil, fmt.Errorf("bitwarden API credentials required: set BW_CLIENTID and BW_CLIENTSECRET environment variables")
-	}
-
-	// Login using API key
-	session, err := p.loginWithAPIKey(ctx, bwPath, clientID, clientSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to login with API key: %w", err)
-	}
-
-	// Get or start bw serve process
-	apiPort := cfg.APIPort
-	if apiPort == 0 {
-		apiPort = 8087 // Default port
-	}
-	apiHostname := cfg.APIHostname
-	if apiHostname == "" {
-		apiHostname = "localhost" // Default hostname
-	}
-
-	bwServe, err := p.getOrStartBWServe(ctx, bwPath, apiPort, apiHostname)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start bw serve: %w", err)
+		return nil, err
 	}
+	format := cfg.Format
 
-	// Ensure we stop bw serve after fetching (defer cleanup)
-	defer func() {
-		p.stopBWServe(bwServe)
-	}()
-
-	// Get master password for unlocking
-	masterPassword := getEnv("BW_PASSWORD")
-	if masterPassword == "" {
-		return nil, fmt.Errorf("BW_PASSWORD environment variable is required to unlock vault")
-	}
-
-	// Unlock the vault via API to get the raw session
-	sessionKey, err := p.unlockVaultViaAPI(ctx, bwServe, masterPassword, session)
+	// Reuse the bw serve process and session of an earlier fetch of this collection, or start them
+	bwServe, err := p.unlockedBWServe(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unlock vault via API: %w", err)
+		return nil, err
 	}
+	sessionKey := bwServe.sessionKey
 
 	// Fetch the item from Bitwarden REST API
 	item, err := bwServe.getItemByID(ctx, sessionKey, cfg.ItemID)
@@ -190,6 +150,17 @@ func (p *BitwardenProvider) Fetch(secretContext provider.SecretContext, mapID st
 	// Parse secrets based on format
 	var secretData map[string]interface{}
 	switch format {
+	case "attachment":
+		// Download the named attachment
+		attachment, err := findAttachment(item, cfg.AttachmentName)
+		if err != nil {
+			return nil, fmt.Errorf("bitwarden item '%s': %w", cfg.ItemID, err)
+		}
+		content, err := bwServe.getAttachment(ctx, sessionKey, cfg.ItemID, attachment.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download attachment '%s' of bitwarden item '%s': %w", cfg.AttachmentName, cfg.ItemID, err)
+		}
+		secretData = map[string]interface{}{attachment.FileName: string(content)}
 	case "note":
 		// Parse notes as JSON
 		if item.Notes == "" {
@@ -311,6 +282,125 @@ func (p *BitwardenProvider) Fetch(secretContext provider.SecretContext, mapID st
 	return kvs, nil
 }
 
+// Close stops the bw serve processes that no other bitwarden provider uses anymore
+func (p *BitwardenProvider) Close() error {
+	bwServeMutex.Lock()
+	defer bwServeMutex.Unlock()
+
+	for key := range p.serves {
+		bwServeUsers[key]--
+		if bwServeUsers[key] <= 0 {
+			delete(bwServeUsers, key)
+			stopBWServe(bwServeInstances[key])
+		}
+	}
+	p.serves = nil
+	return nil
+}
+
+// bwServeKey identifies the bw serve process of a configuration: its CLI, server and address
+func bwServeKey(bwPath, serverURL, hostname string, port int) string {
+	return fmt.Sprintf("%s|%s|%s:%d", bwPath, serverURL, hostname, port)
+}
+
+// useBWServe records that the provider uses the bw serve process of key, until it is closed.
+// The caller must hold bwServeMutex.
+func (p *BitwardenProvider) useBWServe(key string) {
+	if p.serves[key] {
+		return
+	}
+	if p.serves == nil {
+		p.serves = make(map[string]bool)
+	}
+	p.serves[key] = true
+	bwServeUsers[key]++
+}
+
+// unlockedBWServe returns a running bw serve process with an unlocked vault session.
+// A process started by an earlier fetch is reused when it runs for the same CLI, server and address,
+// so bitwarden providers only log in, start bw serve and unlock once, even across the collections
+// of a watch mode run. The process is stopped once every provider using it is closed.
+func (p *BitwardenProvider) unlockedBWServe(ctx context.Context, cfg *BitwardenConfig) (*bwServeProcess, error) {
+	// Determine bw path
+	bwPath := cfg.BWPath
+	if bwPath == "" {
+		bwPath = "bw"
+	}
+
+	// Get or start bw serve process
+	apiPort := cfg.APIPort
+	if apiPort == 0 {
+		apiPort = 8087 // Default port
+	}
+	apiHostname := cfg.APIHostname
+	if apiHostname == "" {
+		apiHostname = "localhost" // Default hostname
+	}
+
+	key := bwServeKey(bwPath, cfg.ServerURL, apiHostname, apiPort)
+
+	bwServeMutex.Lock()
+	defer bwServeMutex.Unlock()
+
+	p.useBWServe(key)
+	if bwServe, ok := bwServeInstances[key]; ok {
+		if bwServe.running(ctx) {
+			return bwServe, nil
+		}
+		// The process exited or stopped answering; start a new one
+		stopBWServe(bwServe)
+	}
+
+	// Check if bw is available
+	if err := p.checkBWAvailable(ctx, bwPath); err != nil {
+		return nil, fmt.Errorf("bitwarden CLI not available: %w. Please install it from https://bitwarden.com/help/cli/", err)
+	}
+
+	// Set server URL if provided
+	if cfg.ServerURL != "" {
+		if err := p.setServerURL(ctx, bwPath, cfg.ServerURL); err != nil {
+			return nil, fmt.Errorf("failed to set Bitwarden server URL: %w", err)
+		}
+	}
+
+	// Check for API credentials
+	clientID := getEnv("BW_CLIENTID")
+	clientSecret := getEnv("BW_CLIENTSECRET")
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("bitwarden API credentials required: set BW_CLIENTID and BW_CLIENTSECRET environment variables")
+	}
+
+	// Login using API key
+	session, err := p.loginWithAPIKey(ctx, bwPath, clientID, clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to login with API key: %w", err)
+	}
+
+	// Get master password for unlocking
+	masterPassword := getEnv("BW_PASSWORD")
+	if masterPassword == "" {
+		return nil, fmt.Errorf("BW_PASSWORD environment variable is required to unlock vault")
+	}
+
+	bwServe, err := p.startBWServe(ctx, bwPath, apiPort, apiHostname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start bw serve: %w", err)
+	}
+
+	// Unlock the vault via API to get the raw session
+	sessionKey, err := p.unlockVaultViaAPI(ctx, bwServe, masterPassword, session)
+	if err != nil {
+		stopBWServe(bwServe)
+		return nil, fmt.Errorf("failed to unlock vault via API: %w", err)
+	}
+
+	bwServe.serverURL = cfg.ServerURL
+	bwServe.sessionKey = sessionKey
+	bwServe.key = key
+	bwServeInstances[key] = bwServe
+	return bwServe, nil
+}
+
 // checkBWAvailable checks if the Bitwarden CLI is available
 func (p *BitwardenProvider) checkBWAvailable(ctx context.Context, bwPath string) error {
 	cmd := exec.CommandContext(ctx, bwPath, "--version")
@@ -506,67 +596,60 @@ func (p *BitwardenProvider) unlockVaultViaAPI(ctx context.Context, bwServe *bwSe
 	return newSession, nil
 }
 
-// stopBWServe stops the bw serve process if it's managed by this provider instance
-func (p *BitwardenProvider) stopBWServe(bwServe *bwServeProcess) {
+// stopBWServe stops a bw serve process. The caller must hold bwServeMutex.
+func stopBWServe(bwServe *bwServeProcess) {
 	if bwServe == nil || bwServe.cmd == nil || bwServe.cmd.Process == nil {
 		return
 	}
 
-	bwServeMutex.Lock()
-	defer bwServeMutex.Unlock()
+	// Send interrupt signal to gracefully stop the process
+	if err := bwServe.cmd.Process.Signal(os.Interrupt); err != nil {
+		// If interrupt fails, try kill
+		bwServe.cmd.Process.Kill()
+	}
+	// Wait for process to exit (with timeout)
+	done := make(chan error, 1)
+	go func() {
+		done <- bwServe.cmd.Wait()
+	}()
 
-	// Only stop if this is the current instance
-	if bwServeInstance == bwServe {
-		// Send interrupt signal to gracefully stop the process
-		if err := bwServe.cmd.Process.Signal(os.Interrupt); err != nil {
-			// If interrupt fails, try kill
-			bwServe.cmd.Process.Kill()
-		}
-		// Wait for process to exit (with timeout)
-		done := make(chan error, 1)
-		go func() {
-			done <- bwServe.cmd.Wait()
-		}()
-
-		select {
-		case <-done:
-			// Process exited
-		case <-time.After(2 * time.Second):
-			// Timeout - force kill
-			bwServe.cmd.Process.Kill()
-		}
+	select {
+	case <-done:
+		// Process exited
+	case <-time.After(2 * time.Second):
+		// Timeout - force kill
+		bwServe.cmd.Process.Kill()
+	}
 
-		// Clear the instance
-		bwServeInstance = nil
+	// Clear the instance
+	if bwServeInstances[bwServe.key] == bwServe {
+		delete(bwServeInstances, bwServe.key)
 	}
 }
 
-// getOrStartBWServe gets or starts the bw serve process
-func (p *BitwardenProvider) getOrStartBWServe(ctx context.Context, bwPath string, port int, hostname string) (*bwServeProcess, error) {
-	bwServeMutex.Lock()
-	defer bwServeMutex.Unlock()
-
-	// Check if there's already a running instance for this port/hostname
-	if bwServeInstance != nil && bwServeInstance.port == port && bwServeInstance.host == hostname {
-		// Verify it's still running
-		if bwServeInstance.cmd.Process != nil {
-			// Check if process is still alive by making a test request to /status
-			testURL := fmt.Sprintf("http://%s:%d/status", hostname, port)
-			req, _ := http.NewRequestWithContext(ctx, "GET", testURL, nil)
-			resp, err := bwServeInstance.client.Do(req)
-			if err == nil {
-				resp.Body.Close()
-				// Server is responding
-				if resp.StatusCode == http.StatusOK {
-					return bwServeInstance, nil
-				}
-			}
-		}
+// running checks that the bw serve process still answers on its /status endpoint
+func (bs *bwServeProcess) running(ctx context.Context) bool {
+	if bs.cmd == nil || bs.cmd.Process == nil {
+		return false
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/status", bs.apiURL), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := bs.client.Do(req)
+	if err != nil {
+		return false
 	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
 
-	// Start new bw serve process
+// startBWServe starts a bw serve process and waits until it is ready
+func (p *BitwardenProvider) startBWServe(ctx context.Context, bwPath string, port int, hostname string) (*bwServeProcess, error) {
+	// Start new bw serve process. It outlives this fetch, so it is not bound to the fetch context:
+	// Close stops it once no provider uses it.
 	apiURL := fmt.Sprintf("http://%s:%d", hostname, port)
-	cmd := exec.CommandContext(ctx, bwPath, "serve", "--port", fmt.Sprintf("%d", port), "--hostname", hostname)
+	cmd := exec.Command(bwPath, "serve", "--port", fmt.Sprintf("%d", port), "--hostname", hostname)
 	cmd.Env = os.Environ()
 
 	// Start the process
@@ -603,15 +686,14 @@ func (p *BitwardenProvider) getOrStartBWServe(ctx context.Context, bwPath string
 		}
 	}
 
-	bwServeInstance = &bwServeProcess{
+	return &bwServeProcess{
 		cmd:    cmd,
 		port:   port,
 		host:   hostname,
 		client: client,
 		apiURL: apiURL,
-	}
-
-	return bwServeInstance, nil
+		bwPath: bwPath,
+	}, nil
 }
 
 // getItemByID fetches an item by ID from the REST API
@@ -667,6 +749,84 @@ func (bs *bwServeProcess) getItemByID(ctx context.Context, sessionKey, itemID st
 	return item, nil
 }
 
+// findAttachment returns the attachment of item with the given file name
+func findAttachment(item BitwardenItem, name string) (BitwardenAttachment, error) {
+	names := make([]string, 0, len(item.Attachments))
+	for _, attachment := range item.Attachments {
+		if attachment.FileName == name {
+			return attachment, nil
+		}
+		names = append(names, attachment.FileName)
+	}
+	if len(names) == 0 {
+		return BitwardenAttachment{}, fmt.Errorf("no attachment named '%s': the item has no attachments", name)
+	}
+	return BitwardenAttachment{}, fmt.Errorf("no attachment named '%s' (attachments: %s)", name, strings.Join(names, ", "))
+}
+
+// getAttachment downloads the contents of an item's attachment from the REST API
+func (bs *bwServeProcess) getAttachment(ctx context.Context, sessionKey, itemID, attachmentID string) ([]byte, error) {
+	url := fmt.Sprintf("%s/object/attachment/%s?itemid=%s", bs.apiURL, attachmentID, itemID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sessionKey))
+
+	resp, err := bs.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *BitwardenProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the Bitwarden configuration
+func validateConfig(config map[string]interface{}) (*BitwardenConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitwarden configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.ItemID == "" {
+		return nil, fmt.Errorf("bitwarden provider requires 'item_id' field in configuration")
+	}
+
+	// Validate format
+	format := strings.ToLower(cfg.Format)
+	if format != "" && format != "note" && format != "fields" && format != "both" && format != "login" && format != "attachment" {
+		return nil, fmt.Errorf("bitwarden provider 'format' must be either 'note', 'fields', 'both', 'login', or 'attachment' (got: %s)", cfg.Format)
+	}
+	if format == "attachment" && cfg.AttachmentName == "" {
+		return nil, fmt.Errorf("bitwarden provider requires 'attachment_name' with 'attachment' format")
+	}
+	if format != "attachment" && cfg.AttachmentName != "" {
+		return nil, fmt.Errorf("bitwarden provider 'attachment_name' requires 'format: attachment'")
+	}
+	if format == "" {
+		format = "both" // Default to both format
+	}
+	cfg.Format = format
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to BitwardenConfig
 func parseConfig(config map[string]interface{}) (*BitwardenConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -38,21 +38,18 @@ func (p *BitwardenSMProvider) Name() string {
 	return "bitwarden_sm"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *BitwardenSMProvider) ConfigFields() []string {
+	return provider.StructFields(BitwardenSMConfig{})
+}
+
 // Fetch fetches all secrets from a Bitwarden Secret Manager project
 // Only Key-Value pairs are extracted from secrets. Note fields are ignored.
 func (p *BitwardenSMProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
-	// Convert map to strongly typed config struct
-	cfg, err := parseSMConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateSMConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid bitwarden_sm configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.OrganizationID == "" {
-		return nil, fmt.Errorf("bitwarden_sm provider requires 'organization_id' field in configuration")
-	}
-	if cfg.ProjectID == "" {
-		return nil, fmt.Errorf("bitwarden_sm provider requires 'project_id' field in configuration")
+		return nil, err
 	}
 
 	// Get server URL from config or environment or default
@@ -194,6 +191,30 @@ func (p *BitwardenSMProvider) ensureClient(serverURL, accessToken string) error
 }
 
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *BitwardenSMProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateSMConfig(config)
+	return err
+}
+
+// validateSMConfig parses and validates the Bitwarden Secrets Manager configuration
+func validateSMConfig(config map[string]interface{}) (*BitwardenSMConfig, error) {
+	cfg, err := parseSMConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitwarden_sm configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.OrganizationID == "" {
+		return nil, fmt.Errorf("bitwarden_sm provider requires 'organization_id' field in configuration")
+	}
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("bitwarden_sm provider requires 'project_id' field in configuration")
+	}
+
+	return cfg, nil
+}
+
 // parseSMConfig converts a map[string]interface{} to BitwardenSMConfig
 func parseSMConfig(config map[string]interface{}) (*BitwardenSMConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
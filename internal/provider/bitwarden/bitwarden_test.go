@@ -0,0 +1,247 @@
+package bitwarden
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// TestMain lets the test binary act as a fake bw CLI when BW_FAKE_LOG is set
+func TestMain(m *testing.M) {
+	if logFile := os.Getenv("BW_FAKE_LOG"); logFile != "" {
+		os.Exit(fakeBW(logFile, os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeBW implements the bw commands used by the provider, logging each command to logFile
+func fakeBW(logFile string, args []string) int {
+	if len(args) == 0 {
+		return 1
+	}
+	if f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+		fmt.Fprintln(f, args[0])
+		f.Close()
+	}
+
+	switch args[0] {
+	case "--version":
+		fmt.Println("2024.1.0")
+	case "status":
+		fmt.Println(`{"status":"unauthenticated"}`)
+	case "login":
+		fmt.Println("login-session")
+	case "serve":
+		mux := http.NewServeMux()
+		mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"locked"}`))
+		})
+		mux.HandleFunc("/unlock", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"success":true,"data":{"raw":"unlocked-session"}}`))
+		})
+		mux.HandleFunc("/object/item/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer unlocked-session" {
+				http.Error(w, "locked", http.StatusUnauthorized)
+				return
+			}
+			id := strings.TrimPrefix(r.URL.Path, "/object/item/")
+			item := BitwardenItem{ID: id, Fields: []BitwardenField{{Name: "ITEM", Value: id}}}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": item})
+		})
+		if err := http.ListenAndServe(fmt.Sprintf("%s:%s", args[4], args[2]), mux); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return 1
+	}
+	return 0
+}
+
+func TestValidateConfig_Attachment(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "attachment format",
+			config: map[string]interface{}{"item_id": "item", "format": "attachment", "attachment_name": "ca.pem"},
+		},
+		{
+			name:    "attachment format without name",
+			config:  map[string]interface{}{"item_id": "item", "format": "attachment"},
+			wantErr: "requires 'attachment_name'",
+		},
+		{
+			name:    "attachment name without attachment format",
+			config:  map[string]interface{}{"item_id": "item", "format": "note", "attachment_name": "ca.pem"},
+			wantErr: "'attachment_name' requires 'format: attachment'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateConfig(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindAttachment(t *testing.T) {
+	item := BitwardenItem{Attachments: []BitwardenAttachment{{ID: "a1", FileName: "ca.pem"}, {ID: "a2", FileName: "kubeconfig"}}}
+
+	attachment, err := findAttachment(item, "kubeconfig")
+	if err != nil || attachment.ID != "a2" {
+		t.Errorf("findAttachment() = %v, %v, want a2", attachment, err)
+	}
+
+	if _, err := findAttachment(item, "missing.pem"); err == nil || !strings.Contains(err.Error(), "no attachment named 'missing.pem' (attachments: ca.pem, kubeconfig)") {
+		t.Errorf("findAttachment() error = %v, want the available attachments", err)
+	}
+	if _, err := findAttachment(BitwardenItem{}, "ca.pem"); err == nil || !strings.Contains(err.Error(), "the item has no attachments") {
+		t.Errorf("findAttachment() error = %v, want no attachments error", err)
+	}
+}
+
+func TestBWServe_GetAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/object/attachment/a1" || r.URL.Query().Get("itemid") != "item" || r.Header.Get("Authorization") != "Bearer session" {
+			http.Error(w, `{"success":false,"message":"Not found."}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("-----BEGIN CERTIFICATE-----\n"))
+	}))
+	defer server.Close()
+
+	bs := &bwServeProcess{client: server.Client(), apiURL: server.URL}
+	content, err := bs.getAttachment(context.Background(), "session", "item", "a1")
+	if err != nil {
+		t.Fatalf("getAttachment() error = %v", err)
+	}
+	if string(content) != "-----BEGIN CERTIFICATE-----\n" {
+		t.Errorf("getAttachment() = %q", content)
+	}
+
+	if _, err := bs.getAttachment(context.Background(), "session", "item", "a2"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("getAttachment() error = %v, want status 404", err)
+	}
+}
+
+// freePort returns a TCP port that is free on the loopback interface
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// bwServeRunning reports whether a bw serve process runs on the loopback port
+func bwServeRunning(port int) bool {
+	bwServeMutex.Lock()
+	defer bwServeMutex.Unlock()
+	for _, bwServe := range bwServeInstances {
+		if bwServe.port == port {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBitwardenProvider_Fetch_ReusesBWServe(t *testing.T) {
+	port := freePort(t)
+
+	logFile := filepath.Join(t.TempDir(), "bw.log")
+	t.Setenv("BW_FAKE_LOG", logFile)
+	t.Setenv("BW_CLIENTID", "client")
+	t.Setenv("BW_CLIENTSECRET", "secret")
+	t.Setenv("BW_PASSWORD", "password")
+
+	// Two provider blocks of one collection
+	first, second := &BitwardenProvider{}, &BitwardenProvider{}
+	defer first.Close()
+	defer second.Close()
+	secretContext := provider.SecretContext{Ctx: context.Background()}
+	for i, p := range []*BitwardenProvider{first, second} {
+		itemID := fmt.Sprintf("item-%d", i)
+		config := map[string]interface{}{"item_id": itemID, "format": "fields", "bw_path": os.Args[0], "api_port": port, "api_hostname": "127.0.0.1"}
+		kvs, err := p.Fetch(secretContext, itemID, config, nil)
+		if err != nil {
+			t.Fatalf("Fetch(%s) error = %v", itemID, err)
+		}
+		if len(kvs) != 1 || kvs[0].Value != itemID {
+			t.Errorf("Fetch(%s) = %v", itemID, kvs)
+		}
+	}
+
+	commands := func() string {
+		content, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatalf("failed to read the bw log: %v", err)
+		}
+		return strings.Join(strings.Fields(string(content)), " ")
+	}
+	if got, want := commands(), "--version status login serve"; got != want {
+		t.Errorf("bw commands = %q, want %q (logged in and started once)", got, want)
+	}
+
+	// The next collection of a long-lived collector reuses the process and its session
+	config := map[string]interface{}{"item_id": "item-0", "format": "fields", "bw_path": os.Args[0], "api_port": port, "api_hostname": "127.0.0.1"}
+	if _, err := first.Fetch(secretContext, "item-0", config, nil); err != nil {
+		t.Fatalf("Fetch() in the next collection error = %v", err)
+	}
+	if got, want := commands(), "--version status login serve"; got != want {
+		t.Errorf("bw commands = %q, want %q (no login on the next collection)", got, want)
+	}
+
+	// A provider with another configuration gets its own process, leaving the shared one running
+	otherPort := freePort(t)
+	other := &BitwardenProvider{}
+	defer other.Close()
+	otherConfig := map[string]interface{}{"item_id": "item-2", "format": "fields", "bw_path": os.Args[0], "api_port": otherPort, "api_hostname": "127.0.0.1"}
+	if _, err := other.Fetch(secretContext, "item-2", otherConfig, nil); err != nil {
+		t.Fatalf("Fetch() with another configuration error = %v", err)
+	}
+	if !bwServeRunning(port) || !bwServeRunning(otherPort) {
+		t.Fatal("expected a bw serve process per configuration")
+	}
+
+	// The shared process is stopped once every provider using it is closed
+	first.Close()
+	if !bwServeRunning(port) {
+		t.Fatal("Close() stopped bw serve while another provider uses it")
+	}
+	second.Close()
+	if bwServeRunning(port) {
+		t.Fatal("Close() of the last provider did not stop bw serve")
+	}
+	if !bwServeRunning(otherPort) {
+		t.Fatal("Close() stopped the process of another configuration")
+	}
+	other.Close()
+	if bwServeRunning(otherPort) {
+		t.Fatal("Close() did not stop the process of the other configuration")
+	}
+}
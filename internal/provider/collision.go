@@ -0,0 +1,14 @@
+package provider
+
+// KeyCollisionError reports a key that cannot be loaded because several sources supply it,
+// e.g. fields with the same name in different sections of a 1Password item
+type KeyCollisionError struct {
+	Key     string   // Colliding key
+	Sources []string // Sources supplying the key, e.g. "section 'db'" or a ref
+	Detail  string   // Full error message, including how to avoid the collision
+}
+
+// Error returns the detailed message of the collision
+func (e *KeyCollisionError) Error() string {
+	return e.Detail
+}
@@ -56,6 +56,11 @@ func (p *DopplerProvider) Name() string {
 	return "doppler"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *DopplerProvider) ConfigFields() []string {
+	return provider.StructFields(DopplerConfig{})
+}
+
 // Fetch fetches secrets from Doppler
 func (p *DopplerProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
@@ -149,6 +154,12 @@ func (p *DopplerProvider) Fetch(secretContext provider.SecretContext, mapID stri
 	return kvs, nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *DopplerProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
 // validateConfig parses and validates the Doppler configuration
 func validateConfig(config map[string]interface{}) (*DopplerConfig, error) {
 	// Parse config map to strongly typed struct
@@ -3,11 +3,17 @@ package dotenv
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/dirathea/sstart/internal/provider"
 )
 
+// FileRefPrefix marks a value that references a file whose contents become the value,
+// when 'resolve_file_refs' is enabled
+const FileRefPrefix = "file://"
+
 // DotEnvProvider implements the provider interface for .env files
 type DotEnvProvider struct{}
 
@@ -22,13 +28,62 @@ func (p *DotEnvProvider) Name() string {
 	return "dotenv"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *DotEnvProvider) ConfigFields() []string {
+	return []string{"path", "resolve_file_refs"}
+}
+
+// SourceFiles returns the .env file read by the provider
+func (p *DotEnvProvider) SourceFiles(config map[string]interface{}) []string {
+	path, ok := config["path"].(string)
+	if !ok || path == "" {
+		return nil
+	}
+	return []string{os.ExpandEnv(path)}
+}
+
+// Probe checks that the .env file can be opened, without reading it
+func (p *DotEnvProvider) Probe(secretContext provider.SecretContext, config map[string]interface{}) (string, error) {
+	if err := p.ValidateConfig(config); err != nil {
+		return "", err
+	}
+	expandedPath := os.ExpandEnv(config["path"].(string))
+
+	f, err := os.Open(expandedPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open .env file at '%s': %w", expandedPath, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat .env file at '%s': %w", expandedPath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("'%s' is a directory, not a .env file", expandedPath)
+	}
+	return fmt.Sprintf("file '%s' is readable", expandedPath), nil
+}
+
+// ValidateConfig checks the configuration without reading the .env file
+func (p *DotEnvProvider) ValidateConfig(config map[string]interface{}) error {
+	if path, ok := config["path"].(string); !ok || path == "" {
+		return fmt.Errorf("dotenv provider requires 'path' field in configuration")
+	}
+	if resolve, ok := config["resolve_file_refs"]; ok {
+		if _, ok := resolve.(bool); !ok {
+			return fmt.Errorf("dotenv provider 'resolve_file_refs' must be a boolean")
+		}
+	}
+	return nil
+}
+
 // Fetch fetches secrets from a .env file
 func (p *DotEnvProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	// Extract path from config
-	path, ok := config["path"].(string)
-	if !ok || path == "" {
-		return nil, fmt.Errorf("dotenv provider requires 'path' field in configuration")
+	if err := p.ValidateConfig(config); err != nil {
+		return nil, err
 	}
+	path := config["path"].(string)
 
 	// Expand path if it contains environment variables
 	expandedPath := os.ExpandEnv(path)
@@ -39,13 +94,26 @@ func (p *DotEnvProvider) Fetch(secretContext provider.SecretContext, mapID strin
 		return nil, fmt.Errorf("failed to read .env file at '%s': %w", expandedPath, err)
 	}
 
+	// Only the values that are loaded are resolved, so unused references are never read
+	resolveFileRefs, _ := config["resolve_file_refs"].(bool)
+	resolve := func(key, value string) (string, error) {
+		if !resolveFileRefs {
+			return value, nil
+		}
+		return resolveFileRef(filepath.Dir(expandedPath), key, value)
+	}
+
 	// If no keys specified, return all
 	if len(keys) == 0 {
 		kvs := make([]provider.KeyValue, 0, len(envMap))
 		for k, v := range envMap {
+			value, err := resolve(k, v)
+			if err != nil {
+				return nil, err
+			}
 			kvs = append(kvs, provider.KeyValue{
 				Key:   k,
-				Value: v,
+				Value: value,
 			})
 		}
 		return kvs, nil
@@ -58,6 +126,10 @@ func (p *DotEnvProvider) Fetch(secretContext provider.SecretContext, mapID strin
 			if targetKey == "==" {
 				targetKey = envKey // Keep same name
 			}
+			value, err := resolve(envKey, value)
+			if err != nil {
+				return nil, err
+			}
 			kvs = append(kvs, provider.KeyValue{
 				Key:   targetKey,
 				Value: value,
@@ -68,3 +140,23 @@ func (p *DotEnvProvider) Fetch(secretContext provider.SecretContext, mapID strin
 	return kvs, nil
 }
 
+// resolveFileRef returns the contents of the file referenced by a value starting with FileRefPrefix,
+// or the value unchanged. Relative paths are resolved against dir, the directory of the .env file.
+func resolveFileRef(dir, key, value string) (string, error) {
+	path, ok := strings.CutPrefix(value, FileRefPrefix)
+	if !ok {
+		return value, nil
+	}
+	if path == "" {
+		return "", fmt.Errorf("key '%s' references an empty file path", key)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file referenced by key '%s': %w", key, err)
+	}
+	return string(data), nil
+}
+
@@ -282,3 +282,112 @@ func containsSubstring(s, substr string) bool {
 	return false
 }
 
+
+func TestDotEnvProvider_Fetch_ResolveFileRefs(t *testing.T) {
+	provider := &DotEnvProvider{}
+
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "certs"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	keyFile := filepath.Join(tmpDir, "certs", "key.pem")
+	if err := os.WriteFile(keyFile, []byte("-----BEGIN KEY-----\nabc\n-----END KEY-----\n"), 0600); err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+	absFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(absFile, []byte("token-value"), 0600); err != nil {
+		t.Fatalf("Failed to create token file: %v", err)
+	}
+	envFile := filepath.Join(tmpDir, ".env")
+	envContent := "TLS_KEY=file://./certs/key.pem\nTOKEN=file://" + absFile + "\nPLAIN=value\nMISSING=file://missing.pem\n"
+	if err := os.WriteFile(envFile, []byte(envContent), 0600); err != nil {
+		t.Fatalf("Failed to create test .env file: %v", err)
+	}
+
+	secretContext := secrets.NewEmptySecretContext(context.Background())
+	keys := map[string]string{"TLS_KEY": "==", "TOKEN": "API_TOKEN", "PLAIN": "=="}
+
+	t.Run("resolved", func(t *testing.T) {
+		config := map[string]interface{}{"path": envFile, "resolve_file_refs": true}
+		result, err := provider.Fetch(secretContext, "test-map", config, keys)
+		if err != nil {
+			t.Fatalf("DotEnvProvider.Fetch() error = %v", err)
+		}
+		expected := map[string]string{
+			"TLS_KEY":   "-----BEGIN KEY-----\nabc\n-----END KEY-----\n",
+			"API_TOKEN": "token-value",
+			"PLAIN":     "value",
+		}
+		if len(result) != len(expected) {
+			t.Errorf("Expected %d key-value pairs, got %v", len(expected), result)
+		}
+		for _, kv := range result {
+			if kv.Value != expected[kv.Key] {
+				t.Errorf("Key %s: got value %q, want %q", kv.Key, kv.Value, expected[kv.Key])
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		config := map[string]interface{}{"path": envFile}
+		result, err := provider.Fetch(secretContext, "test-map", config, nil)
+		if err != nil {
+			t.Fatalf("DotEnvProvider.Fetch() error = %v", err)
+		}
+		for _, kv := range result {
+			if kv.Key == "TLS_KEY" && kv.Value != "file://./certs/key.pem" {
+				t.Errorf("Expected the reference to be kept, got %q", kv.Value)
+			}
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		config := map[string]interface{}{"path": envFile, "resolve_file_refs": true}
+		_, err := provider.Fetch(secretContext, "test-map", config, nil)
+		if err == nil || !containsSubstring(err.Error(), "failed to read file referenced by key 'MISSING'") ||
+			!containsSubstring(err.Error(), filepath.Join(tmpDir, "missing.pem")) {
+			t.Errorf("Expected a missing file error naming the key and path, got %v", err)
+		}
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		config := map[string]interface{}{"path": envFile, "resolve_file_refs": "yes"}
+		_, err := provider.Fetch(secretContext, "test-map", config, nil)
+		if err == nil || !containsSubstring(err.Error(), "'resolve_file_refs' must be a boolean") {
+			t.Errorf("Expected an invalid flag error, got %v", err)
+		}
+	})
+}
+
+func TestDotEnvProvider_Probe(t *testing.T) {
+	provider := &DotEnvProvider{}
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create test .env file: %v", err)
+	}
+	secretContext := secrets.NewEmptySecretContext(context.Background())
+
+	detail, err := provider.Probe(secretContext, map[string]interface{}{"path": envFile})
+	if err != nil {
+		t.Fatalf("DotEnvProvider.Probe() error = %v", err)
+	}
+	if detail != "file '"+envFile+"' is readable" {
+		t.Errorf("DotEnvProvider.Probe() detail = %q", detail)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing file", path: filepath.Join(tmpDir, "missing.env")},
+		{name: "directory", path: tmpDir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.Probe(secretContext, map[string]interface{}{"path": tt.path}); err == nil {
+				t.Errorf("Expected DotEnvProvider.Probe() to fail for %s", tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Entry is a single statement of a .env file: a variable, a comment line or a blank line
+type Entry struct {
+	Key     string // Variable name (empty for comment and blank lines)
+	Value   string // Decoded value, as read by the dotenv provider
+	Comment string // Text after '#' of a comment line or of an inline comment after the value
+	export  bool   // The variable was declared with an 'export' prefix
+	raw     string // Original text, written back unchanged unless the entry is modified
+}
+
+// IsVariable reports whether the entry declares a variable
+func (e *Entry) IsVariable() bool {
+	return e.Key != ""
+}
+
+// File is an ordered representation of a .env file that keeps comments and blank lines,
+// so that it can be edited and written back without reformatting untouched lines
+type File struct {
+	Entries []Entry
+	// The parsed content did not end with a newline
+	noFinalNewline bool
+}
+
+// ReadFile parses the .env file at path
+func ReadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads an ordered, comment-preserving representation of a .env file.
+// Values are decoded exactly like the dotenv provider reads them. Windows line endings are normalized.
+func Parse(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	file := &File{}
+	content := string(data)
+	if content == "" {
+		return file, nil
+	}
+	if strings.HasSuffix(content, "\n") {
+		content = strings.TrimSuffix(content, "\n")
+	} else {
+		file.noFinalNewline = true
+	}
+	lines := strings.Split(content, "\n")
+
+	// Text of the variables parsed so far, to expand references to earlier variables
+	var parsed strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			file.Entries = append(file.Entries, Entry{raw: line})
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			file.Entries = append(file.Entries, Entry{Comment: trimmed[1:], raw: line})
+			continue
+		}
+
+		entry, last, err := parseVariable(lines, i)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		// Only values that may reference earlier variables need the preceding text
+		source := entry.raw
+		if strings.Contains(entry.raw, "$") {
+			source = parsed.String() + entry.raw
+		}
+		values, err := godotenv.Unmarshal(source)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entry.Value = values[entry.Key]
+
+		parsed.WriteString(entry.raw)
+		parsed.WriteString("\n")
+		file.Entries = append(file.Entries, entry)
+		i = last
+	}
+	return file, nil
+}
+
+// parseVariable splits the variable statement starting at lines[start] into its key, raw text and
+// inline comment. It returns the index of the last line of the statement, as quoted values may span lines.
+func parseVariable(lines []string, start int) (Entry, int, error) {
+	line := strings.TrimLeft(lines[start], " \t")
+	entry := Entry{}
+	if rest := strings.TrimPrefix(line, "export"); rest != line && strings.IndexAny(rest, " \t") == 0 {
+		entry.export = true
+		line = strings.TrimLeft(rest, " \t")
+	}
+
+	separator := strings.IndexAny(line, "=:")
+	if separator < 0 {
+		return Entry{}, 0, fmt.Errorf("missing '=' after variable name")
+	}
+	entry.Key = strings.TrimSpace(line[:separator])
+	if entry.Key == "" {
+		return Entry{}, 0, fmt.Errorf("missing variable name")
+	}
+	value := strings.TrimLeft(line[separator+1:], " \t")
+
+	last := start
+	var rest string
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		// A quote preceded by a backslash does not end the value
+		quote := value[0]
+		text := value[1:]
+		for {
+			end := -1
+			for j := 0; j < len(text); j++ {
+				if text[j] == quote && (j == 0 || text[j-1] != '\\') {
+					end = j
+					break
+				}
+			}
+			if end >= 0 {
+				rest = strings.TrimSpace(text[end+1:])
+				break
+			}
+			if last+1 >= len(lines) {
+				return Entry{}, 0, fmt.Errorf("unterminated quoted value for '%s'", entry.Key)
+			}
+			last++
+			text = lines[last]
+		}
+		if strings.HasPrefix(rest, "#") {
+			entry.Comment = rest[1:]
+		}
+	} else {
+		// Like the dotenv provider, the last '#' preceded by whitespace starts a comment
+		for j := len(value) - 1; j > 0; j-- {
+			if value[j] == '#' && (value[j-1] == ' ' || value[j-1] == '\t') {
+				entry.Comment = value[j+1:]
+				break
+			}
+		}
+	}
+
+	entry.raw = strings.Join(lines[start:last+1], "\n")
+	return entry, last, nil
+}
+
+// Get returns the value of key. When a key is declared several times, the last declaration wins.
+func (f *File) Get(key string) (string, bool) {
+	if i := f.index(key); i >= 0 {
+		return f.Entries[i].Value, true
+	}
+	return "", false
+}
+
+// Set updates the value of key in place, keeping its position and inline comment, or appends
+// the variable at the end of the file
+func (f *File) Set(key, value string) error {
+	quoted, err := QuoteValue(value)
+	if err != nil {
+		return fmt.Errorf("cannot write '%s': %w", key, err)
+	}
+	if i := f.index(key); i >= 0 {
+		entry := &f.Entries[i]
+		entry.Value = value
+		entry.raw = renderVariable(entry, quoted)
+		return nil
+	}
+	entry := Entry{Key: key, Value: value}
+	entry.raw = renderVariable(&entry, quoted)
+	f.Entries = append(f.Entries, entry)
+	return nil
+}
+
+// Map returns the variables of the file. When a key is declared several times, the last declaration wins.
+func (f *File) Map() map[string]string {
+	values := make(map[string]string)
+	for _, entry := range f.Entries {
+		if entry.IsVariable() {
+			values[entry.Key] = entry.Value
+		}
+	}
+	return values
+}
+
+// WriteTo writes the file, keeping the original text of every entry that was not modified
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for i, entry := range f.Entries {
+		b.WriteString(entry.raw)
+		if i < len(f.Entries)-1 || !f.noFinalNewline {
+			b.WriteString("\n")
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (f *File) index(key string) int {
+	for i := len(f.Entries) - 1; i >= 0; i-- {
+		if f.Entries[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func renderVariable(entry *Entry, quoted string) string {
+	line := entry.Key + "=" + quoted
+	if entry.export {
+		line = "export " + line
+	}
+	if entry.Comment != "" {
+		line += " #" + entry.Comment
+	}
+	return line
+}
+
+// QuoteValue quotes a value so that dotenv parsers read it back unchanged and a POSIX shell can source it.
+// Values without single quotes or newlines are single-quoted and taken literally; other values are
+// double-quoted with '\', '"', '$' and '`' escaped, keeping newlines literal.
+func QuoteValue(value string) (string, error) {
+	// Dotenv parsers treat a quote preceded by a backslash as escaped and drop carriage returns
+	if strings.HasSuffix(value, "\\") || strings.Contains(value, "\r") {
+		return "", fmt.Errorf("values containing a carriage return or ending with a backslash cannot be read back by dotenv parsers")
+	}
+	if !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'", nil
+	}
+	// Trailing double quotes are trimmed together with the closing quote
+	if strings.HasSuffix(value, "\"") {
+		return "", fmt.Errorf("values containing a single quote or newline and ending with a double quote cannot be read back by dotenv parsers")
+	}
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "`", "\\`")
+	return "\"" + replacer.Replace(value) + "\"", nil
+}
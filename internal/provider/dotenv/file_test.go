@@ -0,0 +1,187 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+const roundTripContent = `# Database settings
+DB_HOST=localhost # primary
+DB_PASSWORD='s3cr3t # not a comment'
+
+export API_KEY="multi
+line \"value\"" # inline comment
+  # indented comment
+URL=${DB_HOST}:5432
+YAML_STYLE: value
+EMPTY=
+`
+
+func TestParse_RoundTripPreservesOrderAndComments(t *testing.T) {
+	file, err := Parse(strings.NewReader(roundTripContent))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var out strings.Builder
+	if _, err := file.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if out.String() != roundTripContent {
+		t.Errorf("Round trip changed the file:\ngot:\n%s\nwant:\n%s", out.String(), roundTripContent)
+	}
+
+	var keys []string
+	for _, entry := range file.Entries {
+		if entry.IsVariable() {
+			keys = append(keys, entry.Key)
+		}
+	}
+	if got := strings.Join(keys, ","); got != "DB_HOST,DB_PASSWORD,API_KEY,URL,YAML_STYLE,EMPTY" {
+		t.Errorf("Unexpected key order: %s", got)
+	}
+
+	comments := map[int]string{0: " Database settings", 1: " primary", 4: " inline comment", 5: " indented comment"}
+	for i, want := range comments {
+		if got := file.Entries[i].Comment; got != want {
+			t.Errorf("Entries[%d].Comment = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParse_ValuesMatchDotenvProvider(t *testing.T) {
+	file, err := Parse(strings.NewReader(roundTripContent))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected, err := godotenv.Unmarshal(roundTripContent)
+	if err != nil {
+		t.Fatalf("godotenv.Unmarshal() error = %v", err)
+	}
+	got := file.Map()
+	if len(got) != len(expected) {
+		t.Errorf("Map() = %v, want %v", got, expected)
+	}
+	for key, want := range expected {
+		if got[key] != want {
+			t.Errorf("%s = %q, want %q", key, got[key], want)
+		}
+	}
+	if got["URL"] != "localhost:5432" {
+		t.Errorf("Expected references to earlier variables to be expanded, got %q", got["URL"])
+	}
+}
+
+func TestFile_Set(t *testing.T) {
+	file, err := Parse(strings.NewReader("# header\nexport A=old # keep me\nB=b\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err := file.Set("A", "it's new"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := file.Set("C", "added"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var out strings.Builder
+	if _, err := file.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := "# header\nexport A=\"it's new\" # keep me\nB=b\nC='added'\n"
+	if out.String() != want {
+		t.Errorf("WriteTo() =\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if value, ok := file.Get("A"); !ok || value != "it's new" {
+		t.Errorf("Get(A) = %q, %v", value, ok)
+	}
+	if err := file.Set("BAD", "ends with \\"); err == nil {
+		t.Error("Expected an error for a value that cannot be read back")
+	}
+}
+
+func TestQuoteValue_ReadBackByGodotenv(t *testing.T) {
+	values := map[string]string{
+		"PLAIN":     "plain",
+		"SPACES":    "with  spaces # and hash",
+		"QUOTES":    `it's "quoted" \ back`,
+		"MULTILINE": "line1\nit's line2\n",
+		"LITERAL":   `a\nb`,
+		"DOLLAR":    "it's $HOME and ${HOME} and $(id)",
+		"BACKTICK":  "it's `id`",
+		"EMPTY":     "",
+	}
+
+	file := &File{}
+	for key, value := range values {
+		if err := file.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.WriteTo(out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out.Close()
+
+	parsed, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatalf("godotenv.Read() error = %v", err)
+	}
+	reparsed, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for key, want := range values {
+		if parsed[key] != want {
+			t.Errorf("godotenv read %s = %q, want %q", key, parsed[key], want)
+		}
+		if got, _ := reparsed.Get(key); got != want {
+			t.Errorf("ReadFile read %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestParse_NoFinalNewline(t *testing.T) {
+	file, err := Parse(strings.NewReader("A=1\n# last"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var out strings.Builder
+	if _, err := file.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if out.String() != "A=1\n# last" {
+		t.Errorf("WriteTo() = %q", out.String())
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "unterminated quote", content: "A=1\nB=\"open\n", errMsg: "line 2: unterminated quoted value for 'B'"},
+		{name: "missing separator", content: "JUST_A_NAME\n", errMsg: "line 1: missing '='"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.errMsg)
+			}
+		})
+	}
+}
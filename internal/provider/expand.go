@@ -0,0 +1,34 @@
+package provider
+
+import "strings"
+
+// ExpandJSONConfigKey is the provider config key that controls whether JSON secrets
+// are expanded into one key per field
+const ExpandJSONConfigKey = "expand_json"
+
+// ExpandJSON reports whether JSON expansion is enabled for a provider's expand_json setting.
+// Expansion is enabled unless it is explicitly set to false.
+func ExpandJSON(setting *bool) bool {
+	return setting == nil || *setting
+}
+
+// RawSecretKey returns the key used for a secret value that is loaded as a single value,
+// e.g. "MY_PROVIDER_SECRET" for the provider id "my-provider"
+func RawSecretKey(mapID string) string {
+	return strings.ToUpper(strings.ReplaceAll(mapID, "-", "_")) + "_SECRET"
+}
+
+// MapRawSecret returns a secret value loaded as a single value under RawSecretKey,
+// applying the key mapping like any other source key
+func MapRawSecret(mapID, value string, keys map[string]string) []KeyValue {
+	sourceKey := RawSecretKey(mapID)
+	targetKey := sourceKey
+	if mappedKey, exists := keys[sourceKey]; exists {
+		if mappedKey != "==" {
+			targetKey = mappedKey
+		}
+	} else if len(keys) > 0 {
+		return []KeyValue{}
+	}
+	return []KeyValue{{Key: targetKey, Value: value}}
+}
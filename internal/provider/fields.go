@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+)
+
+// StructFields returns the configuration options of a provider configuration struct: the names in the
+// json tags of its fields, including the fields of embedded structs. Fields tagged "-" are skipped.
+func StructFields(cfg interface{}) []string {
+	t := reflect.TypeOf(cfg)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			fields = append(fields, StructFields(reflect.New(field.Type).Interface())...)
+			continue
+		}
+		if !field.IsExported() || name == "" {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs returns the functions available to templates in the configuration and in the
+// template provider. String arguments come first, so functions chain in pipelines:
+// {{ .key | trimPrefix "APP_" | lower }}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"trim":  strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string {
+			return strings.TrimPrefix(s, prefix)
+		},
+		"trimSuffix": func(suffix, s string) string {
+			return strings.TrimSuffix(s, suffix)
+		},
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"hasPrefix": func(prefix, s string) bool {
+			return strings.HasPrefix(s, prefix)
+		},
+		"hasSuffix": func(suffix, s string) bool {
+			return strings.HasSuffix(s, suffix)
+		},
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"b64dec": func(s string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return "", fmt.Errorf("b64dec: %w", err)
+			}
+			return string(decoded), nil
+		},
+		// default returns def when value is missing or empty, so it also accepts references to missing keys
+		"default": func(def, value interface{}) interface{} {
+			if value == nil {
+				return def
+			}
+			if s, ok := value.(string); ok && s == "" {
+				return def
+			}
+			return value
+		},
+	}
+}
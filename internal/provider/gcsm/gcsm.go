@@ -4,27 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"os"
 	"strings"
 
 	"cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/provider"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
+// EndpointEnvVar sets the endpoint of every GCP provider that does not set 'endpoint'
+// (e.g., the Secret Manager emulator for a fully emulated environment)
+const EndpointEnvVar = "SSTART_GCP_ENDPOINT"
+
 // GCSMConfig represents the configuration for Google Cloud Secret Manager provider
 type GCSMConfig struct {
 	// ProjectID is the GCP project ID where the secret is stored (required)
 	ProjectID string `json:"project_id" yaml:"project_id"`
 	// SecretID is the name of the secret in GCSM (required)
 	SecretID string `json:"secret_id" yaml:"secret_id"`
-	// Version is the secret version to fetch (optional, defaults to "latest")
+	// Version is the secret version to fetch: a version number, a version alias or "latest" (optional, defaults to "latest")
 	Version string `json:"version,omitempty" yaml:"version,omitempty"`
-	// Endpoint is a custom endpoint URL for GCSM (optional, for local testing/emulator)
+	// Endpoint is a custom endpoint URL for GCSM (optional, for local testing/emulator, defaults to SSTART_GCP_ENDPOINT)
 	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// ExpandJSON controls whether a JSON secret is expanded into one key per field (optional, defaults to true)
+	ExpandJSON *bool `json:"expand_json,omitempty" yaml:"expand_json,omitempty"`
 }
 
 // GCSMProvider implements the provider interface for Google Cloud Secret Manager
@@ -43,21 +52,18 @@ func (p *GCSMProvider) Name() string {
 	return "gcloud_secretmanager"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *GCSMProvider) ConfigFields() []string {
+	return provider.StructFields(GCSMConfig{})
+}
+
 // Fetch fetches secrets from Google Cloud Secret Manager
 func (p *GCSMProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid gcloud_secretmanager configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.ProjectID == "" {
-		return nil, fmt.Errorf("gcloud_secretmanager provider requires 'project_id' field in configuration")
-	}
-	if cfg.SecretID == "" {
-		return nil, fmt.Errorf("gcloud_secretmanager provider requires 'secret_id' field in configuration")
+		return nil, err
 	}
 
 	if err := p.ensureClient(ctx, cfg.Endpoint); err != nil {
@@ -80,16 +86,22 @@ func (p *GCSMProvider) Fetch(secretContext provider.SecretContext, mapID string,
 
 	result, err := p.client.AccessSecretVersion(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch secret from Google Cloud Secret Manager: %w", err)
+		return nil, p.accessError(ctx, secretName, err)
+	}
+
+	secretString := string(result.Payload.Data)
+
+	// Deliver the whole value as a single key when JSON expansion is disabled
+	if !provider.ExpandJSON(cfg.ExpandJSON) {
+		return provider.MapRawSecret(mapID, secretString, keys), nil
 	}
 
 	// Parse the secret value (assuming JSON format)
 	secretData := make(map[string]interface{})
-	secretString := string(result.Payload.Data)
 	if err := json.Unmarshal([]byte(secretString), &secretData); err != nil {
 		// If not JSON, treat as a single value
-		secretKey := strings.ToUpper(strings.ReplaceAll(mapID, "-", "_")) + "_SECRET"
-		log.Printf("WARN: Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
+		secretKey := provider.RawSecretKey(mapID)
+		logger.Warnf("Secret from provider '%s' is not JSON format. Secret loaded to %s", mapID, secretKey)
 		return []provider.KeyValue{
 			{Key: secretKey, Value: secretString},
 		}, nil
@@ -125,6 +137,27 @@ func (p *GCSMProvider) Fetch(secretContext provider.SecretContext, mapID string,
 	return kvs, nil
 }
 
+// accessError explains a failed access to a secret version, naming versions that are disabled, destroyed or missing
+func (p *GCSMProvider) accessError(ctx context.Context, secretName string, err error) error {
+	switch status.Code(err) {
+	case codes.FailedPrecondition:
+		// The version exists but cannot be accessed: its state tells why
+		version, getErr := p.client.GetSecretVersion(ctx, &secretmanagerpb.GetSecretVersionRequest{Name: secretName})
+		if getErr != nil {
+			break
+		}
+		switch version.State {
+		case secretmanagerpb.SecretVersion_DISABLED:
+			return fmt.Errorf("secret version '%s' is disabled: enable it or set 'version' to an enabled version: %w", secretName, err)
+		case secretmanagerpb.SecretVersion_DESTROYED:
+			return fmt.Errorf("secret version '%s' is destroyed and can no longer be accessed: set 'version' to an enabled version: %w", secretName, err)
+		}
+	case codes.NotFound:
+		return fmt.Errorf("secret version '%s' not found: check 'secret_id' and 'version': %w", secretName, err)
+	}
+	return fmt.Errorf("failed to fetch secret from Google Cloud Secret Manager: %w", err)
+}
+
 func (p *GCSMProvider) ensureClient(ctx context.Context, endpoint string) error {
 	if p.client != nil {
 		return nil
@@ -155,6 +188,33 @@ func (p *GCSMProvider) ensureClient(ctx context.Context, endpoint string) error
 	return nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *GCSMProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the Google Cloud Secret Manager configuration
+func validateConfig(config map[string]interface{}) (*GCSMConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gcloud_secretmanager configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("gcloud_secretmanager provider requires 'project_id' field in configuration")
+	}
+	if cfg.SecretID == "" {
+		return nil, fmt.Errorf("gcloud_secretmanager provider requires 'secret_id' field in configuration")
+	}
+	if strings.Contains(cfg.Version, "/") {
+		return nil, fmt.Errorf("gcloud_secretmanager 'version' must be a version number, a version alias or 'latest', got '%s'", cfg.Version)
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to GCSMConfig
 func parseConfig(config map[string]interface{}) (*GCSMConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -168,6 +228,10 @@ func parseConfig(config map[string]interface{}) (*GCSMConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(EndpointEnvVar)
+	}
+
 	return &cfg, nil
 }
 
@@ -2,12 +2,20 @@ package gcsm
 
 import (
 	"context"
+	"net"
+	"strings"
 	"testing"
 
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/dirathea/sstart/internal/secrets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestParseConfig(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "")
+
 	tests := []struct {
 		name          string
 		config        map[string]interface{}
@@ -267,6 +275,34 @@ func TestGCSMProvider_ConfigWithExtraFields(t *testing.T) {
 	}
 }
 
+func TestParseConfig_EndpointFromEnv(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "localhost:8085")
+
+	cfg, err := parseConfig(map[string]interface{}{
+		"project_id": "my-project",
+		"secret_id":  "my-secret",
+	})
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.Endpoint != "localhost:8085" {
+		t.Errorf("Config.Endpoint = %v, want %v from %s", cfg.Endpoint, "localhost:8085", EndpointEnvVar)
+	}
+
+	// An explicit endpoint overrides the environment
+	cfg, err = parseConfig(map[string]interface{}{
+		"project_id": "my-project",
+		"secret_id":  "my-secret",
+		"endpoint":   "http://provider-endpoint:1234",
+	})
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+	if cfg.Endpoint != "http://provider-endpoint:1234" {
+		t.Errorf("Config.Endpoint = %v, want %v", cfg.Endpoint, "http://provider-endpoint:1234")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func containsSubstring(s, substr string) bool {
 	if len(substr) == 0 {
@@ -283,3 +319,97 @@ func containsSubstring(s, substr string) bool {
 	return false
 }
 
+
+// fakeSecretManager serves secret versions by resource name, refusing access to versions that are not enabled
+type fakeSecretManager struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+	versions map[string]*secretmanagerpb.SecretVersion
+	data     map[string]string
+}
+
+func (s *fakeSecretManager) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	version, found := s.versions[req.Name]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "Secret Version [%s] not found.", req.Name)
+	}
+	if version.State != secretmanagerpb.SecretVersion_ENABLED {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is in %s state.", req.Name, version.State)
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{Name: req.Name, Payload: &secretmanagerpb.SecretPayload{Data: []byte(s.data[req.Name])}}, nil
+}
+
+func (s *fakeSecretManager) GetSecretVersion(ctx context.Context, req *secretmanagerpb.GetSecretVersionRequest) (*secretmanagerpb.SecretVersion, error) {
+	version, found := s.versions[req.Name]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "Secret Version [%s] not found.", req.Name)
+	}
+	return version, nil
+}
+
+func TestGCSMProvider_Fetch_Version(t *testing.T) {
+	const secret = "projects/my-project/secrets/my-secret/versions/"
+	fake := &fakeSecretManager{
+		versions: map[string]*secretmanagerpb.SecretVersion{
+			secret + "latest": {Name: secret + "3", State: secretmanagerpb.SecretVersion_ENABLED},
+			secret + "3":      {Name: secret + "3", State: secretmanagerpb.SecretVersion_ENABLED},
+			secret + "2":      {Name: secret + "2", State: secretmanagerpb.SecretVersion_ENABLED},
+			secret + "stable": {Name: secret + "2", State: secretmanagerpb.SecretVersion_ENABLED},
+			secret + "1":      {Name: secret + "1", State: secretmanagerpb.SecretVersion_DISABLED},
+			secret + "0":      {Name: secret + "0", State: secretmanagerpb.SecretVersion_DESTROYED},
+		},
+		data: map[string]string{
+			secret + "latest": `{"API_KEY":"v3"}`,
+			secret + "3":      `{"API_KEY":"v3"}`,
+			secret + "2":      `{"API_KEY":"v2"}`,
+			secret + "stable": `{"API_KEY":"v2"}`,
+		},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(server, fake)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+		wantErr string
+	}{
+		{name: "defaults to latest", want: "v3"},
+		{name: "version number", version: "2", want: "v2"},
+		{name: "version alias", version: "stable", want: "v2"},
+		{name: "disabled version", version: "1", wantErr: "secret version '" + secret + "1' is disabled"},
+		{name: "destroyed version", version: "0", wantErr: "secret version '" + secret + "0' is destroyed"},
+		{name: "missing version", version: "9", wantErr: "secret version '" + secret + "9' not found"},
+		{name: "resource path as version", version: "versions/2", wantErr: "'version' must be a version number, a version alias or 'latest'"},
+	}
+
+	p := &GCSMProvider{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{"project_id": "my-project", "secret_id": "my-secret", "endpoint": listener.Addr().String()}
+			if tt.version != "" {
+				config["version"] = tt.version
+			}
+
+			kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "gcsm", config, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(kvs) != 1 || kvs[0].Key != "API_KEY" || kvs[0].Value != tt.want {
+				t.Errorf("Fetch() = %v, want API_KEY=%s", kvs, tt.want)
+			}
+		})
+	}
+}
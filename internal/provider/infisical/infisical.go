@@ -42,24 +42,18 @@ func (p *InfisicalProvider) Name() string {
 	return "infisical"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *InfisicalProvider) ConfigFields() []string {
+	return provider.StructFields(InfisicalConfig{})
+}
+
 // Fetch fetches secrets from Infisical
 func (p *InfisicalProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid infisical configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.ProjectID == "" {
-		return nil, fmt.Errorf("infisical provider requires 'project_id' field in configuration")
-	}
-	if cfg.Environment == "" {
-		return nil, fmt.Errorf("infisical provider requires 'environment' field in configuration")
-	}
-	if cfg.Path == "" {
-		return nil, fmt.Errorf("infisical provider requires 'path' field in configuration")
+		return nil, err
 	}
 
 	// Ensure client is initialized
@@ -172,6 +166,33 @@ func (p *InfisicalProvider) ensureClient(ctx context.Context) error {
 	return nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *InfisicalProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the Infisical configuration
+func validateConfig(config map[string]interface{}) (*InfisicalConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid infisical configuration: %w", err)
+	}
+
+	// Validate required fields
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("infisical provider requires 'project_id' field in configuration")
+	}
+	if cfg.Environment == "" {
+		return nil, fmt.Errorf("infisical provider requires 'environment' field in configuration")
+	}
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("infisical provider requires 'path' field in configuration")
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to InfisicalConfig
 func parseConfig(config map[string]interface{}) (*InfisicalConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
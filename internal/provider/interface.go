@@ -31,6 +31,8 @@ type SecretsResolver interface {
 type SecretContext struct {
 	Ctx             context.Context
 	SecretsResolver SecretsResolver
+	// SkipFileWrites asks FileWriter providers to render their files without writing them, e.g. for a dry run
+	SkipFileWrites bool
 }
 
 // Provider is the interface that all secret providers must implement
@@ -43,6 +45,77 @@ type Provider interface {
 	Fetch(secretContext SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]KeyValue, error)
 }
 
+// Closer is implemented by providers that hold long-lived clients or sessions.
+// The collector reuses a provider instance across collections while its configuration
+// is unchanged, and calls Close when the instance is replaced or the collector shuts down.
+type Closer interface {
+	Close() error
+}
+
+// CollectionFinisher is implemented by providers that share processes or sessions between the fetches
+// of a single collection, such as a local server started on the first fetch. The collector calls
+// FinishCollection on its provider instances once a collection is over, so they can be torn down.
+type CollectionFinisher interface {
+	FinishCollection()
+}
+
+// Warmer is implemented by providers that can set up their long-lived clients or sessions, e.g. log in,
+// before the first fetch. Warm must not fetch secrets. The collector calls it on the same instance
+// that later fetches, so the fetch reuses what Warm set up.
+type Warmer interface {
+	Warm(secretContext SecretContext, config map[string]interface{}) error
+}
+
+// Prober is implemented by providers that can check their backend is reachable without fetching
+// secret values, e.g. with a health endpoint or a metadata request. Probe returns a short description
+// of what it checked. 'sstart ping' uses it.
+type Prober interface {
+	Probe(secretContext SecretContext, config map[string]interface{}) (string, error)
+}
+
+// ProcessSpawner is implemented by providers that fetch secrets by running external processes,
+// such as a vendor CLI. The collector limits how many of them fetch at the same time.
+type ProcessSpawner interface {
+	SpawnsProcesses() bool
+}
+
+// KeyNamer is implemented by providers that name their output keys themselves, such as templates,
+// and ignore the keys passed to Fetch. The collector applies the 'keys' mapping to their output.
+type KeyNamer interface {
+	NamesOwnKeys() bool
+}
+
+// InputReader is implemented by providers that read input from the user, such as prompts.
+// The collector fetches them one at a time, in configuration order.
+type InputReader interface {
+	ReadsInput() bool
+}
+
+// FileSource is implemented by providers that read secrets from local files.
+// Watch mode re-collects secrets when one of the returned files changes.
+type FileSource interface {
+	SourceFiles(config map[string]interface{}) []string
+}
+
+// FileWriter is implemented by providers that write files as a side effect of fetching, such as
+// templates rendered into files. The collector never serves them from the cache, so the files are
+// written on every collection, unless SecretContext.SkipFileWrites is set.
+type FileWriter interface {
+	WritesFiles(config map[string]interface{}) bool
+}
+
+// ConfigValidator is implemented by providers that can check a configuration without
+// fetching secrets or contacting their backend. 'sstart self-test' uses it.
+type ConfigValidator interface {
+	ValidateConfig(config map[string]interface{}) error
+}
+
+// ConfigSchema is implemented by providers that know every option they accept. Loading a
+// configuration rejects the options of their providers that are not listed.
+type ConfigSchema interface {
+	ConfigFields() []string
+}
+
 // Registry holds all registered providers
 var registry = make(map[string]func() Provider)
 
@@ -68,3 +141,40 @@ func List() []string {
 	}
 	return kinds
 }
+
+// Fields returns the options accepted by providers of the given kind. It returns false when the kind
+// is not registered or its provider does not declare its options.
+func Fields(kind string) ([]string, bool) {
+	p, err := New(kind)
+	if err != nil {
+		return nil, false
+	}
+	schema, ok := p.(ConfigSchema)
+	if !ok {
+		return nil, false
+	}
+	return schema.ConfigFields(), true
+}
+
+// Validate instantiates a provider of the given kind and validates config without fetching secrets.
+// A panic in the provider is reported as an error.
+func Validate(kind string, config map[string]interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("provider '%s' panicked: %v", kind, r)
+		}
+	}()
+
+	p, err := New(kind)
+	if err != nil {
+		return err
+	}
+	if name := p.Name(); name != kind {
+		return fmt.Errorf("provider registered as '%s' reports name '%s'", kind, name)
+	}
+	validator, ok := p.(ConfigValidator)
+	if !ok {
+		return fmt.Errorf("provider '%s' does not support configuration validation", kind)
+	}
+	return validator.ValidateConfig(config)
+}
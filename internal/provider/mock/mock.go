@@ -0,0 +1,153 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// MockConfig represents the configuration for the mock provider
+type MockConfig struct {
+	// Values is the fixed key-value map returned by the provider; numbers and booleans are returned as strings
+	Values map[string]interface{} `json:"values"`
+	// Delay is an artificial latency before returning (e.g., "500ms", "2s")
+	Delay string `json:"delay,omitempty"`
+	// Fail makes every fetch return an error
+	Fail bool `json:"fail,omitempty"`
+	// Error is the error message returned when Fail is set (optional)
+	Error string `json:"error,omitempty"`
+
+	values map[string]string // Values converted to strings by validateConfig
+}
+
+// MockProvider implements the provider interface with an in-memory key-value map.
+// It is intended for tests and local demos that should not depend on a real backend.
+type MockProvider struct{}
+
+func init() {
+	provider.Register("mock", func() provider.Provider {
+		return &MockProvider{}
+	})
+}
+
+// Name returns the provider name
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+// ConfigFields returns the options accepted by the provider
+func (p *MockProvider) ConfigFields() []string {
+	return provider.StructFields(MockConfig{})
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *MockProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the mock configuration
+func validateConfig(config map[string]interface{}) (*MockConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock configuration: %w", err)
+	}
+
+	if cfg.Delay != "" {
+		if _, err := time.ParseDuration(cfg.Delay); err != nil {
+			return nil, fmt.Errorf("invalid mock delay '%s': %w", cfg.Delay, err)
+		}
+	}
+
+	cfg.values = make(map[string]string, len(cfg.Values))
+	for k, v := range cfg.Values {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return nil, fmt.Errorf("invalid mock value '%s': value must be a string, number or boolean", k)
+		}
+		cfg.values[k] = fmt.Sprint(v)
+	}
+
+	return cfg, nil
+}
+
+// parseConfig converts a map[string]interface{} to MockConfig
+func parseConfig(config map[string]interface{}) (*MockConfig, error) {
+	// Use JSON marshaling/unmarshaling for clean conversion
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Numbers are kept as written, rather than converted to float64
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var cfg MockConfig
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Fetch returns the configured values after the configured delay, or an error if fail is set
+func (p *MockProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Delay != "" {
+		delay, err := time.ParseDuration(cfg.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock delay '%s': %w", cfg.Delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-secretContext.Ctx.Done():
+			return nil, secretContext.Ctx.Err()
+		}
+	}
+
+	if cfg.Fail {
+		if cfg.Error != "" {
+			return nil, fmt.Errorf("mock provider failure: %s", cfg.Error)
+		}
+		return nil, fmt.Errorf("mock provider failure")
+	}
+
+	// If no keys specified, return all
+	if len(keys) == 0 {
+		kvs := make([]provider.KeyValue, 0, len(cfg.values))
+		for k, v := range cfg.values {
+			kvs = append(kvs, provider.KeyValue{
+				Key:   k,
+				Value: v,
+			})
+		}
+		return kvs, nil
+	}
+
+	// Map keys according to configuration
+	kvs := make([]provider.KeyValue, 0)
+	for sourceKey, targetKey := range keys {
+		if value, exists := cfg.values[sourceKey]; exists {
+			if targetKey == "==" {
+				targetKey = sourceKey // Keep same name
+			}
+			kvs = append(kvs, provider.KeyValue{
+				Key:   targetKey,
+				Value: value,
+			})
+		}
+	}
+
+	return kvs, nil
+}
@@ -0,0 +1,141 @@
+package mock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+func TestMockProvider_Name(t *testing.T) {
+	provider := &MockProvider{}
+	if got := provider.Name(); got != "mock" {
+		t.Errorf("MockProvider.Name() = %v, want %v", got, "mock")
+	}
+}
+
+func TestMockProvider_Fetch(t *testing.T) {
+	config := map[string]interface{}{
+		"values": map[string]interface{}{
+			"API_KEY": "secret-key",
+			"DB_HOST": "localhost",
+			"DB_PORT": 5432,
+			"ENABLED": true,
+			"RATIO":   0.25,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		keys     map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "no keys returns all values",
+			expected: map[string]string{
+				"API_KEY": "secret-key",
+				"DB_HOST": "localhost",
+				"DB_PORT": "5432",
+				"ENABLED": "true",
+				"RATIO":   "0.25",
+			},
+		},
+		{
+			name: "keys filter and rename values",
+			keys: map[string]string{
+				"API_KEY": "==",
+				"DB_HOST": "DATABASE_HOST",
+				"MISSING": "==",
+			},
+			expected: map[string]string{
+				"API_KEY":       "secret-key",
+				"DATABASE_HOST": "localhost",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockProvider{}
+			kvs, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "mock", config, tt.keys)
+			if err != nil {
+				t.Fatalf("MockProvider.Fetch() unexpected error: %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, kv := range kvs {
+				got[kv.Key] = kv.Value
+			}
+			if len(got) != len(tt.expected) {
+				t.Errorf("MockProvider.Fetch() returned %d keys, want %d: %v", len(got), len(tt.expected), got)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("MockProvider.Fetch()[%s] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestMockProvider_InvalidValue(t *testing.T) {
+	provider := &MockProvider{}
+	config := map[string]interface{}{
+		"values": map[string]interface{}{
+			"NESTED": map[string]interface{}{"key": "value"},
+		},
+	}
+
+	err := provider.ValidateConfig(config)
+	if err == nil || !strings.Contains(err.Error(), "invalid mock value 'NESTED'") {
+		t.Errorf("MockProvider.ValidateConfig() error = %v, want error about 'NESTED'", err)
+	}
+}
+
+func TestMockProvider_Fail(t *testing.T) {
+	provider := &MockProvider{}
+	config := map[string]interface{}{
+		"fail":  true,
+		"error": "backend unavailable",
+	}
+
+	_, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "mock", config, nil)
+	if err == nil {
+		t.Fatal("MockProvider.Fetch() expected error, got none")
+	}
+	if !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("MockProvider.Fetch() error = %v, want error containing 'backend unavailable'", err)
+	}
+}
+
+func TestMockProvider_Delay(t *testing.T) {
+	provider := &MockProvider{}
+	config := map[string]interface{}{
+		"values": map[string]interface{}{"KEY": "value"},
+		"delay":  "50ms",
+	}
+
+	start := time.Now()
+	if _, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "mock", config, nil); err != nil {
+		t.Fatalf("MockProvider.Fetch() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("MockProvider.Fetch() returned after %v, want at least 50ms", elapsed)
+	}
+
+	// Delay must honor context cancellation
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	config["delay"] = "5s"
+	_, err := provider.Fetch(secrets.NewEmptySecretContext(ctx), "mock", config, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("MockProvider.Fetch() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	config["delay"] = "soon"
+	if _, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "mock", config, nil); err == nil {
+		t.Error("MockProvider.Fetch() expected error for invalid delay, got none")
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/1password/onepassword-sdk-go"
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/provider"
 )
 
@@ -21,12 +22,25 @@ type OnePasswordConfig struct {
 	//   - op://VaultName/ItemName/sectionName (whole section)
 	//   - op://VaultName/ItemName (whole item)
 	Ref string `json:"ref" yaml:"ref"`
+	// Refs are further secret references fetched by the same provider, in the same formats as Ref.
+	// Refs sharing a vault/item fetch the item once, and their secrets are merged with the same
+	// collision rules as the fields of a whole item.
+	Refs []string `json:"refs,omitempty" yaml:"refs,omitempty"`
 	// UseSectionPrefix controls whether section names are used as prefixes for field keys.
 	// When true (default), fields in sections will have keys like "SectionName_FieldName".
 	// When false, fields will use just "FieldName", and collisions will be warned.
 	UseSectionPrefix *bool `json:"use_section_prefix,omitempty" yaml:"use_section_prefix,omitempty"`
 }
 
+// allRefs returns Ref followed by Refs
+func (c *OnePasswordConfig) allRefs() []string {
+	var refs []string
+	if c.Ref != "" {
+		refs = append(refs, c.Ref)
+	}
+	return append(refs, c.Refs...)
+}
+
 // OnePasswordProvider implements the provider interface for 1Password
 type OnePasswordProvider struct {
 	client *onepassword.Client
@@ -43,68 +57,184 @@ func (p *OnePasswordProvider) Name() string {
 	return "1password"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *OnePasswordProvider) ConfigFields() []string {
+	return provider.StructFields(OnePasswordConfig{})
+}
+
 // Fetch fetches secrets from 1Password
 func (p *OnePasswordProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid 1password configuration: %w", err)
+		return nil, err
 	}
 
-	// Validate required fields
-	if cfg.Ref == "" {
-		return nil, fmt.Errorf("1password provider requires 'ref' field in configuration")
+	// Ensure client is initialized
+	if err := p.ensureClient(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize 1Password client: %w", err)
 	}
 
-	// Validate ref format
-	if !strings.HasPrefix(cfg.Ref, "op://") {
-		return nil, fmt.Errorf("1password ref must start with 'op://' (got: %s)", cfg.Ref)
+	items := make(map[string]*onepassword.Item)
+	merged := newRefSecrets()
+	for _, ref := range cfg.allRefs() {
+		// Parse the ref to determine what we're fetching
+		parsedRef, err := parseRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ref '%s': %w", ref, err)
+		}
+
+		// Fetch the item once using vault and item from the ref
+		// This is the key optimization: we only make one API call per unique vault/item combination
+		itemKey := parsedRef.Vault + "/" + parsedRef.Item
+		item, ok := items[itemKey]
+		if !ok {
+			item, err = p.getItem(ctx, parsedRef.Vault, parsedRef.Item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get item '%s/%s': %w", parsedRef.Vault, parsedRef.Item, err)
+			}
+			items[itemKey] = item
+		}
+
+		secretData, sections, err := p.extractSecrets(item, cfg, parsedRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := merged.add(parsedRef, secretData, sections); err != nil {
+			return nil, err
+		}
 	}
 
-	// Ensure client is initialized
-	if err := p.ensureClient(ctx); err != nil {
-		return nil, fmt.Errorf("failed to initialize 1Password client: %w", err)
+	// Map keys according to configuration
+	return mapSecretKeys(merged.data, keys), nil
+}
+
+// Warm creates the 1Password client, so the first fetch needs no authentication
+func (p *OnePasswordProvider) Warm(secretContext provider.SecretContext, config map[string]interface{}) error {
+	if _, err := validateConfig(config); err != nil {
+		return err
+	}
+	if err := p.ensureClient(secretContext.Ctx); err != nil {
+		return fmt.Errorf("failed to initialize 1Password client: %w", err)
 	}
+	return nil
+}
 
-	// Parse the ref to determine what we're fetching
-	parsedRef, err := parseRef(cfg.Ref)
+// Probe validates the service account token by listing the vaults it can access, without reading items
+func (p *OnePasswordProvider) Probe(secretContext provider.SecretContext, config map[string]interface{}) (string, error) {
+	if _, err := validateConfig(config); err != nil {
+		return "", err
+	}
+	if err := p.ensureClient(secretContext.Ctx); err != nil {
+		return "", fmt.Errorf("failed to initialize 1Password client: %w", err)
+	}
+	vaults, err := p.client.Vaults().List(secretContext.Ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ref '%s': %w", cfg.Ref, err)
+		return "", fmt.Errorf("failed to list vaults: %w", err)
 	}
+	return fmt.Sprintf("token valid, %d vault(s) accessible", len(vaults)), nil
+}
 
-	// Fetch the item once using vault and item from the ref
-	// This is the key optimization: we only make one API call per unique vault/item combination
-	item, err := p.getItem(ctx, parsedRef.Vault, parsedRef.Item)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get item '%s/%s': %w", parsedRef.Vault, parsedRef.Item, err)
+// refOrigin is where a merged key was loaded from
+type refOrigin struct {
+	ref     *parsedRef
+	section string
+}
+
+// refSecrets merges the secrets extracted from several refs of one provider
+type refSecrets struct {
+	data    map[string]interface{}
+	origins map[string]refOrigin
+}
+
+func newRefSecrets() *refSecrets {
+	return &refSecrets{data: make(map[string]interface{}), origins: make(map[string]refOrigin)}
+}
+
+// add merges the secrets extracted from a ref, given the section of each key ("" for top-level fields).
+// Like the fields of a whole item, a top-level field takes precedence over a section field of the same item,
+// and fields of different sections collide. Keys loaded from different items always collide.
+// Keys are merged in sorted order, so the same collision is reported on every run.
+func (s *refSecrets) add(ref *parsedRef, secretData map[string]interface{}, sections map[string]string) error {
+	keys := make([]string, 0, len(secretData))
+	for key := range secretData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := secretData[key]
+		origin := refOrigin{ref: ref, section: sections[key]}
+		existing, exists := s.origins[key]
+		if !exists {
+			s.origins[key] = origin
+			s.data[key] = value
+			continue
+		}
+
+		sameItem := existing.ref.Vault == ref.Vault && existing.ref.Item == ref.Item
+		switch {
+		case !sameItem:
+			return &provider.KeyCollisionError{
+				Key:     key,
+				Sources: []string{fmt.Sprintf("ref '%s'", existing.ref.Ref), fmt.Sprintf("ref '%s'", ref.Ref)},
+				Detail:  fmt.Sprintf("collision detected: field '%s' is loaded by both ref '%s' and ref '%s'. Use use_section_prefix: true or separate provider blocks to avoid collisions", key, existing.ref.Ref, ref.Ref),
+			}
+		case existing.section == origin.section:
+			// The same field, loaded by overlapping refs
+		case existing.section == "" || origin.section == "":
+			sectionTitle := existing.section + origin.section
+			logger.Warnf("Field '%s' exists as both top-level field and in section '%s' in item '%s/%s'. Top-level field will be used. To load the section field instead, either: (1) rename the top-level field or section in 1Password, or (2) use use_section_prefix: true to load both (section field will be '%s_%s')", key, sectionTitle, ref.Vault, ref.Item, sectionTitle, key)
+			if origin.section == "" {
+				s.origins[key] = origin
+				s.data[key] = value
+			}
+		default:
+			return sectionCollision(key, existing.section, origin.section, ref)
+		}
+	}
+	return nil
+}
+
+// sectionCollision reports a field that exists in two sections of the item of ref
+func sectionCollision(key, section, otherSection string, ref *parsedRef) error {
+	return &provider.KeyCollisionError{
+		Key:     key,
+		Sources: []string{fmt.Sprintf("section '%s'", section), fmt.Sprintf("section '%s'", otherSection)},
+		Detail:  fmt.Sprintf("collision detected: field '%s' exists in both section '%s' and section '%s' in item '%s/%s'. Use use_section_prefix: true to avoid collisions", key, section, otherSection, ref.Vault, ref.Item),
 	}
+}
 
+// extractSecrets extracts the secrets selected by the ref from an already-fetched item,
+// along with the section title of each key ("" for top-level fields)
+func (p *OnePasswordProvider) extractSecrets(item *onepassword.Item, cfg *OnePasswordConfig, parsedRef *parsedRef) (map[string]interface{}, map[string]string, error) {
 	// Resolve ambiguous references (field vs section) using the already-fetched item
 	if err := p.resolveAmbiguousRef(item, cfg, parsedRef); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Extract secrets from the item based on the ref type
-	var secretData map[string]interface{}
-
 	if parsedRef.Field != "" {
 		// Fetching a specific field (or field in section)
-		secretData, err = p.extractField(item, cfg, parsedRef)
+		secretData, err := p.extractField(item, cfg, parsedRef)
+		return secretData, sectionOf(secretData, parsedRef.Section), err
 	} else if parsedRef.Section != "" {
 		// Fetching a whole section
-		secretData, err = p.extractSection(item, cfg, parsedRef)
-	} else {
-		// Fetching the whole item
-		secretData, err = p.extractWholeItem(item, cfg, parsedRef)
+		secretData, err := p.extractSection(item, cfg, parsedRef)
+		return secretData, sectionOf(secretData, parsedRef.Section), err
 	}
+	// Fetching the whole item
+	return p.extractWholeItem(item, cfg, parsedRef)
+}
 
-	if err != nil {
-		return nil, err
+// sectionOf returns the section of every key of secretData when all of them come from one section
+func sectionOf(secretData map[string]interface{}, section string) map[string]string {
+	sections := make(map[string]string, len(secretData))
+	for key := range secretData {
+		sections[key] = section
 	}
-
-	// Map keys according to configuration
-	return mapSecretKeys(secretData, keys), nil
+	return sections
 }
 
 // resolveAmbiguousRef resolves ambiguous references where part3 could be a field or section
@@ -133,7 +263,7 @@ func (p *OnePasswordProvider) resolveAmbiguousRef(item *onepassword.Item, cfg *O
 
 		// If both exist, prioritize top-level field and warn
 		if hasTopLevelField && hasSection {
-			log.Printf("WARNING: Ambiguous reference '%s': both a top-level field '%s' and a section '%s' exist in item '%s/%s'. Using top-level field. To load the section instead, either: (1) rename the top-level field or section in 1Password to avoid ambiguity, or (2) use 'op://%s/%s' with use_section_prefix: true to load all fields from the item", cfg.Ref, parsedRef.Field, parsedRef.Field, parsedRef.Vault, parsedRef.Item, parsedRef.Vault, parsedRef.Item)
+			logger.Warnf("Ambiguous reference '%s': both a top-level field '%s' and a section '%s' exist in item '%s/%s'. Using top-level field. To load the section instead, either: (1) rename the top-level field or section in 1Password to avoid ambiguity, or (2) use 'op://%s/%s' with use_section_prefix: true to load all fields from the item", parsedRef.Ref, parsedRef.Field, parsedRef.Field, parsedRef.Vault, parsedRef.Item, parsedRef.Vault, parsedRef.Item)
 			// Keep as field reference (top-level field takes precedence)
 		} else if hasSection && !hasTopLevelField {
 			// Only section exists, treat as section reference
@@ -263,8 +393,8 @@ func (p *OnePasswordProvider) extractSection(item *onepassword.Item, cfg *OnePas
 	return secretData, nil
 }
 
-// extractWholeItem extracts all fields from an already-fetched 1Password item
-func (p *OnePasswordProvider) extractWholeItem(item *onepassword.Item, cfg *OnePasswordConfig, parsedRef *parsedRef) (map[string]interface{}, error) {
+// extractWholeItem extracts all fields from an already-fetched 1Password item, along with the section of each key
+func (p *OnePasswordProvider) extractWholeItem(item *onepassword.Item, cfg *OnePasswordConfig, parsedRef *parsedRef) (map[string]interface{}, map[string]string, error) {
 	// Build a map of section IDs to section titles
 	sectionIDToTitle := make(map[string]string)
 	for _, section := range item.Sections {
@@ -284,14 +414,14 @@ func (p *OnePasswordProvider) extractWholeItem(item *onepassword.Item, cfg *OneP
 
 	// Second pass: Process section fields
 	if err := p.processSectionFields(item, cfg, parsedRef, sectionIDToTitle, secretData, keyToSection, processedKeys); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if len(secretData) == 0 {
-		return nil, fmt.Errorf("no fields found in item '%s/%s'", parsedRef.Vault, parsedRef.Item)
+		return nil, nil, fmt.Errorf("no fields found in item '%s/%s'", parsedRef.Vault, parsedRef.Item)
 	}
 
-	return secretData, nil
+	return secretData, keyToSection, nil
 }
 
 // processTopLevelFields processes top-level fields from an item
@@ -353,6 +483,7 @@ func (p *OnePasswordProvider) processSectionFields(
 			// Use prefix if explicitly enabled
 			fieldKey = fmt.Sprintf("%s_%s", sectionTitle, field.Title)
 			// With prefix, no collision possible
+			keyToSection[fieldKey] = sectionTitle
 			secretData[fieldKey] = field.Value
 		} else {
 			// No prefix - check for collisions
@@ -361,11 +492,11 @@ func (p *OnePasswordProvider) processSectionFields(
 				existingSection := keyToSection[fieldKey]
 				if existingSection == "" {
 					// Top-level field already exists - it takes precedence, warn about section field
-					log.Printf("WARNING: Field '%s' exists as both top-level field and in section '%s' in item '%s/%s'. Top-level field will be used. To load the section field instead, either: (1) rename the top-level field or section in 1Password, or (2) use use_section_prefix: true to load both (section field will be '%s_%s')", fieldKey, sectionTitle, parsedRef.Vault, parsedRef.Item, sectionTitle, field.Title)
+					logger.Warnf("Field '%s' exists as both top-level field and in section '%s' in item '%s/%s'. Top-level field will be used. To load the section field instead, either: (1) rename the top-level field or section in 1Password, or (2) use use_section_prefix: true to load both (section field will be '%s_%s')", fieldKey, sectionTitle, parsedRef.Vault, parsedRef.Item, sectionTitle, field.Title)
 					// Skip this section field - top-level field already in secretData
 				} else if existingSection != sectionTitle {
 					// Field exists in multiple sections - this is an error
-					return fmt.Errorf("collision detected: field '%s' exists in both section '%s' and section '%s' in item '%s/%s'. Use use_section_prefix: true to avoid collisions", fieldKey, existingSection, sectionTitle, parsedRef.Vault, parsedRef.Item)
+					return sectionCollision(fieldKey, existingSection, sectionTitle, parsedRef)
 				}
 			} else {
 				// No collision, add the field
@@ -410,6 +541,7 @@ func mapSecretKeys(secretData map[string]interface{}, keys map[string]string) []
 
 // parsedRef represents a parsed 1Password reference
 type parsedRef struct {
+	Ref     string
 	Vault   string
 	Item    string
 	Section string
@@ -431,6 +563,7 @@ func parseRef(ref string) (*parsedRef, error) {
 	}
 
 	parsed := &parsedRef{
+		Ref:   ref,
 		Vault: parts[0],
 		Item:  parts[1],
 	}
@@ -507,6 +640,12 @@ func (p *OnePasswordProvider) getItemIDByTitle(ctx context.Context, vaultID, ite
 	return "", fmt.Errorf("item '%s' not found in vault", itemTitle)
 }
 
+// Close drops the cached 1Password client so a later fetch authenticates again
+func (p *OnePasswordProvider) Close() error {
+	p.client = nil
+	return nil
+}
+
 // ensureClient initializes the 1Password client if not already initialized
 func (p *OnePasswordProvider) ensureClient(ctx context.Context) error {
 	if p.client != nil {
@@ -533,6 +672,35 @@ func (p *OnePasswordProvider) ensureClient(ctx context.Context) error {
 	return nil
 }
 
+// ValidateConfig checks the configuration without fetching secrets
+func (p *OnePasswordProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the 1Password configuration
+func validateConfig(config map[string]interface{}) (*OnePasswordConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 1password configuration: %w", err)
+	}
+
+	// Validate required fields
+	refs := cfg.allRefs()
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("1password provider requires 'ref' or 'refs' field in configuration")
+	}
+
+	// Validate ref format
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, "op://") {
+			return nil, fmt.Errorf("1password ref must start with 'op://' (got: %s)", ref)
+		}
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to OnePasswordConfig
 func parseConfig(config map[string]interface{}) (*OnePasswordConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
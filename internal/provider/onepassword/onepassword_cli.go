@@ -0,0 +1,240 @@
+package onepassword
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/1password/onepassword-sdk-go"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// OnePasswordCLIConfig represents the configuration for the 1Password CLI provider
+type OnePasswordCLIConfig struct {
+	OnePasswordConfig
+	// OPPath is the path to the 1Password CLI binary (optional, defaults to "op" in PATH)
+	OPPath string `json:"op_path,omitempty" yaml:"op_path,omitempty"`
+	// Account is the 1Password account to use when several are signed in (optional)
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+}
+
+// OnePasswordCLIProvider implements the provider interface for 1Password by shelling out to the
+// `op` CLI. It uses the CLI's own session (e.g., desktop app integration) instead of a service account.
+type OnePasswordCLIProvider struct {
+	// extractor shares the item extraction logic with the SDK-based provider
+	extractor OnePasswordProvider
+}
+
+// opItem represents the JSON output of `op item get --format json`
+type opItem struct {
+	ID       string      `json:"id"`
+	Title    string      `json:"title"`
+	Sections []opSection `json:"sections"`
+	Fields   []opField   `json:"fields"`
+}
+
+// opSection represents a section in `op item get` output
+type opSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// opField represents a field in `op item get` output
+type opField struct {
+	ID      string     `json:"id"`
+	Label   string     `json:"label"`
+	Value   string     `json:"value"`
+	Section *opSection `json:"section,omitempty"`
+}
+
+func init() {
+	provider.Register("1password_cli", func() provider.Provider {
+		return &OnePasswordCLIProvider{}
+	})
+}
+
+// Name returns the provider name
+func (p *OnePasswordCLIProvider) Name() string {
+	return "1password_cli"
+}
+
+// ConfigFields returns the options accepted by the provider
+func (p *OnePasswordCLIProvider) ConfigFields() []string {
+	return provider.StructFields(OnePasswordCLIConfig{})
+}
+
+// SpawnsProcesses reports that the provider runs the op CLI
+func (p *OnePasswordCLIProvider) SpawnsProcesses() bool {
+	return true
+}
+
+// Fetch fetches secrets from 1Password using the op CLI
+func (p *OnePasswordCLIProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	ctx := secretContext.Ctx
+	// Parse and validate configuration
+	cfg, err := validateCLIConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*onepassword.Item)
+	merged := newRefSecrets()
+	for _, ref := range cfg.allRefs() {
+		parsedRef, err := parseRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ref '%s': %w", ref, err)
+		}
+
+		secretData, sections, err := p.fetchRef(ctx, cfg, parsedRef, items)
+		if err != nil {
+			return nil, err
+		}
+		if err := merged.add(parsedRef, secretData, sections); err != nil {
+			return nil, err
+		}
+	}
+
+	// Map keys according to configuration
+	return mapSecretKeys(merged.data, keys), nil
+}
+
+// fetchRef extracts the secrets of one ref, getting each vault/item at most once across refs
+func (p *OnePasswordCLIProvider) fetchRef(ctx context.Context, cfg *OnePasswordCLIConfig, parsedRef *parsedRef, items map[string]*onepassword.Item) (map[string]interface{}, map[string]string, error) {
+	itemKey := parsedRef.Vault + "/" + parsedRef.Item
+	item, fetched := items[itemKey]
+
+	// A section and field are unambiguous, so the value can be read directly unless the item is already fetched
+	if parsedRef.Section != "" && parsedRef.Field != "" && !fetched {
+		value, err := p.read(ctx, cfg, parsedRef.Ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		fieldName := parsedRef.Field
+		if cfg.UseSectionPrefix != nil && *cfg.UseSectionPrefix {
+			fieldName = fmt.Sprintf("%s_%s", parsedRef.Section, parsedRef.Field)
+		}
+		return map[string]interface{}{fieldName: value}, map[string]string{fieldName: parsedRef.Section}, nil
+	}
+
+	// Otherwise fetch the whole item and extract fields like the SDK provider does
+	if !fetched {
+		var err error
+		item, err = p.getItem(ctx, cfg, parsedRef.Vault, parsedRef.Item)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[itemKey] = item
+	}
+
+	return p.extractor.extractSecrets(item, &cfg.OnePasswordConfig, parsedRef)
+}
+
+// read reads a single value with `op read`
+func (p *OnePasswordCLIProvider) read(ctx context.Context, cfg *OnePasswordCLIConfig, ref string) (string, error) {
+	output, err := p.run(ctx, cfg, "read", "--no-newline", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s': %w", ref, err)
+	}
+	return string(output), nil
+}
+
+// getItem fetches an item with `op item get` and converts it to the SDK item type
+func (p *OnePasswordCLIProvider) getItem(ctx context.Context, cfg *OnePasswordCLIConfig, vaultName, itemTitle string) (*onepassword.Item, error) {
+	output, err := p.run(ctx, cfg, "item", "get", itemTitle, "--vault", vaultName, "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item '%s/%s': %w", vaultName, itemTitle, err)
+	}
+	return parseOPItem(output)
+}
+
+// run executes the op CLI and returns its stdout
+func (p *OnePasswordCLIProvider) run(ctx context.Context, cfg *OnePasswordCLIConfig, args ...string) ([]byte, error) {
+	opPath := cfg.OPPath
+	if opPath == "" {
+		opPath = "op"
+	}
+	if cfg.Account != "" {
+		args = append(args, "--account", cfg.Account)
+	}
+
+	cmd := exec.CommandContext(ctx, opPath, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w (output: %s)", opPath, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// parseOPItem converts `op item get --format json` output to the SDK item type
+func parseOPItem(data []byte) (*onepassword.Item, error) {
+	var raw opItem
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse op item output: %w", err)
+	}
+
+	item := &onepassword.Item{
+		ID:    raw.ID,
+		Title: raw.Title,
+	}
+	for _, section := range raw.Sections {
+		item.Sections = append(item.Sections, onepassword.ItemSection{
+			ID:    section.ID,
+			Title: section.Label,
+		})
+	}
+	for _, field := range raw.Fields {
+		itemField := onepassword.ItemField{
+			ID:    field.ID,
+			Title: field.Label,
+			Value: field.Value,
+		}
+		if field.Section != nil {
+			sectionID := field.Section.ID
+			itemField.SectionID = &sectionID
+		}
+		item.Fields = append(item.Fields, itemField)
+	}
+
+	return item, nil
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *OnePasswordCLIProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateCLIConfig(config)
+	return err
+}
+
+// validateCLIConfig parses and validates the 1Password CLI configuration
+func validateCLIConfig(config map[string]interface{}) (*OnePasswordCLIConfig, error) {
+	cfg, err := parseCLIConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 1password_cli configuration: %w", err)
+	}
+
+	// Validate required fields
+	if len(cfg.allRefs()) == 0 {
+		return nil, fmt.Errorf("1password_cli provider requires 'ref' or 'refs' field in configuration")
+	}
+
+	return cfg, nil
+}
+
+// parseCLIConfig converts a map[string]interface{} to OnePasswordCLIConfig
+func parseCLIConfig(config map[string]interface{}) (*OnePasswordCLIConfig, error) {
+	// Use JSON marshaling/unmarshaling for clean conversion
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cfg OnePasswordCLIConfig
+	if err := json.Unmarshal(jsonData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,289 @@
+package onepassword
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+const testOPItemJSON = `{
+  "id": "abc123",
+  "title": "app",
+  "sections": [
+    {"id": "add more"},
+    {"id": "db", "label": "database"}
+  ],
+  "fields": [
+    {"id": "username", "label": "username", "value": "admin"},
+    {"id": "password", "label": "password", "value": "top-secret"},
+    {"id": "host", "label": "host", "value": "db.internal", "section": {"id": "db", "label": "database"}},
+    {"id": "port", "label": "port", "value": "5432", "section": {"id": "db", "label": "database"}}
+  ]
+}`
+
+func TestParseCLIConfig(t *testing.T) {
+	cfg, err := parseCLIConfig(map[string]interface{}{
+		"ref":                "op://vault/app/database",
+		"op_path":            "/usr/local/bin/op",
+		"account":            "my.1password.com",
+		"use_section_prefix": true,
+	})
+	if err != nil {
+		t.Fatalf("parseCLIConfig() error: %v", err)
+	}
+	if cfg.Ref != "op://vault/app/database" {
+		t.Errorf("Ref = %q", cfg.Ref)
+	}
+	if cfg.OPPath != "/usr/local/bin/op" {
+		t.Errorf("OPPath = %q", cfg.OPPath)
+	}
+	if cfg.Account != "my.1password.com" {
+		t.Errorf("Account = %q", cfg.Account)
+	}
+	if cfg.UseSectionPrefix == nil || !*cfg.UseSectionPrefix {
+		t.Errorf("UseSectionPrefix = %v, want true", cfg.UseSectionPrefix)
+	}
+}
+
+func TestParseOPItem(t *testing.T) {
+	item, err := parseOPItem([]byte(testOPItemJSON))
+	if err != nil {
+		t.Fatalf("parseOPItem() error: %v", err)
+	}
+	if len(item.Sections) != 2 || item.Sections[1].Title != "database" {
+		t.Errorf("unexpected sections: %+v", item.Sections)
+	}
+	if len(item.Fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(item.Fields))
+	}
+	if item.Fields[0].SectionID != nil {
+		t.Errorf("expected top-level field without section, got %v", *item.Fields[0].SectionID)
+	}
+	if item.Fields[2].SectionID == nil || *item.Fields[2].SectionID != "db" {
+		t.Errorf("expected field in section 'db', got %v", item.Fields[2].SectionID)
+	}
+
+	if _, err := parseOPItem([]byte("not json")); err == nil {
+		t.Error("parseOPItem() expected error for invalid output")
+	}
+}
+
+// writeFakeOP writes a stub op CLI that answers `item get` and `read`
+func writeFakeOP(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op CLI requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	itemFile := filepath.Join(dir, "item.json")
+	if err := os.WriteFile(itemFile, []byte(testOPItemJSON), 0600); err != nil {
+		t.Fatalf("failed to write item file: %v", err)
+	}
+
+	opPath := filepath.Join(dir, "op")
+	script := `#!/bin/sh
+echo "$*" >> ` + filepath.Join(dir, "calls.log") + `
+case "$1" in
+  item) cat ` + itemFile + ` ;;
+  read) printf '%s' "read:$3" ;;
+  *) echo "unexpected command: $*" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(opPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake op: %v", err)
+	}
+	return opPath
+}
+
+func TestOnePasswordCLIProvider_Fetch(t *testing.T) {
+	opPath := writeFakeOP(t)
+
+	tests := []struct {
+		name     string
+		ref      string
+		keys     map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "whole item",
+			ref:      "op://vault/app",
+			expected: map[string]string{"username": "admin", "password": "top-secret", "host": "db.internal", "port": "5432"},
+		},
+		{
+			name:     "top-level field",
+			ref:      "op://vault/app/password",
+			keys:     map[string]string{"password": "DB_PASSWORD"},
+			expected: map[string]string{"DB_PASSWORD": "top-secret"},
+		},
+		{
+			name:     "section",
+			ref:      "op://vault/app/database",
+			expected: map[string]string{"host": "db.internal", "port": "5432"},
+		},
+		{
+			name:     "field in section uses op read",
+			ref:      "op://vault/app/database/host",
+			expected: map[string]string{"host": "read:op://vault/app/database/host"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &OnePasswordCLIProvider{}
+			config := map[string]interface{}{"ref": tt.ref, "op_path": opPath}
+
+			kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "op", config, tt.keys)
+			if err != nil {
+				t.Fatalf("Fetch() error: %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, kv := range kvs {
+				got[kv.Key] = kv.Value
+			}
+			if len(got) != len(tt.expected) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.expected)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("Fetch()[%s] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestOnePasswordCLIProvider_Fetch_Refs(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]interface{}
+		expected  map[string]string
+		itemGets  int
+		wantError string
+	}{
+		{
+			name: "ref and refs of one item",
+			config: map[string]interface{}{
+				"ref":  "op://vault/app/password",
+				"refs": []interface{}{"op://vault/app/database", "op://vault/app/database/host"},
+			},
+			expected: map[string]string{"password": "top-secret", "host": "db.internal", "port": "5432"},
+			itemGets: 1,
+		},
+		{
+			name: "overlapping refs",
+			config: map[string]interface{}{
+				"refs": []interface{}{"op://vault/app", "op://vault/app/username"},
+			},
+			expected: map[string]string{"username": "admin", "password": "top-secret", "host": "db.internal", "port": "5432"},
+			itemGets: 1,
+		},
+		{
+			name: "section prefix does not separate items",
+			config: map[string]interface{}{
+				"refs":               []interface{}{"op://vault/app/database", "op://vault/other/database"},
+				"use_section_prefix": true,
+			},
+			wantError: "collision detected: field 'database_host' is loaded by both ref 'op://vault/app/database' and ref 'op://vault/other/database'",
+		},
+		{
+			name: "collision across items",
+			config: map[string]interface{}{
+				"refs": []interface{}{"op://vault/app/password", "op://vault/other/password"},
+			},
+			wantError: "collision detected: field 'password' is loaded by both ref 'op://vault/app/password' and ref 'op://vault/other/password'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opPath := writeFakeOP(t)
+			p := &OnePasswordCLIProvider{}
+			config := map[string]interface{}{"op_path": opPath}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "op", config, nil)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantError)
+				}
+				var collision *provider.KeyCollisionError
+				if strings.HasPrefix(tt.wantError, "collision detected") && (!errors.As(err, &collision) || collision.Key == "") {
+					t.Errorf("Fetch() error = %#v, want a provider.KeyCollisionError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error: %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, kv := range kvs {
+				got[kv.Key] = kv.Value
+			}
+			if len(got) != len(tt.expected) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.expected)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("Fetch()[%s] = %q, want %q", k, got[k], v)
+				}
+			}
+
+			calls, err := os.ReadFile(filepath.Join(filepath.Dir(opPath), "calls.log"))
+			if err != nil {
+				t.Fatalf("failed to read op calls: %v", err)
+			}
+			if gets := strings.Count(string(calls), "item get"); gets != tt.itemGets {
+				t.Errorf("op item get ran %d times, want %d:\n%s", gets, tt.itemGets, calls)
+			}
+		})
+	}
+}
+
+func TestOnePasswordCLIProvider_Errors(t *testing.T) {
+	p := &OnePasswordCLIProvider{}
+	ctx := secrets.NewEmptySecretContext(context.Background())
+
+	if _, err := p.Fetch(ctx, "op", map[string]interface{}{}, nil); err == nil {
+		t.Error("Fetch() expected error for missing ref")
+	}
+	if _, err := p.Fetch(ctx, "op", map[string]interface{}{"ref": "vault/app"}, nil); err == nil {
+		t.Error("Fetch() expected error for ref without op:// prefix")
+	}
+	if _, err := p.Fetch(ctx, "op", map[string]interface{}{"ref": "op://vault/app", "op_path": "/nonexistent/op"}, nil); err == nil {
+		t.Error("Fetch() expected error for missing op binary")
+	}
+}
+
+// TestOnePasswordCLIProvider_Live reads a real reference with the installed op CLI.
+// It requires op to be signed in and OP_CLI_TEST_REF to point to an existing item or field.
+func TestOnePasswordCLIProvider_Live(t *testing.T) {
+	if _, err := exec.LookPath("op"); err != nil {
+		t.Skip("op CLI not available")
+	}
+	ref := os.Getenv("OP_CLI_TEST_REF")
+	if ref == "" {
+		t.Skip("OP_CLI_TEST_REF not set")
+	}
+
+	p := &OnePasswordCLIProvider{}
+	kvs, err := p.Fetch(secrets.NewEmptySecretContext(context.Background()), "op", map[string]interface{}{"ref": ref}, nil)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(kvs) == 0 {
+		t.Error("Fetch() returned no secrets")
+	}
+}
@@ -0,0 +1,39 @@
+package onepassword
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+func TestMapSecretKeys(t *testing.T) {
+	secretData := map[string]interface{}{"HOST": "db.internal", "PORT": "5432", "password": "top-secret"}
+
+	tests := []struct {
+		name string
+		keys map[string]string
+		want []provider.KeyValue
+	}{
+		{
+			name: "no mapping loads every field",
+			want: []provider.KeyValue{{Key: "HOST", Value: "db.internal"}, {Key: "PORT", Value: "5432"}, {Key: "password", Value: "top-secret"}},
+		},
+		{
+			name: "rename and keep-same skip unlisted fields",
+			keys: map[string]string{"HOST": "DB_HOST", "PORT": "=="},
+			want: []provider.KeyValue{{Key: "DB_HOST", Value: "db.internal"}, {Key: "PORT", Value: "5432"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapSecretKeys(secretData, tt.keys)
+			sort.Slice(got, func(i, j int) bool { return got[i].Key < got[j].Key })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mapSecretKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package prompt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"golang.org/x/term"
+)
+
+// PromptConfig represents the configuration for the prompt provider
+type PromptConfig struct {
+	// Key is the name of the secret to ask for (required)
+	Key string `json:"key"`
+	// Message is the text shown to the user (optional, defaults to "Enter <key>: ")
+	Message string `json:"message,omitempty"`
+}
+
+// PromptProvider implements the provider interface by asking the user for a value.
+// On a terminal the value is read without echo; otherwise one line is read from stdin.
+type PromptProvider struct {
+	// reader overrides stdin (used for tests)
+	reader io.Reader
+	// output receives the prompt message (defaults to stderr)
+	output io.Writer
+}
+
+var (
+	// answers caches entered values by key so multiple references in one run don't re-prompt
+	answers   = make(map[string]string)
+	answersMu sync.Mutex
+
+	// stdinReader is shared so consecutive non-interactive prompts read consecutive lines
+	stdinReader     *bufio.Reader
+	stdinReaderOnce sync.Once
+)
+
+func init() {
+	provider.Register("prompt", func() provider.Provider {
+		return &PromptProvider{}
+	})
+}
+
+// Name returns the provider name
+func (p *PromptProvider) Name() string {
+	return "prompt"
+}
+
+// ConfigFields returns the options accepted by the provider
+func (p *PromptProvider) ConfigFields() []string {
+	return provider.StructFields(PromptConfig{})
+}
+
+// ReadsInput reports that the provider reads values from the user
+func (p *PromptProvider) ReadsInput() bool {
+	return true
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *PromptProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the prompt configuration
+func validateConfig(config map[string]interface{}) (*PromptConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt configuration: %w", err)
+	}
+	if cfg.Key == "" {
+		return nil, fmt.Errorf("prompt provider requires 'key' field")
+	}
+
+	return cfg, nil
+}
+
+// parseConfig converts a map[string]interface{} to PromptConfig
+func parseConfig(config map[string]interface{}) (*PromptConfig, error) {
+	// Use JSON marshaling/unmarshaling for clean conversion
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cfg PromptConfig
+	if err := json.Unmarshal(jsonData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Fetch asks the user for the configured key, reusing an earlier answer for the same key
+func (p *PromptProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	answersMu.Lock()
+	defer answersMu.Unlock()
+
+	value, ok := answers[cfg.Key]
+	if !ok {
+		message := cfg.Message
+		if message == "" {
+			message = fmt.Sprintf("Enter %s: ", cfg.Key)
+		}
+		value, err = p.ask(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read value for '%s': %w", cfg.Key, err)
+		}
+		answers[cfg.Key] = value
+	}
+
+	// If no keys specified, return the prompted key
+	if len(keys) == 0 {
+		return []provider.KeyValue{{Key: cfg.Key, Value: value}}, nil
+	}
+
+	// Map keys according to configuration
+	kvs := make([]provider.KeyValue, 0)
+	if targetKey, exists := keys[cfg.Key]; exists {
+		if targetKey == "==" {
+			targetKey = cfg.Key // Keep same name
+		}
+		kvs = append(kvs, provider.KeyValue{
+			Key:   targetKey,
+			Value: value,
+		})
+	}
+
+	return kvs, nil
+}
+
+// ask shows the message and reads a single value
+func (p *PromptProvider) ask(message string) (string, error) {
+	output := p.output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	if p.reader != nil {
+		fmt.Fprint(output, message)
+		return readLine(bufio.NewReader(p.reader))
+	}
+
+	// Secure entry on a terminal: the value is not echoed
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(output, message)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(output)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+
+	stdinReaderOnce.Do(func() {
+		stdinReader = bufio.NewReader(os.Stdin)
+	})
+	return readLine(stdinReader)
+}
+
+// readLine reads one line without its trailing newline
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", fmt.Errorf("no input available on stdin")
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
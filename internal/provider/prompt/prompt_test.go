@@ -0,0 +1,82 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// resetAnswers clears the per-run answer cache between tests
+func resetAnswers(t *testing.T) {
+	t.Helper()
+	answersMu.Lock()
+	answers = make(map[string]string)
+	answersMu.Unlock()
+}
+
+func TestPromptProvider_Name(t *testing.T) {
+	provider := &PromptProvider{}
+	if got := provider.Name(); got != "prompt" {
+		t.Errorf("PromptProvider.Name() = %v, want %v", got, "prompt")
+	}
+}
+
+func TestPromptProvider_Fetch(t *testing.T) {
+	resetAnswers(t)
+
+	var output bytes.Buffer
+	provider := &PromptProvider{
+		reader: strings.NewReader("123456\n"),
+		output: &output,
+	}
+	config := map[string]interface{}{
+		"key":     "DEPLOY_CODE",
+		"message": "One-time code: ",
+	}
+
+	kvs, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "prompt", config, nil)
+	if err != nil {
+		t.Fatalf("PromptProvider.Fetch() unexpected error: %v", err)
+	}
+	if len(kvs) != 1 || kvs[0].Key != "DEPLOY_CODE" || kvs[0].Value != "123456" {
+		t.Errorf("PromptProvider.Fetch() = %v, want [{DEPLOY_CODE 123456}]", kvs)
+	}
+	if output.String() != "One-time code: " {
+		t.Errorf("prompt message = %q, want %q", output.String(), "One-time code: ")
+	}
+
+	// A second reference reuses the answer without prompting again
+	second := &PromptProvider{
+		reader: strings.NewReader(""),
+		output: &output,
+	}
+	output.Reset()
+	kvs, err = second.Fetch(secrets.NewEmptySecretContext(context.Background()), "prompt-2", config, map[string]string{"DEPLOY_CODE": "CODE"})
+	if err != nil {
+		t.Fatalf("PromptProvider.Fetch() unexpected error on cached value: %v", err)
+	}
+	if len(kvs) != 1 || kvs[0].Key != "CODE" || kvs[0].Value != "123456" {
+		t.Errorf("PromptProvider.Fetch() = %v, want [{CODE 123456}]", kvs)
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no prompt for cached value, got %q", output.String())
+	}
+}
+
+func TestPromptProvider_Errors(t *testing.T) {
+	resetAnswers(t)
+
+	provider := &PromptProvider{reader: strings.NewReader(""), output: &bytes.Buffer{}}
+
+	if _, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "prompt", map[string]interface{}{}, nil); err == nil {
+		t.Error("PromptProvider.Fetch() expected error for missing key, got none")
+	}
+
+	_, err := provider.Fetch(secrets.NewEmptySecretContext(context.Background()), "prompt", map[string]interface{}{"key": "CODE"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "no input") {
+		t.Errorf("PromptProvider.Fetch() error = %v, want error for empty input", err)
+	}
+}
@@ -1,7 +1,10 @@
 package provider
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"text/template"
 )
 
 // TestProviderInterface ensures the provider interface compiles correctly
@@ -12,3 +15,166 @@ func TestProviderInterface(t *testing.T) {
 	}
 }
 
+
+// selfTestProvider is a provider for testing Validate
+type selfTestProvider struct {
+	name  string
+	panic bool
+}
+
+func (p *selfTestProvider) Name() string {
+	return p.name
+}
+
+func (p *selfTestProvider) Fetch(SecretContext, string, map[string]interface{}, map[string]string) ([]KeyValue, error) {
+	return nil, nil
+}
+
+func (p *selfTestProvider) ValidateConfig(config map[string]interface{}) error {
+	if p.panic {
+		var values map[string]string
+		values["boom"] = "nil map"
+	}
+	if config["path"] == nil {
+		return fmt.Errorf("requires 'path'")
+	}
+	return nil
+}
+
+// fetchOnlyProvider is a provider without configuration validation
+type fetchOnlyProvider struct{}
+
+func (p *fetchOnlyProvider) Name() string {
+	return "test_fetch_only"
+}
+
+func (p *fetchOnlyProvider) Fetch(SecretContext, string, map[string]interface{}, map[string]string) ([]KeyValue, error) {
+	return nil, nil
+}
+
+func TestValidate(t *testing.T) {
+	Register("test_valid", func() Provider { return &selfTestProvider{name: "test_valid"} })
+	Register("test_panic", func() Provider { return &selfTestProvider{name: "test_panic", panic: true} })
+	Register("test_misnamed", func() Provider { return &selfTestProvider{name: "other"} })
+	Register("test_fetch_only", func() Provider { return &fetchOnlyProvider{} })
+	t.Cleanup(func() {
+		for _, kind := range []string{"test_valid", "test_panic", "test_misnamed", "test_fetch_only"} {
+			delete(registry, kind)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		kind    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{name: "valid", kind: "test_valid", config: map[string]interface{}{"path": "x"}},
+		{name: "invalid config", kind: "test_valid", config: map[string]interface{}{}, wantErr: "requires 'path'"},
+		{name: "panic", kind: "test_panic", config: map[string]interface{}{"path": "x"}, wantErr: "provider 'test_panic' panicked"},
+		{name: "misnamed", kind: "test_misnamed", config: map[string]interface{}{}, wantErr: "reports name 'other'"},
+		{name: "no validation", kind: "test_fetch_only", config: map[string]interface{}{}, wantErr: "does not support configuration validation"},
+		{name: "unknown kind", kind: "test_unknown", config: map[string]interface{}{}, wantErr: "unknown provider kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.kind, tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fieldsTestConfig is a provider configuration struct for testing StructFields
+type fieldsTestConfig struct {
+	embeddedTestConfig
+	Path     string `json:"path"`
+	Region   string `json:"region,omitempty" yaml:"region,omitempty"`
+	Internal string `json:"-"`
+	Untagged string
+}
+
+type embeddedTestConfig struct {
+	Account string `json:"account,omitempty"`
+}
+
+func TestStructFields(t *testing.T) {
+	got := strings.Join(StructFields(&fieldsTestConfig{}), ",")
+	if want := "account,path,region"; got != want {
+		t.Errorf("StructFields() = %s, want %s", got, want)
+	}
+
+	Register("test_schema", func() Provider { return &schemaTestProvider{} })
+	Register("test_fetch_only", func() Provider { return &fetchOnlyProvider{} })
+	t.Cleanup(func() {
+		delete(registry, "test_schema")
+		delete(registry, "test_fetch_only")
+	})
+
+	if fields, ok := Fields("test_schema"); !ok || strings.Join(fields, ",") != "account,path,region" {
+		t.Errorf("Fields(test_schema) = %v, %v", fields, ok)
+	}
+	if _, ok := Fields("test_fetch_only"); ok {
+		t.Error("Fields() should report providers without a schema")
+	}
+	if _, ok := Fields("test_unknown"); ok {
+		t.Error("Fields() should report unknown kinds")
+	}
+}
+
+// schemaTestProvider is a provider declaring its options
+type schemaTestProvider struct {
+	fetchOnlyProvider
+}
+
+func (p *schemaTestProvider) ConfigFields() []string {
+	return StructFields(fieldsTestConfig{})
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: `{{ .key | trimPrefix "APP_" }}`, expected: "DB_HOST"},
+		{template: `{{ .key | trimSuffix "_HOST" | lower }}`, expected: "app_db"},
+		{template: `{{ .key | replace "_" "-" }}`, expected: "APP-DB-HOST"},
+		{template: `{{ if hasPrefix "APP_" .key }}MY_{{ .key | trimPrefix "APP_" }}{{ else }}{{ .key }}{{ end }}`, expected: "MY_DB_HOST"},
+		{template: `{{ "  x " | trim | upper }}`, expected: "X"},
+		{template: `{{ .key | b64enc }}`, expected: "QVBQX0RCX0hPU1Q="},
+		{template: `{{ "QVBQX0RCX0hPU1Q=" | b64dec | lower }}`, expected: "app_db_host"},
+		{template: `{{ .missing | default "localhost" }}`, expected: "localhost"},
+		{template: `{{ .empty | default "localhost" }}`, expected: "localhost"},
+		{template: `{{ .key | default "localhost" }}`, expected: "APP_DB_HOST"},
+		{template: `{{ .missing }}`, expected: "<no value>"},
+	}
+	for _, tt := range tests {
+		tmpl, err := template.New("test").Funcs(TemplateFuncs()).Parse(tt.template)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", tt.template, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, map[string]string{"key": "APP_DB_HOST", "empty": ""}); err != nil {
+			t.Fatalf("Execute(%s) error = %v", tt.template, err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s = %q, want %q", tt.template, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestTemplateFuncs_B64DecInvalid(t *testing.T) {
+	tmpl := template.Must(template.New("test").Funcs(TemplateFuncs()).Parse(`{{ "not base64!" | b64dec }}`))
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err == nil || !strings.Contains(err.Error(), "b64dec") {
+		t.Errorf("Execute() error = %v, want a b64dec error", err)
+	}
+}
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/provider"
+	_ "github.com/mutecomm/go-sqlcipher/v4"
+)
+
+const (
+	// DefaultKeyEnv is the environment variable holding the database key when 'key_env' is not set
+	DefaultKeyEnv = "SSTART_SQLITE_KEY"
+	// DefaultKeyColumn is the column read as the secret key when 'key_column' is not set
+	DefaultKeyColumn = "key"
+	// DefaultValueColumn is the column read as the secret value when 'value_column' is not set
+	DefaultValueColumn = "value"
+)
+
+// SQLiteConfig represents the configuration for the SQLCipher-encrypted SQLite provider
+type SQLiteConfig struct {
+	// Path is the path to the encrypted database file (required)
+	Path string `json:"path" yaml:"path"`
+	// KeyEnv is the environment variable holding the database key (optional, defaults to SSTART_SQLITE_KEY)
+	KeyEnv string `json:"key_env,omitempty" yaml:"key_env,omitempty"`
+	// Table is the table to read key-value rows from (either table or query is required)
+	Table string `json:"table,omitempty" yaml:"table,omitempty"`
+	// KeyColumn is the column holding secret keys in Table (optional, defaults to "key")
+	KeyColumn string `json:"key_column,omitempty" yaml:"key_column,omitempty"`
+	// ValueColumn is the column holding secret values in Table (optional, defaults to "value")
+	ValueColumn string `json:"value_column,omitempty" yaml:"value_column,omitempty"`
+	// Query is a SELECT returning key and value as its first two columns (either table or query is required)
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+}
+
+// SQLiteProvider implements the provider interface for SQLCipher-encrypted SQLite databases
+type SQLiteProvider struct{}
+
+func init() {
+	provider.Register("sqlite", func() provider.Provider {
+		return &SQLiteProvider{}
+	})
+}
+
+// Name returns the provider name
+func (p *SQLiteProvider) Name() string {
+	return "sqlite"
+}
+
+// ConfigFields returns the options accepted by the provider
+func (p *SQLiteProvider) ConfigFields() []string {
+	return provider.StructFields(SQLiteConfig{})
+}
+
+// SourceFiles returns the database file read by the provider
+func (p *SQLiteProvider) SourceFiles(config map[string]interface{}) []string {
+	cfg, err := parseConfig(config)
+	if err != nil || cfg.Path == "" {
+		return nil
+	}
+	return []string{os.ExpandEnv(cfg.Path)}
+}
+
+// Fetch reads key-value rows from an encrypted SQLite database
+func (p *SQLiteProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := cfg.selectQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	keyEnv := cfg.KeyEnv
+	if keyEnv == "" {
+		keyEnv = DefaultKeyEnv
+	}
+	dbKey := os.Getenv(keyEnv)
+	if dbKey == "" {
+		return nil, fmt.Errorf("sqlite provider requires the database key in the %s environment variable", keyEnv)
+	}
+
+	path := os.ExpandEnv(cfg.Path)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database at '%s': %w", path, err)
+	}
+
+	db, err := sql.Open("sqlite3", dataSourceName(path, dbKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database at '%s': %w", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(secretContext.Ctx, query)
+	if err != nil {
+		if strings.Contains(err.Error(), "file is not a database") {
+			return nil, fmt.Errorf("failed to read sqlite database at '%s': wrong key or not an encrypted database", path)
+		}
+		return nil, fmt.Errorf("failed to query sqlite database at '%s': %w", path, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query columns: %w", err)
+	}
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("sqlite query must return key and value columns, got %d column(s)", len(columns))
+	}
+
+	kvs := make([]provider.KeyValue, 0)
+	for rows.Next() {
+		var key, value sql.NullString
+		dest := make([]interface{}, len(columns))
+		dest[0], dest[1] = &key, &value
+		for i := 2; i < len(dest); i++ {
+			dest[i] = new(interface{})
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		if !key.Valid || key.String == "" {
+			continue
+		}
+
+		targetKey := key.String
+		if mappedKey, exists := keys[key.String]; exists {
+			if mappedKey != "==" {
+				targetKey = mappedKey
+			}
+		} else if len(keys) > 0 {
+			// Skip keys not in the mapping
+			continue
+		}
+
+		kvs = append(kvs, provider.KeyValue{Key: targetKey, Value: value.String})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return kvs, nil
+}
+
+// selectQuery returns the configured query, or builds one selecting the key and value columns of the table
+func (c *SQLiteConfig) selectQuery() (string, error) {
+	if c.Path == "" {
+		return "", fmt.Errorf("sqlite provider requires 'path' field in configuration")
+	}
+	if c.Query != "" && c.Table != "" {
+		return "", fmt.Errorf("sqlite provider accepts either 'table' or 'query', not both")
+	}
+	if c.Query != "" {
+		return c.Query, nil
+	}
+	if c.Table == "" {
+		return "", fmt.Errorf("sqlite provider requires 'table' or 'query' field in configuration")
+	}
+
+	keyColumn := c.KeyColumn
+	if keyColumn == "" {
+		keyColumn = DefaultKeyColumn
+	}
+	valueColumn := c.ValueColumn
+	if valueColumn == "" {
+		valueColumn = DefaultValueColumn
+	}
+	return fmt.Sprintf("SELECT %s, %s FROM %s", quoteIdentifier(keyColumn), quoteIdentifier(valueColumn), quoteIdentifier(c.Table)), nil
+}
+
+// quoteIdentifier quotes an SQL identifier so table and column names cannot inject SQL
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// dataSourceName builds a read-only SQLCipher connection string for path unlocked with key
+func dataSourceName(path, key string) string {
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Set("_pragma_key", key)
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + params.Encode()
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *SQLiteProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the sqlite configuration
+func validateConfig(config map[string]interface{}) (*SQLiteConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sqlite configuration: %w", err)
+	}
+
+	if _, err := cfg.selectQuery(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseConfig converts a map[string]interface{} to SQLiteConfig
+func parseConfig(config map[string]interface{}) (*SQLiteConfig, error) {
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cfg SQLiteConfig
+	if err := json.Unmarshal(jsonData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &cfg, nil
+}
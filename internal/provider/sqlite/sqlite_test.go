@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+const testDBKey = "test-db-key"
+
+// createEncryptedDB creates an SQLCipher database with a 'secrets' table holding rows
+func createEncryptedDB(t *testing.T, rows map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secrets.db")
+	db, err := sql.Open("sqlite3", path+"?_pragma_key="+url.QueryEscape(testDBKey))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE secrets (key TEXT PRIMARY KEY, value TEXT, env TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for key, value := range rows {
+		if _, err := db.Exec(`INSERT INTO secrets (key, value, env) VALUES (?, ?, 'prod')`, key, value); err != nil {
+			t.Fatalf("failed to insert row: %v", err)
+		}
+	}
+	return path
+}
+
+func fetch(t *testing.T, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	t.Helper()
+	p := &SQLiteProvider{}
+	return p.Fetch(provider.SecretContext{Ctx: context.Background()}, "sqlite", config, keys)
+}
+
+func toMap(kvs []provider.KeyValue) map[string]string {
+	result := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+func TestSQLiteProvider_Fetch(t *testing.T) {
+	t.Setenv(DefaultKeyEnv, testDBKey)
+	path := createEncryptedDB(t, map[string]string{
+		"API_KEY":     "sqlite-api-key",
+		"DB_PASSWORD": "sqlite-db-password",
+	})
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		keys   map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "table",
+			config: map[string]interface{}{"path": path, "table": "secrets"},
+			want:   map[string]string{"API_KEY": "sqlite-api-key", "DB_PASSWORD": "sqlite-db-password"},
+		},
+		{
+			name:   "table with keys",
+			config: map[string]interface{}{"path": path, "table": "secrets"},
+			keys:   map[string]string{"API_KEY": "MY_API_KEY", "DB_PASSWORD": "=="},
+			want:   map[string]string{"MY_API_KEY": "sqlite-api-key", "DB_PASSWORD": "sqlite-db-password"},
+		},
+		{
+			name: "query",
+			config: map[string]interface{}{
+				"path":  path,
+				"query": "SELECT key, value FROM secrets WHERE env = 'prod' AND key LIKE 'API_%'",
+			},
+			want: map[string]string{"API_KEY": "sqlite-api-key"},
+		},
+		{
+			name:   "custom columns",
+			config: map[string]interface{}{"path": path, "table": "secrets", "key_column": "value", "value_column": "key"},
+			keys:   map[string]string{"sqlite-api-key": "REVERSED"},
+			want:   map[string]string{"REVERSED": "API_KEY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvs, err := fetch(t, tt.config, tt.keys)
+			if err != nil {
+				t.Fatalf("Fetch() error: %v", err)
+			}
+			got := toMap(kvs)
+			if len(got) != len(tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("Fetch()[%s] = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteProvider_Fetch_KeyEnv(t *testing.T) {
+	path := createEncryptedDB(t, map[string]string{"API_KEY": "sqlite-api-key"})
+
+	t.Run("custom key_env", func(t *testing.T) {
+		t.Setenv("MY_DB_KEY", testDBKey)
+		kvs, err := fetch(t, map[string]interface{}{"path": path, "table": "secrets", "key_env": "MY_DB_KEY"}, nil)
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if toMap(kvs)["API_KEY"] != "sqlite-api-key" {
+			t.Errorf("Fetch() = %v", kvs)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		t.Setenv(DefaultKeyEnv, "")
+		_, err := fetch(t, map[string]interface{}{"path": path, "table": "secrets"}, nil)
+		if err == nil || !strings.Contains(err.Error(), DefaultKeyEnv) {
+			t.Errorf("expected missing key error, got %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Setenv(DefaultKeyEnv, "wrong-key")
+		_, err := fetch(t, map[string]interface{}{"path": path, "table": "secrets"}, nil)
+		if err == nil || !strings.Contains(err.Error(), "wrong key") {
+			t.Errorf("expected wrong key error, got %v", err)
+		}
+	})
+}
+
+func TestSQLiteProvider_Fetch_ConfigValidation(t *testing.T) {
+	t.Setenv(DefaultKeyEnv, testDBKey)
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{name: "missing path", config: map[string]interface{}{"table": "secrets"}, wantErr: "'path'"},
+		{name: "missing table and query", config: map[string]interface{}{"path": "x.db"}, wantErr: "'table' or 'query'"},
+		{name: "table and query", config: map[string]interface{}{"path": "x.db", "table": "t", "query": "SELECT 1, 2"}, wantErr: "not both"},
+		{name: "missing file", config: map[string]interface{}{"path": filepath.Join(t.TempDir(), "missing.db"), "table": "t"}, wantErr: "failed to open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetch(t, tt.config, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if got := quoteIdentifier(`secrets"; DROP TABLE x; --`); got != `"secrets""; DROP TABLE x; --"` {
+		t.Errorf("quoteIdentifier() = %s", got)
+	}
+}
@@ -0,0 +1,171 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// DefaultFileMode is the permission of rendered files when 'mode' is not set
+const DefaultFileMode os.FileMode = 0600
+
+// FileConfig is a template rendered into a file. It is written in YAML either as the template
+// itself or as a mapping with 'template' or 'template_file' and an optional 'mode'.
+type FileConfig struct {
+	Template     string      `json:"template,omitempty" yaml:"template,omitempty"`           // Inline template
+	TemplateFile string      `json:"template_file,omitempty" yaml:"template_file,omitempty"` // Path of a file holding the template
+	Mode         interface{} `json:"mode,omitempty" yaml:"mode,omitempty"`                   // Permissions, e.g. 0640 or "0640" (default: 0600)
+}
+
+// UnmarshalJSON accepts an inline template as a plain string
+func (f *FileConfig) UnmarshalJSON(data []byte) error {
+	var template string
+	if err := json.Unmarshal(data, &template); err == nil {
+		*f = FileConfig{Template: template}
+		return nil
+	}
+	type rawFileConfig FileConfig
+	var raw rawFileConfig
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = FileConfig(raw)
+	return nil
+}
+
+// validate checks the file configuration of an output path
+func (f FileConfig) validate(path string) error {
+	if path == "" {
+		return fmt.Errorf("'files' requires non-empty output paths")
+	}
+	if (f.Template == "") == (f.TemplateFile == "") {
+		return fmt.Errorf("file '%s' requires exactly one of 'template' and 'template_file'", path)
+	}
+	if _, err := f.mode(); err != nil {
+		return fmt.Errorf("file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// mode returns the permissions of the file
+func (f FileConfig) mode() (os.FileMode, error) {
+	switch mode := f.Mode.(type) {
+	case nil:
+		return DefaultFileMode, nil
+	case float64:
+		// YAML reads 0640 as an octal number
+		if mode < 0 || mode > 0777 || mode != math.Trunc(mode) {
+			return 0, fmt.Errorf("invalid mode %v: must be permissions such as 0640", mode)
+		}
+		return os.FileMode(mode), nil
+	case string:
+		parsed, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || parsed > 0777 {
+			return 0, fmt.Errorf("invalid mode '%s': must be octal permissions such as \"0640\"", mode)
+		}
+		return os.FileMode(parsed), nil
+	default:
+		return 0, fmt.Errorf("invalid mode %v: must be permissions such as 0640", mode)
+	}
+}
+
+// renderedFile is the content of a file to write
+type renderedFile struct {
+	path    string
+	content string
+	mode    os.FileMode
+}
+
+// renderFiles renders the templates of 'files' with the same data as 'templates', in path order
+func (p *TemplateProvider) renderFiles(cfg *TemplateConfig, data map[string]map[string]string) ([]renderedFile, error) {
+	paths := make([]string, 0, len(cfg.Files))
+	for path := range cfg.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	rendered := make([]renderedFile, 0, len(paths))
+	for _, path := range paths {
+		file := cfg.Files[path]
+		templateStr := file.Template
+		if file.TemplateFile != "" {
+			data, err := os.ReadFile(os.ExpandEnv(file.TemplateFile))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template file for file '%s': %w", path, err)
+			}
+			templateStr = string(data)
+		}
+		content, err := p.resolveTemplate(templateStr, cfg, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render file '%s': %w", path, err)
+		}
+		mode, _ := file.mode()
+		rendered = append(rendered, renderedFile{path: os.ExpandEnv(path), content: content, mode: mode})
+	}
+	return rendered, nil
+}
+
+// writeFiles writes the rendered files, each atomically
+func writeFiles(files []renderedFile) error {
+	for _, file := range files {
+		if err := writeFileAtomic(file.path, []byte(file.content), file.mode); err != nil {
+			return fmt.Errorf("failed to write file '%s': %w", file.path, err)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path,
+// so readers see either the previous or the complete new content, never a partial file
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Removing fails harmlessly once the file is renamed
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SourceFiles returns the template files read by the provider, so watch mode renders again when they change
+func (p *TemplateProvider) SourceFiles(config map[string]interface{}) []string {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, file := range cfg.Files {
+		if file.TemplateFile != "" {
+			paths = append(paths, os.ExpandEnv(file.TemplateFile))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WritesFiles reports whether the configuration renders files. The collector never serves such
+// a provider from the cache, since the files are written when the templates are rendered.
+func (p *TemplateProvider) WritesFiles(config map[string]interface{}) bool {
+	files, ok := config["files"].(map[string]interface{})
+	return ok && len(files) > 0
+}
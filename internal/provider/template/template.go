@@ -3,7 +3,11 @@ package template
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/dirathea/sstart/internal/provider"
@@ -12,9 +16,25 @@ import (
 // TemplateConfig represents the configuration for template provider
 type TemplateConfig struct {
 	// Templates is a map of template expressions using dot notation: PG_URI: pgsql://{{.aws_prod.PG_USERNAME}}:{{.aws_prod.PG_PASSWORD}}@{{.aws_generic.PG_HOST}}
-	Templates map[string]string `yaml:"templates"`
+	Templates map[string]string `json:"templates" yaml:"templates"`
+	// MissingKey controls references to missing providers or keys: "zero" (default) renders them
+	// as <no value>, "error" fails the template
+	MissingKey string `json:"missing_key,omitempty" yaml:"missing_key,omitempty"`
+	// Files maps output paths to templates rendered into them, e.g. an nginx configuration
+	Files map[string]FileConfig `json:"files,omitempty" yaml:"files,omitempty"`
+	// UseEnv exposes the environment of sstart to the templates as .env, e.g. {{.env.ENVIRONMENT}}
+	UseEnv bool `json:"use_env,omitempty" yaml:"use_env,omitempty"`
 }
 
+// EnvKey is the reserved top-level key holding the environment in the template data when use_env is set
+const EnvKey = "env"
+
+// Values of TemplateConfig.MissingKey
+const (
+	MissingKeyZero  = "zero"
+	MissingKeyError = "error"
+)
+
 // TemplateProvider implements the provider interface for template-based secret manipulation
 type TemplateProvider struct{}
 
@@ -29,6 +49,49 @@ func (p *TemplateProvider) Name() string {
 	return "template"
 }
 
+// ConfigFields returns the options accepted by the provider
+func (p *TemplateProvider) ConfigFields() []string {
+	return provider.StructFields(TemplateConfig{})
+}
+
+// NamesOwnKeys reports that the output keys are the names in 'templates', so 'keys' is applied
+// to them by the collector
+func (p *TemplateProvider) NamesOwnKeys() bool {
+	return true
+}
+
+// ValidateConfig checks the configuration without fetching secrets
+func (p *TemplateProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the template configuration
+func validateConfig(config map[string]interface{}) (*TemplateConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template configuration: %w", err)
+	}
+
+	// Get templates from config
+	if len(cfg.Templates) == 0 && len(cfg.Files) == 0 {
+		return nil, fmt.Errorf("template provider requires 'templates' field with template expressions or 'files'")
+	}
+	for path, file := range cfg.Files {
+		if err := file.validate(path); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.MissingKey {
+	case "", MissingKeyZero, MissingKeyError:
+	default:
+		return nil, fmt.Errorf("invalid missing_key '%s': must be %s or %s", cfg.MissingKey, MissingKeyZero, MissingKeyError)
+	}
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to TemplateConfig
 func parseConfig(config map[string]interface{}) (*TemplateConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -50,20 +113,21 @@ func parseConfig(config map[string]interface{}) (*TemplateConfig, error) {
 func (p *TemplateProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	// Get SecretsResolver from secretContext
 	resolver := secretContext.SecretsResolver
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid template configuration: %w", err)
+		return nil, err
 	}
 
-	// Get templates from config
-	if len(cfg.Templates) == 0 {
-		return nil, fmt.Errorf("template provider requires 'templates' field with template expressions")
+	data, err := templateData(cfg, resolver)
+	if err != nil {
+		return nil, err
 	}
 
 	// Resolve each template expression
 	kvs := make([]provider.KeyValue, 0, len(cfg.Templates))
 	for targetKey, templateExpr := range cfg.Templates {
-		resolvedValue, err := p.resolveTemplate(templateExpr, resolver)
+		resolvedValue, err := p.resolveTemplate(templateExpr, cfg, data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve template for key '%s': %w", targetKey, err)
 		}
@@ -73,28 +137,104 @@ func (p *TemplateProvider) Fetch(secretContext provider.SecretContext, mapID str
 		})
 	}
 
+	// Render every file before writing any, so a failing template leaves all files untouched
+	if len(cfg.Files) > 0 {
+		rendered, err := p.renderFiles(cfg, data)
+		if err != nil {
+			return nil, err
+		}
+		if secretContext.SkipFileWrites {
+			return kvs, nil
+		}
+		if err := writeFiles(rendered); err != nil {
+			return nil, err
+		}
+	}
+
 	return kvs, nil
 }
 
+// templateData builds the template data from the resolver, adding the environment under EnvKey
+// when use_env is set.
+// Structure: { "provider_id": { "secret_key": "value", ... }, ... }
+func templateData(cfg *TemplateConfig, resolver provider.SecretsResolver) (map[string]map[string]string, error) {
+	data := resolver.Map()
+	if !cfg.UseEnv {
+		return data, nil
+	}
+	if _, exists := data[EnvKey]; exists {
+		return nil, fmt.Errorf("provider '%s' in 'uses' conflicts with the environment exposed by use_env as .%s", EnvKey, EnvKey)
+	}
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			env[name] = value
+		}
+	}
+	data[EnvKey] = env
+	return data, nil
+}
+
 // resolveTemplate resolves a template expression using Go's text/template package
 // Template syntax: {{.provider_id.secret_key}} (dot notation, similar to Helm templates)
 // Example: {{.aws_prod.PG_USERNAME}} or {{.aws_generic.PG_HOST}}
-func (p *TemplateProvider) resolveTemplate(templateStr string, resolver provider.SecretsResolver) (string, error) {
-	// Build template data structure from resolver
-	// Structure: { "provider_id": { "secret_key": "value", ... }, ... }
-	providerSecrets := resolver.Map()
-
+// With missing_key "error", a reference to a missing provider or key fails instead of rendering <no value>.
+func (p *TemplateProvider) resolveTemplate(templateStr string, cfg *TemplateConfig, data map[string]map[string]string) (string, error) {
 	// Parse the template
-	tmpl, err := template.New("secret_template").Parse(templateStr)
+	tmpl := template.New("secret_template").Funcs(provider.TemplateFuncs())
+	if cfg.MissingKey == MissingKeyError {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	tmpl, err := tmpl.Parse(templateStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	// Execute the template with the data structure
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, providerSecrets); err != nil {
+	if err := tmpl.Execute(&buf, data); err != nil {
+		if missingErr := missingKeyError(err, cfg.UseEnv); missingErr != nil {
+			return "", missingErr
+		}
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
 	return buf.String(), nil
 }
+
+var (
+	// missingKeyPattern matches the error of text/template for a missing map key
+	missingKeyPattern = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+	// referencePattern matches the reference that failed in the message of a template.ExecError
+	referencePattern = regexp.MustCompile(`at <\.([^>]*)>`)
+)
+
+// missingKeyError returns an error naming the missing provider or key when err is a missing key
+// error of text/template, or nil otherwise. With useEnv, .env references a missing environment variable.
+func missingKeyError(err error, useEnv bool) error {
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		return nil
+	}
+	missing := missingKeyPattern.FindStringSubmatch(execErr.Err.Error())
+	if missing == nil {
+		return nil
+	}
+	key := missing[1]
+
+	// The reference is a field chain such as .provider_id.KEY
+	var chain []string
+	if reference := referencePattern.FindStringSubmatch(execErr.Error()); reference != nil {
+		chain = strings.Split(reference[1], ".")
+	}
+	switch {
+	case useEnv && len(chain) >= 2 && chain[0] == EnvKey && chain[1] == key:
+		return fmt.Errorf("missing environment variable '%s' (missing_key: error)", key)
+	case len(chain) >= 1 && chain[0] == key:
+		return fmt.Errorf("missing provider '%s': not listed in 'uses' or returned no secrets (missing_key: error)", key)
+	case len(chain) >= 2 && chain[1] == key:
+		return fmt.Errorf("missing key '%s' of provider '%s' (missing_key: error)", key, chain[0])
+	default:
+		return fmt.Errorf("missing key '%s' (missing_key: error)", key)
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
 
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/provider"
 	"github.com/hashicorp/vault/api"
 )
@@ -21,6 +25,19 @@ const (
 
 	// DefaultJWTAuthMount is the default mount path for JWT auth
 	DefaultJWTAuthMount = "jwt"
+
+	// EngineKV reads static secrets from a KV v1 or v2 engine (default)
+	EngineKV = "kv"
+	// EngineDatabase reads dynamic credentials from a database secrets engine
+	EngineDatabase = "database"
+
+	// DefaultDatabaseMount is the default mount path for the database secrets engine
+	DefaultDatabaseMount = "database"
+
+	// KVVersion1 reads secrets from mount/path without the KV v2 envelope
+	KVVersion1 = 1
+	// KVVersion2 reads secrets from mount/data/path (default)
+	KVVersion2 = 2
 )
 
 // VaultAuthConfig represents authentication configuration for Vault
@@ -39,10 +56,22 @@ type VaultAuthConfig struct {
 type VaultConfig struct {
 	// Address is the Vault server address (optional, defaults to VAULT_ADDR env var)
 	Address string `json:"address,omitempty" yaml:"address,omitempty"`
-	// Path is the path to the secret in Vault (required)
+	// Path is the path to the secret in Vault (required for the kv engine unless paths or path_template is set)
 	Path string `json:"path" yaml:"path"`
-	// Mount is the secret engine mount path (optional, defaults to "secret")
+	// Mount is the secret engine mount path (optional, defaults to "secret", or "database" for the database engine)
 	Mount string `json:"mount,omitempty" yaml:"mount,omitempty"`
+	// KVVersion is the version of the KV engine: 1 or 2 (optional, defaults to 2)
+	KVVersion int `json:"kv_version,omitempty" yaml:"kv_version,omitempty"`
+	// Paths lists several paths read and merged into one set of keys (used instead of Path)
+	Paths []VaultPath `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// PathTemplate is a Go template for the path, rendered once per for_each value with the value as "."
+	PathTemplate string `json:"path_template,omitempty" yaml:"path_template,omitempty"`
+	// ForEach lists the values PathTemplate is rendered with; keys are namespaced by each value
+	ForEach []string `json:"for_each,omitempty" yaml:"for_each,omitempty"`
+	// Engine selects the secrets engine: "kv" (default) or "database"
+	Engine string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	// Role is the database role to generate credentials for (required for the database engine)
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
 	// Auth contains authentication configuration
 	Auth *VaultAuthConfig `json:"auth,omitempty" yaml:"auth,omitempty"`
 
@@ -51,6 +80,31 @@ type VaultConfig struct {
 	SSOIDToken     string `json:"-" yaml:"-"`
 }
 
+// VaultPath is one entry of 'paths', written either as a path or as a mapping with 'path' and 'prefix'
+type VaultPath struct {
+	// Path is the path to the secret, relative to the mount (required)
+	Path string `json:"path" yaml:"path"`
+	// Prefix is prepended to every key read from the path (optional)
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+}
+
+// UnmarshalJSON accepts a plain path string or an object with 'path' and 'prefix'
+func (v *VaultPath) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		v.Path = path
+		return nil
+	}
+
+	type plain VaultPath
+	var entry plain
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("each entry of 'paths' must be a path or a mapping with 'path' and 'prefix': %w", err)
+	}
+	*v = VaultPath(entry)
+	return nil
+}
+
 // VaultProvider implements the provider interface for HashiCorp Vault
 type VaultProvider struct {
 	client *api.Client
@@ -67,68 +121,163 @@ func (p *VaultProvider) Name() string {
 	return "vault"
 }
 
+// ConfigFields returns the options accepted by the provider, including the top-level 'token'
+// shorthand for auth.token
+func (p *VaultProvider) ConfigFields() []string {
+	return append(provider.StructFields(VaultConfig{}), "token")
+}
+
 // Fetch fetches secrets from HashiCorp Vault
 func (p *VaultProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	ctx := secretContext.Ctx
-	// Convert map to strongly typed config struct
-	cfg, err := parseConfig(config)
+	// Parse and validate configuration
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid vault configuration: %w", err)
-	}
-
-	// Validate required fields
-	if cfg.Path == "" {
-		return nil, fmt.Errorf("vault provider requires 'path' field in configuration")
+		return nil, err
 	}
+	engine := cfg.Engine
 
 	if err := p.ensureClient(ctx, cfg); err != nil {
 		return nil, fmt.Errorf("failed to initialize Vault client: %w", err)
 	}
 
-	// Determine mount path (default to "secret")
-	mount := cfg.Mount
-	if mount == "" {
-		mount = "secret"
+	if engine == EngineDatabase {
+		secretData, err := p.readDatabaseCreds(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return mapSecretData(secretData, keys)
 	}
 
-	// Clean the path
-	cleanPath := strings.TrimPrefix(cfg.Path, "/")
+	if cfg.PathTemplate != "" {
+		return p.fetchTemplated(ctx, cfg, keys)
+	}
+	if len(cfg.Paths) > 0 {
+		return p.fetchPaths(ctx, cfg, keys)
+	}
 
-	// Try KV v2 format first (mount/data/path)
-	secretPath := fmt.Sprintf("%s/data/%s", mount, cleanPath)
-	secret, err := p.client.Logical().ReadWithContext(ctx, secretPath)
+	secretData, err := p.readKV(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mapSecretData(secretData, keys)
+}
 
-	// If KV v2 path not found (nil secret with no error), try KV v1 format (mount/path)
-	if secret == nil && err == nil {
-		secretPath = fmt.Sprintf("%s/%s", mount, cleanPath)
-		secret, err = p.client.Logical().ReadWithContext(ctx, secretPath)
+// Warm creates and authenticates the Vault client, so the first fetch needs no login
+func (p *VaultProvider) Warm(secretContext provider.SecretContext, config map[string]interface{}) error {
+	cfg, err := validateConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureClient(secretContext.Ctx, cfg); err != nil {
+		return fmt.Errorf("failed to initialize Vault client: %w", err)
 	}
+	return nil
+}
 
+// Probe reads the health of the Vault server from sys/health, which requires no authentication
+func (p *VaultProvider) Probe(secretContext provider.SecretContext, config map[string]interface{}) (string, error) {
+	cfg, err := validateConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read secret from Vault at path '%s': %w", secretPath, err)
+		return "", err
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		return "", err
 	}
 
-	if secret == nil {
-		return nil, fmt.Errorf("secret not found at path '%s' (tried both KV v1 and v2 formats)", cfg.Path)
+	health, err := client.Sys().HealthWithContext(secretContext.Ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the health of Vault at '%s': %w", client.Address(), err)
+	}
+	if !health.Initialized {
+		return "", fmt.Errorf("vault at '%s' is not initialized", client.Address())
+	}
+	if health.Sealed {
+		return "", fmt.Errorf("vault at '%s' is sealed", client.Address())
+	}
+	state := "active"
+	if health.Standby {
+		state = "standby"
 	}
+	return fmt.Sprintf("%s, %s, version %s", client.Address(), state, health.Version), nil
+}
 
-	// Extract data from the secret (KV v2 format stores data under "data" key)
-	var secretData map[string]interface{}
-	if data, exists := secret.Data["data"]; exists {
-		// KV v2 format - data is nested under "data" key
-		if dataMap, ok := data.(map[string]interface{}); ok {
-			secretData = dataMap
+// fetchTemplated reads the path rendered from path_template for every for_each value.
+// Keys are mapped per path and prefixed with the value as an env-style namespace,
+// e.g. 'API_KEY' read for 'app-1' becomes 'APP_1_API_KEY'.
+func (p *VaultProvider) fetchTemplated(ctx context.Context, cfg *VaultConfig, keys map[string]string) ([]provider.KeyValue, error) {
+	tmpl, err := template.New("path_template").Option("missingkey=error").Parse(cfg.PathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path_template: %w", err)
+	}
+
+	var kvs []provider.KeyValue
+	for _, value := range cfg.ForEach {
+		var path strings.Builder
+		if err := tmpl.Execute(&path, value); err != nil {
+			return nil, fmt.Errorf("failed to render path_template for '%s': %w", value, err)
+		}
+
+		pathCfg := *cfg
+		pathCfg.Path = path.String()
+		secretData, err := p.readKV(ctx, &pathCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		mapped, err := mapSecretData(secretData, keys)
+		if err != nil {
+			return nil, err
+		}
+		namespace := namespaceFor(value)
+		for _, kv := range mapped {
+			kvs = append(kvs, provider.KeyValue{Key: namespace + "_" + kv.Key, Value: kv.Value})
 		}
-	} else {
-		// KV v1 format or direct data - data is at the root
-		secretData = secret.Data
 	}
 
-	if secretData == nil {
-		return nil, fmt.Errorf("no data found in secret at path '%s'", secretPath)
+	return kvs, nil
+}
+
+// fetchPaths reads every entry of 'paths' and merges their data before mapping it with keys.
+// A key read from two paths is an error unless prefixes keep the merged keys apart.
+func (p *VaultProvider) fetchPaths(ctx context.Context, cfg *VaultConfig, keys map[string]string) ([]provider.KeyValue, error) {
+	merged := make(map[string]interface{})
+	sources := make(map[string]string)
+	for _, entry := range cfg.Paths {
+		pathCfg := *cfg
+		pathCfg.Path = entry.Path
+		secretData, err := p.readKV(ctx, &pathCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range secretData {
+			key := entry.Prefix + k
+			if source, exists := sources[key]; exists {
+				return nil, fmt.Errorf("key '%s' is read from both vault paths '%s' and '%s'; set a 'prefix' on one of them", key, source, entry.Path)
+			}
+			sources[key] = entry.Path
+			merged[key] = v
+		}
 	}
 
-	// Map keys according to configuration
+	return mapSecretData(merged, keys)
+}
+
+// namespaceFor converts a for_each value to an env-style key prefix:
+// letters are uppercased and every other character except digits becomes '_'
+func namespaceFor(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, value)
+}
+
+// mapSecretData maps the data of a Vault secret to key-value pairs according to keys
+func mapSecretData(secretData map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
 	kvs := make([]provider.KeyValue, 0)
 	for k, v := range secretData {
 		targetKey := k
@@ -173,31 +322,108 @@ func (p *VaultProvider) Fetch(secretContext provider.SecretContext, mapID string
 	return kvs, nil
 }
 
-func (p *VaultProvider) ensureClient(ctx context.Context, cfg *VaultConfig) error {
-	if p.client != nil {
-		return nil
+// readKV reads a static secret from a KV v2 engine, falling back to KV v1,
+// or only from a KV v1 engine when kv_version is 1
+func (p *VaultProvider) readKV(ctx context.Context, cfg *VaultConfig) (map[string]interface{}, error) {
+	// Determine mount path (default to "secret")
+	mount := cfg.Mount
+	if mount == "" {
+		mount = "secret"
 	}
 
-	// Create default config
-	apiCfg := api.DefaultConfig()
+	// Clean the path
+	cleanPath := strings.TrimPrefix(cfg.Path, "/")
 
-	// Read environment variables first
-	if err := apiCfg.ReadEnvironment(); err != nil {
-		return fmt.Errorf("failed to read environment: %w", err)
+	if cfg.KVVersion == KVVersion1 {
+		return p.readKVv1(ctx, fmt.Sprintf("%s/%s", mount, cleanPath))
 	}
 
-	// Override address if provided
-	if cfg.Address != "" {
-		apiCfg.Address = cfg.Address
-	} else if apiCfg.Address == "" {
-		// If VAULT_ADDR is not set, use default
-		apiCfg.Address = "http://127.0.0.1:8200"
+	// Try KV v2 format first (mount/data/path)
+	secretPath := fmt.Sprintf("%s/data/%s", mount, cleanPath)
+	secret, err := p.client.Logical().ReadWithContext(ctx, secretPath)
+
+	// If KV v2 path not found (nil secret with no error), try KV v1 format (mount/path)
+	if secret == nil && err == nil {
+		secretPath = fmt.Sprintf("%s/%s", mount, cleanPath)
+		secret, err = p.client.Logical().ReadWithContext(ctx, secretPath)
 	}
 
-	// Create client
-	client, err := api.NewClient(apiCfg)
 	if err != nil {
-		return fmt.Errorf("failed to create Vault client: %w", err)
+		return nil, fmt.Errorf("failed to read secret from Vault at path '%s': %w", secretPath, err)
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("secret not found at path '%s' (tried both KV v1 and v2 formats)", cfg.Path)
+	}
+
+	// Extract data from the secret (KV v2 format stores data under "data" key)
+	var secretData map[string]interface{}
+	if data, exists := secret.Data["data"]; exists {
+		// KV v2 format - data is nested under "data" key
+		if dataMap, ok := data.(map[string]interface{}); ok {
+			secretData = dataMap
+		}
+	} else {
+		// KV v1 format or direct data - data is at the root
+		secretData = secret.Data
+	}
+
+	if secretData == nil {
+		return nil, fmt.Errorf("no data found in secret at path '%s'", secretPath)
+	}
+
+	return secretData, nil
+}
+
+// readKVv1 reads a static secret from a KV v1 engine, where the data is stored at the root
+func (p *VaultProvider) readKVv1(ctx context.Context, secretPath string) (map[string]interface{}, error) {
+	secret, err := p.client.Logical().ReadWithContext(ctx, secretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret from Vault at path '%s': %w", secretPath, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("secret not found at path '%s' (KV v1)", secretPath)
+	}
+	if len(secret.Data) == 0 {
+		return nil, fmt.Errorf("no data found in secret at path '%s'", secretPath)
+	}
+	return secret.Data, nil
+}
+
+// readDatabaseCreds generates dynamic credentials from <mount>/creds/<role>.
+// The returned data contains 'username' and 'password'. The credentials are leased
+// and are revoked by Vault when the lease expires.
+func (p *VaultProvider) readDatabaseCreds(ctx context.Context, cfg *VaultConfig) (map[string]interface{}, error) {
+	mount := strings.Trim(cfg.Mount, "/")
+	if mount == "" {
+		mount = DefaultDatabaseMount
+	}
+
+	credsPath := fmt.Sprintf("%s/creds/%s", mount, cfg.Role)
+	secret, err := p.client.Logical().ReadWithContext(ctx, credsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database credentials from Vault at path '%s': %w", credsPath, err)
+	}
+	if secret == nil || len(secret.Data) == 0 {
+		return nil, fmt.Errorf("no database credentials returned at path '%s'", credsPath)
+	}
+
+	if secret.LeaseDuration > 0 {
+		logger.Infof("Vault database credentials for role '%s' are leased for %s (lease %s)",
+			cfg.Role, time.Duration(secret.LeaseDuration)*time.Second, secret.LeaseID)
+	}
+
+	return secret.Data, nil
+}
+
+func (p *VaultProvider) ensureClient(ctx context.Context, cfg *VaultConfig) error {
+	if p.client != nil {
+		return nil
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
 	}
 
 	// Determine auth method
@@ -229,6 +455,33 @@ func (p *VaultProvider) ensureClient(ctx context.Context, cfg *VaultConfig) erro
 	return nil
 }
 
+// newClient creates an unauthenticated Vault client for the configured address,
+// falling back to VAULT_ADDR and then to the local default address
+func newClient(cfg *VaultConfig) (*api.Client, error) {
+	// Create default config
+	apiCfg := api.DefaultConfig()
+
+	// Read environment variables first
+	if err := apiCfg.ReadEnvironment(); err != nil {
+		return nil, fmt.Errorf("failed to read environment: %w", err)
+	}
+
+	// Override address if provided
+	if cfg.Address != "" {
+		apiCfg.Address = cfg.Address
+	} else if apiCfg.Address == "" {
+		// If VAULT_ADDR is not set, use default
+		apiCfg.Address = "http://127.0.0.1:8200"
+	}
+
+	// Create client
+	client, err := api.NewClient(apiCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+	return client, nil
+}
+
 // authenticateWithToken sets up token-based authentication
 func (p *VaultProvider) authenticateWithToken(client *api.Client, token string) error {
 	// Set token if provided, otherwise use VAULT_TOKEN env var
@@ -299,6 +552,61 @@ func (p *VaultProvider) authenticateWithJWT(ctx context.Context, client *api.Cli
 	return nil
 }
 
+// ValidateConfig checks the configuration without contacting Vault
+func (p *VaultProvider) ValidateConfig(config map[string]interface{}) error {
+	_, err := validateConfig(config)
+	return err
+}
+
+// validateConfig parses and validates the Vault configuration; Engine is normalized to lowercase
+func validateConfig(config map[string]interface{}) (*VaultConfig, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault configuration: %w", err)
+	}
+
+	engine := strings.ToLower(cfg.Engine)
+	if engine == "" {
+		engine = EngineKV
+	}
+
+	// Validate required fields
+	switch engine {
+	case EngineKV:
+		sources := 0
+		for _, set := range []bool{cfg.Path != "", len(cfg.Paths) > 0, cfg.PathTemplate != ""} {
+			if set {
+				sources++
+			}
+		}
+		if sources > 1 {
+			return nil, fmt.Errorf("vault provider accepts only one of 'path', 'paths' or 'path_template'")
+		}
+		if cfg.PathTemplate != "" {
+			if len(cfg.ForEach) == 0 {
+				return nil, fmt.Errorf("vault provider requires 'for_each' with 'path_template'")
+			}
+		} else if len(cfg.Paths) > 0 {
+			for i, entry := range cfg.Paths {
+				if entry.Path == "" {
+					return nil, fmt.Errorf("vault provider requires a path for paths[%d]", i)
+				}
+			}
+		} else if cfg.Path == "" {
+			return nil, fmt.Errorf("vault provider requires 'path' field in configuration")
+		}
+	case EngineDatabase:
+		if cfg.Role == "" {
+			return nil, fmt.Errorf("vault provider requires 'role' field in configuration for the database engine")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported vault engine: %s (supported: kv, database)", cfg.Engine)
+	}
+	cfg.Engine = engine
+
+	return cfg, nil
+}
+
 // parseConfig converts a map[string]interface{} to VaultConfig
 func parseConfig(config map[string]interface{}) (*VaultConfig, error) {
 	// Use JSON marshaling/unmarshaling for clean conversion
@@ -312,6 +620,14 @@ func parseConfig(config map[string]interface{}) (*VaultConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	switch cfg.KVVersion {
+	case 0:
+		cfg.KVVersion = KVVersion2
+	case KVVersion1, KVVersion2:
+	default:
+		return nil, fmt.Errorf("unsupported kv_version: %d (supported: 1, 2)", cfg.KVVersion)
+	}
+
 	// Extract SSO tokens from the config map (these are injected by the collector)
 	if accessToken, ok := config["_sso_access_token"].(string); ok {
 		cfg.SSOAccessToken = accessToken
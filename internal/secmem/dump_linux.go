@@ -0,0 +1,13 @@
+//go:build linux
+
+package secmem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// DisableCoreDumps marks the process as not dumpable, so the kernel writes no core dump for it and
+// other processes of the same user cannot attach to it. Commands started afterwards are not affected.
+func DisableCoreDumps() error {
+	return unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package secmem
+
+import (
+	"syscall"
+)
+
+// DisableCoreDumps sets the core file size limit of the process to zero, so no core dump is written.
+// Commands started afterwards inherit the limit.
+func DisableCoreDumps() error {
+	return syscall.Setrlimit(syscall.RLIMIT_CORE, &syscall.Rlimit{Cur: 0, Max: 0})
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package secmem
+
+import (
+	"errors"
+)
+
+// DisableCoreDumps is not supported on Windows, where crash dumps are configured system-wide
+func DisableCoreDumps() error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,70 @@
+// Package secmem reduces how long secret values linger in memory and keeps them out of core dumps.
+// Go's runtime may copy values it does not own, so this is best-effort.
+package secmem
+
+import (
+	"unsafe"
+)
+
+// Redacted is what a Value prints and marshals to, so it never ends up in logs
+const Redacted = "[REDACTED]"
+
+// Value holds a secret in a byte slice that it owns, so it can be zeroed once it is no longer needed
+type Value struct {
+	b []byte
+}
+
+// Values is a group of values zeroed together
+type Values []*Value
+
+// New returns a value holding a copy of the concatenation of parts. Concatenating here avoids
+// intermediate strings, which could not be zeroed.
+func New(parts ...string) *Value {
+	n := 0
+	for _, part := range parts {
+		n += len(part)
+	}
+	b := make([]byte, 0, n)
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+	return &Value{b: b}
+}
+
+// Bytes returns the backing bytes of the value, which are zeroed by Zero
+func (v *Value) Bytes() []byte {
+	return v.b
+}
+
+// UnsafeString returns the value as a string sharing its backing bytes, without copying them.
+// The string must not be used after Zero, as its content changes.
+func (v *Value) UnsafeString() string {
+	return unsafe.String(unsafe.SliceData(v.b), len(v.b))
+}
+
+// Zero overwrites the backing bytes of the value with zeros
+func (v *Value) Zero() {
+	clear(v.b)
+}
+
+// String returns Redacted, so printing a value never reveals it
+func (v *Value) String() string {
+	return Redacted
+}
+
+// GoString returns Redacted, for %#v
+func (v *Value) GoString() string {
+	return Redacted
+}
+
+// MarshalText returns Redacted, so encoding a value never reveals it
+func (v *Value) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// Zero zeroes every value of the group
+func (vs Values) Zero() {
+	for _, v := range vs {
+		v.Zero()
+	}
+}
@@ -0,0 +1,56 @@
+package secmem
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestValue_ZeroClearsBytes(t *testing.T) {
+	v := New("API_KEY", "=", "s3cr3t")
+	s := v.UnsafeString()
+	if s != "API_KEY=s3cr3t" {
+		t.Fatalf("UnsafeString() = %q", s)
+	}
+	backing := v.Bytes()
+
+	v.Zero()
+
+	for i, b := range backing {
+		if b != 0 {
+			t.Fatalf("Byte %d not zeroed: %q", i, backing)
+		}
+	}
+	// The string shares the backing bytes, so no copy of the secret is left behind
+	if s != string(make([]byte, len("API_KEY=s3cr3t"))) {
+		t.Errorf("Expected the string to be zeroed, got %q", s)
+	}
+}
+
+func TestValues_Zero(t *testing.T) {
+	values := Values{New("first"), New("second")}
+	values.Zero()
+	for _, v := range values {
+		for _, b := range v.Bytes() {
+			if b != 0 {
+				t.Fatalf("Value not zeroed: %q", v.Bytes())
+			}
+		}
+	}
+}
+
+func TestValue_Redacted(t *testing.T) {
+	v := New("s3cr3t")
+	for _, printed := range []string{fmt.Sprint(v), fmt.Sprintf("%v %s %#v", v, v, v)} {
+		if printed != Redacted && printed != Redacted+" "+Redacted+" "+Redacted {
+			t.Errorf("Printed value = %q", printed)
+		}
+	}
+	encoded, err := json.Marshal(map[string]*Value{"key": v})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(encoded) != `{"key":"[REDACTED]"}` {
+		t.Errorf("json.Marshal() = %s", encoded)
+	}
+}
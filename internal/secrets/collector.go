@@ -2,15 +2,25 @@ package secrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/dirathea/sstart/internal/cache"
 	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/jsonout"
+	"github.com/dirathea/sstart/internal/logger"
 	"github.com/dirathea/sstart/internal/oidc"
 	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/transform"
 )
 
 const (
@@ -18,207 +28,1735 @@ const (
 	AccessTokenConfigKey = "_sso_access_token"
 	// IDTokenConfigKey is the key used to inject ID token into provider config
 	IDTokenConfigKey = "_sso_id_token"
+
+	// ssoLoginHint tells the user how to obtain fresh SSO tokens
+	ssoLoginHint = "authenticate first with 'sstart login'"
 )
 
-// Collector collects secrets from all configured providers
-type Collector struct {
-	config      *config.Config
-	ssoClient   *oidc.Client
-	accessToken string
-	idToken     string
-	forceAuth   bool
-	cache       *cache.Cache
+// Collector collects secrets from all configured providers
+type Collector struct {
+	config     *config.Config
+	sso        map[string]*ssoIdentity // SSO identities by name (config.DefaultSSOIdentity for the default one)
+	forceAuth  bool
+	requireSSO bool
+	authFlow   oidc.AuthFlow
+	strictKeys bool
+	expandJSON *bool
+	cache      *cache.Cache
+	// Whether providers render files without writing them (see provider.SecretContext.SkipFileWrites)
+	skipFileWrites bool
+
+	postProcessors []PostProcessor
+	eventHandlers  []EventHandler
+	eventsMu       sync.Mutex
+
+	// Retry budget shared by all providers during the current collection
+	retryBudget *retryBudget
+
+	// Limits how many process-spawning providers fetch at the same time
+	maxExecProviders int
+	execLimiter      *execLimiter
+
+	// Provider id that set each key during the current collection, for source_priority
+	keySources map[string]string
+	// Every provider id that supplied each key during the current collection, in order
+	keyProviders map[string][]string
+	// Outcome of every provider decided during the current collection, for strict_uses
+	providerOutcomes map[string]string
+	// Values supplied for each key, parallel to keyProviders (only recorded to explain collisions)
+	keyValues map[string][]string
+	// Key collisions that failed providers during the current collection, and the writer of their report
+	collisionFailures []collisionFailure
+	explainCollisions io.Writer
+	// Key provenance of the last collection, and the file it is written to
+	provenance []KeyProvenance
+	traceFile  string
+
+	// Secrets fetched during the current collection by providers with an explicit cache_key
+	sharedFetches map[string]provider.Secrets
+	// Guards the per-provider secrets and shared fetches while providers are fetched concurrently
+	collectMu sync.Mutex
+
+	// Number of providers fetched at the same time
+	concurrency int
+	// Maximum duration of each fetch attempt for providers without a 'timeout' (zero: none)
+	timeout time.Duration
+	// Maximum duration of fetching all providers of a collection (zero: none)
+	collectionTimeout time.Duration
+
+	// Provider instances reused across collections, keyed by provider ID
+	instances   map[string]*providerInstance
+	instancesMu sync.Mutex
+}
+
+// providerInstance is a provider reused across collections while its configuration and SSO tokens are unchanged
+type providerInstance struct {
+	key      string // See instanceKey
+	provider provider.Provider
+}
+
+// PostProcessor transforms the final collected secrets, e.g. to add derived values or remove keys.
+// It may modify and return the given map or return a new one.
+type PostProcessor func(map[string]string) (map[string]string, error)
+
+// CollectorOption is a functional option for configuring the Collector
+type CollectorOption func(*Collector)
+
+// WithForceAuth returns an option that forces re-authentication by ignoring cached tokens
+func WithForceAuth(forceAuth bool) CollectorOption {
+	return func(c *Collector) {
+		c.forceAuth = forceAuth
+	}
+}
+
+// WithRequireSSO returns an option that fails collection before any provider is fetched
+// unless a valid (or refreshable) SSO token is available, instead of starting a login flow
+// or letting providers fall back to their own authentication
+func WithRequireSSO(requireSSO bool) CollectorOption {
+	return func(c *Collector) {
+		c.requireSSO = requireSSO
+	}
+}
+
+// WithAuthFlow returns an option that selects the interactive SSO login flow (default: oidc.AuthFlowAuto)
+func WithAuthFlow(flow oidc.AuthFlow) CollectorOption {
+	return func(c *Collector) {
+		c.authFlow = flow
+	}
+}
+
+// WithStrictKeys returns an option that fails collection when a provider returns source keys
+// that are not listed in its 'keys' mapping, instead of silently dropping them
+func WithStrictKeys(strictKeys bool) CollectorOption {
+	return func(c *Collector) {
+		c.strictKeys = strictKeys
+	}
+}
+
+// WithExpandJSON returns an option that sets the expand_json default for providers that
+// do not configure it, overriding the configuration's global expand_json
+func WithExpandJSON(expandJSON bool) CollectorOption {
+	return func(c *Collector) {
+		c.expandJSON = &expandJSON
+	}
+}
+
+// WithFileWrites returns an option that sets whether providers write files as a side effect of
+// fetching, such as templates rendered into files (default: true). Without file writes, the files
+// are still rendered, so template errors are reported, but existing files are left untouched.
+func WithFileWrites(enabled bool) CollectorOption {
+	return func(c *Collector) {
+		c.skipFileWrites = !enabled
+	}
+}
+
+// WithMaxExecProviders returns an option that limits how many process-spawning providers
+// (e.g. bitwarden, 1password_cli) fetch at the same time. Zero or less removes the limit.
+func WithMaxExecProviders(max int) CollectorOption {
+	return func(c *Collector) {
+		c.maxExecProviders = max
+	}
+}
+
+// WithConcurrency returns an option that sets how many providers are fetched at the same time.
+// Secrets are still merged in configuration order. Zero or less fetches all selected providers at once.
+func WithConcurrency(n int) CollectorOption {
+	return func(c *Collector) {
+		c.concurrency = n
+	}
+}
+
+// WithTimeout returns an option that limits each fetch attempt of providers that do not configure
+// their own 'timeout'. A provider that does not return in time fails with a timeout error, even if
+// it does not watch its context. Zero or less means no timeout.
+func WithTimeout(timeout time.Duration) CollectorOption {
+	return func(c *Collector) {
+		c.timeout = timeout
+	}
+}
+
+// WithCollectionTimeout returns an option that limits how long each collection may spend fetching
+// providers, after SSO authentication. Once it expires, Collect fails with a timeout error without
+// waiting for providers that do not watch their context. Zero or less means no timeout.
+func WithCollectionTimeout(timeout time.Duration) CollectorOption {
+	return func(c *Collector) {
+		c.collectionTimeout = timeout
+	}
+}
+
+// WithPostProcessor returns an option that runs fn on the final collected secrets,
+// after all providers are merged and keys are normalized. Multiple post-processors run in order.
+func WithPostProcessor(fn func(map[string]string) (map[string]string, error)) CollectorOption {
+	return func(c *Collector) {
+		c.postProcessors = append(c.postProcessors, fn)
+	}
+}
+
+// WithTraceFile returns an option that writes the provenance of every collected key
+// (the provider it came from and the providers it overrode, never values) to path after each collection
+func WithTraceFile(path string) CollectorOption {
+	return func(c *Collector) {
+		c.traceFile = path
+	}
+}
+
+// WithExplainCollisions returns an option that writes a report of key collisions to w after each collection:
+// every key supplied by several providers, with value lengths and masked previews and the reason a value was
+// kept, and the collisions that made a provider fail
+func WithExplainCollisions(w io.Writer) CollectorOption {
+	return func(c *Collector) {
+		c.explainCollisions = w
+	}
+}
+
+// WithEventHandler returns an option that sends provider access events to fn as they happen.
+// Events carry provider ids, key names and outcomes, never secret values. Multiple handlers run in order.
+func WithEventHandler(fn EventHandler) CollectorOption {
+	return func(c *Collector) {
+		c.eventHandlers = append(c.eventHandlers, fn)
+	}
+}
+
+// NewCollector creates a new secrets collector
+func NewCollector(cfg *config.Config, opts ...CollectorOption) *Collector {
+	collector := &Collector{config: cfg, maxExecProviders: DefaultMaxExecProviders, concurrency: DefaultConcurrency}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(collector)
+	}
+	collector.execLimiter = newExecLimiter(collector.maxExecProviders)
+
+	// Initialize the SSO clients of the configured identities
+	if cfg.SSO != nil {
+		collector.sso = make(map[string]*ssoIdentity, len(cfg.SSO.Identities)+1)
+		if cfg.SSO.OIDC != nil {
+			collector.sso[config.DefaultSSOIdentity] = newSSOIdentity(config.DefaultSSOIdentity, cfg.SSO)
+		}
+		for name := range cfg.SSO.Identities {
+			if name != config.DefaultSSOIdentity {
+				collector.sso[name] = newSSOIdentity(name, cfg.SSO)
+			}
+		}
+	}
+
+	// Initialize cache if enabled
+	if cfg.IsCacheEnabled() {
+		cacheOpts := []cache.Option{}
+		if ttl := cfg.GetCacheTTL(); ttl > 0 {
+			cacheOpts = append(cacheOpts, cache.WithTTL(ttl))
+		}
+		collector.cache = cache.New(cacheOpts...)
+	}
+
+	return collector
+}
+
+// Collect fetches secrets from all providers and combines them
+func (c *Collector) Collect(ctx context.Context, providerIDs []string) (provider.Secrets, error) {
+	// Authenticate with SSO if configured
+	if err := c.authenticateSSO(ctx, providerIDs); err != nil {
+		return nil, fmt.Errorf("SSO authentication failed: %w", err)
+	}
+
+	if c.collectionTimeout <= 0 {
+		return c.collect(ctx, providerIDs)
+	}
+
+	collectCtx, cancel := context.WithTimeout(ctx, c.collectionTimeout)
+	defer cancel()
+
+	type collectReturn struct {
+		secrets provider.Secrets
+		err     error
+	}
+	done := make(chan collectReturn, 1)
+	started := time.Now()
+	go func() {
+		secrets, err := c.collect(collectCtx, providerIDs)
+		done <- collectReturn{secrets: secrets, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err != nil && errors.Is(collectCtx.Err(), context.DeadlineExceeded) {
+			return nil, collectionTimeoutError(started, c.collectionTimeout, result.err)
+		}
+		return result.secrets, result.err
+	case <-collectCtx.Done():
+		// Wait for the cancelled collection to stop, so that it no longer uses the collector's
+		// per-collection state when the next collection starts. Fetches observe the cancellation,
+		// so this only takes as long as the running providers need to notice it.
+		<-done
+		if errors.Is(collectCtx.Err(), context.DeadlineExceeded) {
+			return nil, collectionTimeoutError(started, c.collectionTimeout, nil)
+		}
+		return nil, collectCtx.Err()
+	}
+}
+
+// collectionTimeoutError reports a collection that exceeded its timeout, with the provider error it caused if any
+func collectionTimeoutError(started time.Time, timeout time.Duration, cause error) error {
+	err := fmt.Errorf("secret collection timed out after %s (collection timeout %s): %w", time.Since(started).Round(time.Millisecond), timeout, context.DeadlineExceeded)
+	if cause != nil {
+		err = fmt.Errorf("%w: %v", err, cause)
+	}
+	return err
+}
+
+// collect fetches and combines the secrets of a collection once SSO authentication is done
+func (c *Collector) collect(ctx context.Context, providerIDs []string) (provider.Secrets, error) {
+	secrets := make(provider.Secrets)
+	// Track secrets by provider ID for template providers
+	providerSecrets := make(provider.ProviderSecretsMap)
+
+	// Every collection starts with a fresh retry budget
+	c.retryBudget = newRetryBudget(c.config.RetryBudget)
+	c.keySources = make(map[string]string)
+	c.keyProviders = make(map[string][]string)
+	c.keyValues = make(map[string][]string)
+	c.providerOutcomes = make(map[string]string)
+	c.collisionFailures = nil
+	c.sharedFetches = make(map[string]provider.Secrets)
+	defer c.finishCollection()
+	if c.explainCollisions != nil {
+		defer c.writeCollisionReport(c.explainCollisions)
+	}
+
+	// If no providers specified, use all providers in order
+	if len(providerIDs) == 0 {
+		for _, provider := range c.config.Providers {
+			providerIDs = append(providerIDs, provider.ID)
+		}
+	}
+
+	// Run every provider after the providers it depends on
+	providerIDs, err := c.orderByDependencies(providerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// First pass: collect unconditional providers in order.
+	// Providers with a 'requires' condition are deferred until the provider they reference has been decided,
+	// together with the providers that depend on a deferred provider.
+	var unconditional, deferred []*config.ProviderConfig
+	deferredIDs := make(map[string]bool)
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if providerCfg.Requires != nil || c.dependsOnAny(providerCfg, deferredIDs) {
+			deferred = append(deferred, providerCfg)
+			deferredIDs[providerCfg.ID] = true
+			continue
+		}
+		unconditional = append(unconditional, providerCfg)
+	}
+	if err := c.collectPlan(ctx, unconditional, secrets, providerSecrets); err != nil {
+		return nil, err
+	}
+
+	// Second pass: evaluate conditional providers
+	if err := c.collectConditional(ctx, deferred, secrets, providerSecrets); err != nil {
+		return nil, err
+	}
+
+	// Normalize final key names if configured
+	if c.config.UppercaseKeys {
+		normalized, err := UppercaseKeys(secrets)
+		if err != nil {
+			c.recordCollisionFailure("", err)
+			return nil, err
+		}
+		secrets = normalized
+	}
+
+	// Protect the child environment from keys that must not come from a secret store
+	DropDeniedKeys(secrets, c.config.DenyKeys)
+
+	// Let embedding programs adjust the final set
+	for i, postProcess := range c.postProcessors {
+		processed, err := postProcess(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("post-processor %d failed: %w", i+1, err)
+		}
+		secrets = processed
+	}
+
+	c.buildProvenance(secrets)
+	if c.traceFile != "" {
+		if err := c.writeTraceFile(c.traceFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// DropDeniedKeys removes every key listed in denyKeys from secrets, warning about each one
+func DropDeniedKeys(secrets provider.Secrets, denyKeys []string) {
+	for _, key := range denyKeys {
+		if _, exists := secrets[key]; exists {
+			delete(secrets, key)
+			logger.Warnf("Dropping collected key '%s': it is listed in deny_keys", key)
+		}
+	}
+}
+
+// UppercaseKeys returns a copy of secrets with all key names uppercased.
+// It returns an error if two keys differ only by case, since one would silently overwrite the other.
+func UppercaseKeys(secrets provider.Secrets) (provider.Secrets, error) {
+	normalized := make(provider.Secrets, len(secrets))
+	originals := make(map[string]string, len(secrets))
+	for key, value := range secrets {
+		upper := strings.ToUpper(key)
+		if existing, exists := originals[upper]; exists {
+			first, second := existing, key
+			if first > second {
+				first, second = second, first
+			}
+			return nil, &provider.KeyCollisionError{
+				Key:     upper,
+				Sources: []string{fmt.Sprintf("key '%s'", first), fmt.Sprintf("key '%s'", second)},
+				Detail:  fmt.Sprintf("uppercase_keys: keys '%s' and '%s' collide as '%s'", first, second, upper),
+			}
+		}
+		originals[upper] = key
+		normalized[upper] = value
+	}
+	return normalized, nil
+}
+
+// collectConditional collects providers gated by a 'requires' condition, and the providers depending on them.
+// A provider is evaluated once the provider it references has been collected or skipped;
+// if no pending provider can make progress, the remaining conditions can never be evaluated
+// (the referenced provider is not selected, or conditions form a cycle) and an error is returned.
+func (c *Collector) collectConditional(ctx context.Context, pending []*config.ProviderConfig, secrets provider.Secrets, providerSecrets provider.ProviderSecretsMap) error {
+	// Providers whose outcome is known once the plan runs: all providers collected so far,
+	// and every pending provider placed in the plan, whether it will be collected or skipped
+	decided := make(map[string]bool, len(providerSecrets)+len(pending))
+	for providerID := range providerSecrets {
+		decided[providerID] = true
+	}
+	// Explicit dependencies that are still waiting to be decided
+	waiting := make(map[string]bool, len(pending))
+	for _, providerCfg := range pending {
+		waiting[providerCfg.ID] = true
+	}
+
+	var plan []*config.ProviderConfig
+	for len(pending) > 0 {
+		var remaining []*config.ProviderConfig
+		for _, providerCfg := range pending {
+			if c.dependsOnAny(providerCfg, waiting) || providerCfg.Requires != nil && !decided[providerCfg.Requires.Provider] {
+				remaining = append(remaining, providerCfg)
+				continue
+			}
+			plan = append(plan, providerCfg)
+			decided[providerCfg.ID] = true
+			delete(waiting, providerCfg.ID)
+		}
+
+		if len(remaining) == len(pending) {
+			ids := make([]string, 0, len(remaining))
+			for _, providerCfg := range remaining {
+				if providerCfg.Requires == nil {
+					continue
+				}
+				ids = append(ids, fmt.Sprintf("'%s' (requires '%s')", providerCfg.ID, providerCfg.Requires.Provider))
+			}
+			return fmt.Errorf("cannot evaluate 'requires' for provider(s) %s: referenced providers are not selected or form a cycle", strings.Join(ids, ", "))
+		}
+		pending = remaining
+	}
+
+	return c.collectPlan(ctx, plan, secrets, providerSecrets)
+}
+
+// orderByDependencies returns providerIDs reordered so that every provider comes after the selected
+// providers listed in its 'depends_on'. Otherwise the given order is kept. Dependencies that are not
+// selected are ignored, as they are not collected.
+func (c *Collector) orderByDependencies(providerIDs []string) ([]string, error) {
+	selected := make(map[string]bool, len(providerIDs))
+	configs := make([]*config.ProviderConfig, 0, len(providerIDs))
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			return nil, err
+		}
+		selected[providerCfg.ID] = true
+		configs = append(configs, providerCfg)
+	}
+
+	// Repeatedly place the earliest provider whose dependencies are placed, so that providers only
+	// move as far as needed and later providers keep overriding earlier ones otherwise
+	ordered := make([]string, 0, len(configs))
+	placed := make(map[string]bool, len(configs))
+	for len(configs) > 0 {
+		next := -1
+		for i, providerCfg := range configs {
+			ready := true
+			for _, dep := range providerCfg.DependsOn {
+				if selected[dep] && !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			ids := make([]string, 0, len(configs))
+			for _, providerCfg := range configs {
+				ids = append(ids, providerCfg.ID)
+			}
+			return nil, fmt.Errorf("depends_on of provider(s) %s forms a cycle", strings.Join(ids, ", "))
+		}
+		ordered = append(ordered, configs[next].ID)
+		placed[configs[next].ID] = true
+		configs = append(configs[:next], configs[next+1:]...)
+	}
+	return ordered, nil
+}
+
+// dependsOnAny reports whether providerCfg lists any of ids in its 'depends_on',
+// or in its 'uses' when strict_uses requires those providers to be decided first
+func (c *Collector) dependsOnAny(providerCfg *config.ProviderConfig, ids map[string]bool) bool {
+	for _, dep := range providerCfg.DependsOn {
+		if ids[dep] {
+			return true
+		}
+	}
+	if providerCfg.StrictUses {
+		for _, name := range providerCfg.Uses {
+			if ids[c.config.ResolveProviderID(name)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectPlan fetches the providers of plan concurrently and merges their secrets into secrets
+// in plan order, so later providers override earlier ones regardless of which finished first.
+// The errors of all failed providers are returned together.
+func (c *Collector) collectPlan(ctx context.Context, plan []*config.ProviderConfig, secrets provider.Secrets, providerSecrets provider.ProviderSecretsMap) error {
+	results := c.fetchAll(ctx, plan, providerSecrets)
+
+	var errs []error
+	for i, providerCfg := range plan {
+		result := results[i]
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		if result.outcome != OutcomeFetched && result.outcome != OutcomeCached {
+			continue
+		}
+		// Merge secrets (later providers override earlier ones unless a merge strategy is configured)
+		for _, kv := range result.kvs {
+			c.mergeSecret(secrets, providerCfg.ID, kv.Key, kv.Value)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// collectProvider evaluates the 'requires' condition of plan[i] and fetches its secrets,
+// recording them in providerSecrets and emitting provider_start and provider_finish events
+func (c *Collector) collectProvider(ctx context.Context, plan []*config.ProviderConfig, i int, providerSecrets provider.ProviderSecretsMap) fetchResult {
+	providerCfg := plan[i]
+	if requires := providerCfg.Requires; requires != nil {
+		c.collectMu.Lock()
+		depSecrets := providerSecrets[requires.Provider]
+		c.collectMu.Unlock()
+		if !requires.Matches(depSecrets) {
+			c.recordOutcome(providerCfg.ID, OutcomeSkipped)
+			c.emitFinish(providerCfg, OutcomeSkipped, time.Time{}, nil, nil)
+			return fetchResult{outcome: OutcomeSkipped}
+		}
+	}
+
+	if err := c.strictUsesError(providerCfg); err != nil {
+		c.recordOutcome(providerCfg.ID, OutcomeError)
+		c.emitFinish(providerCfg, OutcomeError, time.Time{}, nil, err)
+		return fetchResult{outcome: OutcomeError, err: err}
+	}
+
+	c.emit(Event{Type: EventProviderStart, Provider: providerCfg.ID, Kind: providerCfg.Kind, Labels: providerCfg.Labels})
+	started := time.Now()
+
+	kvs, outcome, err := c.fetchProvider(ctx, providerCfg, c.visibleSecrets(plan, i, providerSecrets))
+	c.recordCollisionFailure(providerCfg.ID, err)
+	if err != nil {
+		c.recordOutcome(providerCfg.ID, OutcomeError)
+	} else {
+		c.recordOutcome(providerCfg.ID, outcome)
+	}
+	if err != nil && !providerCfg.IsRequired() {
+		// An optional provider contributes no keys. Its dependents are still collected, without its
+		// secrets, unless they set strict_uses.
+		logger.Warnf("Provider '%s' is not required, continuing without its secrets: %v", providerCfg.ID, err)
+		c.emitFinish(providerCfg, OutcomeError, started, nil, err)
+		return fetchResult{outcome: OutcomeError}
+	}
+	var fetched provider.Secrets
+	if err == nil {
+		// Store secrets by provider ID for resolver
+		fetched = make(provider.Secrets, len(kvs))
+		for _, kv := range kvs {
+			fetched[kv.Key] = kv.Value
+		}
+		c.collectMu.Lock()
+		providerSecrets[providerCfg.ID] = fetched
+		c.collectMu.Unlock()
+	}
+	c.emitFinish(providerCfg, outcome, started, fetched, err)
+	return fetchResult{kvs: kvs, outcome: outcome, err: err}
+}
+
+// recordOutcome records how a provider was decided during the current collection
+func (c *Collector) recordOutcome(providerID, outcome string) {
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+	c.providerOutcomes[providerID] = outcome
+}
+
+// strictUsesError returns an error if providerCfg sets strict_uses and one of its 'uses' providers
+// was not collected, naming why: it failed, was skipped by its 'requires' condition or was not selected
+func (c *Collector) strictUsesError(providerCfg *config.ProviderConfig) error {
+	if !providerCfg.StrictUses {
+		return nil
+	}
+
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+	for _, name := range providerCfg.Uses {
+		var reason string
+		switch c.providerOutcomes[c.config.ResolveProviderID(name)] {
+		case OutcomeFetched, OutcomeCached:
+			continue
+		case OutcomeError:
+			reason = "failed to collect"
+		case OutcomeSkipped:
+			reason = "was skipped: its 'requires' condition does not hold"
+		case outcomeBlocked:
+			reason = "was not collected: one of its prerequisites failed"
+		default:
+			reason = "was not collected: it is not selected"
+		}
+		return fmt.Errorf("provider '%s' uses provider '%s', which %s (strict_uses)", providerCfg.ID, name, reason)
+	}
+	return nil
+}
+
+// visibleSecrets returns the secrets of the providers listed in the 'uses' of plan[i] that were collected
+// before it: in an earlier pass, or earlier in plan. Providers later in plan are never visible, even if
+// they finish first, so a provider sees the same secrets as when providers are collected one by one.
+func (c *Collector) visibleSecrets(plan []*config.ProviderConfig, i int, providerSecrets provider.ProviderSecretsMap) provider.ProviderSecretsMap {
+	if len(plan[i].Uses) == 0 {
+		return nil
+	}
+	later := make(map[string]bool, len(plan)-i)
+	for _, providerCfg := range plan[i:] {
+		later[providerCfg.ID] = true
+	}
+
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+	visible := make(provider.ProviderSecretsMap, len(plan[i].Uses))
+	for _, name := range plan[i].Uses {
+		id := c.config.ResolveProviderID(name)
+		if later[id] {
+			continue
+		}
+		if secrets, ok := providerSecrets[id]; ok {
+			visible[id] = secrets
+		}
+	}
+	return visible
+}
+
+// fetchProvider fetches secrets from a single provider, or reuses cached ones. visible holds the
+// secrets of the providers in its 'uses' collected so far. It returns the secrets and whether
+// they were fetched or cached.
+func (c *Collector) fetchProvider(ctx context.Context, providerCfg *config.ProviderConfig, visible provider.ProviderSecretsMap) ([]provider.KeyValue, string, error) {
+	providerID := providerCfg.ID
+
+	expandedConfig, configKey := c.providerConfig(providerCfg)
+
+	// Reuse the provider instance from a previous collection, so long-lived clients survive reloads
+	prov, err := c.providerFor(providerCfg, configKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create provider '%s': %w", providerID, err)
+	}
+
+	// Providers writing files are always fetched, as their files would not be written from the cache
+	writer, isWriter := prov.(provider.FileWriter)
+	cacheable := !isWriter || !writer.WritesFiles(expandedConfig)
+
+	// Use the configuration key as cache key, unless the provider sets its own
+	cacheKey := configKey
+	if providerCfg.CacheKey != "" && cacheable {
+		cacheKey = cache.CustomCacheKey(providerCfg.CacheKey)
+
+		// Providers sharing a cache_key are only fetched once per collection
+		c.collectMu.Lock()
+		shared, found := c.sharedFetches[cacheKey]
+		c.collectMu.Unlock()
+		if found {
+			return secretsToKeyValues(shared), OutcomeCached, nil
+		}
+	}
+
+	// Try to get secrets from cache if enabled
+	if c.cache != nil && cacheable {
+		if cachedSecrets, found := c.cache.Get(cacheKey); found {
+			return secretsToKeyValues(cachedSecrets), OutcomeCached, nil
+		}
+	}
+
+	// Inject SSO tokens into provider config if available
+	c.injectTokensIntoConfig(providerCfg, expandedConfig)
+
+	// Create SecretContext with resolver for providers
+	// Providers can optionally use SecretsResolver to access secrets from other providers
+	// This follows the principle of least privilege - providers only access secrets they explicitly request
+	// If 'uses' is specified, create a filtered resolver that only includes secrets from allowed providers
+	// If 'uses' is not specified, pass an empty resolver (no access to other providers' secrets)
+	var secretContext provider.SecretContext
+	if len(providerCfg.Uses) > 0 {
+		aliasedSecrets, allowed := c.resolveAliases(visible, providerCfg.Uses)
+		secretContext = NewSecretContext(ctx, aliasedSecrets, allowed)
+	} else {
+		// Pass empty provider secrets map when 'uses' is not defined
+		secretContext = NewEmptySecretContext(ctx)
+	}
+	secretContext.SkipFileWrites = c.skipFileWrites
+
+	// Providers only receive the key names of the mapping; exclusions and patterns are applied here
+	mapping, err := newKeyMapping(providerCfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("provider '%s': %w", providerID, err)
+	}
+
+	// In strict mode, fetch all source keys and apply the mapping here so dropped keys can be detected.
+	// Case-insensitive matching and patterns are applied here as well, since providers match keys exactly,
+	// and so is the mapping of providers naming their own keys, since they ignore it.
+	strict := (c.strictKeys || providerCfg.StrictKeys) && !mapping.empty()
+	ignoreCase := providerCfg.CaseInsensitiveKeys && !mapping.empty()
+	namer, namesOwnKeys := prov.(provider.KeyNamer)
+	mapHere := strict || ignoreCase || mapping.hasPatterns() || namesOwnKeys && namer.NamesOwnKeys()
+	fetchKeys := mapping.keys
+	if mapHere {
+		fetchKeys = nil
+	}
+
+	// Fetch secrets from this provider's single source
+	kvs, err := c.fetchWithRetries(ctx, prov, secretContext, providerCfg, expandedConfig, fetchKeys)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch from provider '%s': %w", providerID, err)
+	}
+
+	// Keys still have their source names when there is no mapping or it is applied here.
+	// Otherwise, excluded keys are already left out, as they are removed from the mapping.
+	if mapping.empty() || mapHere {
+		kvs = mapping.dropExcluded(kvs, providerCfg.CaseInsensitiveKeys)
+	}
+
+	if mapHere && !mapping.empty() {
+		var dropped []string
+		if ignoreCase {
+			kvs, dropped, err = mapKeysIgnoreCase(kvs, mapping.keys, mapping.patterns)
+		} else {
+			kvs, dropped, err = mapKeys(kvs, mapping.keys, mapping.patterns)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("provider '%s': %w", providerID, err)
+		}
+		if strict && len(dropped) > 0 {
+			return nil, "", fmt.Errorf("provider '%s' returned keys not listed in 'keys' (strict keys): %s", providerID, strings.Join(dropped, ", "))
+		}
+	}
+
+	if providerCfg.KeysTemplate != "" {
+		kvs, err = renameKeys(kvs, providerCfg.KeysTemplate)
+		if err != nil {
+			return nil, "", fmt.Errorf("provider '%s': %w", providerID, err)
+		}
+	}
+
+	if providerCfg.ValueTransform != nil {
+		kvs, err = applyValueTransform(ctx, providerCfg, kvs)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to transform values of provider '%s': %w", providerID, err)
+		}
+	}
+
+	if providerCfg.AsJSONKey != "" {
+		kvs, err = asJSONObject(kvs, providerCfg.AsJSONKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("provider '%s': %w", providerID, err)
+		}
+	}
+
+	fetched := make(provider.Secrets, len(kvs))
+	for _, kv := range kvs {
+		fetched[kv.Key] = kv.Value
+	}
+
+	// Cache the secrets if caching is enabled
+	if c.cache != nil && cacheable {
+		_ = c.cache.SetProvider(cacheKey, providerID, providerCfg.Kind, fetched)
+	}
+	if providerCfg.CacheKey != "" && cacheable {
+		c.collectMu.Lock()
+		c.sharedFetches[cacheKey] = fetched
+		c.collectMu.Unlock()
+	}
+
+	return kvs, OutcomeFetched, nil
+}
+
+// Prewarm sets up the clients and sessions of the given providers (all providers when empty) ahead of
+// the first collection: it authenticates with SSO, then lets every provider implementing provider.Warmer
+// log in, concurrently. The provider instances are kept, so later collections reuse them without new
+// handshakes. A provider that fails to warm up is only warned about, since fetching sets it up again.
+func (c *Collector) Prewarm(ctx context.Context, providerIDs []string) error {
+	if err := c.authenticateSSO(ctx, providerIDs); err != nil {
+		return fmt.Errorf("SSO authentication failed: %w", err)
+	}
+
+	if len(providerIDs) == 0 {
+		for _, providerCfg := range c.config.Providers {
+			providerIDs = append(providerIDs, providerCfg.ID)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.warmProvider(ctx, providerCfg); err != nil {
+				logger.Warnf("Failed to prewarm provider '%s': %v", providerCfg.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// warmProvider creates the provider instance later fetches reuse and warms it up if it supports it
+func (c *Collector) warmProvider(ctx context.Context, providerCfg *config.ProviderConfig) error {
+	expandedConfig, configKey := c.providerConfig(providerCfg)
+	prov, err := c.providerFor(providerCfg, configKey)
+	if err != nil {
+		return err
+	}
+	warmer, ok := prov.(provider.Warmer)
+	if !ok {
+		return nil
+	}
+	c.injectTokensIntoConfig(providerCfg, expandedConfig)
+	return warmer.Warm(NewEmptySecretContext(ctx), expandedConfig)
+}
+
+// providerConfig returns the configuration passed to a provider, with templates expanded and defaults applied,
+// and the key identifying it for caching and provider instance reuse
+func (c *Collector) providerConfig(providerCfg *config.ProviderConfig) (map[string]interface{}, string) {
+	// Expand template variables in config (e.g., in path fields)
+	expandedConfig := expandConfigTemplates(providerCfg.Config)
+
+	// Apply the global expand_json default unless the provider sets its own
+	if expandJSON := c.expandJSONDefault(); expandJSON != nil {
+		if _, set := expandedConfig[provider.ExpandJSONConfigKey]; !set {
+			expandedConfig[provider.ExpandJSONConfigKey] = *expandJSON
+		}
+	}
+
+	// Generate the key based on provider configuration
+	configKey := cache.GenerateCacheKey(providerCfg.ID, providerCfg.Kind, expandedConfig)
+	if providerCfg.ValueTransform != nil || providerCfg.AsJSONKey != "" || len(providerCfg.Keys) > 0 ||
+		providerCfg.KeysTemplate != "" || len(providerCfg.ExcludeKeys) > 0 || providerCfg.CaseInsensitiveKeys {
+		// Cached values are mapped and transformed, so a mapping or transform change must invalidate them
+		keyConfig := make(map[string]interface{}, len(expandedConfig)+6)
+		for k, v := range expandedConfig {
+			keyConfig[k] = v
+		}
+		if providerCfg.ValueTransform != nil {
+			keyConfig["value_transform"] = map[string]interface{}{
+				"type":   providerCfg.ValueTransform.Type,
+				"keys":   providerCfg.ValueTransform.Keys,
+				"config": providerCfg.ValueTransform.Config,
+			}
+		}
+		if providerCfg.AsJSONKey != "" {
+			keyConfig["as_json_key"] = providerCfg.AsJSONKey
+		}
+		if len(providerCfg.Keys) > 0 {
+			keyConfig["keys"] = providerCfg.Keys
+		}
+		if providerCfg.KeysTemplate != "" {
+			keyConfig["keys_template"] = providerCfg.KeysTemplate
+		}
+		if len(providerCfg.ExcludeKeys) > 0 {
+			keyConfig["exclude_keys"] = providerCfg.ExcludeKeys
+		}
+		if providerCfg.CaseInsensitiveKeys {
+			keyConfig["case_insensitive_keys"] = true
+		}
+		configKey = cache.GenerateCacheKey(providerCfg.ID, providerCfg.Kind, keyConfig)
+	}
+	return expandedConfig, configKey
+}
+
+// secretsToKeyValues returns secrets as key-value pairs sorted by key
+func secretsToKeyValues(secrets provider.Secrets) []provider.KeyValue {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]provider.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, provider.KeyValue{Key: key, Value: secrets[key]})
+	}
+	return kvs
+}
+
+// applyValueTransform rewrites the values of a provider's secrets with its value_transform,
+// limited to the transform's 'keys' when set
+func applyValueTransform(ctx context.Context, providerCfg *config.ProviderConfig, kvs []provider.KeyValue) ([]provider.KeyValue, error) {
+	valueTransform := providerCfg.ValueTransform
+	t, err := transform.New(valueTransform.Type)
+	if err != nil {
+		return nil, err
+	}
+	transformConfig := expandConfigTemplates(valueTransform.Config)
+
+	// Track which of the transform's keys were found
+	all := len(valueTransform.Keys) == 0
+	found := make(map[string]bool, len(valueTransform.Keys))
+	for _, key := range valueTransform.Keys {
+		found[key] = false
+	}
+
+	transformed := make([]provider.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		if _, listed := found[kv.Key]; listed || all {
+			found[kv.Key] = true
+			value, err := t.Apply(ctx, transformConfig, kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s failed for key '%s': %w", t.Name(), kv.Key, err)
+			}
+			kv.Value = value
+		}
+		transformed = append(transformed, kv)
+	}
+
+	for _, key := range valueTransform.Keys {
+		if !found[key] {
+			logger.Warnf("value_transform key '%s' is not provided by provider '%s'", key, providerCfg.ID)
+		}
+	}
+	return transformed, nil
+}
+
+// SourceFiles returns the local files read by the given providers (all providers if empty),
+// for providers implementing provider.FileSource
+func (c *Collector) SourceFiles(providerIDs []string) ([]string, error) {
+	if len(providerIDs) == 0 {
+		for _, providerCfg := range c.config.Providers {
+			providerIDs = append(providerIDs, providerCfg.ID)
+		}
+	}
+
+	var files []string
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			return nil, err
+		}
+		prov, err := provider.New(providerCfg.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider '%s': %w", providerID, err)
+		}
+		if source, ok := prov.(provider.FileSource); ok {
+			files = append(files, source.SourceFiles(expandConfigTemplates(providerCfg.Config))...)
+		}
+	}
+	return files, nil
+}
+
+// expandJSONDefault returns the expand_json default from the collector option or the configuration,
+// or nil when neither sets it
+func (c *Collector) expandJSONDefault() *bool {
+	if c.expandJSON != nil {
+		return c.expandJSON
+	}
+	return c.config.ExpandJSON
+}
+
+// mergeSecret sets key to value from providerID, combining it with an earlier provider's value
+// according to the key's merge strategy. With a source_priority for the key, a value from a
+// provider ranked below the one that set the key is ignored.
+func (c *Collector) mergeSecret(secrets provider.Secrets, providerID, key, value string) {
+	c.keyProviders[key] = append(c.keyProviders[key], providerID)
+	if c.explainCollisions != nil {
+		c.keyValues[key] = append(c.keyValues[key], value)
+	}
+
+	existing, exists := secrets[key]
+	if exists && c.sourceRank(key, providerID) > c.sourceRank(key, c.keySources[key]) {
+		return
+	}
+	c.keySources[key] = providerID
+
+	strategy, configured := c.config.MergeStrategy[key]
+	if !exists || !configured {
+		secrets[key] = value
+		return
+	}
+
+	switch strategy.Strategy {
+	case config.MergeConcat:
+		secrets[key] = joinNonEmpty(strategy.Separator, existing, value)
+	case config.MergeUnique:
+		secrets[key] = uniqueItems(strategy.Separator, existing, value)
+	default:
+		secrets[key] = value
+	}
 }
 
-// CollectorOption is a functional option for configuring the Collector
-type CollectorOption func(*Collector)
-
-// WithForceAuth returns an option that forces re-authentication by ignoring cached tokens
-func WithForceAuth(forceAuth bool) CollectorOption {
-	return func(c *Collector) {
-		c.forceAuth = forceAuth
+// sourceRank returns the position of providerID in the key's source_priority.
+// Unlisted providers rank after all listed ones; without a priority every provider ranks equally.
+func (c *Collector) sourceRank(key, providerID string) int {
+	priority := c.config.SourcePriority[key]
+	for i, id := range priority {
+		if id == providerID {
+			return i
+		}
 	}
+	return len(priority)
 }
 
-// NewCollector creates a new secrets collector
-func NewCollector(cfg *config.Config, opts ...CollectorOption) *Collector {
-	collector := &Collector{config: cfg}
+// joinNonEmpty joins the non-empty values with sep
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
 
-	// Apply options
-	for _, opt := range opts {
-		opt(collector)
+// uniqueItems splits the values into items on sep and joins the distinct, non-empty items
+// in order of first appearance. Surrounding whitespace is ignored when comparing items.
+func uniqueItems(sep string, values ...string) string {
+	seen := make(map[string]bool)
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, sep) {
+			item = strings.TrimSpace(item)
+			if item == "" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			items = append(items, item)
+		}
 	}
+	return strings.Join(items, sep)
+}
 
-	// Initialize SSO client if configured
-	if cfg.SSO != nil && cfg.SSO.OIDC != nil {
-		client, err := oidc.NewClient(cfg.SSO.OIDC)
-		if err == nil {
-			collector.ssoClient = client
+// providerFor returns the provider instance for providerCfg, creating it on first use.
+// configKey identifies the provider's configuration; when it or the provider's SSO tokens
+// change, the previous instance is closed and a new one is created.
+func (c *Collector) providerFor(providerCfg *config.ProviderConfig, configKey string) (provider.Provider, error) {
+	key := c.instanceKey(providerCfg, configKey)
+
+	c.instancesMu.Lock()
+	defer c.instancesMu.Unlock()
+
+	if instance, ok := c.instances[providerCfg.ID]; ok {
+		if instance.key == key {
+			return instance.provider, nil
 		}
+		closeProvider(providerCfg.ID, instance.provider)
+		delete(c.instances, providerCfg.ID)
 	}
 
-	// Initialize cache if enabled
-	if cfg.IsCacheEnabled() {
-		cacheOpts := []cache.Option{}
-		if ttl := cfg.GetCacheTTL(); ttl > 0 {
-			cacheOpts = append(cacheOpts, cache.WithTTL(ttl))
+	prov, err := provider.New(providerCfg.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.instances == nil {
+		c.instances = make(map[string]*providerInstance)
+	}
+	c.instances[providerCfg.ID] = &providerInstance{key: key, provider: prov}
+	return prov, nil
+}
+
+// instanceKey identifies a provider instance by its configuration and the SSO tokens injected into it,
+// so that clients which logged in with a token are recreated once the token is refreshed
+func (c *Collector) instanceKey(providerCfg *config.ProviderConfig, configKey string) string {
+	identity := c.ssoIdentityFor(providerCfg)
+	if identity == nil || (identity.accessToken == "" && identity.idToken == "") {
+		return configKey
+	}
+	return cache.GenerateCacheKey(providerCfg.ID, providerCfg.Kind, map[string]interface{}{
+		"config":       configKey,
+		"access_token": identity.accessToken, // Not AccessTokenConfigKey, which cache keys ignore
+		"id_token":     identity.idToken,
+	})
+}
+
+// abandonInstance keeps a provider instance whose fetch was abandoned from being reused while the fetch
+// still runs: later collections get a new instance, and this one is closed once finished is closed
+func (c *Collector) abandonInstance(providerID string, prov provider.Provider, finished <-chan struct{}) {
+	select {
+	case <-finished:
+		return
+	default:
+	}
+
+	c.instancesMu.Lock()
+	instance, ok := c.instances[providerID]
+	owned := ok && instance.provider == prov
+	if owned {
+		delete(c.instances, providerID)
+	}
+	c.instancesMu.Unlock()
+
+	if owned {
+		go func() {
+			<-finished
+			closeProvider(providerID, prov)
+		}()
+	}
+}
+
+// Close releases the provider instances kept across collections.
+// Providers implementing provider.Closer are closed; the collector can still be used afterwards.
+func (c *Collector) Close() error {
+	c.instancesMu.Lock()
+	defer c.instancesMu.Unlock()
+
+	var errs []string
+	for id, instance := range c.instances {
+		if closer, ok := instance.provider.(provider.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Sprintf("provider '%s': %v", id, err))
+			}
 		}
-		collector.cache = cache.New(cacheOpts...)
 	}
+	c.instances = nil
 
-	return collector
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("failed to close providers: %s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
-// Collect fetches secrets from all providers and combines them
-func (c *Collector) Collect(ctx context.Context, providerIDs []string) (provider.Secrets, error) {
-	secrets := make(provider.Secrets)
-	// Track secrets by provider ID for template providers
-	providerSecrets := make(provider.ProviderSecretsMap)
+// finishCollection lets the provider instances tear down what they shared during the collection
+func (c *Collector) finishCollection() {
+	c.instancesMu.Lock()
+	defer c.instancesMu.Unlock()
 
-	// Authenticate with SSO if configured
-	if err := c.authenticateSSO(ctx); err != nil {
-		return nil, fmt.Errorf("SSO authentication failed: %w", err)
+	for _, instance := range c.instances {
+		if finisher, ok := instance.provider.(provider.CollectionFinisher); ok {
+			finisher.FinishCollection()
+		}
 	}
+}
 
-	// If no providers specified, use all providers in order
-	if len(providerIDs) == 0 {
-		for _, provider := range c.config.Providers {
-			providerIDs = append(providerIDs, provider.ID)
+// closeProvider closes a replaced provider instance, warning on failure
+func closeProvider(providerID string, prov provider.Provider) {
+	if closer, ok := prov.(provider.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Warnf("Failed to close provider '%s': %v", providerID, err)
 		}
 	}
+}
 
-	// Collect from each provider
-	for _, providerID := range providerIDs {
-		providerCfg, err := c.config.GetProvider(providerID)
+// fetchWithRetries fetches from a provider, retrying failures up to the provider's 'retries'
+// while the collection's retry budget allows it
+func (c *Collector) fetchWithRetries(ctx context.Context, prov provider.Provider, secretContext provider.SecretContext, providerCfg *config.ProviderConfig, providerConfig map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	budget := c.retryBudget
+	if budget == nil {
+		budget = newRetryBudget(c.config.RetryBudget)
+	}
+
+	// An attempt still running when the fetch gives up must not share its instance with later collections
+	var finished <-chan struct{}
+	defer func() {
+		if finished != nil {
+			c.abandonInstance(providerCfg.ID, prov, finished)
+		}
+	}()
+
+	for retry := 0; ; retry++ {
+		release, err := c.execLimiter.acquire(ctx, prov)
+		if err != nil {
+			return nil, err
+		}
+		var kvs []provider.KeyValue
+		kvs, finished, err = c.fetchOnce(prov, secretContext, providerCfg, providerConfig, keys, release)
+		if err == nil {
+			return kvs, nil
+		}
+		if retry >= providerCfg.Retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := retryDelay(providerCfg, retry+1)
+		if !budget.take(delay) {
+			return nil, fmt.Errorf("%w (retry budget exhausted after %d retries)", err, retry)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		// Wait for the previous attempt, which may have been abandoned, so the instance never fetches concurrently
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// excludeKey is the 'keys' target that excludes a source key, like listing it in 'exclude_keys'
+const excludeKey = "!"
+
+// keyMapping is a provider's 'keys' mapping and 'exclude_keys', split into what providers apply
+// themselves, key names, and what the collector applies: exclusions and patterns
+type keyMapping struct {
+	keys             map[string]string // Key names mapped to target names, nil when there are none
+	patterns         []keyPattern      // Patterns mapped to target names
+	excluded         map[string]bool   // Excluded key names
+	excludedPatterns []keyPattern      // Excluded patterns
+}
+
+// keyPattern is a 'keys' entry whose source is a glob (e.g. 'STRIPE_*') or a /regex/
+type keyPattern struct {
+	source string
+	re     *regexp.Regexp
+	target string
+}
+
+// newKeyMapping splits a provider's key mapping. An excluded key is never loaded, even when it is also mapped.
+func newKeyMapping(providerCfg *config.ProviderConfig) (*keyMapping, error) {
+	mapping := &keyMapping{excluded: make(map[string]bool, len(providerCfg.ExcludeKeys))}
+	for _, key := range providerCfg.ExcludeKeys {
+		mapping.excluded[key] = true
+	}
+
+	// Sorted, so that errors listing several patterns are stable
+	sources := make([]string, 0, len(providerCfg.Keys))
+	for source := range providerCfg.Keys {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		target := providerCfg.Keys[source]
+		if !isKeyPattern(source) {
+			if target == excludeKey {
+				mapping.excluded[source] = true
+			}
+			continue
+		}
+		re, err := compileKeyPattern(source, providerCfg.CaseInsensitiveKeys)
 		if err != nil {
 			return nil, err
 		}
+		pattern := keyPattern{source: source, re: re, target: target}
+		if target == excludeKey {
+			mapping.excludedPatterns = append(mapping.excludedPatterns, pattern)
+		} else {
+			mapping.patterns = append(mapping.patterns, pattern)
+		}
+	}
+
+	for _, source := range sources {
+		if isKeyPattern(source) || mapping.excluded[source] {
+			continue
+		}
+		if mapping.keys == nil {
+			mapping.keys = make(map[string]string, len(sources))
+		}
+		mapping.keys[source] = providerCfg.Keys[source]
+	}
+	return mapping, nil
+}
+
+// empty reports whether the mapping maps no key, so that every key that is not excluded is loaded
+func (m *keyMapping) empty() bool {
+	return len(m.keys) == 0 && len(m.patterns) == 0
+}
+
+// hasPatterns reports whether the mapping has patterns, which providers cannot apply
+func (m *keyMapping) hasPatterns() bool {
+	return len(m.patterns) > 0 || len(m.excludedPatterns) > 0
+}
+
+// dropExcluded returns kvs without the excluded keys, optionally matching key names ignoring case
+func (m *keyMapping) dropExcluded(kvs []provider.KeyValue, ignoreCase bool) []provider.KeyValue {
+	if len(m.excluded) == 0 && len(m.excludedPatterns) == 0 {
+		return kvs
+	}
+	excluded := m.excluded
+	if ignoreCase {
+		excluded = make(map[string]bool, len(m.excluded))
+		for key := range m.excluded {
+			excluded[strings.ToLower(key)] = true
+		}
+	}
+
+	kept := make([]provider.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		key := kv.Key
+		if ignoreCase {
+			key = strings.ToLower(key)
+		}
+		if excluded[key] || slices.ContainsFunc(m.excludedPatterns, func(p keyPattern) bool { return p.re.MatchString(kv.Key) }) {
+			continue
+		}
+		kept = append(kept, kv)
+	}
+	return kept
+}
 
-		// Expand template variables in config (e.g., in path fields)
-		expandedConfig := expandConfigTemplates(providerCfg.Config)
+// isKeyPattern reports whether a 'keys' source is a /regex/ or a glob rather than a key name
+func isKeyPattern(source string) bool {
+	return isRegexKeyPattern(source) || strings.ContainsAny(source, "*?[")
+}
 
-		// Generate cache key based on provider configuration
-		cacheKey := cache.GenerateCacheKey(providerID, providerCfg.Kind, expandedConfig)
+func isRegexKeyPattern(source string) bool {
+	return len(source) > 2 && strings.HasPrefix(source, "/") && strings.HasSuffix(source, "/")
+}
 
-		// Try to get secrets from cache if enabled
-		if c.cache != nil {
-			if cachedSecrets, found := c.cache.Get(cacheKey); found {
-				// Use cached secrets
-				providerSecrets[providerID] = cachedSecrets
-				for k, v := range cachedSecrets {
-					secrets[k] = v
+// compileKeyPattern compiles a 'keys' pattern into a regular expression matching whole key names.
+// In globs, every '*' and '?' is a capture group, so targets can use the matched text as $1, $2...
+func compileKeyPattern(source string, ignoreCase bool) (*regexp.Regexp, error) {
+	var expr string
+	if isRegexKeyPattern(source) {
+		expr = source[1 : len(source)-1]
+	} else {
+		var b strings.Builder
+		for i := 0; i < len(source); i++ {
+			switch c := source[i]; c {
+			case '*':
+				b.WriteString("(.*)")
+			case '?':
+				b.WriteString("(.)")
+			case '[':
+				end := strings.IndexByte(source[i+1:], ']')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid 'keys' pattern '%s': missing ']'", source)
 				}
-				continue
+				class := source[i+1 : i+1+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + class + "]")
+				i += end + 1
+			default:
+				b.WriteString(regexp.QuoteMeta(string(c)))
 			}
 		}
+		expr = b.String()
+	}
 
-		// Create provider instance
-		prov, err := provider.New(providerCfg.Kind)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create provider '%s': %w", providerID, err)
+	expr = "^(?:" + expr + ")$"
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'keys' pattern '%s': %w", source, err)
+	}
+	return re, nil
+}
+
+// matchKeyPatterns returns the target name for key from the single pattern matching it.
+// Capture groups are substituted in the target, and "==" keeps the key name.
+func matchKeyPatterns(key string, patterns []keyPattern) (string, bool, error) {
+	var matched []keyPattern
+	for _, pattern := range patterns {
+		if pattern.re.MatchString(key) {
+			matched = append(matched, pattern)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", false, nil
+	case 1:
+	default:
+		sources := make([]string, len(matched))
+		for i, pattern := range matched {
+			sources[i] = pattern.source
 		}
+		return "", false, fmt.Errorf("source key '%s' matches several 'keys' patterns: %s", key, strings.Join(sources, ", "))
+	}
+
+	pattern := matched[0]
+	if pattern.target == "==" {
+		return key, true, nil
+	}
+	target := pattern.re.ExpandString(nil, pattern.target, key, pattern.re.FindStringSubmatchIndex(key))
+	if len(target) == 0 {
+		return "", false, fmt.Errorf("'keys' pattern '%s' maps source key '%s' to an empty name", pattern.source, key)
+	}
+	return string(target), true, nil
+}
 
-		// Inject SSO tokens into provider config if available
-		c.injectTokensIntoConfig(expandedConfig)
+// renameKeys computes the name of every key by executing keysTemplate with the current name as .key
+func renameKeys(kvs []provider.KeyValue, keysTemplate string) ([]provider.KeyValue, error) {
+	tmpl, err := template.New("keys_template").Funcs(provider.TemplateFuncs()).Option("missingkey=error").Parse(keysTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keys_template: %w", err)
+	}
 
-		// Create SecretContext with resolver for providers
-		// Providers can optionally use SecretsResolver to access secrets from other providers
-		// This follows the principle of least privilege - providers only access secrets they explicitly request
-		// If 'uses' is specified, create a filtered resolver that only includes secrets from allowed providers
-		// If 'uses' is not specified, pass an empty resolver (no access to other providers' secrets)
-		var secretContext provider.SecretContext
-		if len(providerCfg.Uses) > 0 {
-			secretContext = NewSecretContext(ctx, providerSecrets, providerCfg.Uses)
-		} else {
-			// Pass empty provider secrets map when 'uses' is not defined
-			secretContext = NewEmptySecretContext(ctx)
+	renamed := make([]provider.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		var name strings.Builder
+		if err := tmpl.Execute(&name, map[string]string{"key": kv.Key}); err != nil {
+			return nil, fmt.Errorf("failed to render keys_template for key '%s': %w", kv.Key, err)
 		}
+		if name.Len() == 0 {
+			return nil, fmt.Errorf("keys_template renders an empty name for key '%s'", kv.Key)
+		}
+		renamed = append(renamed, provider.KeyValue{Key: name.String(), Value: kv.Value})
+	}
+	return renamed, nil
+}
 
-		// Fetch secrets from this provider's single source
-		kvs, err := prov.Fetch(secretContext, providerCfg.ID, expandedConfig, providerCfg.Keys)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch from provider '%s': %w", providerID, err)
+// asJSONObject serializes key-value pairs into a single JSON object stored under key
+func asJSONObject(kvs []provider.KeyValue, key string) ([]provider.KeyValue, error) {
+	object := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		object[kv.Key] = kv.Value
+	}
+
+	var encoded strings.Builder
+	if err := jsonout.Encode(&encoded, object, false); err != nil {
+		return nil, fmt.Errorf("failed to serialize secrets to '%s': %w", key, err)
+	}
+	return []provider.KeyValue{{Key: key, Value: strings.TrimSuffix(encoded.String(), "\n")}}, nil
+}
+
+// mapKeys applies a key mapping to source key-value pairs, returning the mapped pairs
+// and the sorted list of source keys that are not present in the mapping.
+// Key names take precedence over patterns.
+func mapKeys(kvs []provider.KeyValue, keys map[string]string, patterns []keyPattern) ([]provider.KeyValue, []string, error) {
+	mapped := make([]provider.KeyValue, 0, len(kvs))
+	var dropped []string
+	for _, kv := range kvs {
+		targetKey, exists := keys[kv.Key]
+		if !exists {
+			var err error
+			targetKey, exists, err = matchKeyPatterns(kv.Key, patterns)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if !exists {
+			dropped = append(dropped, kv.Key)
+			continue
+		}
+		if targetKey == "==" {
+			targetKey = kv.Key // Keep same name
 		}
+		mapped = append(mapped, provider.KeyValue{Key: targetKey, Value: kv.Value})
+	}
+	sort.Strings(dropped)
+	return mapped, dropped, nil
+}
 
-		// Store secrets by provider ID for resolver
-		providerSecrets[providerID] = make(provider.Secrets)
-		for _, kv := range kvs {
-			providerSecrets[providerID][kv.Key] = kv.Value
+// mapKeysIgnoreCase applies a key mapping like mapKeys, matching source keys against the mapping
+// ignoring case. A source key with an exact entry in the mapping always uses it; two source keys
+// matching the same entry only by case, or one matching several entries, are an error.
+// With "==" the key keeps the name written in the mapping. Source keys matching no key name are
+// matched against the patterns, which are compiled to ignore case.
+func mapKeysIgnoreCase(kvs []provider.KeyValue, keys map[string]string, patterns []keyPattern) ([]provider.KeyValue, []string, error) {
+	folded := make(map[string][]string, len(keys))
+	for key := range keys {
+		lower := strings.ToLower(key)
+		folded[lower] = append(folded[lower], key)
+	}
+	exact := make(map[string]bool, len(kvs))
+	for _, kv := range kvs {
+		if _, exists := keys[kv.Key]; exists {
+			exact[kv.Key] = true
 		}
+	}
 
-		// Cache the secrets if caching is enabled
-		if c.cache != nil {
-			_ = c.cache.Set(cacheKey, providerSecrets[providerID])
+	mapped := make([]provider.KeyValue, 0, len(kvs))
+	matchedBy := make(map[string]string)
+	var dropped []string
+	for _, kv := range kvs {
+		mappingKey := kv.Key
+		if !exact[kv.Key] {
+			var candidates []string
+			for _, key := range folded[strings.ToLower(kv.Key)] {
+				if !exact[key] {
+					candidates = append(candidates, key)
+				}
+			}
+			switch len(candidates) {
+			case 0:
+				targetKey, matched, err := matchKeyPatterns(kv.Key, patterns)
+				if err != nil {
+					return nil, nil, err
+				}
+				if matched {
+					mapped = append(mapped, provider.KeyValue{Key: targetKey, Value: kv.Value})
+				} else {
+					dropped = append(dropped, kv.Key)
+				}
+				continue
+			case 1:
+				mappingKey = candidates[0]
+			default:
+				sort.Strings(candidates)
+				return nil, nil, fmt.Errorf("source key '%s' matches several 'keys' entries ignoring case: %s", kv.Key, strings.Join(candidates, ", "))
+			}
+			if other, claimed := matchedBy[mappingKey]; claimed {
+				first, second := other, kv.Key
+				if second < first {
+					first, second = second, first
+				}
+				return nil, nil, fmt.Errorf("source keys '%s' and '%s' both match 'keys' entry '%s' ignoring case", first, second, mappingKey)
+			}
+			matchedBy[mappingKey] = kv.Key
 		}
 
-		// Merge secrets (later providers override earlier ones)
-		for _, kv := range kvs {
-			secrets[kv.Key] = kv.Value
+		targetKey := keys[mappingKey]
+		if targetKey == "==" {
+			targetKey = mappingKey // Keep the name written in the mapping
 		}
+		mapped = append(mapped, provider.KeyValue{Key: targetKey, Value: kv.Value})
 	}
+	sort.Strings(dropped)
+	return mapped, dropped, nil
+}
 
-	return secrets, nil
+// resolveAliases exposes collected provider secrets under both their id and their alias.
+// It returns the aliased view together with the allowed names for 'uses', so that a provider
+// listed by either name can be referenced by either name.
+func (c *Collector) resolveAliases(providerSecrets provider.ProviderSecretsMap, uses []string) (provider.ProviderSecretsMap, []string) {
+	aliased := make(provider.ProviderSecretsMap, len(providerSecrets))
+	for id, psecrets := range providerSecrets {
+		aliased[id] = psecrets
+	}
+
+	allowed := make([]string, 0, len(uses))
+	for _, name := range uses {
+		id := c.config.ResolveProviderID(name)
+		allowed = append(allowed, id)
+
+		providerCfg, err := c.config.GetProvider(id)
+		if err != nil || providerCfg.Alias == "" {
+			continue
+		}
+		allowed = append(allowed, providerCfg.Alias)
+		if psecrets, ok := providerSecrets[id]; ok {
+			aliased[providerCfg.Alias] = psecrets
+		}
+	}
+
+	return aliased, allowed
+}
+
+// ssoIdentity holds the SSO client of an identity and the tokens it obtained
+type ssoIdentity struct {
+	name        string       // Identity name, config.DefaultSSOIdentity for the default identity
+	client      *oidc.Client // Nil when the client could not be created
+	err         error        // Why the client could not be created
+	accessToken string
+	idToken     string
+}
+
+// newSSOIdentity creates the SSO client of the named identity of an SSO configuration
+func newSSOIdentity(name string, sso *config.SSOConfig) *ssoIdentity {
+	client, err := oidc.NewClient(sso.Identity(name), oidc.WithTokenStorage(oidc.StorageBackend(sso.TokenStorage)), oidc.WithIdentity(name))
+	return &ssoIdentity{name: name, client: client, err: err}
+}
+
+// ssoIdentityFor returns the SSO identity whose tokens a provider receives, or nil if there is none
+func (c *Collector) ssoIdentityFor(providerCfg *config.ProviderConfig) *ssoIdentity {
+	if providerCfg.SSORef == "" {
+		return c.sso[config.DefaultSSOIdentity]
+	}
+	return c.sso[providerCfg.SSORef]
+}
+
+// referencedSSOIdentities returns the names of the identities other than the default one that the given
+// providers (all providers when empty) reference with 'sso_ref', sorted
+func (c *Collector) referencedSSOIdentities(providerIDs []string) ([]string, error) {
+	if len(providerIDs) == 0 {
+		for _, providerCfg := range c.config.Providers {
+			providerIDs = append(providerIDs, providerCfg.ID)
+		}
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			// Reported when the provider is collected
+			continue
+		}
+		name := providerCfg.SSORef
+		if name == "" || name == config.DefaultSSOIdentity || seen[name] {
+			continue
+		}
+		if c.sso[name] == nil {
+			return nil, fmt.Errorf("provider '%s' references unknown sso identity '%s'", providerCfg.ID, name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
-// authenticateSSO handles SSO authentication if configured
-func (c *Collector) authenticateSSO(ctx context.Context) error {
-	if c.ssoClient == nil {
+// authenticateSSO handles SSO authentication if configured: it authenticates the default identity and
+// the identities referenced by the 'sso_ref' of the given providers (all providers when empty)
+func (c *Collector) authenticateSSO(ctx context.Context, providerIDs []string) error {
+	identities := make([]*ssoIdentity, 0, 1)
+	if identity := c.sso[config.DefaultSSOIdentity]; identity != nil && identity.client != nil {
+		identities = append(identities, identity)
+	} else if identity != nil && c.requireSSO {
+		return fmt.Errorf("--require-sso is set but the SSO client could not be created: %w", identity.err)
+	}
+
+	names, err := c.referencedSSOIdentities(providerIDs)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		identity := c.sso[name]
+		if identity.client == nil {
+			return fmt.Errorf("sso identity '%s': the SSO client could not be created: %w", name, identity.err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if len(identities) == 0 {
+		if c.requireSSO && len(c.sso) == 0 {
+			return fmt.Errorf("--require-sso is set but sso.oidc is not configured")
+		}
 		return nil
 	}
 
-	// Check if already authenticated (skip if --force-auth is set)
-	if !c.forceAuth && c.ssoClient.IsAuthenticated() {
-		// Try to get the access token
-		token, err := c.ssoClient.GetAccessToken(ctx)
-		if err == nil {
-			c.accessToken = token
-			// Also get ID token if available
-			tokens, err := c.ssoClient.GetTokens()
-			if err == nil && tokens.IDToken != "" {
-				c.idToken = tokens.IDToken
+	if err := oidc.ValidateAuthFlow(c.authFlow); err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		if err := c.authenticateIdentity(ctx, identity); err != nil {
+			if identity.name != config.DefaultSSOIdentity {
+				return fmt.Errorf("sso identity '%s': %w", identity.name, err)
 			}
+			return err
+		}
+	}
+	return nil
+}
+
+// authenticateIdentity obtains the tokens of an SSO identity: stored tokens, refreshed if they expired,
+// or a new login
+func (c *Collector) authenticateIdentity(ctx context.Context, identity *ssoIdentity) error {
+	client := identity.client
+
+	// A keyring that does not respond (e.g. because it is locked) hides the stored tokens;
+	// report it rather than starting a new login
+	if !c.forceAuth && !client.HasClientCredentials() {
+		if err := client.StorageError(); err != nil {
+			return err
+		}
+	}
+
+	if c.requireSSO && !c.forceAuth && !client.TokensExist() && !client.HasClientCredentials() {
+		return fmt.Errorf("--require-sso is set but no SSO tokens were found; %s", ssoLoginHint)
+	}
+
+	// Use the stored tokens, refreshing them if they expired (skip if --force-auth is set)
+	var refreshErr error
+	if !c.forceAuth && client.TokensExist() {
+		tokens, err := client.RefreshIfExpired(ctx)
+		if err == nil {
+			identity.accessToken = tokens.AccessToken
+			identity.idToken = tokens.IDToken
 			return nil
 		}
-		// Token expired or invalid, need to re-authenticate
+		// Tokens expired and could not be refreshed, need to re-authenticate
+		refreshErr = err
+	}
+
+	// With --require-sso, never start an interactive login; a client credentials login
+	// is non-interactive and either yields a token or fails
+	if c.requireSSO && !c.forceAuth && !client.HasClientCredentials() {
+		return fmt.Errorf("--require-sso is set but the SSO token is expired and could not be refreshed (%v); %s", refreshErr, ssoLoginHint)
 	}
 
 	// If client credentials are configured, use client credentials flow (non-interactive)
 	// This is for CI/CD and service accounts - never fall back to browser
-	if c.ssoClient.HasClientCredentials() {
-		result, err := c.ssoClient.LoginWithClientCredentials(ctx)
+	if client.HasClientCredentials() {
+		result, err := client.LoginWithClientCredentials(ctx)
 		if err != nil {
 			return fmt.Errorf("client credentials authentication failed: %w", err)
 		}
 		// Store tokens
 		if result.Tokens != nil {
-			c.accessToken = result.Tokens.AccessToken
-			c.idToken = result.Tokens.IDToken
+			identity.accessToken = result.Tokens.AccessToken
+			identity.idToken = result.Tokens.IDToken
 		}
 		return nil
 	}
 
-	// No client secret configured - use interactive login flow (browser-based)
-	result, err := c.ssoClient.Login(ctx)
+	// No client secret configured - use interactive login flow (browser or device)
+	result, err := client.LoginWithFlow(ctx, c.authFlow)
 	if err != nil {
 		return err
 	}
 
 	// Store tokens
 	if result.Tokens != nil {
-		c.accessToken = result.Tokens.AccessToken
-		c.idToken = result.Tokens.IDToken
+		identity.accessToken = result.Tokens.AccessToken
+		identity.idToken = result.Tokens.IDToken
 	}
 
 	return nil
 }
 
-// injectTokensIntoConfig adds SSO tokens to the provider config for provider authentication
-func (c *Collector) injectTokensIntoConfig(config map[string]interface{}) {
-	if c.accessToken != "" {
-		config[AccessTokenConfigKey] = c.accessToken
+// injectTokensIntoConfig adds the tokens of the provider's SSO identity to the provider config for
+// provider authentication
+func (c *Collector) injectTokensIntoConfig(providerCfg *config.ProviderConfig, config map[string]interface{}) {
+	identity := c.ssoIdentityFor(providerCfg)
+	if identity == nil {
+		return
 	}
-	if c.idToken != "" {
-		config[IDTokenConfigKey] = c.idToken
+	if identity.accessToken != "" {
+		config[AccessTokenConfigKey] = identity.accessToken
+	}
+	if identity.idToken != "" {
+		config[IDTokenConfigKey] = identity.idToken
 	}
 }
 
@@ -267,9 +1805,8 @@ func expandTemplate(template string) string {
 		return match
 	})
 
-	// Also support simple ${VAR} or $VAR syntax
-	result = os.ExpandEnv(result)
-
+	// ${VAR} and $VAR are expanded when the configuration is loaded (see config.ExpandEnv),
+	// expanding them again would undo $$ escapes
 	return result
 }
 
@@ -286,6 +1823,28 @@ func Redact(text string, secrets provider.Secrets) string {
 	return result
 }
 
+// MinArgSecretLength is the shortest secret value FindSecretsInArgs looks for,
+// so that trivial values like "1" or "true" do not produce false positives
+const MinArgSecretLength = 4
+
+// FindSecretsInArgs returns the sorted keys of secrets whose values appear in any of args
+func FindSecretsInArgs(args []string, secrets provider.Secrets) []string {
+	var found []string
+	for key, value := range secrets {
+		if len(value) < MinArgSecretLength {
+			continue
+		}
+		for _, arg := range args {
+			if strings.Contains(arg, value) {
+				found = append(found, key)
+				break
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
 // Mask masks a secret value, showing only first and last characters
 func Mask(value string) string {
 	if len(value) <= 4 {
@@ -309,3 +1868,58 @@ func (c *Collector) ClearCache() error {
 func (c *Collector) GetCache() *cache.Cache {
 	return c.cache
 }
+
+// fetchOnce calls the provider's Fetch once. Fetch gets a derived context, bounded by the timeout if any,
+// and the call is abandoned once the timeout expires or the collection is cancelled, even if the provider
+// does not watch its context. release is called once Fetch has actually returned, and the returned
+// channel is closed then, so an abandoned call keeps its exec slot until it is done.
+func (c *Collector) fetchOnce(prov provider.Provider, secretContext provider.SecretContext, providerCfg *config.ProviderConfig, providerConfig map[string]interface{}, keys map[string]string, release func()) ([]provider.KeyValue, <-chan struct{}, error) {
+	timeout := providerCfg.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+
+	var fetchCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		fetchCtx, cancel = context.WithTimeout(secretContext.Ctx, timeout)
+	} else {
+		fetchCtx, cancel = context.WithCancel(secretContext.Ctx)
+	}
+	defer cancel()
+	attemptContext := secretContext
+	attemptContext.Ctx = fetchCtx
+
+	type fetchReturn struct {
+		kvs []provider.KeyValue
+		err error
+	}
+	done := make(chan fetchReturn, 1)
+	finished := make(chan struct{})
+	started := time.Now()
+	go func() {
+		defer close(finished)
+		defer release()
+		kvs, err := prov.Fetch(attemptContext, providerCfg.ID, providerConfig, keys)
+		done <- fetchReturn{kvs: kvs, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		<-finished
+		if result.err != nil && timeout > 0 && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+			return nil, finished, timeoutError(started, timeout)
+		}
+		return result.kvs, finished, result.err
+	case <-fetchCtx.Done():
+		if timeout > 0 && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+			return nil, finished, timeoutError(started, timeout)
+		}
+		return nil, finished, fetchCtx.Err()
+	}
+}
+
+// timeoutError reports a fetch attempt that exceeded its timeout, with the time it ran
+func timeoutError(started time.Time, timeout time.Duration) error {
+	return fmt.Errorf("timed out after %s (timeout %s): %w", time.Since(started).Round(time.Millisecond), timeout, context.DeadlineExceeded)
+}
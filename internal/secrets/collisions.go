@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// collisionFailure is a key collision that failed a provider or the collection
+type collisionFailure struct {
+	provider  string // Provider that failed, empty when the collection failed after merging
+	collision *provider.KeyCollisionError
+}
+
+// recordCollisionFailure keeps the key collision behind err, if any, for the collision report
+func (c *Collector) recordCollisionFailure(providerID string, err error) {
+	var collision *provider.KeyCollisionError
+	if c.explainCollisions == nil || !errors.As(err, &collision) {
+		return
+	}
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+	c.collisionFailures = append(c.collisionFailures, collisionFailure{provider: providerID, collision: collision})
+}
+
+// writeCollisionReport writes every key supplied by several providers during the last collection,
+// with the providers' value lengths and masked previews and the reason a value was kept,
+// followed by the key collisions that made a provider or the collection fail
+func (c *Collector) writeCollisionReport(w io.Writer) {
+	var keys []string
+	for key, providerIDs := range c.keyProviders {
+		if len(providerIDs) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 && len(c.collisionFailures) == 0 {
+		_, _ = io.WriteString(w, "Key collision report: no key collisions\n")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Key collision report:\n")
+	for _, key := range keys {
+		winner := c.keySources[key]
+		strategy, configured := c.config.MergeStrategy[key]
+		merged := configured && strategy.Strategy != config.MergeOverride
+		fmt.Fprintf(&b, "  %s: supplied by %d providers, %s\n", key, len(c.keyProviders[key]), c.collisionOutcome(key, winner))
+		for i, providerID := range c.keyProviders[key] {
+			value := c.keyValues[key][i]
+			marker := ""
+			if providerID == winner && !merged {
+				marker = " (kept)"
+			}
+			fmt.Fprintf(&b, "    - %s: length %d, %s%s\n", providerID, len(value), Mask(value), marker)
+		}
+	}
+	for _, failure := range c.collisionFailures {
+		if failure.provider != "" {
+			fmt.Fprintf(&b, "  %s: provider '%s' failed: %s\n", failure.collision.Key, failure.provider, failure.collision.Detail)
+		} else {
+			fmt.Fprintf(&b, "  %s: collection failed: %s\n", failure.collision.Key, failure.collision.Detail)
+		}
+		for _, source := range failure.collision.Sources {
+			fmt.Fprintf(&b, "    - %s\n", source)
+		}
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+// collisionOutcome explains how the values of a key supplied by several providers were resolved
+func (c *Collector) collisionOutcome(key, winner string) string {
+	if strategy, configured := c.config.MergeStrategy[key]; configured && strategy.Strategy != config.MergeOverride {
+		return fmt.Sprintf("values combined with the '%s' merge strategy", strategy.Strategy)
+	}
+	if priority := c.config.SourcePriority[key]; len(priority) > 0 && c.sourceRank(key, winner) < len(priority) {
+		return fmt.Sprintf("kept '%s': it ranks highest in source_priority", winner)
+	}
+	return fmt.Sprintf("kept '%s': it comes last in configuration order", winner)
+}
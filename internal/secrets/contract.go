@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/jsonout"
+)
+
+// ContractVersion is the version of the output contract format
+const ContractVersion = 1
+
+// Value types inferred for the output contract
+const (
+	ContractTypeString = "string"
+	ContractTypeNumber = "number"
+	ContractTypeBool   = "bool"
+	ContractTypeJSON   = "json"
+)
+
+// Contract describes the shape of a collected secret set without any values,
+// so downstream services can check they received the keys they expect
+type Contract struct {
+	Version int           `json:"version"`
+	Keys    []ContractKey `json:"keys"`
+}
+
+// ContractKey describes one key of the contract
+type ContractKey struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// BuildContract returns the contract of a collected secret set, with keys sorted by name
+func BuildContract(secrets map[string]string) Contract {
+	contract := Contract{Version: ContractVersion, Keys: make([]ContractKey, 0, len(secrets))}
+	for key, value := range secrets {
+		contract.Keys = append(contract.Keys, ContractKey{Name: key, Type: InferType(value)})
+	}
+	sort.Slice(contract.Keys, func(i, j int) bool {
+		return contract.Keys[i].Name < contract.Keys[j].Name
+	})
+	return contract
+}
+
+// InferType guesses the type of a secret value: bool for true/false, number for numeric values,
+// json for JSON objects and arrays, and string otherwise
+func InferType(value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case trimmed == "":
+		return ContractTypeString
+	case strings.EqualFold(trimmed, "true") || strings.EqualFold(trimmed, "false"):
+		return ContractTypeBool
+	case isNumber(trimmed):
+		return ContractTypeNumber
+	case (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)):
+		return ContractTypeJSON
+	default:
+		return ContractTypeString
+	}
+}
+
+// isNumber reports whether s is a finite decimal number
+func isNumber(s string) bool {
+	// ParseFloat also accepts Inf, NaN and hex floats, which are not plain numbers
+	if strings.ContainsAny(s, "xXnN") {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// WriteContract stores the contract of a collected secret set as JSON, indented when pretty is set
+func WriteContract(path string, secrets map[string]string, pretty bool) error {
+	var buf bytes.Buffer
+	if err := jsonout.Encode(&buf, BuildContract(secrets), pretty); err != nil {
+		return fmt.Errorf("failed to marshal contract: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write contract file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// Event types emitted during a collection
+const (
+	EventProviderStart  = "provider_start"
+	EventProviderFinish = "provider_finish"
+)
+
+// Outcomes of a provider_finish event
+const (
+	OutcomeFetched = "fetched" // Secrets were fetched from the provider
+	OutcomeCached  = "cached"  // Secrets came from the cache or a provider sharing the same cache_key
+	OutcomeSkipped = "skipped" // The provider's 'requires' condition did not hold
+	OutcomeError   = "error"   // The provider failed
+)
+
+// Event describes access to a provider during a collection. It never contains secret values.
+type Event struct {
+	Time       time.Time         `json:"time"`
+	Type       string            `json:"type"`
+	Provider   string            `json:"provider"`
+	Kind       string            `json:"kind"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Outcome    string            `json:"outcome,omitempty"`
+	Keys       []string          `json:"keys,omitempty"`
+	DurationMs int64             `json:"duration_ms,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// EventHandler receives collection events as they happen
+type EventHandler func(Event)
+
+// JSONEventWriter returns an event handler writing each event to w as one line of JSON
+func JSONEventWriter(w io.Writer) EventHandler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	return func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = encoder.Encode(event)
+	}
+}
+
+// emit sends an event to the registered handlers
+func (c *Collector) emit(event Event) {
+	if len(c.eventHandlers) == 0 {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	// Providers are fetched concurrently; handlers receive one event at a time
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	for _, handler := range c.eventHandlers {
+		handler(event)
+	}
+}
+
+// emitFinish sends the provider_finish event of a provider with the names of the keys it provided
+func (c *Collector) emitFinish(providerCfg *config.ProviderConfig, outcome string, started time.Time, providerSecrets provider.Secrets, err error) {
+	event := Event{
+		Type:     EventProviderFinish,
+		Provider: providerCfg.ID,
+		Kind:     providerCfg.Kind,
+		Labels:   providerCfg.Labels,
+		Outcome:  outcome,
+	}
+	if !started.IsZero() {
+		event.DurationMs = time.Since(started).Milliseconds()
+	}
+	if err != nil {
+		event.Outcome = OutcomeError
+		event.Error = err.Error()
+	} else {
+		for key := range providerSecrets {
+			event.Keys = append(event.Keys, key)
+		}
+		sort.Strings(event.Keys)
+	}
+	c.emit(event)
+}
@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"context"
+
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// DefaultMaxExecProviders is the default number of process-spawning providers that may fetch at once
+const DefaultMaxExecProviders = 2
+
+// execLimiter bounds how many process-spawning providers fetch at the same time
+type execLimiter struct {
+	slots chan struct{}
+}
+
+// newExecLimiter creates a limiter allowing max concurrent fetches; zero or less is unlimited
+func newExecLimiter(max int) *execLimiter {
+	if max <= 0 {
+		return &execLimiter{}
+	}
+	return &execLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire waits for a slot if prov spawns processes and returns the function that releases it
+func (l *execLimiter) acquire(ctx context.Context, prov provider.Provider) (func(), error) {
+	spawner, ok := prov.(provider.ProcessSpawner)
+	if l == nil || l.slots == nil || !ok || !spawner.SpawnsProcesses() {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// concurrencyStub records how many fetches run at the same time
+type concurrencyStub struct {
+	spawns  bool
+	delay   time.Duration // How long a fetch takes, ignoring its context (default 20ms)
+	mu      sync.Mutex
+	running int
+	peak    int
+	calls   int
+}
+
+func (p *concurrencyStub) Name() string { return "concurrency_stub" }
+
+func (p *concurrencyStub) SpawnsProcesses() bool { return p.spawns }
+
+func (p *concurrencyStub) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	p.mu.Lock()
+	p.running++
+	p.calls++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+	p.mu.Unlock()
+
+	delay := p.delay
+	if delay == 0 {
+		delay = 20 * time.Millisecond
+	}
+	time.Sleep(delay)
+
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+	return []provider.KeyValue{{Key: "KEY", Value: "value"}}, nil
+}
+
+// fetchConcurrently runs n fetches from prov at the same time through the collector
+func fetchConcurrently(t *testing.T, c *Collector, prov provider.Provider, n int) {
+	t.Helper()
+	ctx := context.Background()
+	providerCfg := &config.ProviderConfig{ID: "stub"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.fetchWithRetries(ctx, prov, NewEmptySecretContext(ctx), providerCfg, map[string]interface{}{}, nil); err != nil {
+				t.Errorf("fetch failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMaxExecProviders(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []CollectorOption
+		spawns   bool
+		wantPeak int
+	}{
+		{name: "default limit", spawns: true, wantPeak: DefaultMaxExecProviders},
+		{name: "custom limit", opts: []CollectorOption{WithMaxExecProviders(3)}, spawns: true, wantPeak: 3},
+		{name: "unlimited", opts: []CollectorOption{WithMaxExecProviders(0)}, spawns: true, wantPeak: 6},
+		{name: "non-spawning provider", spawns: false, wantPeak: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector(&config.Config{}, tt.opts...)
+			stub := &concurrencyStub{spawns: tt.spawns}
+
+			fetchConcurrently(t, c, stub, 6)
+
+			if tt.wantPeak < 6 && stub.peak > tt.wantPeak {
+				t.Errorf("expected at most %d concurrent fetches, got %d", tt.wantPeak, stub.peak)
+			}
+			if tt.wantPeak == 6 && stub.peak < 2 {
+				t.Errorf("expected fetches to run concurrently, got peak %d", stub.peak)
+			}
+		})
+	}
+}
+
+func TestExecLimiterHonorsContext(t *testing.T) {
+	limiter := newExecLimiter(1)
+	stub := &concurrencyStub{spawns: true}
+
+	release, err := limiter.acquire(context.Background(), stub)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.acquire(ctx, stub); err == nil {
+		t.Error("expected acquire to fail once the context is cancelled")
+	}
+}
+
+func TestAbandonedFetchHoldsExecSlot(t *testing.T) {
+	c := NewCollector(&config.Config{}, WithMaxExecProviders(1))
+	stub := &concurrencyStub{spawns: true, delay: 50 * time.Millisecond}
+	providerCfg := &config.ProviderConfig{ID: "stub", Timeout: 10 * time.Millisecond, Retries: 2, RetryDelay: time.Millisecond}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.fetchWithRetries(ctx, stub, NewEmptySecretContext(ctx), providerCfg, map[string]interface{}{}, nil); err == nil {
+				t.Error("expected every attempt to time out")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The last abandoned fetch holds the slot until it returns
+	release, err := c.execLimiter.acquire(ctx, stub)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	release()
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if stub.calls != 6 {
+		t.Errorf("expected 6 fetch attempts, got %d", stub.calls)
+	}
+	if stub.peak > 1 {
+		t.Errorf("expected abandoned fetches to keep their exec slot, got %d concurrent fetches", stub.peak)
+	}
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FingerprintPrefix identifies the hash algorithm used for fingerprints
+const FingerprintPrefix = "sha256:"
+
+// Fingerprint returns a stable hash of a collected secret set.
+// It changes whenever a key is added, removed or its value changes, without revealing any value.
+func Fingerprint(secrets map[string]string) string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		// NUL separators keep "A"+"BC" distinct from "AB"+"C"
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(secrets[key]))
+		h.Write([]byte{0})
+	}
+	return FingerprintPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// ValueFingerprint returns a short hash of a single value, to tell values apart without revealing them
+func ValueFingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return FingerprintPrefix + hex.EncodeToString(sum[:])[:12]
+}
+
+// ReadBaseline reads a fingerprint from a baseline file.
+// It returns an empty string without error if the file does not exist.
+func ReadBaseline(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	baseline := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(baseline, FingerprintPrefix) {
+		return "", fmt.Errorf("baseline file %s does not contain a fingerprint", path)
+	}
+	return baseline, nil
+}
+
+// WriteBaseline stores a fingerprint in a baseline file
+func WriteBaseline(path, fingerprint string) error {
+	if err := os.WriteFile(path, []byte(fingerprint+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// Statuses of a ProbeResult
+const (
+	ProbeOK          = "ok"          // The provider's backend is reachable
+	ProbeFailed      = "failed"      // The probe failed
+	ProbeUnsupported = "unsupported" // The provider kind cannot be probed
+)
+
+// ProbeResult is the outcome of probing one provider. It never contains secret values.
+type ProbeResult struct {
+	Provider string
+	Kind     string
+	Status   string
+	Latency  time.Duration // Duration of the probe, zero when unsupported
+	Detail   string        // What the probe checked, when it succeeded
+	Err      error
+}
+
+// Ping checks that the backends of the given providers (all providers when empty) are reachable,
+// concurrently, with the providers implementing provider.Prober. No secret values are fetched.
+// It authenticates with SSO first, since providers may need its tokens. Results are returned in
+// the order of providerIDs.
+func (c *Collector) Ping(ctx context.Context, providerIDs []string) ([]ProbeResult, error) {
+	if len(providerIDs) == 0 {
+		for _, providerCfg := range c.config.Providers {
+			providerIDs = append(providerIDs, providerCfg.ID)
+		}
+	}
+
+	providerCfgs := make([]*config.ProviderConfig, 0, len(providerIDs))
+	for _, providerID := range providerIDs {
+		providerCfg, err := c.config.GetProvider(providerID)
+		if err != nil {
+			return nil, err
+		}
+		providerCfgs = append(providerCfgs, providerCfg)
+	}
+
+	if err := c.authenticateSSO(ctx, providerIDs); err != nil {
+		return nil, fmt.Errorf("SSO authentication failed: %w", err)
+	}
+
+	results := make([]ProbeResult, len(providerCfgs))
+	var wg sync.WaitGroup
+	for i, providerCfg := range providerCfgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.probeProvider(ctx, providerCfg)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// probeProvider probes one provider within its fetch timeout
+func (c *Collector) probeProvider(ctx context.Context, providerCfg *config.ProviderConfig) ProbeResult {
+	result := ProbeResult{Provider: providerCfg.ID, Kind: providerCfg.Kind}
+
+	expandedConfig, configKey := c.providerConfig(providerCfg)
+	prov, err := c.providerFor(providerCfg, configKey)
+	if err != nil {
+		result.Status, result.Err = ProbeFailed, err
+		return result
+	}
+	prober, ok := prov.(provider.Prober)
+	if !ok {
+		result.Status = ProbeUnsupported
+		return result
+	}
+	c.injectTokensIntoConfig(providerCfg, expandedConfig)
+
+	timeout := providerCfg.Timeout
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	result.Detail, result.Err = prober.Probe(NewEmptySecretContext(ctx), expandedConfig)
+	result.Latency = time.Since(started)
+	result.Status = ProbeOK
+	if result.Err != nil {
+		result.Status, result.Detail = ProbeFailed, ""
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/config"
+)
+
+// KeyProvenance records which provider supplied a collected key
+type KeyProvenance struct {
+	Key        string   // Final key name
+	Provider   string   // Provider whose value was kept (empty if the key was added by a post-processor)
+	Overridden []string // Other providers that supplied the key, in collection order
+	Merged     bool     // The values of the other providers were combined by a merge strategy
+}
+
+// String formats the provenance as one diff-friendly line, e.g. "DB_PASSWORD vault (overrides dotenv)"
+func (p KeyProvenance) String() string {
+	source := p.Provider
+	if source == "" {
+		source = "(post-processor)"
+	}
+	line := p.Key + " " + source
+	if len(p.Overridden) > 0 {
+		verb := "overrides"
+		if p.Merged {
+			verb = "merged with"
+		}
+		line += " (" + verb + " " + strings.Join(p.Overridden, ", ") + ")"
+	}
+	return line
+}
+
+// Provenance returns the provider of every key from the last collection, sorted by key.
+// It never contains secret values.
+func (c *Collector) Provenance() []KeyProvenance {
+	provenance := make([]KeyProvenance, len(c.provenance))
+	copy(provenance, c.provenance)
+	return provenance
+}
+
+// buildProvenance records the provenance of the final secrets of a collection
+func (c *Collector) buildProvenance(secrets map[string]string) {
+	// Final keys may have been uppercased after merging
+	sourceKeys := make(map[string]string, len(c.keyProviders))
+	for key := range c.keyProviders {
+		sourceKeys[key] = key
+		if c.config.UppercaseKeys {
+			sourceKeys[strings.ToUpper(key)] = key
+		}
+	}
+
+	c.provenance = make([]KeyProvenance, 0, len(secrets))
+	for key := range secrets {
+		entry := KeyProvenance{Key: key}
+		if sourceKey, ok := sourceKeys[key]; ok {
+			entry.Provider = c.keySources[sourceKey]
+			strategy, configured := c.config.MergeStrategy[sourceKey]
+			entry.Merged = configured && strategy.Strategy != config.MergeOverride
+			for _, providerID := range c.keyProviders[sourceKey] {
+				if providerID != entry.Provider {
+					entry.Overridden = append(entry.Overridden, providerID)
+				}
+			}
+		}
+		c.provenance = append(c.provenance, entry)
+	}
+	sort.Slice(c.provenance, func(i, j int) bool {
+		return c.provenance[i].Key < c.provenance[j].Key
+	})
+}
+
+// writeTraceFile writes the provenance of the last collection to path, one key per line
+func (c *Collector) writeTraceFile(path string) error {
+	var b strings.Builder
+	for _, entry := range c.provenance {
+		b.WriteString(entry.String())
+		b.WriteString("\n")
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return nil
+}
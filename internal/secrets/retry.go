@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+)
+
+// retryBudget tracks the retries left for one collection across all providers
+type retryBudget struct {
+	mu          sync.Mutex
+	limitCount  bool
+	retriesLeft int
+	limitWait   bool
+	waitLeft    time.Duration
+}
+
+// newRetryBudget creates a budget from the configuration; a nil configuration is unlimited
+func newRetryBudget(cfg *config.RetryBudgetConfig) *retryBudget {
+	budget := &retryBudget{}
+	if cfg == nil {
+		return budget
+	}
+	if cfg.MaxRetries > 0 {
+		budget.limitCount = true
+		budget.retriesLeft = cfg.MaxRetries
+	}
+	if cfg.MaxWait > 0 {
+		budget.limitWait = true
+		budget.waitLeft = cfg.MaxWait
+	}
+	return budget
+}
+
+// take reserves one retry preceded by the given delay.
+// It returns false, reserving nothing, if the budget cannot cover it.
+func (b *retryBudget) take(delay time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limitCount && b.retriesLeft <= 0 {
+		return false
+	}
+	if b.limitWait && delay > b.waitLeft {
+		return false
+	}
+
+	if b.limitCount {
+		b.retriesLeft--
+	}
+	if b.limitWait {
+		b.waitLeft -= delay
+	}
+	return true
+}
+
+// retryDelay returns the delay before the given retry (1-based), doubling from the provider's base delay
+func retryDelay(providerCfg *config.ProviderConfig, retry int) time.Duration {
+	delay := providerCfg.RetryDelay
+	if delay <= 0 {
+		delay = config.DefaultRetryDelay
+	}
+	return delay << (retry - 1)
+}
+
+// sleepContext waits for the delay or until the context is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+)
+
+// DefaultConcurrency is the default number of providers fetched at the same time
+const DefaultConcurrency = 4
+
+// outcomeBlocked records a provider that was not collected because one of its prerequisites failed
+const outcomeBlocked = "blocked"
+
+// fetchResult is the outcome of collecting one provider of a plan
+type fetchResult struct {
+	kvs     []provider.KeyValue
+	outcome string
+	err     error
+	// The provider was not collected because one of its prerequisites failed
+	blocked bool
+}
+
+// fetchAll collects the providers of plan, up to the collector's concurrency at the same time.
+// A provider starts once its prerequisites have finished, and is not collected if one of them failed.
+// Whenever a slot is free, the earliest provider of the plan that is ready starts, so providers start
+// in plan order unless they wait for a prerequisite. Results are returned in plan order.
+func (c *Collector) fetchAll(ctx context.Context, plan []*config.ProviderConfig, providerSecrets provider.ProviderSecretsMap) []fetchResult {
+	prerequisites := c.prerequisites(plan)
+	results := make([]fetchResult, len(plan))
+	started := make([]bool, len(plan))
+	finished := make([]bool, len(plan))
+	completed := make(chan int)
+
+	// nextReady returns the earliest provider that has not started and whose prerequisites have finished,
+	// or -1 if there is none. blocked reports that one of its prerequisites failed or was blocked.
+	nextReady := func() (next int, blocked bool) {
+		for i := range plan {
+			if started[i] {
+				continue
+			}
+			ready := true
+			for _, j := range prerequisites[i] {
+				if !finished[j] {
+					ready = false
+					break
+				}
+				blocked = blocked || results[j].err != nil || results[j].blocked
+			}
+			if ready {
+				return i, blocked
+			}
+			blocked = false
+		}
+		return -1, false
+	}
+
+	running, remaining := 0, len(plan)
+	for remaining > 0 {
+		for c.concurrency <= 0 || running < c.concurrency {
+			i, blocked := nextReady()
+			if i < 0 {
+				break
+			}
+			started[i] = true
+			if blocked || ctx.Err() != nil {
+				if blocked {
+					results[i].blocked = true
+					// With strict_uses, a failed 'uses' provider fails the provider itself
+					results[i].err = c.strictUsesError(plan[i])
+					c.recordOutcome(plan[i].ID, outcomeBlocked)
+				} else {
+					results[i].err = fmt.Errorf("failed to fetch from provider '%s': %w", plan[i].ID, ctx.Err())
+				}
+				finished[i] = true
+				remaining--
+				continue
+			}
+			running++
+			go func(i int) {
+				results[i] = c.collectProvider(ctx, plan, i, providerSecrets)
+				completed <- i
+			}(i)
+		}
+		// Prerequisites always come earlier in the plan, so a provider is running whenever any is left
+		if running == 0 {
+			break
+		}
+		i := <-completed
+		finished[i] = true
+		running--
+		remaining--
+	}
+
+	return results
+}
+
+// prerequisites returns, for every provider of plan, the indices of the earlier providers it must wait for:
+// the providers in its 'depends_on', 'requires' and 'uses', the providers sharing its cache_key (fetched once),
+// and, for providers reading user input, the previous such provider so prompts keep their order
+func (c *Collector) prerequisites(plan []*config.ProviderConfig) [][]int {
+	readsInput := make([]bool, len(plan))
+	for i, providerCfg := range plan {
+		if prov, err := provider.New(providerCfg.Kind); err == nil {
+			reader, ok := prov.(provider.InputReader)
+			readsInput[i] = ok && reader.ReadsInput()
+		}
+	}
+
+	prerequisites := make([][]int, len(plan))
+	for i, providerCfg := range plan {
+		waitFor := make(map[string]bool, len(providerCfg.DependsOn)+len(providerCfg.Uses)+1)
+		for _, dep := range providerCfg.DependsOn {
+			waitFor[dep] = true
+		}
+		for _, name := range providerCfg.Uses {
+			waitFor[c.config.ResolveProviderID(name)] = true
+		}
+		if providerCfg.Requires != nil {
+			waitFor[providerCfg.Requires.Provider] = true
+		}
+
+		for j, earlier := range plan[:i] {
+			sharesCacheKey := providerCfg.CacheKey != "" && earlier.CacheKey == providerCfg.CacheKey
+			if waitFor[earlier.ID] || sharesCacheKey || readsInput[i] && readsInput[j] {
+				prerequisites[i] = append(prerequisites[i], j)
+			}
+		}
+	}
+	return prerequisites
+}
@@ -0,0 +1,155 @@
+package gcpkms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dirathea/sstart/internal/provider/gcsm"
+	"github.com/dirathea/sstart/internal/transform"
+	"google.golang.org/api/cloudkms/v1"
+	"google.golang.org/api/option"
+)
+
+// DefaultLocation is the KMS location used when 'location' is not set
+const DefaultLocation = "global"
+
+// KMSConfig represents the configuration for the gcp_kms_decrypt value transform
+type KMSConfig struct {
+	// ProjectID is the GCP project ID holding the key ring (required unless key_name is a full resource name)
+	ProjectID string `json:"project_id,omitempty" yaml:"project_id,omitempty"`
+	// Location is the KMS location of the key ring (optional, defaults to "global")
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	// KeyRing is the name of the key ring (required unless key_name is a full resource name)
+	KeyRing string `json:"keyring,omitempty" yaml:"keyring,omitempty"`
+	// KeyName is the name of the crypto key, or its full resource name
+	// (projects/<project>/locations/<location>/keyRings/<keyring>/cryptoKeys/<key>) (required)
+	KeyName string `json:"key_name" yaml:"key_name"`
+	// Endpoint is a custom endpoint URL for Cloud KMS (optional, for local testing; defaults to SSTART_GCP_ENDPOINT)
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+}
+
+// KMSDecrypt decrypts base64-encoded ciphertext values with a Cloud KMS symmetric key
+type KMSDecrypt struct {
+	service *cloudkms.Service
+}
+
+func init() {
+	transform.Register("gcp_kms_decrypt", func() transform.Transform {
+		return &KMSDecrypt{}
+	})
+}
+
+// Name returns the transform name
+func (t *KMSDecrypt) Name() string {
+	return "gcp_kms_decrypt"
+}
+
+// Apply decrypts a base64-encoded ciphertext value
+func (t *KMSDecrypt) Apply(ctx context.Context, config map[string]interface{}, value string) (string, error) {
+	cfg, err := parseConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("invalid gcp_kms_decrypt configuration: %w", err)
+	}
+
+	keyName, err := cfg.ResourceName()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext := strings.TrimSpace(value)
+	if _, err := base64.StdEncoding.DecodeString(ciphertext); err != nil {
+		return "", fmt.Errorf("value is not base64-encoded ciphertext: %w", err)
+	}
+
+	if err := t.ensureService(ctx, cfg.Endpoint); err != nil {
+		return "", fmt.Errorf("failed to initialize Cloud KMS client: %w", err)
+	}
+
+	resp, err := t.service.Projects.Locations.KeyRings.CryptoKeys.Decrypt(keyName, &cloudkms.DecryptRequest{
+		Ciphertext: ciphertext,
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with Cloud KMS key '%s': %w", keyName, err)
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode Cloud KMS plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// ResourceName returns the full resource name of the crypto key
+func (c *KMSConfig) ResourceName() (string, error) {
+	if c.KeyName == "" {
+		return "", fmt.Errorf("gcp_kms_decrypt requires 'key_name' field in configuration")
+	}
+	if strings.HasPrefix(c.KeyName, "projects/") {
+		return c.KeyName, nil
+	}
+	if c.ProjectID == "" {
+		return "", fmt.Errorf("gcp_kms_decrypt requires 'project_id' field in configuration unless 'key_name' is a full resource name")
+	}
+	if c.KeyRing == "" {
+		return "", fmt.Errorf("gcp_kms_decrypt requires 'keyring' field in configuration unless 'key_name' is a full resource name")
+	}
+
+	location := c.Location
+	if location == "" {
+		location = DefaultLocation
+	}
+	return fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s", c.ProjectID, location, c.KeyRing, c.KeyName), nil
+}
+
+func (t *KMSDecrypt) ensureService(ctx context.Context, endpoint string) error {
+	if t.service != nil {
+		return nil
+	}
+
+	// Build client options
+	opts := []option.ClientOption{}
+
+	// If using a custom endpoint (e.g., a local test server), configure it
+	if endpoint != "" {
+		// The REST client joins request paths to the endpoint, which must end with a slash
+		if !strings.HasSuffix(endpoint, "/") {
+			endpoint += "/"
+		}
+		opts = append(opts, option.WithEndpoint(endpoint))
+		opts = append(opts, option.WithoutAuthentication())
+	}
+	// Otherwise use Application Default Credentials, like the gcloud_secretmanager provider
+
+	service, err := cloudkms.NewService(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create Cloud KMS client: %w", err)
+	}
+
+	t.service = service
+	return nil
+}
+
+// parseConfig converts a map[string]interface{} to KMSConfig
+func parseConfig(config map[string]interface{}) (*KMSConfig, error) {
+	// Use JSON marshaling/unmarshaling for clean conversion
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cfg KMSConfig
+	if err := json.Unmarshal(jsonData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// Point at the same emulator as the gcloud_secretmanager providers
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(gcsm.EndpointEnvVar)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,172 @@
+package gcpkms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider/gcsm"
+	"google.golang.org/api/cloudkms/v1"
+)
+
+const testKeyName = "projects/my-project/locations/europe-west1/keyRings/my-ring/cryptoKeys/my-key"
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "parts",
+			config: map[string]interface{}{"project_id": "my-project", "location": "europe-west1", "keyring": "my-ring", "key_name": "my-key"},
+			want:   testKeyName,
+		},
+		{
+			name:   "default location",
+			config: map[string]interface{}{"project_id": "my-project", "keyring": "my-ring", "key_name": "my-key"},
+			want:   "projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key",
+		},
+		{
+			name:   "full resource name",
+			config: map[string]interface{}{"key_name": testKeyName},
+			want:   testKeyName,
+		},
+		{
+			name:    "missing key_name",
+			config:  map[string]interface{}{"project_id": "my-project", "keyring": "my-ring"},
+			wantErr: "'key_name'",
+		},
+		{
+			name:    "missing keyring",
+			config:  map[string]interface{}{"project_id": "my-project", "key_name": "my-key"},
+			wantErr: "'keyring'",
+		},
+		{
+			name:    "missing project",
+			config:  map[string]interface{}{"keyring": "my-ring", "key_name": "my-key"},
+			wantErr: "'project_id'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseConfig(tt.config)
+			if err != nil {
+				t.Fatalf("parseConfig failed: %v", err)
+			}
+			got, err := cfg.ResourceName()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %s, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResourceName failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// newKMSStub serves the Cloud KMS decrypt endpoint, "decrypting" by stripping an "enc:" prefix
+func newKMSStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/"+testKeyName+":decrypt" {
+			http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
+			return
+		}
+		var req cloudkms.DecryptRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ciphertext, _ := base64.StdEncoding.DecodeString(req.Ciphertext)
+		plaintext, ok := strings.CutPrefix(string(ciphertext), "enc:")
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Decryption failed"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(cloudkms.DecryptResponse{
+			Plaintext: base64.StdEncoding.EncodeToString([]byte(plaintext)),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestApply(t *testing.T) {
+	server := newKMSStub(t)
+	config := map[string]interface{}{"key_name": testKeyName, "endpoint": server.URL}
+	transform := &KMSDecrypt{}
+
+	value, err := transform.Apply(context.Background(), config, base64.StdEncoding.EncodeToString([]byte("enc:s3cr3t")))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if value != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got %q", value)
+	}
+
+	if _, err := transform.Apply(context.Background(), config, "not base64!"); err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("expected a base64 error, got %v", err)
+	}
+
+	_, err = transform.Apply(context.Background(), config, base64.StdEncoding.EncodeToString([]byte("plain")))
+	if err == nil || !strings.Contains(err.Error(), "Decryption failed") {
+		t.Errorf("expected a decryption error, got %v", err)
+	}
+}
+
+func TestApply_EndpointFromEnv(t *testing.T) {
+	server := newKMSStub(t)
+	t.Setenv(gcsm.EndpointEnvVar, server.URL)
+
+	value, err := (&KMSDecrypt{}).Apply(context.Background(), map[string]interface{}{"key_name": testKeyName}, base64.StdEncoding.EncodeToString([]byte("enc:s3cr3t")))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if value != "s3cr3t" {
+		t.Errorf("expected s3cr3t from the endpoint in %s, got %q", gcsm.EndpointEnvVar, value)
+	}
+}
+
+// TestApply_Live encrypts and decrypts a value with a real Cloud KMS key.
+// It requires SSTART_TEST_GCP_KMS_KEY (full key resource name) and Application Default Credentials.
+func TestApply_Live(t *testing.T) {
+	keyName := os.Getenv("SSTART_TEST_GCP_KMS_KEY")
+	if keyName == "" {
+		t.Skip("SSTART_TEST_GCP_KMS_KEY not set, skipping live Cloud KMS test")
+	}
+
+	ctx := context.Background()
+	service, err := cloudkms.NewService(ctx)
+	if err != nil {
+		t.Skipf("Cloud KMS credentials not available: %v", err)
+	}
+	encrypted, err := service.Projects.Locations.KeyRings.CryptoKeys.Encrypt(keyName, &cloudkms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString([]byte("live-secret")),
+	}).Context(ctx).Do()
+	if err != nil {
+		t.Fatalf("failed to encrypt test value: %v", err)
+	}
+
+	value, err := (&KMSDecrypt{}).Apply(ctx, map[string]interface{}{"key_name": keyName}, encrypted.Ciphertext)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if value != "live-secret" {
+		t.Errorf("expected live-secret, got %q", value)
+	}
+}
@@ -0,0 +1,46 @@
+// Package transform defines the interface and registry for value transforms, which rewrite
+// secret values after they are fetched from a provider (e.g. decrypting envelope-encrypted values).
+package transform
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Transform rewrites a single secret value
+type Transform interface {
+	// Name returns the name of the transform
+	Name() string
+
+	// Apply returns the transformed value
+	// config contains transform-specific configuration fields (e.g., key name, location, etc.)
+	Apply(ctx context.Context, config map[string]interface{}, value string) (string, error)
+}
+
+// Registry holds all registered transforms
+var registry = make(map[string]func() Transform)
+
+// Register registers a transform factory function
+func Register(name string, factory func() Transform) {
+	registry[name] = factory
+}
+
+// New creates a new transform instance by name
+func New(name string) (Transform, error) {
+	factory, exists := registry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown value transform: %s", name)
+	}
+	return factory(), nil
+}
+
+// List returns the names of all registered transforms, sorted
+func List() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
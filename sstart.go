@@ -0,0 +1,129 @@
+// Package sstart collects secrets from the providers of an sstart configuration, for programs that
+// embed sstart instead of running the CLI. Every built-in provider is registered by importing it.
+//
+//	secrets, err := sstart.Collect(ctx, ".sstart.yml", sstart.WithProviders("aws-*"))
+//
+// Collect returns the secrets the run command injects into the environment of its command.
+package sstart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/aws"
+	_ "github.com/dirathea/sstart/internal/provider/bitwarden"
+	_ "github.com/dirathea/sstart/internal/provider/doppler"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	_ "github.com/dirathea/sstart/internal/provider/gcsm"
+	_ "github.com/dirathea/sstart/internal/provider/infisical"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/onepassword"
+	_ "github.com/dirathea/sstart/internal/provider/prompt"
+	_ "github.com/dirathea/sstart/internal/provider/sqlite"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// Option configures Collect
+type Option func(*options)
+
+type options struct {
+	configFormat string
+	providers    []string
+	collector    []secrets.CollectorOption
+}
+
+// WithConfigFormat sets the format of the configuration, "yaml" or "json"
+// (default: detected from the file extension, like --config-format)
+func WithConfigFormat(format string) Option {
+	return func(o *options) {
+		o.configFormat = format
+	}
+}
+
+// WithProviders limits the collection to the selected providers: ids, aliases, glob patterns
+// matched against ids (e.g. "aws-*") or kind:<kind> selectors, like --providers (default: all providers)
+func WithProviders(selectors ...string) Option {
+	return func(o *options) {
+		o.providers = append(o.providers, selectors...)
+	}
+}
+
+// WithForceAuth re-authenticates with SSO, ignoring stored tokens, like --force-auth
+func WithForceAuth(forceAuth bool) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithForceAuth(forceAuth))
+	}
+}
+
+// WithStrictKeys fails when a provider returns source keys not listed in its 'keys' mapping, like --strict-keys
+func WithStrictKeys(strictKeys bool) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithStrictKeys(strictKeys))
+	}
+}
+
+// WithExpandJSON sets whether JSON secrets are expanded into one key per field, for providers that
+// do not set expand_json, like --expand-json
+func WithExpandJSON(expandJSON bool) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithExpandJSON(expandJSON))
+	}
+}
+
+// WithConcurrency sets the maximum number of providers fetching at once (0 for no limit), like --concurrency
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithConcurrency(n))
+	}
+}
+
+// WithProviderTimeout sets the maximum duration of each provider fetch, for providers without a
+// 'timeout', like --provider-timeout
+func WithProviderTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithTimeout(timeout))
+	}
+}
+
+// WithCollectionTimeout sets the maximum duration of fetching all providers, like --collection-timeout
+func WithCollectionTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.collector = append(o.collector, secrets.WithCollectionTimeout(timeout))
+	}
+}
+
+// Collect loads the configuration at configPath ("-" for stdin) and fetches the secrets of its
+// providers, returning the same key-value map the run command injects into the environment.
+// Warnings raised while collecting are written to stderr, as with the CLI.
+func Collect(ctx context.Context, configPath string, opts ...Option) (map[string]string, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg, err := config.LoadWithFormat(configPath, o.configFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var providerIDs []string
+	if len(o.providers) > 0 {
+		providerIDs, err = cfg.SelectProviders(o.providers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	collector := secrets.NewCollector(cfg, o.collector...)
+	defer collector.Close()
+
+	collected, err := collector.Collect(ctx, providerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect secrets: %w", err)
+	}
+	return collected, nil
+}
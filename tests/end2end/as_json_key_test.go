@@ -0,0 +1,146 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_AsJSONKey tests that as_json_key loads a provider's secrets as a single JSON object
+func TestE2E_AsJSONKey(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		configYAML string
+		expected   map[string]string // Plain secrets expected besides the JSON key
+		jsonKey    string
+		jsonObject map[string]string
+	}{
+		{
+			name: "whole provider",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      DB_HOST: localhost
+      DB_URL: postgres://app@localhost/app?sslmode=disable&x=<y>
+    as_json_key: APP_CONFIG_JSON
+`,
+			expected: map[string]string{},
+			jsonKey:  "APP_CONFIG_JSON",
+			jsonObject: map[string]string{
+				"DB_HOST": "localhost",
+				"DB_URL":  "postgres://app@localhost/app?sslmode=disable&x=<y>",
+			},
+		},
+		{
+			name: "applied after keys",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      host: localhost
+      password: secret
+      debug: "true"
+    keys:
+      host: DB_HOST
+      password: DB_PASSWORD
+    as_json_key: APP_CONFIG_JSON
+`,
+			expected:   map[string]string{},
+			jsonKey:    "APP_CONFIG_JSON",
+			jsonObject: map[string]string{"DB_HOST": "localhost", "DB_PASSWORD": "secret"},
+		},
+		{
+			name: "other providers are unaffected",
+			configYAML: `
+providers:
+  - kind: mock
+    id: blob
+    values:
+      API_KEY: key
+    as_json_key: BLOB_JSON
+  - kind: mock
+    id: plain
+    values:
+      PLAIN: value
+`,
+			expected:   map[string]string{"PLAIN": "value"},
+			jsonKey:    "BLOB_JSON",
+			jsonObject: map[string]string{"API_KEY": "key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected)+1 {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected)+1, len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+			for key := range tt.jsonObject {
+				if _, found := collectedSecrets[key]; found {
+					t.Errorf("Secret '%s' should only be loaded inside '%s'", key, tt.jsonKey)
+				}
+			}
+
+			blob, found := collectedSecrets[tt.jsonKey]
+			if !found {
+				t.Fatalf("Expected secret '%s' to be loaded", tt.jsonKey)
+			}
+			var object map[string]string
+			if err := json.Unmarshal([]byte(blob), &object); err != nil {
+				t.Fatalf("Secret '%s' is not a JSON object: %v (%s)", tt.jsonKey, err, blob)
+			}
+			if len(object) != len(tt.jsonObject) {
+				t.Errorf("Expected %d keys in '%s', got %d: %v", len(tt.jsonObject), tt.jsonKey, len(object), object)
+			}
+			for key, value := range tt.jsonObject {
+				if object[key] != value {
+					t.Errorf("Key '%s' in '%s': expected '%s', got '%s'", key, tt.jsonKey, value, object[key])
+				}
+			}
+			if strings.Contains(blob, `\u0026`) || strings.Contains(blob, "\n") {
+				t.Errorf("Expected '%s' on a single line without escaped HTML characters, got: %s", tt.jsonKey, blob)
+			}
+		})
+	}
+}
+
+// TestE2E_AsJSONKey_Invalid tests that an empty as_json_key is rejected when loading the configuration
+func TestE2E_AsJSONKey_Invalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      KEY: value
+    as_json_key: ""
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid as_json_key") {
+		t.Errorf("Expected an invalid as_json_key error, got: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package end2end
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_AuditStdout tests that --audit-stdout streams provider events without values
+func TestE2E_AuditStdout(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: database
+    values:
+      DB_USER: audit-user-value
+      DB_PASSWORD: audit-password-value
+  - kind: mock
+    id: api
+    values:
+      API_TOKEN: audit-token-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--audit-stdout", "run", "--", "true")
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sstart run failed: %v\nOutput: %s", err, output)
+	}
+	if strings.Contains(string(output), "-value") {
+		t.Errorf("Expected no secret values in audit events, got: %s", output)
+	}
+
+	var events []secrets.Event
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		var event secrets.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Failed to parse event %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	// Providers are fetched concurrently, so only the order of each provider's own events is fixed
+	want := map[string][]struct {
+		eventType string
+		outcome   string
+		keys      string
+	}{
+		"database": {
+			{secrets.EventProviderStart, "", ""},
+			{secrets.EventProviderFinish, secrets.OutcomeFetched, "DB_PASSWORD,DB_USER"},
+		},
+		"api": {
+			{secrets.EventProviderStart, "", ""},
+			{secrets.EventProviderFinish, secrets.OutcomeFetched, "API_TOKEN"},
+		},
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d: %s", len(events), output)
+	}
+	seen := make(map[string]int)
+	for i, event := range events {
+		expected := want[event.Provider]
+		n := seen[event.Provider]
+		seen[event.Provider]++
+		if n >= len(expected) {
+			t.Errorf("Event %d: unexpected event %+v", i, event)
+			continue
+		}
+		w := expected[n]
+		if event.Type != w.eventType || event.Outcome != w.outcome || strings.Join(event.Keys, ",") != w.keys {
+			t.Errorf("Event %d: expected %s %s %s [%s], got %+v", i, w.eventType, event.Provider, w.outcome, w.keys, event)
+		}
+		if event.Kind != "mock" || event.Time.IsZero() {
+			t.Errorf("Event %d: expected kind and time to be set, got %+v", i, event)
+		}
+	}
+}
+
+// TestE2E_AuditStdout_Rejected tests that --audit-stdout is rejected by commands writing their output to stdout
+func TestE2E_AuditStdout_Rejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: database
+    values:
+      DB_USER: audit-user-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"env"},
+		{"export"},
+		{"get", "DB_USER"},
+		{"fingerprint"},
+		{"mcp"},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			cmd := exec.Command(sstartBinary, append([]string{"--config", configFile, "--audit-stdout"}, args...)...)
+			cmd.Dir = tmpDir
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("Expected sstart %s to fail, got: %s", args[0], output)
+			}
+			want := "--audit-stdout cannot be used with '" + args[0] + "'"
+			if !strings.Contains(string(output), want) {
+				t.Errorf("Expected error containing %q, got: %s", want, output)
+			}
+			if strings.Contains(string(output), "-value") {
+				t.Errorf("Expected no secrets to be collected, got: %s", output)
+			}
+		})
+	}
+
+	// Writing the export to a file leaves stdout to the events
+	outFile := filepath.Join(tmpDir, "secrets.json")
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--audit-stdout", "export", "--out", outFile)
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sstart export --out failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), secrets.EventProviderFinish) {
+		t.Errorf("Expected audit events on stdout, got: %s", output)
+	}
+}
+
+// TestE2E_AuditEvents_Outcomes tests the events of skipped and failing providers
+func TestE2E_AuditEvents_Outcomes(t *testing.T) {
+	resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: flaky_stub
+    id: gate
+  - kind: flaky_stub
+    id: optional
+    requires:
+      provider: gate
+      key: GATE
+      equals: other
+  - kind: flaky_stub
+    id: broken
+    failures: 1
+`)
+
+	var events []secrets.Event
+	collector := secrets.NewCollector(cfg, secrets.WithEventHandler(func(event secrets.Event) {
+		events = append(events, event)
+	}))
+	if _, err := collector.Collect(context.Background(), nil); err == nil {
+		t.Fatal("Expected the collection to fail")
+	}
+
+	outcomes := make(map[string]secrets.Event)
+	for _, event := range events {
+		if event.Type == secrets.EventProviderFinish {
+			outcomes[event.Provider] = event
+		}
+	}
+	if outcomes["gate"].Outcome != secrets.OutcomeFetched {
+		t.Errorf("Expected gate to be fetched, got %+v", outcomes["gate"])
+	}
+	if got := outcomes["broken"]; got.Outcome != secrets.OutcomeError || !strings.Contains(got.Error, "transient failure") {
+		t.Errorf("Expected broken to report an error, got %+v", got)
+	}
+	// The failing provider stops the collection before conditional providers are evaluated
+	if _, ok := outcomes["optional"]; ok {
+		t.Errorf("Expected no event for optional, got %+v", outcomes["optional"])
+	}
+
+	resetFlakyCalls()
+	events = nil
+	cfg.Providers = cfg.Providers[:2]
+	if _, err := secrets.NewCollector(cfg, secrets.WithEventHandler(func(event secrets.Event) {
+		events = append(events, event)
+	})).Collect(context.Background(), nil); err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Provider != "optional" || last.Type != secrets.EventProviderFinish || last.Outcome != secrets.OutcomeSkipped {
+		t.Errorf("Expected optional to be skipped, got %+v", last)
+	}
+}
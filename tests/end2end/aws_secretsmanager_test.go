@@ -175,3 +175,63 @@ providers:
 
 	t.Logf("Successfully collected %d secrets from AWS Secrets Manager provider without key mappings", len(collectedSecrets))
 }
+
+// TestE2E_AWSSecretsManager_PreferPendingOnRotation tests that prefer_pending_on_rotation reads the
+// AWSPENDING version while a rotation is in progress, and AWSCURRENT otherwise
+func TestE2E_AWSSecretsManager_PreferPendingOnRotation(t *testing.T) {
+	ctx := context.Background()
+
+	// Setup LocalStack container
+	localstack := SetupLocalStack(ctx, t)
+	defer func() {
+		if err := localstack.Cleanup(); err != nil {
+			t.Errorf("Failed to terminate localstack container: %v", err)
+		}
+	}()
+
+	// A secret with a current version and a pending version from an unfinished rotation
+	secretName := "test/myapp/rotating"
+	SetupAWSSecret(ctx, t, localstack, secretName, map[string]string{"DB_PASSWORD": "current-password"})
+	SetupAWSPendingSecretVersion(ctx, t, localstack, secretName, map[string]string{"DB_PASSWORD": "pending-password"})
+
+	tests := []struct {
+		name          string
+		preferPending bool
+		expected      string
+	}{
+		{name: "prefer pending", preferPending: true, expected: "pending-password"},
+		{name: "current by default", preferPending: false, expected: "current-password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configFile := filepath.Join(tmpDir, ".sstart.yml")
+			configYAML := fmt.Sprintf(`
+providers:
+  - kind: aws_secretsmanager
+    id: aws-rotating
+    secret_id: %s
+    region: us-east-1
+    endpoint: %s
+    prefer_pending_on_rotation: %t
+`, secretName, localstack.Endpoint, tt.preferPending)
+			if err := os.WriteFile(configFile, []byte(configYAML), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := config.Load(configFile)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+			if collectedSecrets["DB_PASSWORD"] != tt.expected {
+				t.Errorf("Expected DB_PASSWORD=%s, got %q", tt.expected, collectedSecrets["DB_PASSWORD"])
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package end2end
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestE2E_RunBanner tests that 'run' prints a banner with the config, provider count and
+// masked SSO identity with --verbose, and nothing with --quiet
+func TestE2E_RunBanner(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+inherit: false
+
+sso:
+  oidc:
+    clientId: banner-client
+    issuer: http://127.0.0.1:1
+    scopes: [openid]
+
+providers:
+  - kind: mock
+    id: banner-first
+    values:
+      BANNER_FIRST: banner-first-value
+  - kind: mock
+    id: banner-second
+    values:
+      BANNER_SECOND: banner-second-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// Store a valid token with an ID token in an isolated config home
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user-123",
+		"email": "alice@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-key"))
+	if err != nil {
+		t.Fatalf("Failed to sign ID token: %v", err)
+	}
+	data, err := json.Marshal(&oidc.Tokens{
+		AccessToken: "banner-access-token",
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		Expiry:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal tokens: %v", err)
+	}
+	configHome := t.TempDir()
+	tokenPath := filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName)
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		t.Fatalf("Failed to create token directory: %v", err)
+	}
+	if err := os.WriteFile(tokenPath, data, 0600); err != nil {
+		t.Fatalf("Failed to write tokens: %v", err)
+	}
+
+	run := func(t *testing.T, args ...string) (string, string) {
+		t.Helper()
+		args = append([]string{"--config", configFile}, args...)
+		cmd := exec.Command(sstartBinary, append(args, "--", "sh", "-c", "echo $BANNER_FIRST")...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, oidc.SSOSecretEnvVar+"=")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr.String())
+		}
+		return stdout.String(), stderr.String()
+	}
+
+	t.Run("verbose", func(t *testing.T) {
+		stdout, stderr := run(t, "--verbose", "run")
+		if strings.TrimSpace(stdout) != "banner-first-value" {
+			t.Errorf("Banner must not be written to the command's stdout, got %q", stdout)
+		}
+		for _, want := range []string{
+			"config:    " + configFile,
+			"providers: 2 (banner-first, banner-second)",
+			"inherit:   false",
+			"sso:       a***@example.com",
+		} {
+			if !strings.Contains(stderr, want) {
+				t.Errorf("Expected banner to contain %q, got:\n%s", want, stderr)
+			}
+		}
+		if strings.Contains(stderr, "alice") || strings.Contains(stderr, "banner-first-value") {
+			t.Errorf("Banner must mask the identity and never contain values, got:\n%s", stderr)
+		}
+	})
+
+	t.Run("selected_providers", func(t *testing.T) {
+		_, stderr := run(t, "--verbose", "run", "--providers", "banner-first")
+		if !strings.Contains(stderr, "providers: 1 (banner-first)") {
+			t.Errorf("Expected banner to count only selected providers, got:\n%s", stderr)
+		}
+	})
+
+	t.Run("quiet", func(t *testing.T) {
+		_, stderr := run(t, "--verbose", "--quiet", "run")
+		if strings.Contains(stderr, "sstart run") {
+			t.Errorf("Expected no banner with --quiet, got:\n%s", stderr)
+		}
+	})
+
+	t.Run("not_a_terminal", func(t *testing.T) {
+		_, stderr := run(t, "run")
+		if strings.Contains(stderr, "sstart run") {
+			t.Errorf("Expected no banner when stderr is not a terminal, got:\n%s", stderr)
+		}
+	})
+}
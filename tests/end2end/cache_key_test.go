@@ -0,0 +1,96 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/cache"
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_CacheKey_SharedKeyDedupes tests that providers with the same cache_key are fetched once
+func TestE2E_CacheKey_SharedKeyDedupes(t *testing.T) {
+	calls := resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: flaky_stub
+    id: primary
+    cache_key: shared-secret
+  - kind: flaky_stub
+    id: replica
+    region: eu-west-1
+    cache_key: shared-secret
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if calls["primary"] != 1 || calls["replica"] != 0 {
+		t.Errorf("Expected only the first provider to be fetched, got %v", calls)
+	}
+	if collectedSecrets["PRIMARY"] != "primary" {
+		t.Errorf("Expected PRIMARY=primary, got %v", collectedSecrets)
+	}
+	if _, ok := collectedSecrets["REPLICA"]; ok {
+		t.Errorf("Expected the replica to reuse the primary's secrets, got %v", collectedSecrets)
+	}
+}
+
+// TestE2E_CacheKey_DifferentKeysFetchSeparately tests that distinct cache_keys are not deduplicated,
+// even when the provider configurations are identical
+func TestE2E_CacheKey_DifferentKeysFetchSeparately(t *testing.T) {
+	calls := resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: flaky_stub
+    id: blue
+    cache_key: blue-secret
+  - kind: flaky_stub
+    id: green
+    cache_key: green-secret
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if calls["blue"] != 1 || calls["green"] != 1 {
+		t.Errorf("Expected both providers to be fetched once, got %v", calls)
+	}
+	if collectedSecrets["BLUE"] != "blue" || collectedSecrets["GREEN"] != "green" {
+		t.Errorf("Expected BLUE and GREEN, got %v", collectedSecrets)
+	}
+}
+
+// TestE2E_CacheKey_Config tests parsing and validation of cache_key
+func TestE2E_CacheKey_Config(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: flaky_stub
+    cache_key: shared-secret
+`)
+	providerCfg := cfg.Providers[0]
+	if providerCfg.CacheKey != "shared-secret" {
+		t.Errorf("Expected cache_key 'shared-secret', got %q", providerCfg.CacheKey)
+	}
+	if _, ok := providerCfg.Config["cache_key"]; ok {
+		t.Error("Expected cache_key not to be passed to the provider config")
+	}
+
+	if cache.CustomCacheKey("a") == cache.CustomCacheKey("b") {
+		t.Error("Expected different cache keys for different cache_key values")
+	}
+
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte("providers:\n  - kind: flaky_stub\n    cache_key: 42\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if _, err := config.Load(configFile); err == nil || !strings.Contains(err.Error(), "cache_key") {
+		t.Errorf("Expected a cache_key validation error, got %v", err)
+	}
+}
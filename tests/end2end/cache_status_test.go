@@ -0,0 +1,145 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/cache"
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// writeCachedDotenvConfig writes a cache-enabled config with two dotenv providers
+func writeCachedDotenvConfig(t *testing.T, tmpDir string) string {
+	t.Helper()
+
+	firstEnv := filepath.Join(tmpDir, "first.env")
+	secondEnv := filepath.Join(tmpDir, "second.env")
+	if err := os.WriteFile(firstEnv, []byte("STATUS_FIRST=first-secret-value\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if err := os.WriteFile(secondEnv, []byte("STATUS_SECOND=second-secret-value\nSTATUS_THIRD=third\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configContent := `
+cache:
+  enabled: true
+  ttl: 10m
+
+providers:
+  - kind: dotenv
+    id: status-first
+    path: ` + firstEnv + `
+  - kind: dotenv
+    id: status-second
+    path: ` + secondEnv + `
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return configFile
+}
+
+// TestE2E_Cache_Entries tests that cached entries record their provider and can be cleared per provider
+func TestE2E_Cache_Entries(t *testing.T) {
+	testCache := cache.New()
+	if !testCache.IsAvailable() {
+		t.Skip("keyring not available, skipping cache test")
+	}
+	_ = testCache.Clear()
+	defer func() { _ = testCache.Clear() }()
+
+	cfg, err := config.Load(writeCachedDotenvConfig(t, t.TempDir()))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if _, err := secrets.NewCollector(cfg).Collect(context.Background(), nil); err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	entries := testCache.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 cached entries, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].ProviderID != "status-first" || entries[0].Kind != "dotenv" || entries[0].KeyCount != 1 {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].ProviderID != "status-second" || entries[1].KeyCount != 2 {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	removed, err := testCache.ClearProviderID("status-first")
+	if err != nil {
+		t.Fatalf("Failed to clear provider: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 removed entry, got %d", removed)
+	}
+	entries = testCache.Entries()
+	if len(entries) != 1 || entries[0].ProviderID != "status-second" {
+		t.Errorf("Expected only status-second to remain, got %+v", entries)
+	}
+
+	if err := testCache.Clear(); err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	if entries := testCache.Entries(); len(entries) != 0 {
+		t.Errorf("Expected no entries after clear, got %+v", entries)
+	}
+}
+
+// TestE2E_Cache_StatusCommand tests that 'cache status' lists entries without values and 'cache clear' empties the cache
+func TestE2E_Cache_StatusCommand(t *testing.T) {
+	testCache := cache.New()
+	if !testCache.IsAvailable() {
+		t.Skip("keyring not available, skipping cache test")
+	}
+	_ = testCache.Clear()
+	defer func() { _ = testCache.Clear() }()
+
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	configFile := writeCachedDotenvConfig(t, tmpDir)
+
+	sstart := func(args ...string) string {
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile}, args...)...)
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("sstart %v failed: %v\nOutput: %s", args, err, output)
+		}
+		return string(output)
+	}
+
+	// Populate the cache
+	sstart("show")
+
+	status := sstart("cache", "status")
+	for _, want := range []string{"Backend: keyring", "status-first", "status-second", "dotenv"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("Expected status to contain %q, got:\n%s", want, status)
+		}
+	}
+	if strings.Contains(status, "first-secret-value") || strings.Contains(status, "second-secret-value") {
+		t.Errorf("Status must not reveal secret values, got:\n%s", status)
+	}
+
+	sstart("cache", "clear", "--provider", "status-first")
+	status = sstart("cache", "status")
+	if strings.Contains(status, "status-first") || !strings.Contains(status, "status-second") {
+		t.Errorf("Expected only status-second after clearing status-first, got:\n%s", status)
+	}
+
+	sstart("cache", "clear")
+	status = sstart("cache", "status")
+	if !strings.Contains(status, "No cached entries") {
+		t.Errorf("Expected no cached entries after clear, got:\n%s", status)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/dirathea/sstart/internal/cache"
 	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/keyring"
 	_ "github.com/dirathea/sstart/internal/provider/dotenv"
 	"github.com/dirathea/sstart/internal/secrets"
 )
@@ -238,6 +240,131 @@ providers:
 	_ = testCache.Clear()
 }
 
+// memoryKeyring is an in-memory keyring, so caching can be tested without a system keyring
+type memoryKeyring struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (k *memoryKeyring) Get(service, user string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	value, ok := k.entries[service+"/"+user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return value, nil
+}
+
+func (k *memoryKeyring) Set(service, user, password string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.entries == nil {
+		k.entries = make(map[string]string)
+	}
+	k.entries[service+"/"+user] = password
+	return nil
+}
+
+func (k *memoryKeyring) Delete(service, user string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.entries[service+"/"+user]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(k.entries, service+"/"+user)
+	return nil
+}
+
+// TestE2E_Cache_KeyMappingChange tests that changing the key mapping of a provider invalidates its cached secrets
+func TestE2E_Cache_KeyMappingChange(t *testing.T) {
+	t.Cleanup(keyring.SetBackend(&memoryKeyring{}))
+	testCache := cache.New()
+
+	tests := []struct {
+		name     string
+		mapping  string
+		expected map[string]string
+	}{
+		{
+			name:     "keys",
+			mapping:  "keys:\n      API_KEY: MAPPED_KEY\n      DB_PASS: ==",
+			expected: map[string]string{"MAPPED_KEY": "secret", "DB_PASS": "password"},
+		},
+		{
+			name:     "keys_template",
+			mapping:  "keys_template: 'APP_{{ .key }}'",
+			expected: map[string]string{"APP_API_KEY": "secret", "APP_DB_PASS": "password"},
+		},
+		{
+			name:     "exclude_keys",
+			mapping:  "exclude_keys: [DB_PASS]",
+			expected: map[string]string{"API_KEY": "secret"},
+		},
+		{
+			name:     "case_insensitive_keys",
+			mapping:  "case_insensitive_keys: true\n    keys:\n      api_key: LOWER_MAPPED",
+			expected: map[string]string{"LOWER_MAPPED": "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = testCache.Clear()
+			defer func() { _ = testCache.Clear() }()
+
+			tmpDir := t.TempDir()
+			envFile := filepath.Join(tmpDir, ".env")
+			if err := os.WriteFile(envFile, []byte("API_KEY=secret\nDB_PASS=password\n"), 0600); err != nil {
+				t.Fatalf("Failed to write env file: %v", err)
+			}
+
+			collect := func(mapping string) map[string]string {
+				t.Helper()
+				configFile := filepath.Join(tmpDir, ".sstart.yml")
+				configContent := `
+cache:
+  enabled: true
+  ttl: 10m
+
+providers:
+  - kind: dotenv
+    id: test-env
+    path: ` + envFile + `
+    ` + mapping + `
+`
+				if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+					t.Fatalf("Failed to write config file: %v", err)
+				}
+				cfg, err := config.Load(configFile)
+				if err != nil {
+					t.Fatalf("Failed to load config: %v", err)
+				}
+				collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+				if err != nil {
+					t.Fatalf("Failed to collect: %v", err)
+				}
+				return collected
+			}
+
+			// Cache the secrets under their source names first
+			if first := collect(""); first["API_KEY"] != "secret" {
+				t.Fatalf("Expected API_KEY=secret, got %v", first)
+			}
+
+			collected := collect(tt.mapping)
+			if len(collected) != len(tt.expected) {
+				t.Errorf("Expected %v, got %v (stale cache entry used)", tt.expected, collected)
+			}
+			for key, value := range tt.expected {
+				if collected[key] != value {
+					t.Errorf("Expected %s=%s, got %v (stale cache entry used)", key, value, collected)
+				}
+			}
+		})
+	}
+}
+
 // TestE2E_Cache_ConfigParsing tests cache configuration parsing
 func TestE2E_Cache_ConfigParsing(t *testing.T) {
 	tests := []struct {
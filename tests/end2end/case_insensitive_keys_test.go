@@ -0,0 +1,123 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_CaseInsensitiveKeys tests that case_insensitive_keys matches source keys against 'keys' ignoring case
+func TestE2E_CaseInsensitiveKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "exact matching drops keys in a different case",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      db_host: localhost
+    keys:
+      DB_HOST: ==
+`,
+			expected: map[string]string{},
+		},
+		{
+			name: "lowercase source key matches an uppercase mapping",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    values:
+      db_host: localhost
+      db_port: "5432"
+      unrelated: dropped
+    keys:
+      DB_HOST: ==
+      DB_PORT: DATABASE_PORT
+`,
+			expected: map[string]string{"DB_HOST": "localhost", "DATABASE_PORT": "5432"},
+		},
+		{
+			name: "exact match takes precedence over a case-insensitive one",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    values:
+      DB_HOST: exact
+      db_host: folded
+    keys:
+      DB_HOST: ==
+`,
+			expected: map[string]string{"DB_HOST": "exact"},
+		},
+		{
+			name: "source keys differing only by case collide",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    values:
+      db_host: lower
+      Db_Host: mixed
+    keys:
+      DB_HOST: ==
+`,
+			expectError: "source keys 'Db_Host' and 'db_host' both match 'keys' entry 'DB_HOST' ignoring case",
+		},
+		{
+			name: "strict keys reports keys that match no entry",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    strict_keys: true
+    values:
+      db_host: localhost
+      new_key: added-later
+    keys:
+      DB_HOST: ==
+`,
+			expectError: "strict keys): new_key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if tt.expectError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing '%s', got none", tt.expectError)
+				}
+				if !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+		})
+	}
+}
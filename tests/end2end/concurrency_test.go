@@ -0,0 +1,134 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Concurrency_MergeOrder tests that providers are fetched concurrently while their secrets
+// are merged in configuration order, whichever provider finishes first
+func TestE2E_Concurrency_MergeOrder(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: slow-first
+    delay: 300ms
+    values:
+      SHARED: from-slow-first
+      FIRST_ONLY: first
+  - kind: mock
+    id: second
+    delay: 300ms
+    values:
+      SECOND_ONLY: second
+  - kind: mock
+    id: fast-last
+    delay: 10ms
+    values:
+      SHARED: from-fast-last
+  - kind: mock
+    id: fourth
+    delay: 300ms
+    values:
+      FOURTH_ONLY: fourth
+`)
+
+	tests := []struct {
+		name        string
+		concurrency int
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{name: "default", concurrency: secrets.DefaultConcurrency, maxDuration: 800 * time.Millisecond},
+		{name: "unbounded", concurrency: 0, maxDuration: 800 * time.Millisecond},
+		{name: "sequential", concurrency: 1, minDuration: 900 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := secrets.NewCollector(cfg, secrets.WithConcurrency(tt.concurrency))
+			defer collector.Close()
+
+			start := time.Now()
+			collected, err := collector.Collect(context.Background(), nil)
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if collected["SHARED"] != "from-fast-last" {
+				t.Errorf("Expected the last configured provider to win, got SHARED=%q", collected["SHARED"])
+			}
+			if len(collected) != 4 {
+				t.Errorf("Expected 4 secrets, got %v", collected)
+			}
+			if tt.maxDuration > 0 && elapsed > tt.maxDuration {
+				t.Errorf("Expected providers to be fetched concurrently, took %v", elapsed)
+			}
+			if elapsed < tt.minDuration {
+				t.Errorf("Expected providers to be fetched one at a time, took %v", elapsed)
+			}
+		})
+	}
+}
+
+// TestE2E_Concurrency_AggregatedErrors tests that the errors of all failing providers are returned together
+func TestE2E_Concurrency_AggregatedErrors(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: broken-one
+    fail: true
+    error: first backend down
+  - kind: mock
+    id: healthy
+    values:
+      HEALTHY: "true"
+  - kind: mock
+    id: broken-two
+    fail: true
+    error: second backend down
+  - kind: mock
+    id: after-broken
+    depends_on: [broken-one]
+    values:
+      AFTER: "true"
+`)
+
+	var finished []string
+	collector := secrets.NewCollector(cfg, secrets.WithEventHandler(func(event secrets.Event) {
+		if event.Type == secrets.EventProviderFinish {
+			finished = append(finished, event.Provider+":"+event.Outcome)
+		}
+	}))
+	defer collector.Close()
+
+	_, err := collector.Collect(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Expected the collection to fail")
+	}
+	for _, want := range []string{
+		"failed to fetch from provider 'broken-one': mock provider failure: first backend down",
+		"failed to fetch from provider 'broken-two': mock provider failure: second backend down",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+	// Errors are listed in configuration order
+	if strings.Index(err.Error(), "broken-one") > strings.Index(err.Error(), "broken-two") {
+		t.Errorf("Expected errors in configuration order, got: %v", err)
+	}
+
+	outcomes := strings.Join(finished, ",")
+	if !strings.Contains(outcomes, "healthy:fetched") {
+		t.Errorf("Expected healthy providers to be fetched despite failures, got %s", outcomes)
+	}
+	if strings.Contains(outcomes, "after-broken") {
+		t.Errorf("Expected a provider depending on a failed provider not to be fetched, got %s", outcomes)
+	}
+}
@@ -0,0 +1,94 @@
+package end2end
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Config_ExpandEnv tests that environment variables are expanded in provider configuration
+// when the configuration is loaded
+func TestE2E_Config_ExpandEnv(t *testing.T) {
+	t.Setenv("SSTART_TEST_ENDPOINT", "https://vault.staging.internal")
+	t.Setenv("SSTART_TEST_REGION", "")
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: ${SSTART_TEST_ENDPOINT}/v1
+    mount: ${SSTART_TEST_REGION:-secret}
+    path: $SSTART_TEST_UNSET_VARIABLE
+    auth:
+      role: ${SSTART_TEST_UNSET_VARIABLE:-reader}
+    for_each: ["${SSTART_TEST_ENDPOINT}", "${SSTART_TEST_UNSET_VARIABLE:-localhost}"]
+  - kind: mock
+    values:
+      PRICE: costs $$5
+      ENDPOINT: ${SSTART_TEST_ENDPOINT}
+      LITERAL: $${SSTART_TEST_ENDPOINT}
+`)
+
+	providerCfg, err := cfg.GetProvider("vault")
+	if err != nil {
+		t.Fatalf("Failed to get provider config: %v", err)
+	}
+	if got := providerCfg.Config["address"]; got != "https://vault.staging.internal/v1" {
+		t.Errorf("address = %v, want expanded variable", got)
+	}
+	if got := providerCfg.Config["mount"]; got != "secret" {
+		t.Errorf("mount = %v, want default for empty variable", got)
+	}
+	if got := providerCfg.Config["path"]; got != "" {
+		t.Errorf("path = %v, want empty for unset variable", got)
+	}
+	auth, _ := providerCfg.Config["auth"].(map[string]interface{})
+	if got := auth["role"]; got != "reader" {
+		t.Errorf("auth.role = %v, want default in nested mapping", got)
+	}
+	forEach, _ := providerCfg.Config["for_each"].([]interface{})
+	if len(forEach) != 2 || forEach[0] != "https://vault.staging.internal" || forEach[1] != "localhost" {
+		t.Errorf("for_each = %v, want expanded list", forEach)
+	}
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), []string{"mock"})
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	expected := map[string]string{
+		"PRICE":    "costs $5",
+		"ENDPOINT": "https://vault.staging.internal",
+		"LITERAL":  "${SSTART_TEST_ENDPOINT}",
+	}
+	for key, value := range expected {
+		if collected[key] != value {
+			t.Errorf("%s = %q, want %q", key, collected[key], value)
+		}
+	}
+}
+
+// TestE2E_Config_ExpandEnvFunc tests the expansion forms of config.ExpandEnv
+func TestE2E_Config_ExpandEnvFunc(t *testing.T) {
+	t.Setenv("SSTART_TEST_NAME", "app")
+	t.Setenv("SSTART_TEST_EMPTY", "")
+
+	tests := map[string]string{
+		"$SSTART_TEST_NAME":                  "app",
+		"${SSTART_TEST_NAME}-db":             "app-db",
+		"${SSTART_TEST_EMPTY:-fallback}":     "fallback",
+		"${SSTART_TEST_NAME:-fallback}":      "app",
+		"${SSTART_TEST_UNSET_VARIABLE:-a/b}": "a/b",
+		"$SSTART_TEST_UNSET_VARIABLE":        "",
+		"$$SSTART_TEST_NAME":                 "$SSTART_TEST_NAME",
+		"100$$":                              "100$",
+		"no variables":                       "no variables",
+	}
+	for input, want := range tests {
+		if got := config.ExpandEnv(input); got != want {
+			t.Errorf("ExpandEnv(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// writeConfigFiles writes configuration files relative to dir
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create config directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+	}
+}
+
+// TestE2E_Config_Include tests that included files are merged under the including file,
+// with providers merged by id and include paths relative to the including file
+func TestE2E_Config_Include(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFiles(t, tmpDir, map[string]string{
+		"shared/base.yml": `
+include: [defaults.json]
+inherit: false
+providers:
+  - kind: mock
+    id: vault
+    values:
+      DB_HOST: db.internal
+      DB_PASSWORD: base-password
+  - kind: mock
+    id: logging
+    values:
+      LOG_LEVEL: info
+`,
+		"shared/defaults.json": `{"providers": [{"kind": "mock", "id": "defaults", "values": {"REGION": "eu-west-1"}}]}`,
+		".sstart.yml": `
+include: shared/base.yml
+providers:
+  - kind: mock
+    id: vault
+    values:
+      DB_PASSWORD: local-password
+  - kind: mock
+    id: local
+    values:
+      DEBUG: "true"
+`,
+	})
+
+	cfg, err := config.Load(filepath.Join(tmpDir, ".sstart.yml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Inherit {
+		t.Error("Expected 'inherit: false' from the included file")
+	}
+
+	var ids []string
+	for _, p := range cfg.Providers {
+		ids = append(ids, p.ID)
+	}
+	if got, want := strings.Join(ids, ","), "defaults,vault,logging,local"; got != want {
+		t.Errorf("Provider ids = %s, want %s", got, want)
+	}
+
+	collector := secrets.NewCollector(cfg)
+	collected, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		"REGION":      "eu-west-1",
+		"DB_HOST":     "db.internal",
+		"DB_PASSWORD": "local-password",
+		"LOG_LEVEL":   "info",
+		"DEBUG":       "true",
+	}
+	for key, value := range expected {
+		if collected[key] != value {
+			t.Errorf("%s = %q, want %q", key, collected[key], value)
+		}
+	}
+}
+
+// TestE2E_Config_IncludeErrors tests include cycles, missing files and invalid include values
+func TestE2E_Config_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		expectError string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				".sstart.yml": "include: a.yml\n",
+				"a.yml":       "include: b.yml\n",
+				"b.yml":       "include: a.yml\n",
+			},
+			expectError: "config include cycle: ",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				".sstart.yml": "include: .sstart.yml\n",
+			},
+			expectError: "config include cycle: ",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				".sstart.yml": "include: missing.yml\n",
+			},
+			expectError: "failed to load include 'missing.yml'",
+		},
+		{
+			name: "invalid include",
+			files: map[string]string{
+				".sstart.yml": "include:\n  path: base.yml\n",
+			},
+			expectError: "invalid include",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeConfigFiles(t, tmpDir, tt.files)
+
+			_, err := config.Load(filepath.Join(tmpDir, ".sstart.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
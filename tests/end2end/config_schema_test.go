@@ -0,0 +1,100 @@
+package end2end
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/aws"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+)
+
+// TestE2E_Config_UnknownFields tests that options a provider kind does not accept are rejected when
+// the configuration is loaded, naming the provider, the option and the closest valid option
+func TestE2E_Config_UnknownFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+	}{
+		{
+			name: "mistyped provider option",
+			configYAML: `
+providers:
+  - kind: aws_secretsmanager
+    id: aws-prod
+    secret_ID: myapp/prod
+`,
+			expectError: "provider 'aws-prod': unknown field 'secret_ID' for kind 'aws_secretsmanager' (did you mean 'secret_id'?)",
+		},
+		{
+			name: "mistyped common option",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+    exclude_key: [API_KEY]
+`,
+			expectError: "provider 'mock': unknown field 'exclude_key' for kind 'mock' (did you mean 'exclude_keys'?)",
+		},
+		{
+			name: "unrelated option",
+			configYAML: `
+providers:
+  - kind: mock
+    id: local
+    region: us-east-1
+`,
+			expectError: "provider 'local': unknown field 'region' for kind 'mock' (valid fields: delay, error, fail, values)",
+		},
+		{
+			name: "option of another kind",
+			configYAML: `
+providers:
+  - kind: vault
+    path: myapp
+    secret_id: myapp
+`,
+			expectError: "provider 'vault': unknown field 'secret_id' for kind 'vault'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+			if err := os.WriteFile(configFile, []byte(tt.configYAML), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			_, err := config.Load(configFile)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+// TestE2E_Config_KnownFields tests that documented options, including vault's top-level token
+// shorthand, and providers without a declared schema load without errors
+func TestE2E_Config_KnownFields(t *testing.T) {
+	loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: http://127.0.0.1:8200
+    path: myapp
+    token: dev-token
+    auth:
+      method: token
+  - kind: aws_secretsmanager
+    secret_id: myapp/prod
+    region: us-east-1
+    expand_json: false
+  - kind: flaky_stub
+    id: custom
+    failures: 1
+`)
+}
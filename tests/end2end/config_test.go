@@ -142,7 +142,7 @@ providers:
 			providerKind: "dotenv",
 			providerID:   "dotenv",
 			validateFunc: func(t *testing.T, cfg map[string]interface{}) {
-				expectedPath := "${HOME}/.config/myapp/.env"
+				expectedPath := os.Getenv("HOME") + "/.config/myapp/.env"
 				if path, ok := cfg["path"].(string); !ok || path != expectedPath {
 					t.Errorf("expected path='%s', got %v", expectedPath, cfg["path"])
 				}
@@ -832,6 +832,42 @@ sso:
 				}
 			},
 		},
+		{
+			name: "SSO config with audience and resource",
+			yamlContent: `
+sso:
+  oidc:
+    clientId: my-sso-client-id
+    issuer: https://example.com/oidc
+    scopes:
+      - openid
+    audience: https://vault.example.com
+    resource: https://vault.example.com/v1/auth/jwt
+`,
+			expectError: false,
+			validateFunc: func(t *testing.T, cfg *config.Config) {
+				if cfg.SSO.OIDC.Audience != "https://vault.example.com" {
+					t.Errorf("expected Audience='https://vault.example.com', got '%s'", cfg.SSO.OIDC.Audience)
+				}
+				if cfg.SSO.OIDC.Resource != "https://vault.example.com/v1/auth/jwt" {
+					t.Errorf("expected Resource='https://vault.example.com/v1/auth/jwt', got '%s'", cfg.SSO.OIDC.Resource)
+				}
+			},
+		},
+		{
+			name: "SSO config with relative resource",
+			yamlContent: `
+sso:
+  oidc:
+    clientId: my-sso-client-id
+    issuer: https://example.com/oidc
+    scopes:
+      - openid
+    resource: vault
+`,
+			expectError:   true,
+			errorContains: "invalid sso.oidc.resource 'vault': must be an absolute URI without a fragment",
+		},
 		{
 			name: "Config without SSO",
 			yamlContent: `
@@ -911,6 +947,85 @@ sso:
 			expectError:   true,
 			errorContains: "sso.oidc.scopes is required",
 		},
+		{
+			name: "SSO config with named identities",
+			yamlContent: `
+sso:
+  oidc:
+    default:
+      clientId: cluster-a-client
+      issuer: https://a.example.com/oidc
+      scopes: [openid]
+    cluster-b:
+      clientId: cluster-b-client
+      issuer: https://b.example.com/oidc
+      scopes: openid profile
+providers:
+  - kind: dotenv
+    path: .env
+    sso_ref: cluster-b
+`,
+			expectError: false,
+			validateFunc: func(t *testing.T, cfg *config.Config) {
+				if len(cfg.SSO.Identities) != 2 {
+					t.Fatalf("expected 2 SSO identities, got %d", len(cfg.SSO.Identities))
+				}
+				if cfg.SSO.OIDC == nil || cfg.SSO.OIDC.ClientID != "cluster-a-client" {
+					t.Errorf("expected the 'default' identity as OIDC config, got %+v", cfg.SSO.OIDC)
+				}
+				clusterB := cfg.SSO.Identity("cluster-b")
+				if clusterB == nil || clusterB.ClientID != "cluster-b-client" || len(clusterB.Scopes) != 2 {
+					t.Errorf("expected the 'cluster-b' identity, got %+v", clusterB)
+				}
+				if cfg.Providers[0].SSORef != "cluster-b" {
+					t.Errorf("expected SSORef='cluster-b', got '%s'", cfg.Providers[0].SSORef)
+				}
+				if _, ok := cfg.Providers[0].Config["sso_ref"]; ok {
+					t.Error("expected sso_ref not to be passed to the provider")
+				}
+			},
+		},
+		{
+			name: "SSO config with named identity missing clientId",
+			yamlContent: `
+sso:
+  oidc:
+    cluster-b:
+      issuer: https://b.example.com/oidc
+      scopes: [openid]
+`,
+			expectError:   true,
+			errorContains: "sso.oidc.cluster-b.clientId is required",
+		},
+		{
+			name: "SSO config with invalid identity name",
+			yamlContent: `
+sso:
+  oidc:
+    cluster/b:
+      clientId: cluster-b-client
+      issuer: https://b.example.com/oidc
+      scopes: [openid]
+`,
+			expectError:   true,
+			errorContains: "invalid sso.oidc identity name 'cluster/b'",
+		},
+		{
+			name: "Provider referencing unknown SSO identity",
+			yamlContent: `
+sso:
+  oidc:
+    clientId: my-sso-client-id
+    issuer: https://example.com/oidc
+    scopes: [openid]
+providers:
+  - kind: dotenv
+    path: .env
+    sso_ref: cluster-b
+`,
+			expectError:   true,
+			errorContains: "provider 'dotenv': sso_ref references unknown sso identity 'cluster-b'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,96 @@
+package end2end
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_InferType tests the value types inferred for the output contract
+func TestE2E_InferType(t *testing.T) {
+	tests := map[string]string{
+		"hunter2":          secrets.ContractTypeString,
+		"":                 secrets.ContractTypeString,
+		"5432":             secrets.ContractTypeNumber,
+		"-1.5e3":           secrets.ContractTypeNumber,
+		"true":             secrets.ContractTypeBool,
+		"FALSE":            secrets.ContractTypeBool,
+		`{"user":"admin"}`: secrets.ContractTypeJSON,
+		`["a","b"]`:        secrets.ContractTypeJSON,
+		"{not json":        secrets.ContractTypeString,
+		"NaN":              secrets.ContractTypeString,
+		"0x1F":             secrets.ContractTypeString,
+	}
+	for value, want := range tests {
+		if got := secrets.InferType(value); got != want {
+			t.Errorf("InferType(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+// TestE2E_Env_Contract tests that env --contract lists every key with its type and no values
+func TestE2E_Env_Contract(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      CONTRACT_PASSWORD: s3cr3t-value
+      CONTRACT_PORT: "5432"
+      CONTRACT_DEBUG: "true"
+      CONTRACT_SETTINGS: '{"region":"eu-west-1"}'
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	contractFile := filepath.Join(tmpDir, "contract.json")
+	cmd := exec.Command(sstartBinary, "--config", configFile, "env", "--format", "json", "--contract", contractFile)
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sstart env failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "s3cr3t-value") {
+		t.Errorf("Expected secrets to still be exported, got: %s", output)
+	}
+
+	data, err := os.ReadFile(contractFile)
+	if err != nil {
+		t.Fatalf("Failed to read contract file: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t-value") || strings.Contains(string(data), "eu-west-1") {
+		t.Errorf("Contract must not contain values, got: %s", data)
+	}
+
+	var contract secrets.Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to parse contract: %v\n%s", err, data)
+	}
+	if contract.Version != secrets.ContractVersion {
+		t.Errorf("Expected contract version %d, got %d", secrets.ContractVersion, contract.Version)
+	}
+
+	want := []secrets.ContractKey{
+		{Name: "CONTRACT_DEBUG", Type: secrets.ContractTypeBool},
+		{Name: "CONTRACT_PASSWORD", Type: secrets.ContractTypeString},
+		{Name: "CONTRACT_PORT", Type: secrets.ContractTypeNumber},
+		{Name: "CONTRACT_SETTINGS", Type: secrets.ContractTypeJSON},
+	}
+	if len(contract.Keys) != len(want) {
+		t.Fatalf("Expected %d keys in the contract, got %v", len(want), contract.Keys)
+	}
+	for i, key := range want {
+		if contract.Keys[i] != key {
+			t.Errorf("Expected contract key %d to be %v, got %v", i, key, contract.Keys[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package end2end
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_DenyKeys tests that denied keys are dropped from collected secrets
+func TestE2E_DenyKeys(t *testing.T) {
+	providers := `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      LD_PRELOAD: /tmp/evil.so
+      PATH: /tmp/evil-bin
+`
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		wantPresent []string
+		wantDropped []string
+	}{
+		{
+			name:        "default blocklist",
+			configYAML:  providers,
+			wantPresent: []string{"API_KEY"},
+			wantDropped: []string{"LD_PRELOAD", "PATH"},
+		},
+		{
+			name:        "custom blocklist allows LD_PRELOAD",
+			configYAML:  "deny_keys: [PATH]\n" + providers,
+			wantPresent: []string{"API_KEY", "LD_PRELOAD"},
+			wantDropped: []string{"PATH"},
+		},
+		{
+			name:        "empty blocklist disables denial",
+			configYAML:  "deny_keys: []\n" + providers,
+			wantPresent: []string{"API_KEY", "LD_PRELOAD", "PATH"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			for _, key := range tt.wantPresent {
+				if _, exists := collectedSecrets[key]; !exists {
+					t.Errorf("Expected %s to be collected", key)
+				}
+			}
+			for _, key := range tt.wantDropped {
+				if _, exists := collectedSecrets[key]; exists {
+					t.Errorf("Expected %s to be dropped by deny_keys", key)
+				}
+			}
+		})
+	}
+}
+
+// TestE2E_DenyKeys_Default tests that the default blocklist is applied when deny_keys is omitted
+func TestE2E_DenyKeys_Default(t *testing.T) {
+	cfg := loadMockConfig(t, "providers:\n  - kind: mock\n")
+	if len(cfg.DenyKeys) != len(config.DefaultDenyKeys) {
+		t.Errorf("Expected default deny_keys %v, got %v", config.DefaultDenyKeys, cfg.DenyKeys)
+	}
+}
@@ -0,0 +1,141 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_DependsOn_Order tests that a provider runs after the providers in its depends_on,
+// even when it is declared before them or depends on a conditional provider
+func TestE2E_DependsOn_Order(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: consumer
+    depends_on: [setup]
+    values:
+      CONSUMER_KEY: consumer-value
+  - kind: mock
+    id: other
+    values:
+      OTHER_KEY: other-value
+  - kind: mock
+    id: setup
+    values:
+      SETUP_READY: "true"
+  - kind: mock
+    id: late
+    depends_on: [gated]
+    values:
+      LATE_KEY: late-value
+  - kind: mock
+    id: gated
+    requires:
+      provider: setup
+      key: SETUP_READY
+      equals: "true"
+    values:
+      GATED_KEY: gated-value
+`)
+
+	// Position of each provider's start and finish among the events
+	started := make(map[string]int)
+	finished := make(map[string]int)
+	var count int
+	collector := secrets.NewCollector(cfg, secrets.WithEventHandler(func(event secrets.Event) {
+		switch event.Type {
+		case secrets.EventProviderStart:
+			started[event.Provider] = count
+		case secrets.EventProviderFinish:
+			finished[event.Provider] = count
+		}
+		count++
+	}))
+	defer collector.Close()
+	collected, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	for dependent, prerequisite := range map[string]string{"consumer": "setup", "late": "gated"} {
+		if started[dependent] < finished[prerequisite] {
+			t.Errorf("Expected '%s' to start after '%s' finished (events: started %v, finished %v)", dependent, prerequisite, started, finished)
+		}
+	}
+	for _, key := range []string{"CONSUMER_KEY", "OTHER_KEY", "SETUP_READY", "LATE_KEY", "GATED_KEY"} {
+		if _, ok := collected[key]; !ok {
+			t.Errorf("Expected %s to be collected, got %v", key, collected)
+		}
+	}
+
+	t.Run("unselected_dependency", func(t *testing.T) {
+		collector := secrets.NewCollector(cfg)
+		defer collector.Close()
+		collected, err := collector.Collect(context.Background(), []string{"consumer"})
+		if err != nil {
+			t.Fatalf("Failed to collect secrets: %v", err)
+		}
+		if len(collected) != 1 || collected["CONSUMER_KEY"] != "consumer-value" {
+			t.Errorf("Expected only the consumer's secrets, got %v", collected)
+		}
+	})
+}
+
+// TestE2E_DependsOn_Invalid tests that cycles, self references and unknown providers in depends_on are rejected
+func TestE2E_DependsOn_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers string
+		wantErr   string
+	}{
+		{
+			name: "cycle",
+			providers: `
+  - kind: mock
+    id: first
+    depends_on: [second]
+  - kind: mock
+    id: second
+    depends_on: [third]
+  - kind: mock
+    id: third
+    depends_on: [first]`,
+			wantErr: "depends_on forms a cycle: first -> second -> third -> first",
+		},
+		{
+			name: "self",
+			providers: `
+  - kind: mock
+    id: first
+    depends_on: [first]`,
+			wantErr: "provider 'first': depends_on cannot reference itself",
+		},
+		{
+			name: "unknown",
+			providers: `
+  - kind: mock
+    id: first
+    depends_on: [missing]`,
+			wantErr: "provider 'first': depends_on references unknown provider 'missing'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+			if err := os.WriteFile(configFile, []byte("providers:"+tt.providers+"\n"), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			_, err := config.Load(configFile)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestE2E_Run_EnvFile tests that run --env-file passes the command a private dotenv file with the secrets,
+// and removes it when the command exits, including when sstart forwards a signal to it
+func TestE2E_Run_EnvFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      ENV_FILE_TOKEN: tok-1234567890
+      ENV_FILE_QUOTED: it's "quoted" here
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	t.Run("exits", func(t *testing.T) {
+		cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--env-file", "--",
+			"sh", "-c", `echo "$SSTART_ENV_FILE"; cat "$SSTART_ENV_FILE"`)
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("sstart run --env-file failed: %v\nOutput: %s", err, output)
+		}
+
+		lines := strings.SplitN(string(output), "\n", 2)
+		path := lines[0]
+		if path == "" || !filepath.IsAbs(path) {
+			t.Fatalf("Expected SSTART_ENV_FILE to hold an absolute path, got:\n%s", output)
+		}
+		for _, expected := range []string{"ENV_FILE_TOKEN='tok-1234567890'\n", `ENV_FILE_QUOTED="it's \"quoted\" here"`} {
+			if len(lines) < 2 || !strings.Contains(lines[1], expected) {
+				t.Errorf("Expected %q in the env file, got:\n%s", expected, output)
+			}
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected the env file to be removed after the command exited, got: %v", err)
+		}
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		pathFile := filepath.Join(t.TempDir(), "env-file-path")
+		script := `echo "$SSTART_ENV_FILE" > "` + pathFile + `"; trap 'exit 0' TERM; while true; do sleep 0.1; done`
+		cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--env-file", "--", "sh", "-c", script)
+		cmd.Dir = tmpDir
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Failed to start sstart: %v", err)
+		}
+		defer func() { _ = cmd.Process.Kill() }()
+
+		var path string
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if data, err := os.ReadFile(pathFile); err == nil && strings.HasSuffix(string(data), "\n") {
+				path = strings.TrimSpace(string(data))
+				break
+			}
+		}
+		if path == "" {
+			t.Fatal("Timed out waiting for the command to start")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Expected the env file to exist while the command runs: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected the env file to have 0600 permissions, got %o", perm)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "ENV_FILE_TOKEN='tok-1234567890'\n") {
+			t.Errorf("Expected the secrets in the env file, got %q (%v)", data, err)
+		}
+
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			t.Fatalf("Failed to signal sstart: %v", err)
+		}
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Expected sstart to exit with the command, got: %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected the env file to be removed after the signal, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_ExcludeKeys tests that exclude_keys and keys mapped to "!" load every key except the excluded ones
+func TestE2E_ExcludeKeys(t *testing.T) {
+	ctx := context.Background()
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("DB_HOST=localhost\nDB_PASSWORD=secret\nDEBUG=true\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		configYAML string
+		expected   map[string]string
+	}{
+		{
+			name: "exclude_keys loads all other keys",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      DB_HOST: localhost
+      DEBUG: "true"
+    exclude_keys: [DEBUG]
+`,
+			expected: map[string]string{"API_KEY": "key", "DB_HOST": "localhost"},
+		},
+		{
+			name: "keys mapped to ! load all other keys",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      DB_HOST: localhost
+      DEBUG: "true"
+    keys:
+      DEBUG: "!"
+      DB_HOST: "!"
+`,
+			expected: map[string]string{"API_KEY": "key"},
+		},
+		{
+			name: "mappings stay an allowlist and exclusions win",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      DB_HOST: localhost
+      DEBUG: "true"
+    keys:
+      API_KEY: ==
+      DB_HOST: DATABASE_HOST
+    exclude_keys: [DB_HOST]
+`,
+			expected: map[string]string{"API_KEY": "key"},
+		},
+		{
+			name: "case-insensitive exclusion",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    values:
+      api_key: key
+      debug: "true"
+    exclude_keys: [DEBUG]
+`,
+			expected: map[string]string{"api_key": "key"},
+		},
+		{
+			name: "dotenv provider",
+			configYAML: `
+providers:
+  - kind: dotenv
+    path: ` + envFile + `
+    exclude_keys: [DB_PASSWORD]
+`,
+			expected: map[string]string{"DB_HOST": "localhost", "DEBUG": "true"},
+		},
+		{
+			name: "strict keys only checks the mapping",
+			configYAML: `
+providers:
+  - kind: mock
+    strict_keys: true
+    values:
+      API_KEY: key
+      DEBUG: "true"
+    keys:
+      DEBUG: "!"
+`,
+			expected: map[string]string{"API_KEY": "key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/aws"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+const expandJSONSecret = `{"API_KEY":"aws-api-key","DB_PASSWORD":"aws-db-password"}`
+
+// newSecretsManagerStub starts a server answering GetSecretValue with secretString for any secret id
+func newSecretsManagerStub(t *testing.T, secretString string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			http.Error(w, "unsupported operation", http.StatusBadRequest)
+			return
+		}
+
+		var input struct {
+			SecretId string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"ARN":          "arn:aws:secretsmanager:us-east-1:000000000000:secret:" + input.SecretId,
+			"Name":         input.SecretId,
+			"SecretString": secretString,
+			"VersionId":    "v1",
+		})
+	}))
+}
+
+// TestE2E_ExpandJSON tests JSON expansion of an AWS secret turned on and off globally and per provider
+func TestE2E_ExpandJSON(t *testing.T) {
+	server := newSecretsManagerStub(t, expandJSONSecret)
+	defer server.Close()
+
+	providerYAML := `
+  - kind: aws_secretsmanager
+    id: app-config
+    secret_id: myapp/config
+    region: us-east-1
+    endpoint: ` + server.URL
+
+	tests := []struct {
+		name       string
+		configYAML string
+		opts       []secrets.CollectorOption
+		expected   map[string]string
+	}{
+		{
+			name:       "expanded by default",
+			configYAML: "providers:" + providerYAML,
+			expected:   map[string]string{"API_KEY": "aws-api-key", "DB_PASSWORD": "aws-db-password"},
+		},
+		{
+			name:       "disabled globally",
+			configYAML: "expand_json: false\nproviders:" + providerYAML,
+			expected:   map[string]string{"APP_CONFIG_SECRET": expandJSONSecret},
+		},
+		{
+			name:       "disabled per provider with a named key",
+			configYAML: "providers:" + providerYAML + "\n    expand_json: false\n    keys:\n      APP_CONFIG_SECRET: APP_CONFIG_JSON",
+			expected:   map[string]string{"APP_CONFIG_JSON": expandJSONSecret},
+		},
+		{
+			name:       "provider setting wins over global",
+			configYAML: "expand_json: false\nproviders:" + providerYAML + "\n    expand_json: true",
+			expected:   map[string]string{"API_KEY": "aws-api-key", "DB_PASSWORD": "aws-db-password"},
+		},
+		{
+			name:       "collector option overrides global",
+			configYAML: "expand_json: true\nproviders:" + providerYAML,
+			opts:       []secrets.CollectorOption{secrets.WithExpandJSON(false)},
+			expected:   map[string]string{"APP_CONFIG_SECRET": expandJSONSecret},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML+"\n")
+			collectedSecrets, err := secrets.NewCollector(cfg, tt.opts...).Collect(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %v", len(tt.expected), collectedSecrets)
+			}
+			for key, want := range tt.expected {
+				if got := collectedSecrets[key]; got != want {
+					t.Errorf("Expected %s=%q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestE2E_ExpandJSON_Flag tests that --no-expand-json delivers the JSON secret as a single value
+func TestE2E_ExpandJSON_Flag(t *testing.T) {
+	server := newSecretsManagerStub(t, expandJSONSecret)
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: aws_secretsmanager
+    id: app-config
+    secret_id: myapp/config
+    region: us-east-1
+    endpoint: ` + server.URL + `
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--no-expand-json", "get", "APP_CONFIG_SECRET")
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sstart get failed: %v\nOutput: %s", err, output)
+	}
+	if strings.TrimSpace(string(output)) != expandJSONSecret {
+		t.Errorf("Expected the raw JSON secret, got %q", output)
+	}
+}
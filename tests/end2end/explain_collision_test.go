@@ -0,0 +1,151 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_ExplainCollision tests the report of keys supplied by several providers
+func TestE2E_ExplainCollision(t *testing.T) {
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+		expected    []string // Lines expected in the report
+		unexpected  []string // Substrings that must not appear in the report
+	}{
+		{
+			name: "cross-provider collision",
+			configYAML: `
+providers:
+  - kind: mock
+    id: dotenv
+    values:
+      DB_PASSWORD: local-password
+      ONLY_LOCAL: local
+  - kind: mock
+    id: vault
+    values:
+      DB_PASSWORD: vault-password-123
+`,
+			expected: []string{
+				"Key collision report:",
+				"  DB_PASSWORD: supplied by 2 providers, kept 'vault': it comes last in configuration order",
+				"    - dotenv: length 14, lo****rd",
+				"    - vault: length 18, va****23 (kept)",
+			},
+			unexpected: []string{"ONLY_LOCAL", "local-password", "vault-password-123"},
+		},
+		{
+			name: "source priority",
+			configYAML: `
+source_priority:
+  API_KEY: [first]
+providers:
+  - kind: mock
+    id: first
+    values:
+      API_KEY: first-key
+  - kind: mock
+    id: second
+    values:
+      API_KEY: second-key
+`,
+			expected: []string{
+				"  API_KEY: supplied by 2 providers, kept 'first': it ranks highest in source_priority",
+				"    - first: length 9, fi****ey (kept)",
+				"    - second: length 10, se****ey",
+			},
+		},
+		{
+			name: "merge strategy",
+			configYAML: `
+merge_strategy:
+  PATH_LIST:
+    strategy: concat
+providers:
+  - kind: mock
+    id: first
+    values:
+      PATH_LIST: /a
+  - kind: mock
+    id: second
+    values:
+      PATH_LIST: /b
+`,
+			expected: []string{
+				"  PATH_LIST: supplied by 2 providers, values combined with the 'concat' merge strategy",
+				"    - first: length 2, ****",
+				"    - second: length 2, ****",
+			},
+			unexpected: []string{"(kept)"},
+		},
+		{
+			name: "collision failing the collection",
+			configYAML: `
+uppercase_keys: true
+providers:
+  - kind: mock
+    values:
+      db_host: lower
+      DB_HOST: upper
+`,
+			expectError: "collide as 'DB_HOST'",
+			expected: []string{
+				"  DB_HOST: collection failed: uppercase_keys: keys 'DB_HOST' and 'db_host' collide as 'DB_HOST'",
+				"    - key 'DB_HOST'",
+				"    - key 'db_host'",
+			},
+		},
+		{
+			name: "no collisions",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      KEY: value
+`,
+			expected: []string{"Key collision report: no key collisions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			var report bytes.Buffer
+			_, err := secrets.NewCollector(cfg, secrets.WithExplainCollisions(&report)).Collect(context.Background(), nil)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			lines := strings.Split(report.String(), "\n")
+			for _, expected := range tt.expected {
+				found := false
+				for _, line := range lines {
+					if line == expected {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected report line %q, got:\n%s", expected, report.String())
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(report.String(), unexpected) {
+					t.Errorf("Expected report without %q, got:\n%s", unexpected, report.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package end2end
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+// TestE2E_Export tests that export prints collected secrets as a JSON object with values unchanged
+func TestE2E_Export(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: vault-prod
+    values:
+      EXPORT_QUOTED: it's "quoted" \ back
+      EXPORT_HTML: <a href="x">&amp;</a>
+      EXPORT_MULTILINE: "line1\nline2"
+  - kind: mock
+    id: other
+    values:
+      EXPORT_OTHER: other-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	export := func(t *testing.T, args ...string) (map[string]string, string) {
+		t.Helper()
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile, "export"}, args...)...)
+		cmd.Dir = tmpDir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("sstart export failed: %v\nStderr: %s", err, stderr.String())
+		}
+		var exported map[string]string
+		if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil {
+			t.Fatalf("Output is not a JSON object: %v\nOutput: %s", err, stdout.String())
+		}
+		return exported, stdout.String()
+	}
+
+	t.Run("all_providers", func(t *testing.T) {
+		exported, raw := export(t)
+		expected := map[string]string{
+			"EXPORT_QUOTED":    `it's "quoted" \ back`,
+			"EXPORT_HTML":      `<a href="x">&amp;</a>`,
+			"EXPORT_MULTILINE": "line1\nline2",
+			"EXPORT_OTHER":     "other-value",
+		}
+		if len(exported) != len(expected) {
+			t.Errorf("Expected %d secrets, got %v", len(expected), exported)
+		}
+		for key, want := range expected {
+			if got := exported[key]; got != want {
+				t.Errorf("Expected %s=%q, got %q", key, want, got)
+			}
+		}
+		if !strings.Contains(raw, `<a href=\"x\">&amp;</a>`) {
+			t.Errorf("Expected HTML characters to be written unescaped, got:\n%s", raw)
+		}
+	})
+
+	t.Run("selected_provider", func(t *testing.T) {
+		exported, _ := export(t, "--providers", "vault-prod")
+		if _, found := exported["EXPORT_OTHER"]; found || len(exported) != 3 {
+			t.Errorf("Expected only the secrets of vault-prod, got %v", exported)
+		}
+	})
+}
+
+// TestE2E_ExportDotenv tests that export --format dotenv writes a file that dotenv parsers read back unchanged
+func TestE2E_ExportDotenv(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: app
+    values:
+      DOTENV_PLAIN: plain-value
+      DOTENV_SPACES: "value with  spaces # not a comment"
+      DOTENV_QUOTES: it's "quoted" \ back
+      DOTENV_MULTILINE: "line1\nit's line2"
+      DOTENV_LITERAL: "a\\nb and a backtick ` + "`" + `"
+      DOTENV_EMPTY: ""
+  - kind: dotenv
+    id: dollars
+    path: dollars.env
+  - kind: mock
+    id: other
+    values:
+      DOTENV_OTHER: other-value
+`
+	// Dollar signs come from a dotenv file, as config values are expanded
+	dollars := "DOTENV_DOLLAR='costs $5 and $NAME'\nDOTENV_DOLLAR_ML=\"it's \\${NAME}\n\\$(id)\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "dollars.env"), []byte(dollars), 0600); err != nil {
+		t.Fatalf("Failed to write dotenv file: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	export := func(t *testing.T, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile, "export", "--format", "dotenv"}, args...)...)
+		cmd.Dir = tmpDir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("sstart export failed: %v\nStderr: %s", err, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	t.Run("round_trip", func(t *testing.T) {
+		outFile := filepath.Join(tmpDir, ".env.generated")
+		if stdout := export(t, "--out", outFile); stdout != "" {
+			t.Errorf("Expected nothing on stdout with --out, got %q", stdout)
+		}
+		info, err := os.Stat(outFile)
+		if err != nil {
+			t.Fatalf("Expected the output file to be written: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected file permissions 0600, got %o", perm)
+		}
+
+		parsed, err := godotenv.Read(outFile)
+		if err != nil {
+			t.Fatalf("Generated file is not readable by godotenv: %v", err)
+		}
+		expected := map[string]string{
+			"DOTENV_PLAIN":     "plain-value",
+			"DOTENV_SPACES":    "value with  spaces # not a comment",
+			"DOTENV_QUOTES":    `it's "quoted" \ back`,
+			"DOTENV_MULTILINE": "line1\nit's line2",
+			"DOTENV_LITERAL":   "a\\nb and a backtick `",
+			"DOTENV_DOLLAR":    "costs $5 and $NAME",
+			"DOTENV_DOLLAR_ML": "it's ${NAME}\n$(id)",
+			"DOTENV_EMPTY":     "",
+			"DOTENV_OTHER":     "other-value",
+		}
+		if len(parsed) != len(expected) {
+			t.Errorf("Expected %d secrets, got %v", len(expected), parsed)
+		}
+		for key, want := range expected {
+			if got := parsed[key]; got != want {
+				t.Errorf("Expected %s=%q, got %q", key, want, got)
+			}
+		}
+	})
+
+	t.Run("sorted_stdout", func(t *testing.T) {
+		stdout := export(t, "--providers", "other")
+		if stdout != "DOTENV_OTHER='other-value'\n" {
+			t.Errorf("Expected only the secrets of 'other', got %q", stdout)
+		}
+		if !strings.HasPrefix(export(t), "DOTENV_DOLLAR=") {
+			t.Errorf("Expected lines sorted by key")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package end2end
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Fingerprint_Stable tests that the fingerprint only depends on the secret set
+func TestE2E_Fingerprint_Stable(t *testing.T) {
+	a := secrets.Fingerprint(map[string]string{"A": "1", "B": "2"})
+	b := secrets.Fingerprint(map[string]string{"B": "2", "A": "1"})
+	if a != b {
+		t.Errorf("Expected equal fingerprints for equal sets, got %s and %s", a, b)
+	}
+	if a == secrets.Fingerprint(map[string]string{"A": "1", "B": "3"}) {
+		t.Error("Expected a different fingerprint when a value changes")
+	}
+	if secrets.Fingerprint(map[string]string{"A": "BC"}) == secrets.Fingerprint(map[string]string{"AB": "C"}) {
+		t.Error("Expected key/value boundaries to affect the fingerprint")
+	}
+	if !strings.HasPrefix(a, secrets.FingerprintPrefix) {
+		t.Errorf("Expected fingerprint prefixed with %s, got %s", secrets.FingerprintPrefix, a)
+	}
+}
+
+// TestE2E_Fingerprint_Baseline tests the baseline gate flags against matching and differing baselines
+func TestE2E_Fingerprint_Baseline(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("GATE_SECRET=v1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	baselineFile := filepath.Join(tmpDir, "baseline.fingerprint")
+	fingerprint := func(extraArgs ...string) error {
+		args := append([]string{"--config", configFile, "fingerprint", "--baseline", baselineFile}, extraArgs...)
+		cmd := exec.Command(sstartBinary, args...)
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Logf("fingerprint %v output: %s", extraArgs, output)
+		}
+		return err
+	}
+
+	// A missing baseline counts as changed
+	if err := fingerprint("--fail-if-changed"); err == nil {
+		t.Error("Expected --fail-if-changed to fail without a baseline")
+	}
+
+	if err := fingerprint("--write-baseline"); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+	data, err := os.ReadFile(baselineFile)
+	if err != nil {
+		t.Fatalf("Failed to read baseline: %v", err)
+	}
+	if strings.Contains(string(data), "v1") {
+		t.Errorf("Baseline must not contain secret values: %s", data)
+	}
+
+	// Matching baseline
+	if err := fingerprint("--fail-if-changed"); err != nil {
+		t.Errorf("Expected --fail-if-changed to pass with matching baseline: %v", err)
+	}
+	if err := fingerprint("--fail-if-unchanged"); err == nil {
+		t.Error("Expected --fail-if-unchanged to fail with matching baseline")
+	}
+
+	// Differing baseline
+	if err := os.WriteFile(envFile, []byte("GATE_SECRET=v2\n"), 0600); err != nil {
+		t.Fatalf("Failed to update env file: %v", err)
+	}
+	if err := fingerprint("--fail-if-unchanged"); err != nil {
+		t.Errorf("Expected --fail-if-unchanged to pass with differing baseline: %v", err)
+	}
+	if err := fingerprint("--fail-if-changed", "--write-baseline"); err == nil {
+		t.Error("Expected --fail-if-changed to fail with differing baseline")
+	}
+
+	// The baseline was updated by the previous run, so the change is now approved
+	if err := fingerprint("--fail-if-changed"); err != nil {
+		t.Errorf("Expected --fail-if-changed to pass after updating the baseline: %v", err)
+	}
+}
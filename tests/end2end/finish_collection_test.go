@@ -0,0 +1,88 @@
+package end2end
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// sharedSession is a session shared by every shared_stub instance, like bw serve for bitwarden providers
+var sharedSession struct {
+	mu       sync.Mutex
+	open     bool
+	opened   int
+	finished int
+}
+
+// sharedStubProvider opens the shared session on its first fetch and reuses it for the rest of the collection
+type sharedStubProvider struct{}
+
+func init() {
+	provider.Register("shared_stub", func() provider.Provider {
+		return &sharedStubProvider{}
+	})
+}
+
+func (p *sharedStubProvider) Name() string {
+	return "shared_stub"
+}
+
+func (p *sharedStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	sharedSession.mu.Lock()
+	defer sharedSession.mu.Unlock()
+	if !sharedSession.open {
+		sharedSession.open = true
+		sharedSession.opened++
+	}
+	return []provider.KeyValue{{Key: "SHARED_" + mapID, Value: "value"}}, nil
+}
+
+func (p *sharedStubProvider) FinishCollection() {
+	sharedSession.mu.Lock()
+	defer sharedSession.mu.Unlock()
+	if sharedSession.open {
+		sharedSession.open = false
+		sharedSession.finished++
+	}
+}
+
+// TestE2E_FinishCollection tests that providers share a session during a collection and tear it down once it is over
+func TestE2E_FinishCollection(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: shared_stub
+    id: first
+  - kind: shared_stub
+    id: second
+  - kind: mock
+    id: plain
+    values:
+      PLAIN: value
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+
+	for i := 1; i <= 2; i++ {
+		collected, err := collector.Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Collection %d failed: %v", i, err)
+		}
+		if collected["SHARED_first"] != "value" || collected["SHARED_second"] != "value" {
+			t.Errorf("Collection %d: unexpected secrets %v", i, collected)
+		}
+
+		sharedSession.mu.Lock()
+		open, opened, finished := sharedSession.open, sharedSession.opened, sharedSession.finished
+		sharedSession.mu.Unlock()
+		if open {
+			t.Errorf("Collection %d: shared session still open after the collection", i)
+		}
+		if opened != i || finished != i {
+			t.Errorf("Collection %d: session opened %d and finished %d times, expected %d", i, opened, finished, i)
+		}
+	}
+}
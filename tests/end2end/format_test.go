@@ -316,6 +316,14 @@ exit 0
 		t.Errorf("Warning should mention the environment variable name 'AWS_NON_JSON_SECRET'. Output: %s", outputStr)
 	}
 
+	// The warning is repeated in the summary printed at the end of the run
+	if !strings.Contains(outputStr, "sstart: 1 warning:\n  - Secret from provider 'aws-non-json' is not JSON format") {
+		t.Errorf("Warning summary should list the non-JSON warning. Output: %s", outputStr)
+	}
+	if strings.Index(outputStr, "sstart: 1 warning:") < strings.Index(outputStr, "SUCCESS") {
+		t.Errorf("Warning summary should be printed after the command output. Output: %s", outputStr)
+	}
+
 	if !strings.Contains(outputStr, "SUCCESS") {
 		t.Errorf("Test script failed. Output: %s", outputStr)
 	}
@@ -0,0 +1,70 @@
+package end2end
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestE2E_Get_OutputVariants tests the raw, newline and base64 output of the get command
+func TestE2E_Get_OutputVariants(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("GET_SECRET=s3cr3t\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	get := func(args ...string) (string, error) {
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile, "get"}, args...)...)
+		cmd.Dir = tmpDir
+		output, err := cmd.Output()
+		return string(output), err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte("s3cr3t"))
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "default is raw", args: []string{"GET_SECRET"}, want: "s3cr3t"},
+		{name: "explicit raw", args: []string{"GET_SECRET", "--raw"}, want: "s3cr3t"},
+		{name: "newline", args: []string{"GET_SECRET", "--newline"}, want: "s3cr3t\n"},
+		{name: "base64", args: []string{"GET_SECRET", "--base64"}, want: encoded},
+		{name: "base64 with newline", args: []string{"GET_SECRET", "--base64", "--newline"}, want: encoded + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := get(tt.args...)
+			if err != nil {
+				t.Fatalf("get %v failed: %v", tt.args, err)
+			}
+			if got != tt.want {
+				t.Errorf("get %v = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := get("MISSING_SECRET"); err == nil {
+		t.Error("Expected an error for a missing secret")
+	}
+	if _, err := get("GET_SECRET", "--raw", "--newline"); err == nil {
+		t.Error("Expected an error when --raw and --newline are combined")
+	}
+}
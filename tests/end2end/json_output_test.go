@@ -0,0 +1,88 @@
+package end2end
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_JSONOutput_PrettyCompact tests that --pretty indents and --compact flattens the JSON outputs,
+// and that output that is not a terminal is compact by default
+func TestE2E_JSONOutput_PrettyCompact(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(`
+providers:
+  - kind: mock
+    values:
+      JSON_OUTPUT_USER: admin
+      JSON_OUTPUT_PORT: "5432"
+`), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		flags  []string
+		pretty bool
+	}{
+		{name: "default", pretty: false},
+		{name: "pretty", flags: []string{"--pretty"}, pretty: true},
+		{name: "compact", flags: []string{"--compact"}, pretty: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractFile := filepath.Join(t.TempDir(), "contract.json")
+			outputs := map[string][]byte{}
+
+			args := append([]string{"--config", configFile}, tt.flags...)
+			output, err := exec.Command(sstartBinary, append(args, "export")...).Output()
+			if err != nil {
+				t.Fatalf("sstart export failed: %v", err)
+			}
+			outputs["export"] = output
+
+			output, err = exec.Command(sstartBinary, append(args, "env", "--format", "json", "--contract", contractFile)...).Output()
+			if err != nil {
+				t.Fatalf("sstart env failed: %v", err)
+			}
+			outputs["env"] = output
+
+			contract, err := os.ReadFile(contractFile)
+			if err != nil {
+				t.Fatalf("Failed to read contract: %v", err)
+			}
+			outputs["contract"] = contract
+
+			for name, data := range outputs {
+				if !json.Valid(data) {
+					t.Errorf("%s: expected valid JSON, got %s", name, data)
+				}
+				indented := strings.Contains(string(data), "\n  ")
+				lines := strings.Count(strings.TrimSuffix(string(data), "\n"), "\n") + 1
+				if tt.pretty && !indented {
+					t.Errorf("%s: expected indented JSON, got %s", name, data)
+				}
+				if !tt.pretty && (indented || lines != 1) {
+					t.Errorf("%s: expected single-line JSON, got %s", name, data)
+				}
+			}
+		})
+	}
+
+	t.Run("pretty_and_compact", func(t *testing.T) {
+		output, err := exec.Command(sstartBinary, "--config", configFile, "--pretty", "--compact", "export").CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected --pretty and --compact to conflict, output: %s", output)
+		}
+		if !strings.Contains(string(output), "none of the others can be") {
+			t.Errorf("Expected a mutually exclusive flags error, got: %s", output)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_KeyPatterns tests that glob and /regex/ sources in 'keys' remap keys in bulk
+func TestE2E_KeyPatterns(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "glob with capture substitution",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      STRIPE_API_KEY: sk
+      STRIPE_WEBHOOK_SECRET: whsec
+      UNRELATED: dropped
+    keys:
+      STRIPE_*: PAYMENT_$1
+`,
+			expected: map[string]string{"PAYMENT_API_KEY": "sk", "PAYMENT_WEBHOOK_SECRET": "whsec"},
+		},
+		{
+			name: "regex with named group",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      STRIPE_LIVE_API_KEY: live
+      STRIPE_LIVE_WEBHOOK_SECRET: whsec
+    keys:
+      /STRIPE_(?:LIVE_)?(?P<name>.*)/: PAYMENT_${name}
+`,
+			expected: map[string]string{"PAYMENT_API_KEY": "live", "PAYMENT_WEBHOOK_SECRET": "whsec"},
+		},
+		{
+			name: "regex keeping names and literal precedence",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      STRIPE_API_KEY: sk
+      STRIPE_PUBLIC_KEY: pk
+      DB_HOST: localhost
+    keys:
+      /STRIPE_.*_KEY/: ==
+      STRIPE_PUBLIC_KEY: PUBLISHABLE_KEY
+      DB_HOST: ==
+`,
+			expected: map[string]string{"STRIPE_API_KEY": "sk", "PUBLISHABLE_KEY": "pk", "DB_HOST": "localhost"},
+		},
+		{
+			name: "excluded pattern",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      STRIPE_API_KEY: sk
+      STRIPE_TEST_API_KEY: test
+      DB_HOST: localhost
+    keys:
+      STRIPE_TEST_*: "!"
+`,
+			expected: map[string]string{"STRIPE_API_KEY": "sk", "DB_HOST": "localhost"},
+		},
+		{
+			name: "case-insensitive pattern",
+			configYAML: `
+providers:
+  - kind: mock
+    case_insensitive_keys: true
+    values:
+      stripe_api_key: sk
+    keys:
+      STRIPE_*: PAYMENT_$1
+`,
+			expected: map[string]string{"PAYMENT_api_key": "sk"},
+		},
+		{
+			name: "strict keys report keys matching no pattern",
+			configYAML: `
+providers:
+  - kind: mock
+    strict_keys: true
+    values:
+      STRIPE_API_KEY: sk
+      UNRELATED: dropped
+    keys:
+      STRIPE_?PI_KEY: ==
+`,
+			expectError: "returned keys not listed in 'keys' (strict keys): UNRELATED",
+		},
+		{
+			name: "several matching patterns",
+			configYAML: `
+providers:
+  - kind: mock
+    id: ambiguous
+    values:
+      STRIPE_API_KEY: sk
+    keys:
+      STRIPE_*: A_$1
+      "*_KEY": B_$1
+`,
+			expectError: "provider 'ambiguous': source key 'STRIPE_API_KEY' matches several 'keys' patterns: *_KEY, STRIPE_*",
+		},
+		{
+			name: "single-character glob star",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      HOST: localhost
+      PORT: "5432"
+    keys:
+      "*": APP_$1
+`,
+			expected: map[string]string{"APP_HOST": "localhost", "APP_PORT": "5432"},
+		},
+		{
+			name: "single-character glob question mark",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      A: first
+      AB: dropped
+    keys:
+      "?": KEY_$1
+`,
+			expected: map[string]string{"KEY_A": "first"},
+		},
+		{
+			name: "invalid regex",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      KEY: value
+    keys:
+      /KEY(/: ==
+`,
+			expectError: "invalid 'keys' pattern '/KEY(/'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_KeysTemplate tests that keys_template computes target key names from source key names
+func TestE2E_KeysTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "strip a prefix",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      APP_DB_HOST: localhost
+      APP_DB_PORT: "5432"
+      OTHER: other
+    keys_template: '{{ .key | trimPrefix "APP_" }}'
+`,
+			expected: map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432", "OTHER": "other"},
+		},
+		{
+			name: "add a prefix",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      api-key: key
+    keys_template: 'MYAPP_{{ .key | replace "-" "_" | upper }}'
+`,
+			expected: map[string]string{"MYAPP_API_KEY": "key"},
+		},
+		{
+			name: "applied after keys and exclusions",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      APP_DB_HOST: localhost
+      APP_DEBUG: "true"
+      PASSWORD: secret
+    keys:
+      APP_DB_HOST: ==
+      PASSWORD: APP_DB_PASSWORD
+      APP_DEBUG: "!"
+    keys_template: '{{ .key | trimPrefix "APP_" }}'
+`,
+			expected: map[string]string{"DB_HOST": "localhost", "DB_PASSWORD": "secret"},
+		},
+		{
+			name: "empty name",
+			configYAML: `
+providers:
+  - kind: mock
+    id: empty
+    values:
+      APP_: value
+    keys_template: '{{ .key | trimPrefix "APP_" }}'
+`,
+			expectError: "provider 'empty': keys_template renders an empty name for key 'APP_'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+		})
+	}
+}
+
+// TestE2E_KeysTemplate_Invalid tests that an invalid keys_template is rejected when loading the configuration
+func TestE2E_KeysTemplate_Invalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      KEY: value
+    keys_template: '{{ .key | unknownFunc }}'
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid keys_template") {
+		t.Errorf("Expected an invalid keys_template error, got: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_ProviderLabels tests that a provider's labels are attached to its collection events
+func TestE2E_ProviderLabels(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: payments-db
+    labels:
+      team: payments
+      tier: 1
+    values:
+      DB_PASSWORD: secret
+  - kind: mock
+    id: shared
+    values:
+      LOG_LEVEL: info
+`)
+
+	var events []secrets.Event
+	var stream bytes.Buffer
+	collector := secrets.NewCollector(cfg,
+		secrets.WithEventHandler(func(event secrets.Event) {
+			events = append(events, event)
+		}),
+		secrets.WithEventHandler(secrets.JSONEventWriter(&stream)),
+	)
+	if _, err := collector.Collect(context.Background(), nil); err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %+v", events)
+	}
+	for _, event := range events {
+		switch event.Provider {
+		case "payments-db":
+			if event.Labels["team"] != "payments" || event.Labels["tier"] != "1" || len(event.Labels) != 2 {
+				t.Errorf("Expected the payments-db labels on its %s event, got %v", event.Type, event.Labels)
+			}
+		case "shared":
+			if event.Labels != nil {
+				t.Errorf("Expected no labels on the shared %s event, got %v", event.Type, event.Labels)
+			}
+		}
+	}
+
+	// Labels are part of the JSON events, and omitted for providers without labels
+	for _, line := range strings.Split(strings.TrimSpace(stream.String()), "\n") {
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Failed to parse event %q: %v", line, err)
+		}
+		labels, found := event["labels"]
+		if event["provider"] == "payments-db" {
+			if got, _ := labels.(map[string]interface{}); got["team"] != "payments" {
+				t.Errorf("Expected team=payments in the JSON event, got %s", line)
+			}
+		} else if found {
+			t.Errorf("Expected no labels in the JSON event, got %s", line)
+		}
+	}
+}
+
+// TestE2E_ProviderLabels_Invalid tests that labels must be a mapping of scalar values
+func TestE2E_ProviderLabels_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      string
+		expectError string
+	}{
+		{name: "not a mapping", labels: "labels: [payments]", expectError: "invalid labels: must be a mapping"},
+		{name: "nested value", labels: "labels:\n      team: [payments]", expectError: "invalid label 'team'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+			configYAML := "providers:\n  - kind: mock\n    " + tt.labels + "\n"
+			if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			_, err := config.Load(configFile)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing '%s', got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+)
+
+// TestE2E_LoginLogout tests that 'sstart login' stores SSO tokens and 'sstart logout' removes them
+func TestE2E_LoginLogout(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	issuer := newDeviceFlowIssuer(t)
+	configFile := writeDeviceFlowConfig(t, tmpDir, issuer.URL)
+	configHome := t.TempDir()
+	tokenPath := filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName)
+
+	// run executes sstart with the config and an isolated config home
+	run := func(t *testing.T, args ...string) (string, string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, sstartBinary, append([]string{"--config", configFile}, args...)...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, oidc.SSOSecretEnvVar+"=")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("login", func(t *testing.T) {
+		stdout, stderr, err := run(t, "--auth-flow", "device", "login")
+		if err != nil {
+			t.Fatalf("sstart login failed: %v\nStderr: %s", err, stderr)
+		}
+		for _, want := range []string{"Logged in.", "Storage:   file (" + tokenPath + ")", "Expires:"} {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("Expected login output to contain %q, got: %s", want, stdout)
+			}
+		}
+		if _, err := os.Stat(tokenPath); err != nil {
+			t.Errorf("Expected tokens to be stored: %v", err)
+		}
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		stdout, stderr, err := run(t, "logout")
+		if err != nil {
+			t.Fatalf("sstart logout failed: %v\nStderr: %s", err, stderr)
+		}
+		if !strings.Contains(stdout, "Logged out.") {
+			t.Errorf("Expected 'Logged out.', got: %s", stdout)
+		}
+		if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+			t.Errorf("Expected the token file to be removed, got stat error %v", err)
+		}
+
+		stdout, _, err = run(t, "logout")
+		if err != nil || !strings.Contains(stdout, "Not logged in.") {
+			t.Errorf("Expected a second logout to report 'Not logged in.', got %q, %v", stdout, err)
+		}
+	})
+
+	t.Run("memory_storage", func(t *testing.T) {
+		_, stderr, err := run(t, "--token-storage", "memory", "login")
+		if err == nil {
+			t.Fatal("Expected login with memory token storage to fail")
+		}
+		if !strings.Contains(stderr, "token storage 'memory' does not keep tokens") {
+			t.Errorf("Expected a memory token storage error, got: %s", stderr)
+		}
+	})
+
+	t.Run("unknown_identity", func(t *testing.T) {
+		_, stderr, err := run(t, "login", "--identity", "cluster-b")
+		if err == nil {
+			t.Fatal("Expected login with an unknown identity to fail")
+		}
+		if !strings.Contains(stderr, "sso identity 'cluster-b' not found in config file") {
+			t.Errorf("Expected an unknown identity error, got: %s", stderr)
+		}
+	})
+}
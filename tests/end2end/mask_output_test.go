@@ -0,0 +1,91 @@
+package end2end
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Run_MaskOutput tests that --mask-output replaces collected values in the command's stdout and stderr,
+// including values split across writes and lines
+func TestE2E_Run_MaskOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      MASK_API_TOKEN: tok-1234567890
+      MASK_CERT: "cert-line-one\ncert-line-two"
+      MASK_SHORT: on
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// The token is written in two parts, the certificate spans two lines and the last line has no newline
+	script := `echo "token=$MASK_API_TOKEN"
+printf 'split=tok-12345'; sleep 0.2; printf '67890 done\n'
+echo "$MASK_CERT"
+echo "stderr=$MASK_API_TOKEN" >&2
+echo "short=$MASK_SHORT"
+printf 'last=%s' "$MASK_API_TOKEN"`
+
+	run := func(extraArgs ...string) (string, string, error) {
+		args := append([]string{"--config", configFile, "run"}, extraArgs...)
+		args = append(args, "--", "sh", "-c", script)
+		cmd := exec.Command(sstartBinary, args...)
+		cmd.Dir = tmpDir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		stdout, stderr, err := run()
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr)
+		}
+		if !strings.Contains(stdout, "token=tok-1234567890") {
+			t.Errorf("Expected unmasked output without --mask-output, got: %s", stdout)
+		}
+	})
+
+	t.Run("masks", func(t *testing.T) {
+		stdout, stderr, err := run("--mask-output")
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr)
+		}
+		expected := "token=****\nsplit=**** done\n****\nshort=on\nlast=****"
+		if stdout != expected {
+			t.Errorf("Expected stdout %q, got %q", expected, stdout)
+		}
+		if !strings.Contains(stderr, "stderr=****\n") || strings.Contains(stderr, "tok-1234567890") {
+			t.Errorf("Expected the token masked in stderr, got: %s", stderr)
+		}
+	})
+
+	t.Run("min_length", func(t *testing.T) {
+		stdout, stderr, err := run("--mask-output", "--mask-min-length", "2")
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr)
+		}
+		if !strings.Contains(stdout, "short=****\n") {
+			t.Errorf("Expected the short value masked with --mask-min-length 2, got: %s", stdout)
+		}
+	})
+
+	t.Run("min_length_requires_mask_output", func(t *testing.T) {
+		_, stderr, err := run("--mask-min-length", "2")
+		if err == nil || !strings.Contains(stderr, "--mask-min-length requires --mask-output") {
+			t.Errorf("Expected --mask-min-length without --mask-output to fail, got: %v\nStderr: %s", err, stderr)
+		}
+	})
+}
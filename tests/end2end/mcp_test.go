@@ -160,7 +160,7 @@ mcp:
   servers:
     - id: test
 `,
-			wantError: "command is required",
+			wantError: "command or mcp.servers[0].url is required",
 		},
 		{
 			name: "duplicate server id",
@@ -182,6 +182,39 @@ mcp:
 `,
 			wantError: "at least one server",
 		},
+		{
+			name: "invalid shutdown timeout",
+			config: `
+mcp:
+  servers:
+    - id: test
+      command: echo
+      shutdown_timeout: soon
+`,
+			wantError: "invalid shutdown_timeout format 'soon' for mcp server 'test'",
+		},
+		{
+			name: "invalid restart policy",
+			config: `
+mcp:
+  servers:
+    - id: test
+      command: echo
+      restart: always
+`,
+			wantError: "restart must be 'never' or 'on-failure', got 'always'",
+		},
+		{
+			name: "invalid namespace separator",
+			config: `
+mcp:
+  namespace_separator: x
+  servers:
+    - id: test
+      command: echo
+`,
+			wantError: "namespace_separator must not contain letters, digits or '%', got 'x'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,95 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_MergeStrategy tests combining a key contributed by two providers with concat and unique
+func TestE2E_MergeStrategy(t *testing.T) {
+	cfg := loadMockConfig(t, `
+merge_strategy:
+  ALLOWED_ORIGINS: concat
+  SCOPES:
+    strategy: unique
+    separator: " "
+  TAGS: unique
+providers:
+  - kind: mock
+    id: base
+    values:
+      ALLOWED_ORIGINS: https://a.example.com
+      SCOPES: read write
+      TAGS: api,web
+      LOG_LEVEL: info
+  - kind: mock
+    id: overlay
+    values:
+      ALLOWED_ORIGINS: https://b.example.com,https://a.example.com
+      SCOPES: write admin
+      TAGS: web, worker
+      LOG_LEVEL: debug
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		"ALLOWED_ORIGINS": "https://a.example.com,https://b.example.com,https://a.example.com",
+		"SCOPES":          "read write admin",
+		"TAGS":            "api,web,worker",
+		"LOG_LEVEL":       "debug",
+	}
+	for key, want := range expected {
+		if got := collectedSecrets[key]; got != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got)
+		}
+	}
+}
+
+// TestE2E_MergeStrategy_SingleProvider tests that a strategy leaves a key from one provider unchanged
+func TestE2E_MergeStrategy_SingleProvider(t *testing.T) {
+	cfg := loadMockConfig(t, `
+merge_strategy:
+  TAGS: unique
+providers:
+  - kind: mock
+    values:
+      TAGS: api,api
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["TAGS"] != "api,api" {
+		t.Errorf("Expected TAGS to be unchanged, got %q", collectedSecrets["TAGS"])
+	}
+}
+
+// TestE2E_MergeStrategy_Invalid tests that an unknown strategy is rejected
+func TestE2E_MergeStrategy_Invalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+merge_strategy:
+  TAGS: append
+providers:
+  - kind: mock
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid merge strategy") {
+		t.Errorf("Expected invalid merge strategy error, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// loadMockConfig writes the given YAML to a temporary config file and loads it
+func loadMockConfig(t *testing.T, configYAML string) *config.Config {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// TestE2E_MockProvider_Collect tests the collector with mock providers and key mappings
+func TestE2E_MockProvider_Collect(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: mock-a
+    values:
+      API_KEY: key-a
+      DB_HOST: host-a
+    keys:
+      API_KEY: ==
+  - kind: mock
+    id: mock-b
+    values:
+      DB_HOST: host-b
+      DB_PORT: 5432
+      DB_SSL: true
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		"API_KEY": "key-a",
+		"DB_HOST": "host-b",
+		"DB_PORT": "5432",
+		"DB_SSL":  "true",
+	}
+	if len(collectedSecrets) != len(expected) {
+		t.Errorf("Expected %d secrets, got %d: %v", len(expected), len(collectedSecrets), collectedSecrets)
+	}
+	for key, value := range expected {
+		if collectedSecrets[key] != value {
+			t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+		}
+	}
+}
+
+// TestE2E_MockProvider_Fail tests that a failing mock provider fails the collection
+func TestE2E_MockProvider_Fail(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: healthy
+    values:
+      API_KEY: key
+  - kind: mock
+    id: broken
+    fail: true
+    error: backend unavailable
+`)
+
+	_, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Expected collection to fail, got none")
+	}
+	if !strings.Contains(err.Error(), "provider 'broken'") || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestE2E_MockProvider_Delay tests that a slow mock provider honors the collection context deadline
+func TestE2E_MockProvider_Delay(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    delay: 5s
+    values:
+      API_KEY: key
+`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+	if err == nil {
+		t.Fatal("Expected collection to time out, got none")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Collection did not stop at the deadline, took %v", elapsed)
+	}
+}
@@ -0,0 +1,56 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Run_NoDump tests that the command receives its secrets intact when sstart zeroes its own copy
+// of the environment after starting it
+func TestE2E_Run_NoDump(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      NO_DUMP_SECRET: s3cr3t-value
+      NO_DUMP_OTHER: other-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	envBinary, err := exec.LookPath("env")
+	if err != nil {
+		t.Skip("env binary not available")
+	}
+
+	for _, args := range [][]string{
+		{"--no-dump", "run", "--", envBinary},
+		{"--no-dump", "run", "--sort-env=false", "--", envBinary},
+		{"--no-dump", "--", envBinary},
+	} {
+		t.Run(strings.Join(args[:len(args)-2], " "), func(t *testing.T) {
+			cmd := exec.Command(sstartBinary, append([]string{"--config", configFile}, args...)...)
+			cmd.Dir = tmpDir
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("sstart failed: %v\nOutput: %s", err, output)
+			}
+			if strings.Contains(string(output), "Failed to disable core dumps") {
+				t.Errorf("Expected core dumps to be disabled, got: %s", output)
+			}
+			for _, expected := range []string{"NO_DUMP_SECRET=s3cr3t-value", "NO_DUMP_OTHER=other-value"} {
+				if !strings.Contains(string(output), expected+"\n") {
+					t.Errorf("Expected %s in the command environment, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package end2end
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// newHealthServer serves a Vault sys/health endpoint and records every requested path
+func newHealthServer(t *testing.T, health string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path != "/v1/sys/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(health))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// TestE2E_Ping tests that providers are probed without fetching secrets
+func TestE2E_Ping(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+	healthy, healthyPaths := newHealthServer(t, `{"initialized":true,"sealed":false,"standby":false,"version":"1.15.0"}`)
+	sealed, _ := newHealthServer(t, `{"initialized":true,"sealed":true,"standby":true,"version":"1.15.0"}`)
+
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    id: vault-prod
+    address: `+healthy.URL+`
+    path: myapp/config
+  - kind: vault
+    id: vault-sealed
+    address: `+sealed.URL+`
+    path: myapp/config
+  - kind: dotenv
+    id: local
+    path: `+envFile+`
+  - kind: dotenv
+    id: missing
+    path: `+filepath.Join(tmpDir, "missing.env")+`
+  - kind: mock
+    values:
+      KEY: value
+`)
+
+	results, err := secrets.NewCollector(cfg).Ping(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	expected := []struct {
+		provider string
+		status   string
+		detail   string
+	}{
+		{"vault-prod", secrets.ProbeOK, healthy.URL + ", active, version 1.15.0"},
+		{"vault-sealed", secrets.ProbeFailed, "is sealed"},
+		{"local", secrets.ProbeOK, "file '" + envFile + "' is readable"},
+		{"missing", secrets.ProbeFailed, "failed to open .env file"},
+		{"mock", secrets.ProbeUnsupported, ""},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Provider != want.provider || got.Status != want.status {
+			t.Errorf("Result %d: expected %s %s, got %s %s (%v)", i, want.provider, want.status, got.Provider, got.Status, got.Err)
+			continue
+		}
+		switch want.status {
+		case secrets.ProbeOK:
+			if got.Detail != want.detail || got.Latency <= 0 {
+				t.Errorf("Provider %s: expected detail %q and a latency, got %q, %s", want.provider, want.detail, got.Detail, got.Latency)
+			}
+		case secrets.ProbeFailed:
+			if got.Err == nil || !strings.Contains(got.Err.Error(), want.detail) {
+				t.Errorf("Provider %s: expected error containing %q, got %v", want.provider, want.detail, got.Err)
+			}
+		}
+	}
+
+	// The probe is read-only: only the health endpoint is requested
+	for _, path := range healthyPaths() {
+		if path != "/v1/sys/health" {
+			t.Errorf("Expected only sys/health to be requested, got %s", path)
+		}
+	}
+}
+
+// TestE2E_Ping_Command tests the output and exit status of sstart ping
+func TestE2E_Ping_Command(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	healthy, _ := newHealthServer(t, `{"initialized":true,"sealed":false,"standby":true,"version":"1.15.0"}`)
+
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: vault
+    id: vault-prod
+    address: ` + healthy.URL + `
+    path: myapp/config
+  - kind: dotenv
+    id: local
+    path: ` + envFile + `
+  - kind: dotenv
+    id: missing
+    path: ` + filepath.Join(tmpDir, "missing.env") + `
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "ping", "--providers", "vault-prod,local")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "VAULT_TOKEN=")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sstart ping failed: %v\nOutput: %s", err, output)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 || strings.Join(strings.Fields(lines[0]), " ") != "PROVIDER KIND STATUS LATENCY DETAIL" {
+		t.Fatalf("Expected a header and 2 providers, got:\n%s", output)
+	}
+	if fields := strings.Fields(lines[1]); len(fields) < 4 || fields[0] != "vault-prod" || fields[2] != "ok" || !strings.Contains(lines[1], "standby, version 1.15.0") {
+		t.Errorf("Expected vault-prod to be ok on a standby node, got: %s", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); len(fields) < 4 || fields[0] != "local" || fields[2] != "ok" {
+		t.Errorf("Expected local to be ok, got: %s", lines[2])
+	}
+	if strings.Contains(string(output), "secret") {
+		t.Errorf("Expected no secret values in the output, got:\n%s", output)
+	}
+
+	cmd = exec.Command(sstartBinary, "--config", configFile, "ping")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "VAULT_TOKEN=")
+	output, err = cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "1 of 3 providers failed the probe") {
+		t.Errorf("Expected sstart ping to fail for the missing file, got: %v\n%s", err, output)
+	}
+}
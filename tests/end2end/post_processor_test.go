@@ -0,0 +1,66 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Collector_PostProcessor tests that post-processors can add derived keys and remove others
+func TestE2E_Collector_PostProcessor(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    values:
+      DB_HOST: db.internal
+      DB_PORT: "5432"
+      DB_DEBUG: "true"
+`)
+
+	addDSN := func(env map[string]string) (map[string]string, error) {
+		env["DB_ADDR"] = env["DB_HOST"] + ":" + env["DB_PORT"]
+		return env, nil
+	}
+	dropDebug := func(env map[string]string) (map[string]string, error) {
+		delete(env, "DB_DEBUG")
+		return env, nil
+	}
+
+	collector := secrets.NewCollector(cfg, secrets.WithPostProcessor(addDSN), secrets.WithPostProcessor(dropDebug))
+	collectedSecrets, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	if got := collectedSecrets["DB_ADDR"]; got != "db.internal:5432" {
+		t.Errorf("Expected derived DB_ADDR 'db.internal:5432', got '%s'", got)
+	}
+	if _, exists := collectedSecrets["DB_DEBUG"]; exists {
+		t.Errorf("Expected DB_DEBUG to be removed by post-processor")
+	}
+	if got := collectedSecrets["DB_HOST"]; got != "db.internal" {
+		t.Errorf("Expected DB_HOST to be kept, got '%s'", got)
+	}
+}
+
+// TestE2E_Collector_PostProcessorError tests that a failing post-processor fails collection
+func TestE2E_Collector_PostProcessorError(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    values:
+      KEY: value
+`)
+
+	failing := func(env map[string]string) (map[string]string, error) {
+		return nil, errors.New("derived value unavailable")
+	}
+
+	_, err := secrets.NewCollector(cfg, secrets.WithPostProcessor(failing)).Collect(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "derived value unavailable") {
+		t.Errorf("Expected post-processor error, got %v", err)
+	}
+}
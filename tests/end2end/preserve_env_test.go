@@ -0,0 +1,81 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Run_PreserveEnv tests that --preserve-env passes specific variables through with inherit: false
+func TestE2E_Run_PreserveEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+inherit: false
+providers:
+  - kind: mock
+    values:
+      PRESERVE_SECRET: secret-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	pathValue := os.Getenv("PATH")
+	if pathValue == "" {
+		t.Skip("PATH is not set")
+	}
+
+	// Resolve env by absolute path since the child starts without the inherited PATH otherwise
+	envBinary, err := exec.LookPath("env")
+	if err != nil {
+		t.Skip("env binary not available")
+	}
+
+	runEnv := func(extraArgs ...string) map[string]string {
+		args := append([]string{"--config", configFile, "run"}, extraArgs...)
+		args = append(args, "--", envBinary)
+		cmd := exec.Command(sstartBinary, args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "PRESERVE_OTHER=other-value")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nOutput: %s", err, output)
+		}
+
+		env := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if key, value, ok := strings.Cut(line, "="); ok {
+				env[key] = value
+			}
+		}
+		return env
+	}
+
+	t.Run("without_preserve_env", func(t *testing.T) {
+		env := runEnv()
+		if _, ok := env["PATH"]; ok {
+			t.Errorf("PATH should not be inherited with inherit: false")
+		}
+		if env["PRESERVE_SECRET"] != "secret-value" {
+			t.Errorf("Expected PRESERVE_SECRET=secret-value, got '%s'", env["PRESERVE_SECRET"])
+		}
+	})
+
+	t.Run("with_preserve_env", func(t *testing.T) {
+		env := runEnv("--preserve-env", "PATH")
+		if env["PATH"] != pathValue {
+			t.Errorf("Expected PATH to pass through, got '%s'", env["PATH"])
+		}
+		if _, ok := env["PRESERVE_OTHER"]; ok {
+			t.Errorf("PRESERVE_OTHER should not be passed through")
+		}
+		if env["PRESERVE_SECRET"] != "secret-value" {
+			t.Errorf("Expected PRESERVE_SECRET=secret-value, got '%s'", env["PRESERVE_SECRET"])
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// warmStubHandshakes counts the logins of every warm_stub instance
+var warmStubHandshakes atomic.Int32
+
+// warmStubProvider logs in once per instance, either when warmed up or on its first fetch
+type warmStubProvider struct {
+	loggedIn bool
+}
+
+func init() {
+	provider.Register("warm_stub", func() provider.Provider {
+		return &warmStubProvider{}
+	})
+}
+
+func (p *warmStubProvider) Name() string {
+	return "warm_stub"
+}
+
+func (p *warmStubProvider) login() {
+	if !p.loggedIn {
+		warmStubHandshakes.Add(1)
+		p.loggedIn = true
+	}
+}
+
+func (p *warmStubProvider) Warm(secretContext provider.SecretContext, config map[string]interface{}) error {
+	p.login()
+	return nil
+}
+
+func (p *warmStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	p.login()
+	return []provider.KeyValue{{Key: "WARM_" + strings.ToUpper(mapID), Value: "warm-value"}}, nil
+}
+
+// TestE2E_Prewarm tests that prewarmed providers are reused by later collections without new handshakes
+func TestE2E_Prewarm(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: warm_stub
+    id: first
+  - kind: warm_stub
+    id: second
+  - kind: mock
+    id: plain
+    values:
+      PLAIN: plain-value
+`)
+	warmStubHandshakes.Store(0)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+	if err := collector.Prewarm(context.Background(), nil); err != nil {
+		t.Fatalf("Prewarm() error = %v", err)
+	}
+	if got := warmStubHandshakes.Load(); got != 2 {
+		t.Fatalf("Expected one handshake per provider while prewarming, got %d", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		collected, err := collector.Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		if collected["WARM_FIRST"] != "warm-value" || collected["WARM_SECOND"] != "warm-value" || collected["PLAIN"] != "plain-value" {
+			t.Errorf("Unexpected secrets: %v", collected)
+		}
+	}
+	if got := warmStubHandshakes.Load(); got != 2 {
+		t.Errorf("Expected no handshakes after prewarming, got %d more", got-2)
+	}
+
+	t.Run("requires_watch", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		sstartBinary := buildSstart(t, tmpDir)
+		configFile := filepath.Join(tmpDir, ".sstart.yml")
+		if err := os.WriteFile(configFile, []byte("providers:\n  - kind: mock\n    values:\n      A: b\n"), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		output, err := exec.Command(sstartBinary, "--config", configFile, "run", "--prewarm", "--", "true").CombinedOutput()
+		if err == nil || !strings.Contains(string(output), "--prewarm requires --watch or --watch-interval") {
+			t.Errorf("Expected --prewarm to require watch mode, got err=%v output: %s", err, output)
+		}
+	})
+}
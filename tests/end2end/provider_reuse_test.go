@@ -0,0 +1,264 @@
+package end2end
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// clientStubProvider lazily builds a client on first fetch and records how often that happens
+type clientStubProvider struct {
+	client *string
+}
+
+var (
+	clientStubConstructed int
+	clientStubClosed      int
+	clientStubMu          sync.Mutex
+)
+
+func init() {
+	provider.Register("client_stub", func() provider.Provider {
+		return &clientStubProvider{}
+	})
+}
+
+func (p *clientStubProvider) Name() string {
+	return "client_stub"
+}
+
+func (p *clientStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	if p.client == nil {
+		clientStubMu.Lock()
+		clientStubConstructed++
+		clientStubMu.Unlock()
+		value, _ := config["value"].(string)
+		p.client = &value
+	}
+	return []provider.KeyValue{{Key: "CLIENT_VALUE", Value: *p.client}}, nil
+}
+
+func (p *clientStubProvider) Close() error {
+	clientStubMu.Lock()
+	defer clientStubMu.Unlock()
+	clientStubClosed++
+	p.client = nil
+	return nil
+}
+
+// resetClientStub clears the construction and close counters between tests
+func resetClientStub() {
+	clientStubMu.Lock()
+	defer clientStubMu.Unlock()
+	clientStubConstructed = 0
+	clientStubClosed = 0
+}
+
+// TestE2E_ProviderReuse_AcrossReloads tests that a provider's client is constructed once across reloads
+// and closed when the collector shuts down
+func TestE2E_ProviderReuse_AcrossReloads(t *testing.T) {
+	resetClientStub()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: client_stub
+    id: reused
+    value: first
+`)
+
+	collector := secrets.NewCollector(cfg)
+	for reload := 0; reload < 2; reload++ {
+		collectedSecrets, err := collector.Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Reload %d: failed to collect secrets: %v", reload, err)
+		}
+		if collectedSecrets["CLIENT_VALUE"] != "first" {
+			t.Errorf("Reload %d: expected CLIENT_VALUE=first, got %v", reload, collectedSecrets)
+		}
+	}
+
+	if clientStubConstructed != 1 {
+		t.Errorf("Expected the client to be constructed once across reloads, got %d", clientStubConstructed)
+	}
+
+	if err := collector.Close(); err != nil {
+		t.Fatalf("Failed to close collector: %v", err)
+	}
+	if clientStubClosed != 1 {
+		t.Errorf("Expected the provider to be closed once on shutdown, got %d", clientStubClosed)
+	}
+}
+
+// TestE2E_ProviderReuse_ConfigChange tests that a configuration change replaces the provider instance
+func TestE2E_ProviderReuse_ConfigChange(t *testing.T) {
+	resetClientStub()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: client_stub
+    id: changing
+    value: first
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+
+	if _, err := collector.Collect(context.Background(), nil); err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	// Simulate a reload with a changed provider configuration
+	cfg.Providers[0].Config["value"] = "second"
+	collectedSecrets, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets after config change: %v", err)
+	}
+	if collectedSecrets["CLIENT_VALUE"] != "second" {
+		t.Errorf("Expected CLIENT_VALUE=second after config change, got %v", collectedSecrets)
+	}
+	if clientStubConstructed != 2 {
+		t.Errorf("Expected a new client after the config change, got %d constructions", clientStubConstructed)
+	}
+	if clientStubClosed != 1 {
+		t.Errorf("Expected the replaced provider to be closed, got %d closes", clientStubClosed)
+	}
+}
+
+// TestE2E_ProviderReuse_TokenRefresh tests that a refreshed SSO token replaces the provider instance,
+// as clients that logged in with the previous token would keep using its credentials
+func TestE2E_ProviderReuse_TokenRefresh(t *testing.T) {
+	resetClientStub()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(oidc.SSOSecretEnvVar, "")
+	writeTokenFile(t, configHome, oidc.TokenFileName, "first-token")
+
+	cfg := loadMockConfig(t, `
+sso:
+  token_storage: file
+  oidc:
+    clientId: reuse-client
+    issuer: https://sso.example.com
+    scopes: [openid]
+providers:
+  - kind: client_stub
+    id: logged-in
+    value: first
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+	for reload := 0; reload < 2; reload++ {
+		if _, err := collector.Collect(context.Background(), nil); err != nil {
+			t.Fatalf("Reload %d: failed to collect secrets: %v", reload, err)
+		}
+	}
+	if clientStubConstructed != 1 {
+		t.Fatalf("Expected the client to be reused while the token is unchanged, got %d constructions", clientStubConstructed)
+	}
+
+	writeTokenFile(t, configHome, oidc.TokenFileName, "refreshed-token")
+	if _, err := collector.Collect(context.Background(), nil); err != nil {
+		t.Fatalf("Failed to collect secrets after the token refresh: %v", err)
+	}
+	if clientStubConstructed != 2 || clientStubClosed != 1 {
+		t.Errorf("Expected the refreshed token to replace the provider, got %d constructions and %d closes", clientStubConstructed, clientStubClosed)
+	}
+}
+
+// blockingClientStubProvider blocks the first fetch until blockingClientRelease is closed, ignoring its
+// context, and records the instances fetching and fetches running concurrently on one instance
+type blockingClientStubProvider struct {
+	running atomic.Int32
+}
+
+var (
+	blockingClientRelease    chan struct{}
+	blockingClientBlocked    atomic.Bool
+	blockingClientInstances  sync.Map
+	blockingClientOverlapped atomic.Bool
+	blockingClientClosed     atomic.Int32
+)
+
+func init() {
+	provider.Register("blocking_client_stub", func() provider.Provider {
+		return &blockingClientStubProvider{}
+	})
+}
+
+func (p *blockingClientStubProvider) Name() string {
+	return "blocking_client_stub"
+}
+
+func (p *blockingClientStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	if p.running.Add(1) > 1 {
+		blockingClientOverlapped.Store(true)
+	}
+	defer p.running.Add(-1)
+	blockingClientInstances.Store(p, true)
+
+	if blockingClientBlocked.CompareAndSwap(false, true) {
+		<-blockingClientRelease
+	}
+	return []provider.KeyValue{{Key: "BLOCKING_VALUE", Value: "fetched"}}, nil
+}
+
+func (p *blockingClientStubProvider) Close() error {
+	blockingClientClosed.Add(1)
+	return nil
+}
+
+// TestE2E_ProviderReuse_AbandonedFetch tests that an instance whose fetch was abandoned is not handed to
+// the next collection while that fetch still runs, and is closed once it returns
+func TestE2E_ProviderReuse_AbandonedFetch(t *testing.T) {
+	blockingClientRelease = make(chan struct{})
+	blockingClientBlocked.Store(false)
+	blockingClientInstances.Clear()
+	blockingClientOverlapped.Store(false)
+	blockingClientClosed.Store(0)
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: blocking_client_stub
+    id: blocking
+    timeout: 50ms
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+
+	if _, err := collector.Collect(context.Background(), nil); err == nil {
+		t.Fatal("Expected the first collection to time out")
+	}
+	collected, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets while the abandoned fetch runs: %v", err)
+	}
+	if collected["BLOCKING_VALUE"] != "fetched" {
+		t.Errorf("Expected BLOCKING_VALUE=fetched, got %v", collected)
+	}
+	instances := 0
+	blockingClientInstances.Range(func(_, _ any) bool {
+		instances++
+		return true
+	})
+	if instances != 2 {
+		t.Errorf("Expected a new instance while the abandoned fetch runs, got %d instances", instances)
+	}
+	if blockingClientOverlapped.Load() {
+		t.Error("Expected no concurrent fetches on one instance")
+	}
+
+	close(blockingClientRelease)
+	deadline := time.Now().Add(5 * time.Second)
+	for blockingClientClosed.Load() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the abandoned instance to be closed once its fetch returned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,133 @@
+package end2end
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestE2E_ProvidersFromEnv tests that SSTART_PROVIDERS scopes collection with id, glob and kind selectors,
+// and that --providers takes precedence over the environment
+func TestE2E_ProvidersFromEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envFile, []byte("DOTENV_KEY=dotenv-value\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: aws-dev
+    values:
+      AWS_DEV_KEY: aws-dev-value
+  - kind: mock
+    id: aws-prod
+    values:
+      AWS_PROD_KEY: aws-prod-value
+  - kind: mock
+    id: vault-prod
+    alias: vault
+    values:
+      VAULT_KEY: vault-value
+  - kind: dotenv
+    id: local
+    path: ` + envFile + `
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	export := func(env []string, args ...string) ([]string, string, error) {
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile, "export"}, args...)...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), env...)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, stderr.String(), err
+		}
+		var exported map[string]string
+		if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil {
+			t.Fatalf("Output is not a JSON object: %v\nOutput: %s", err, stdout.String())
+		}
+		var keys []string
+		for key := range exported {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys, stderr.String(), nil
+	}
+
+	tests := []struct {
+		name     string
+		env      []string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no selection",
+			env:      []string{"SSTART_PROVIDERS="},
+			expected: "AWS_DEV_KEY,AWS_PROD_KEY,DOTENV_KEY,VAULT_KEY",
+		},
+		{
+			name:     "glob and id from env",
+			env:      []string{"SSTART_PROVIDERS=aws-*,vault-prod"},
+			expected: "AWS_DEV_KEY,AWS_PROD_KEY,VAULT_KEY",
+		},
+		{
+			name:     "kind selector and alias from env",
+			env:      []string{"SSTART_PROVIDERS= kind:dotenv , vault"},
+			expected: "DOTENV_KEY,VAULT_KEY",
+		},
+		{
+			name:     "flag takes precedence over env",
+			env:      []string{"SSTART_PROVIDERS=aws-*"},
+			args:     []string{"--providers", "*-prod"},
+			expected: "AWS_PROD_KEY,VAULT_KEY",
+		},
+		{
+			name:     "custom env variable",
+			env:      []string{"SSTART_PROVIDERS=aws-*", "CI_PROVIDERS=local"},
+			args:     []string{"--providers-from-env", "CI_PROVIDERS"},
+			expected: "DOTENV_KEY",
+		},
+		{
+			name:     "env ignored",
+			env:      []string{"SSTART_PROVIDERS=aws-dev"},
+			args:     []string{"--providers-from-env", ""},
+			expected: "AWS_DEV_KEY,AWS_PROD_KEY,DOTENV_KEY,VAULT_KEY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, stderr, err := export(tt.env, tt.args...)
+			if err != nil {
+				t.Fatalf("sstart export failed: %v\nStderr: %s", err, stderr)
+			}
+			if got := strings.Join(keys, ","); got != tt.expected {
+				t.Errorf("Expected keys %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("selector matching nothing", func(t *testing.T) {
+		_, stderr, err := export([]string{"SSTART_PROVIDERS=gcp-*"})
+		if err == nil {
+			t.Fatal("Expected export to fail for a selector matching no provider")
+		}
+		if !strings.Contains(stderr, "provider selector 'gcp-*' matches no provider") {
+			t.Errorf("Expected a selector error, got: %s", stderr)
+		}
+	})
+}
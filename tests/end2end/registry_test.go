@@ -0,0 +1,189 @@
+package end2end
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// registryProviders is the provider registry served to the tests
+const registryProviders = `
+providers:
+  - kind: mock
+    id: db-prod
+    values:
+      DB_HOST: db.internal
+      DB_PASSWORD: db-secret
+  - kind: mock
+    id: cache-prod
+    values:
+      CACHE_URL: redis://cache.internal
+  - kind: mock
+    id: unused
+    values:
+      UNUSED: unused
+`
+
+// newRegistryServer serves registryProviders, requiring the given Authorization header
+func newRegistryServer(t *testing.T, authorization string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/providers" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != authorization {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(registryProviders))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// loadRegistryConfig writes configYAML with REGISTRY_URL replaced and loads it
+func loadRegistryConfig(t *testing.T, configYAML, registryURL string) (*config.Config, error) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML = strings.ReplaceAll(configYAML, "REGISTRY_URL", registryURL)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return config.Load(configFile)
+}
+
+// TestE2E_Registry tests that providers named in use_from_registry are loaded from a remote registry
+func TestE2E_Registry(t *testing.T) {
+	t.Setenv("REGISTRY_TOKEN", "registry-token")
+	server := newRegistryServer(t, "Bearer registry-token")
+
+	cfg, err := loadRegistryConfig(t, `
+registry:
+  url: REGISTRY_URL/providers
+  headers:
+    Authorization: Bearer ${REGISTRY_TOKEN}
+use_from_registry: [db-prod, cache-prod]
+providers:
+  - kind: mock
+    id: app
+    depends_on: [db-prod]
+    values:
+      APP_NAME: myapp
+`, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var ids []string
+	for _, providerCfg := range cfg.Providers {
+		ids = append(ids, providerCfg.ID)
+	}
+	if got := strings.Join(ids, ","); got != "db-prod,cache-prod,app" {
+		t.Errorf("Expected providers db-prod,cache-prod,app, got %s", got)
+	}
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	expected := map[string]string{
+		"DB_HOST":     "db.internal",
+		"DB_PASSWORD": "db-secret",
+		"CACHE_URL":   "redis://cache.internal",
+		"APP_NAME":    "myapp",
+	}
+	if len(collected) != len(expected) {
+		t.Errorf("Expected %d secrets, got %d: %v", len(expected), len(collected), collected)
+	}
+	for key, value := range expected {
+		if collected[key] != value {
+			t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collected[key])
+		}
+	}
+}
+
+// TestE2E_Registry_LocalOverride tests that a configured provider replaces the registry provider with the same id
+func TestE2E_Registry_LocalOverride(t *testing.T) {
+	server := newRegistryServer(t, "")
+
+	cfg, err := loadRegistryConfig(t, `
+registry:
+  url: REGISTRY_URL/providers
+use_from_registry: [db-prod, cache-prod]
+providers:
+  - kind: mock
+    id: db-prod
+    values:
+      DB_HOST: localhost
+`, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collected["DB_HOST"] != "localhost" {
+		t.Errorf("Expected the configured db-prod provider, got DB_HOST=%s", collected["DB_HOST"])
+	}
+	if _, found := collected["DB_PASSWORD"]; found {
+		t.Errorf("Expected the registry db-prod provider to be replaced, got %v", collected)
+	}
+	if collected["CACHE_URL"] != "redis://cache.internal" {
+		t.Errorf("Expected CACHE_URL from the registry, got %v", collected)
+	}
+}
+
+// TestE2E_Registry_Errors tests the errors of registry configurations
+func TestE2E_Registry_Errors(t *testing.T) {
+	server := newRegistryServer(t, "")
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+	}{
+		{
+			name: "unknown provider",
+			configYAML: `
+registry:
+  url: REGISTRY_URL/providers
+use_from_registry: [db-staging]
+`,
+			expectError: "use_from_registry references unknown provider 'db-staging'",
+		},
+		{
+			name: "missing registry",
+			configYAML: `
+use_from_registry: [db-prod]
+`,
+			expectError: "use_from_registry requires registry.url",
+		},
+		{
+			name: "registry error",
+			configYAML: `
+registry:
+  url: REGISTRY_URL/missing
+use_from_registry: [db-prod]
+`,
+			expectError: "request failed with status 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRegistryConfig(t, tt.configYAML, server.URL)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing '%s', got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
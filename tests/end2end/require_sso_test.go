@@ -0,0 +1,144 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+)
+
+// TestE2E_RequireSSO tests that --require-sso fails before fetching secrets unless a valid token is stored
+func TestE2E_RequireSSO(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+sso:
+  oidc:
+    clientId: require-sso-client
+    issuer: http://127.0.0.1:1
+    scopes: [openid]
+
+providers:
+  - kind: mock
+    values:
+      REQUIRE_SSO_KEY: guarded-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// run executes 'sstart get' with tokens (if any) stored in an isolated config home
+	run := func(t *testing.T, tokens *oidc.Tokens, extraArgs ...string) (string, string, error) {
+		configHome := t.TempDir()
+		if tokens != nil {
+			data, err := json.Marshal(tokens)
+			if err != nil {
+				t.Fatalf("Failed to marshal tokens: %v", err)
+			}
+			tokenPath := filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName)
+			if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+				t.Fatalf("Failed to create token directory: %v", err)
+			}
+			if err := os.WriteFile(tokenPath, data, 0600); err != nil {
+				t.Fatalf("Failed to write tokens: %v", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		args := append([]string{"--config", configFile}, extraArgs...)
+		args = append(args, "get", "REQUIRE_SSO_KEY")
+		cmd := exec.CommandContext(ctx, sstartBinary, args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, oidc.SSOSecretEnvVar+"=")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			t.Fatalf("sstart did not fail fast: %v\nStderr: %s", ctx.Err(), stderr.String())
+		}
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("no_tokens", func(t *testing.T) {
+		_, stderr, err := run(t, nil, "--require-sso")
+		if err == nil {
+			t.Fatal("Expected sstart to fail without stored SSO tokens")
+		}
+		if !strings.Contains(stderr, "--require-sso is set but no SSO tokens were found") {
+			t.Errorf("Expected a missing-token error, got: %s", stderr)
+		}
+		if !strings.Contains(stderr, "--force-auth") {
+			t.Errorf("Expected the error to explain how to authenticate, got: %s", stderr)
+		}
+	})
+
+	t.Run("expired_token", func(t *testing.T) {
+		tokens := &oidc.Tokens{
+			AccessToken: "expired-access-token",
+			TokenType:   "Bearer",
+			Expiry:      time.Now().Add(-time.Hour),
+		}
+		_, stderr, err := run(t, tokens, "--require-sso")
+		if err == nil {
+			t.Fatal("Expected sstart to fail with an expired, non-refreshable token")
+		}
+		if !strings.Contains(stderr, "expired and could not be refreshed") {
+			t.Errorf("Expected an expired-token error, got: %s", stderr)
+		}
+	})
+
+	t.Run("valid_token", func(t *testing.T) {
+		tokens := &oidc.Tokens{
+			AccessToken: "valid-access-token",
+			TokenType:   "Bearer",
+			Expiry:      time.Now().Add(time.Hour),
+		}
+		stdout, stderr, err := run(t, tokens, "--require-sso")
+		if err != nil {
+			t.Fatalf("sstart failed with a valid token: %v\nStderr: %s", err, stderr)
+		}
+		if stdout != "guarded-value" {
+			t.Errorf("Expected 'guarded-value', got %q", stdout)
+		}
+	})
+}
+
+// TestE2E_RequireSSO_NotConfigured tests that --require-sso fails when no SSO is configured
+func TestE2E_RequireSSO_NotConfigured(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      REQUIRE_SSO_KEY: guarded-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--require-sso", "get", "REQUIRE_SSO_KEY")
+	cmd.Dir = tmpDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatal("Expected sstart to fail without sso.oidc configured")
+	}
+	if !strings.Contains(stderr.String(), "sso.oidc is not configured") {
+		t.Errorf("Expected a not-configured error, got: %s", stderr.String())
+	}
+}
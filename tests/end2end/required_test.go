@@ -0,0 +1,156 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_RequiredFalse tests that a failing provider with 'required: false' contributes no keys
+// instead of failing the collection, while required providers still fail it
+func TestE2E_RequiredFalse(t *testing.T) {
+	tests := []struct {
+		name        string
+		configYAML  string
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "optional provider fails",
+			configYAML: `
+providers:
+  - kind: mock
+    id: vault
+    values:
+      DB_PASSWORD: secret
+  - kind: mock
+    id: local
+    required: false
+    fail: true
+    error: failed to open .env file
+    values:
+      DB_PASSWORD: local-override
+`,
+			expected: map[string]string{"DB_PASSWORD": "secret"},
+		},
+		{
+			name: "required provider fails",
+			configYAML: `
+providers:
+  - kind: mock
+    id: vault
+    required: true
+    fail: true
+    error: connection refused
+  - kind: mock
+    id: local
+    required: false
+    values:
+      LOG_LEVEL: debug
+`,
+			expectError: "failed to fetch from provider 'vault': mock provider failure: connection refused",
+		},
+		{
+			name: "dependents of an optional provider",
+			configYAML: `
+providers:
+  - kind: mock
+    id: local
+    required: false
+    fail: true
+  - kind: mock
+    id: vault
+    values:
+      DB_USER: app
+  - kind: template
+    uses: [local, vault]
+    templates:
+      DB_URL: postgres://{{.vault.DB_USER}}@localhost/app
+`,
+			expected: map[string]string{"DB_USER": "app", "DB_URL": "postgres://app@localhost/app"},
+		},
+		{
+			name: "strict_uses dependent of an optional provider",
+			configYAML: `
+providers:
+  - kind: mock
+    id: local
+    required: false
+    fail: true
+  - kind: template
+    uses: [local]
+    strict_uses: true
+    templates:
+      DB_URL: postgres://{{.local.DB_USER}}@localhost/app
+`,
+			expectError: "provider 'template' uses provider 'local', which failed to collect (strict_uses)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			var mu sync.Mutex
+			outcomes := make(map[string]string)
+			collector := secrets.NewCollector(cfg, secrets.WithEventHandler(func(event secrets.Event) {
+				if event.Type == secrets.EventProviderFinish {
+					mu.Lock()
+					outcomes[event.Provider] = event.Outcome
+					mu.Unlock()
+				}
+			}))
+			collected, err := collector.Collect(context.Background(), nil)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collected) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collected), collected)
+			}
+			for key, value := range tt.expected {
+				if collected[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collected[key])
+				}
+			}
+			// The failure is still reported in the collection events
+			if outcomes["local"] != secrets.OutcomeError {
+				t.Errorf("Expected the optional provider to finish with outcome '%s', got '%s'", secrets.OutcomeError, outcomes["local"])
+			}
+		})
+	}
+}
+
+// TestE2E_RequiredInvalid tests that a non-boolean 'required' is rejected
+func TestE2E_RequiredInvalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    required: "no"
+    values:
+      API_KEY: secret
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid required 'no': must be true or false") {
+		t.Errorf("Expected an invalid required error, got: %v", err)
+	}
+}
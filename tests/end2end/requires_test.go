@@ -0,0 +1,190 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Requires_FeatureFlag tests that a feature flag from one provider gates another provider's collection
+func TestE2E_Requires_FeatureFlag(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		featureFlag string
+		expectB     bool
+	}{
+		{name: "flag enabled", featureFlag: "enabled", expectB: true},
+		{name: "flag disabled", featureFlag: "disabled", expectB: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			flagsFile := filepath.Join(tmpDir, "flags.env")
+			if err := os.WriteFile(flagsFile, []byte(fmt.Sprintf("FEATURE_X=%s\n", tt.featureFlag)), 0600); err != nil {
+				t.Fatalf("Failed to write flags file: %v", err)
+			}
+
+			featureFile := filepath.Join(tmpDir, "feature.env")
+			if err := os.WriteFile(featureFile, []byte("FEATURE_X_API_KEY=feature-secret\n"), 0600); err != nil {
+				t.Fatalf("Failed to write feature file: %v", err)
+			}
+
+			// Provider B is declared before provider A to verify the two-pass evaluation
+			configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    id: provider-b
+    path: %s
+    requires:
+      provider: provider-a
+      key: FEATURE_X
+      equals: enabled
+
+  - kind: dotenv
+    id: provider-a
+    path: %s
+`, featureFile, flagsFile)
+
+			configFile := filepath.Join(tmpDir, ".sstart.yml")
+			if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := config.Load(configFile)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			collector := secrets.NewCollector(cfg)
+			collectedSecrets, err := collector.Collect(ctx, nil)
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if collectedSecrets["FEATURE_X"] != tt.featureFlag {
+				t.Errorf("Expected FEATURE_X='%s', got '%s'", tt.featureFlag, collectedSecrets["FEATURE_X"])
+			}
+
+			value, exists := collectedSecrets["FEATURE_X_API_KEY"]
+			if tt.expectB {
+				if !exists || value != "feature-secret" {
+					t.Errorf("Expected FEATURE_X_API_KEY='feature-secret', got '%s' (exists: %v)", value, exists)
+				}
+			} else if exists {
+				t.Errorf("Expected FEATURE_X_API_KEY to be skipped, got '%s'", value)
+			}
+		})
+	}
+}
+
+// TestE2E_Requires_ImpossibleOrdering tests that unsatisfiable conditions are reported
+func TestE2E_Requires_ImpossibleOrdering(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	envFile := filepath.Join(tmpDir, "test.env")
+	if err := os.WriteFile(envFile, []byte("FLAG=enabled\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	t.Run("cycle", func(t *testing.T) {
+		configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    id: first
+    path: %[1]s
+    requires:
+      provider: second
+      key: FLAG
+  - kind: dotenv
+    id: second
+    path: %[1]s
+    requires:
+      provider: first
+      key: FLAG
+`, envFile)
+
+		configFile := filepath.Join(tmpDir, "cycle.yml")
+		if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		cfg, err := config.Load(configFile)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+
+		_, err = secrets.NewCollector(cfg).Collect(ctx, nil)
+		if err == nil {
+			t.Fatal("Expected error for cyclic requires, got none")
+		}
+		if !strings.Contains(err.Error(), "form a cycle") {
+			t.Errorf("Expected cycle error, got: %v", err)
+		}
+	})
+
+	t.Run("referenced provider not selected", func(t *testing.T) {
+		configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    id: flags
+    path: %[1]s
+  - kind: dotenv
+    id: gated
+    path: %[1]s
+    requires:
+      provider: flags
+      key: FLAG
+`, envFile)
+
+		configFile := filepath.Join(tmpDir, "unselected.yml")
+		if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		cfg, err := config.Load(configFile)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+
+		_, err = secrets.NewCollector(cfg).Collect(ctx, []string{"gated"})
+		if err == nil {
+			t.Fatal("Expected error when referenced provider is not selected, got none")
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+    requires:
+      provider: missing
+      key: FLAG
+`, envFile)
+
+		configFile := filepath.Join(tmpDir, "unknown.yml")
+		if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		_, err := config.Load(configFile)
+		if err == nil {
+			t.Fatal("Expected error for unknown requires provider, got none")
+		}
+		if !strings.Contains(err.Error(), "unknown provider 'missing'") {
+			t.Errorf("Expected unknown provider error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// flakyStubProvider fails the first 'failures' fetches of each provider id, then returns KEY=<id>
+type flakyStubProvider struct{}
+
+var (
+	flakyCalls   = make(map[string]int)
+	flakyCallsMu sync.Mutex
+)
+
+func init() {
+	provider.Register("flaky_stub", func() provider.Provider {
+		return &flakyStubProvider{}
+	})
+}
+
+func (p *flakyStubProvider) Name() string {
+	return "flaky_stub"
+}
+
+func (p *flakyStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	flakyCallsMu.Lock()
+	defer flakyCallsMu.Unlock()
+
+	flakyCalls[mapID]++
+	failures, _ := config["failures"].(int)
+	if flakyCalls[mapID] <= failures {
+		return nil, fmt.Errorf("transient failure %d", flakyCalls[mapID])
+	}
+	return []provider.KeyValue{{Key: strings.ToUpper(mapID), Value: mapID}}, nil
+}
+
+// resetFlakyCalls clears the call counters between tests
+func resetFlakyCalls() map[string]int {
+	flakyCallsMu.Lock()
+	defer flakyCallsMu.Unlock()
+	flakyCalls = make(map[string]int)
+	return flakyCalls
+}
+
+// TestE2E_RetryBudget_Retries tests that provider retries recover from transient failures
+func TestE2E_RetryBudget_Retries(t *testing.T) {
+	calls := resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: flaky_stub
+    id: first
+    failures: 2
+    retries: 3
+    retry_delay: 1ms
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["FIRST"] != "first" {
+		t.Errorf("Expected FIRST=first, got %v", collectedSecrets)
+	}
+	if calls["first"] != 3 {
+		t.Errorf("Expected 3 fetches, got %d", calls["first"])
+	}
+}
+
+// TestE2E_RetryBudget_MaxRetries tests that the retry count is shared across providers
+func TestE2E_RetryBudget_MaxRetries(t *testing.T) {
+	calls := resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+retry_budget:
+  max_retries: 3
+providers:
+  - kind: flaky_stub
+    id: first
+    failures: 2
+    retries: 5
+    retry_delay: 1ms
+  - kind: flaky_stub
+    id: second
+    failures: 10
+    retries: 5
+    retry_delay: 1ms
+`)
+
+	// Fetch one provider at a time, so the budget is used up in configuration order
+	_, err := secrets.NewCollector(cfg, secrets.WithConcurrency(1)).Collect(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Fatalf("Expected retry budget error, got %v", err)
+	}
+	// first: 1 fetch + 2 retries; second: 1 fetch + the 1 retry left in the budget
+	if calls["first"] != 3 || calls["second"] != 2 {
+		t.Errorf("Expected 3 and 2 fetches, got %d and %d", calls["first"], calls["second"])
+	}
+}
+
+// TestE2E_RetryBudget_MaxWait tests that the total wait between retries is bounded across providers
+func TestE2E_RetryBudget_MaxWait(t *testing.T) {
+	calls := resetFlakyCalls()
+	cfg := loadMockConfig(t, `
+retry_budget:
+  max_wait: 150ms
+providers:
+  - kind: flaky_stub
+    id: first
+    failures: 2
+    retries: 5
+    retry_delay: 50ms
+  - kind: flaky_stub
+    id: second
+    failures: 10
+    retries: 5
+    retry_delay: 50ms
+  - kind: flaky_stub
+    id: third
+    failures: 10
+    retries: 5
+    retry_delay: 50ms
+`)
+
+	// Without a budget, the second provider alone would wait 50+100+200+400+800ms
+	start := time.Now()
+	_, err := secrets.NewCollector(cfg, secrets.WithConcurrency(1)).Collect(context.Background(), nil)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Fatalf("Expected retry budget error, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected collection to be bounded by the retry budget, took %v", elapsed)
+	}
+	// first uses 50ms+100ms of the budget, so second and third fail without retrying
+	if calls["first"] != 3 || calls["second"] != 1 || calls["third"] != 1 {
+		t.Errorf("Unexpected fetch counts: %v", calls)
+	}
+}
@@ -0,0 +1,131 @@
+package end2end
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_RunAll_SharedCollection tests that run-all collects secrets once and shares them across tasks
+func TestE2E_RunAll_SharedCollection(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("RUN_ALL_SECRET=original\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// The first task rewrites the source file; the second task must still see the
+	// value from the single collection performed before any task started
+	firstOut := filepath.Join(tmpDir, "first.out")
+	secondOut := filepath.Join(tmpDir, "second.out")
+	tasksFile := filepath.Join(tmpDir, "tasks.yml")
+	tasksYAML := fmt.Sprintf(`
+tasks:
+  - name: first
+    command: ["sh", "-c", "echo \"$RUN_ALL_SECRET\" > %s && echo RUN_ALL_SECRET=changed > %s"]
+  - name: second
+    command: ["sh", "-c", "echo \"$RUN_ALL_SECRET\" > %s"]
+`, firstOut, envFile, secondOut)
+	if err := os.WriteFile(tasksFile, []byte(tasksYAML), 0600); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+
+	runCmd := exec.Command(sstartBinary, "--config", configFile, "run-all", "--file", tasksFile)
+	runCmd.Dir = tmpDir
+	if output, err := runCmd.CombinedOutput(); err != nil {
+		t.Fatalf("run-all failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, outFile := range []string{firstOut, secondOut} {
+		data, err := os.ReadFile(outFile)
+		if err != nil {
+			t.Fatalf("Failed to read task output %s: %v", outFile, err)
+		}
+		if got := strings.TrimSpace(string(data)); got != "original" {
+			t.Errorf("%s: expected 'original', got '%s'", filepath.Base(outFile), got)
+		}
+	}
+}
+
+// TestE2E_RunAll_ExitCodes tests exit code aggregation in fail-fast and continue modes
+func TestE2E_RunAll_ExitCodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("RUN_ALL_SECRET=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		extraArgs    []string
+		expectMarker bool
+	}{
+		{name: "fail_fast", expectMarker: false},
+		{name: "continue_on_error", extraArgs: []string{"--continue-on-error"}, expectMarker: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marker := filepath.Join(tmpDir, tt.name+".marker")
+			tasksFile := filepath.Join(tmpDir, tt.name+".yml")
+			tasksYAML := fmt.Sprintf(`
+tasks:
+  - name: failing
+    command: ["sh", "-c", "exit 3"]
+  - name: marker
+    command: ["touch", "%s"]
+`, marker)
+			if err := os.WriteFile(tasksFile, []byte(tasksYAML), 0600); err != nil {
+				t.Fatalf("Failed to write tasks file: %v", err)
+			}
+
+			args := append([]string{"--config", configFile, "run-all", "--file", tasksFile}, tt.extraArgs...)
+			runCmd := exec.Command(sstartBinary, args...)
+			runCmd.Dir = tmpDir
+			output, err := runCmd.CombinedOutput()
+
+			exitError, ok := err.(*exec.ExitError)
+			if !ok {
+				t.Fatalf("Expected exit error, got: %v\nOutput: %s", err, output)
+			}
+			if exitError.ExitCode() != 3 {
+				t.Errorf("Expected exit code 3, got %d\nOutput: %s", exitError.ExitCode(), output)
+			}
+
+			_, statErr := os.Stat(marker)
+			if tt.expectMarker && statErr != nil {
+				t.Errorf("Expected second task to run, marker missing")
+			}
+			if !tt.expectMarker && statErr == nil {
+				t.Errorf("Expected second task to be skipped, marker exists")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package end2end
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Run_DetectSecretsInArgs tests that a collected value passed as a child argument is reported
+func TestE2E_Run_DetectSecretsInArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      ARGS_API_TOKEN: tok-1234567890
+      ARGS_SHORT: on
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	run := func(extraArgs ...string) (string, string, error) {
+		args := append([]string{"--config", configFile, "run"}, extraArgs...)
+		args = append(args, "--", "echo", "--token=tok-1234567890", "on")
+		cmd := exec.Command(sstartBinary, args...)
+		cmd.Dir = tmpDir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		_, stderr, err := run()
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr)
+		}
+		if strings.Contains(stderr, "ARGS_API_TOKEN") {
+			t.Errorf("Expected no warning without --detect-secrets-in-args, got: %s", stderr)
+		}
+	})
+
+	t.Run("warns", func(t *testing.T) {
+		stdout, stderr, err := run("--detect-secrets-in-args")
+		if err != nil {
+			t.Fatalf("sstart run failed: %v\nStderr: %s", err, stderr)
+		}
+		if !strings.Contains(stdout, "--token=tok-1234567890") {
+			t.Errorf("Expected the command to run, got stdout: %s", stdout)
+		}
+		if !strings.Contains(stderr, "WARN: Command arguments contain the value of secret(s) ARGS_API_TOKEN") {
+			t.Errorf("Expected a warning naming ARGS_API_TOKEN, got: %s", stderr)
+		}
+		if strings.Contains(stderr, "ARGS_SHORT") {
+			t.Errorf("Expected short values to be ignored, got: %s", stderr)
+		}
+		if strings.Contains(stderr, "tok-1234567890") {
+			t.Errorf("The warning must not contain the secret value, got: %s", stderr)
+		}
+	})
+
+	t.Run("fails_on_warn", func(t *testing.T) {
+		stdout, stderr, err := run("--detect-secrets-in-args", "--fail-on-warn")
+		if err == nil {
+			t.Fatal("Expected sstart run to fail with --fail-on-warn")
+		}
+		if stdout != "" {
+			t.Errorf("Expected the command not to run, got stdout: %s", stdout)
+		}
+		if !strings.Contains(stderr, "ARGS_API_TOKEN") {
+			t.Errorf("Expected the error to name ARGS_API_TOKEN, got: %s", stderr)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package end2end
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestE2E_SelfTest tests that 'sstart self-test' validates every built-in provider without network access
+func TestE2E_SelfTest(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	cmd := exec.Command(sstartBinary, "self-test")
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sstart self-test failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, kind := range []string{"aws_secretsmanager", "bitwarden", "dotenv", "gcloud_secretmanager", "mock", "template", "vault"} {
+		if !strings.Contains(string(output), "ok    "+kind+"\n") {
+			t.Errorf("Expected provider '%s' to pass, got:\n%s", kind, output)
+		}
+	}
+	if strings.Contains(string(output), "FAIL") || !strings.Contains(string(output), "providers passed") {
+		t.Errorf("Expected all providers to pass, got:\n%s", output)
+	}
+}
@@ -0,0 +1,65 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Run_SortEnv tests that the child environment has each key once, with secrets winning, in sorted order
+func TestE2E_Run_SortEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    values:
+      SORT_DUPLICATE: from-secret
+      AAA_SORT_SECRET: first
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	envBinary, err := exec.LookPath("env")
+	if err != nil {
+		t.Skip("env binary not available")
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--", envBinary)
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "SORT_DUPLICATE=inherited", "ZZZ_SORT_INHERITED=last")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sstart run failed: %v\nOutput: %s", err, output)
+	}
+
+	var keys []string
+	seen := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if _, duplicate := seen[key]; duplicate {
+			t.Errorf("Key %s appears more than once in the child environment", key)
+		}
+		seen[key] = value
+		keys = append(keys, key)
+	}
+
+	if seen["SORT_DUPLICATE"] != "from-secret" {
+		t.Errorf("Expected SORT_DUPLICATE=from-secret, got '%s'", seen["SORT_DUPLICATE"])
+	}
+	if seen["ZZZ_SORT_INHERITED"] != "last" {
+		t.Errorf("Expected ZZZ_SORT_INHERITED=last, got '%s'", seen["ZZZ_SORT_INHERITED"])
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("Expected the child environment to be sorted, got keys %v", keys)
+	}
+}
@@ -0,0 +1,115 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_SourcePriority tests that a key is taken from its preferred provider regardless of declaration order
+func TestE2E_SourcePriority(t *testing.T) {
+	cfg := loadMockConfig(t, `
+source_priority:
+  DB_PASSWORD: [vault, aws]
+  API_URL: [vault-alias]
+providers:
+  - kind: mock
+    id: vault
+    alias: vault-alias
+    values:
+      DB_PASSWORD: from-vault
+      API_URL: https://vault.example.com
+      LOG_LEVEL: info
+  - kind: mock
+    id: aws
+    values:
+      DB_PASSWORD: from-aws
+      API_URL: https://aws.example.com
+      LOG_LEVEL: debug
+  - kind: mock
+    id: local
+    values:
+      API_URL: https://local.example.com
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		// Taken from the earlier-declared provider due to source_priority
+		"DB_PASSWORD": "from-vault",
+		// Unlisted providers rank below listed ones, even when declared later
+		"API_URL": "https://vault.example.com",
+		// Keys without a priority follow declaration order
+		"LOG_LEVEL": "debug",
+	}
+	for key, want := range expected {
+		if got := collectedSecrets[key]; got != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got)
+		}
+	}
+}
+
+// TestE2E_SourcePriority_LaterProviderWins tests that a preferred provider declared later overrides as usual
+// and that unselected preferred providers leave the default order in place
+func TestE2E_SourcePriority_LaterProviderWins(t *testing.T) {
+	cfg := loadMockConfig(t, `
+source_priority:
+  TOKEN: [second]
+providers:
+  - kind: mock
+    id: first
+    values:
+      TOKEN: from-first
+  - kind: mock
+    id: second
+    values:
+      TOKEN: from-second
+  - kind: mock
+    id: third
+    values:
+      TOKEN: from-third
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["TOKEN"] != "from-second" {
+		t.Errorf("Expected TOKEN=from-second, got %q", collectedSecrets["TOKEN"])
+	}
+
+	collectedSecrets, err = secrets.NewCollector(cfg).Collect(context.Background(), []string{"first", "third"})
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["TOKEN"] != "from-third" {
+		t.Errorf("Expected TOKEN=from-third without the preferred provider, got %q", collectedSecrets["TOKEN"])
+	}
+}
+
+// TestE2E_SourcePriority_UnknownProvider tests that source_priority must reference configured providers
+func TestE2E_SourcePriority_UnknownProvider(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+source_priority:
+  TOKEN: [missing]
+providers:
+  - kind: mock
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "unknown provider 'missing'") {
+		t.Errorf("Expected an unknown provider error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+)
+
+// writeDeviceFlowConfig writes a config using the issuer for SSO, storing tokens in a file
+func writeDeviceFlowConfig(t *testing.T, dir, issuer string) string {
+	t.Helper()
+
+	configFile := filepath.Join(dir, ".sstart.yml")
+	configYAML := `
+sso:
+  token_storage: file
+  oidc:
+    clientId: device-client
+    issuer: ` + issuer + `
+    scopes: [openid]
+
+providers:
+  - kind: mock
+    values:
+      DEVICE_KEY: device-value
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return configFile
+}
+
+// newDeviceFlowIssuer starts an OIDC issuer granting tokens through the device authorization grant
+func newDeviceFlowIssuer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                        issuer.URL,
+				"authorization_endpoint":        issuer.URL + "/authorize",
+				"token_endpoint":                issuer.URL + "/token",
+				"device_authorization_endpoint": issuer.URL + "/device",
+				"jwks_uri":                      issuer.URL + "/keys",
+			})
+		case "/keys":
+			w.Write([]byte(`{"keys":[]}`))
+		case "/device":
+			w.Write([]byte(`{"device_code":"device-1","user_code":"WXYZ-1234","verification_uri":"https://auth.example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			w.Write([]byte(`{"access_token":"device-access","refresh_token":"device-refresh","token_type":"Bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// TestE2E_SSO_DeviceFlow tests authenticating with the device authorization grant
+func TestE2E_SSO_DeviceFlow(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	issuer := newDeviceFlowIssuer(t)
+
+	configFile := writeDeviceFlowConfig(t, tmpDir, issuer.URL)
+	configHome := t.TempDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, sstartBinary, "--config", configFile, "--auth-flow", "device", "get", "DEVICE_KEY")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, oidc.SSOSecretEnvVar+"=")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("sstart failed: %v\nStderr: %s", err, stderr.String())
+	}
+
+	if stdout.String() != "device-value" {
+		t.Errorf("Expected 'device-value', got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "https://auth.example.com/device") || !strings.Contains(stderr.String(), "WXYZ-1234") {
+		t.Errorf("Expected the verification URL and user code on stderr, got: %s", stderr.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName))
+	if err != nil {
+		t.Fatalf("Expected tokens to be stored: %v", err)
+	}
+	var tokens oidc.Tokens
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		t.Fatalf("Failed to parse stored tokens: %v", err)
+	}
+	if tokens.AccessToken != "device-access" || tokens.RefreshToken != "device-refresh" {
+		t.Errorf("Expected device tokens to be stored, got %+v", tokens)
+	}
+}
+
+// TestE2E_SSO_AuthFlowInvalid tests that an unknown --auth-flow is rejected
+func TestE2E_SSO_AuthFlowInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	configFile := writeDeviceFlowConfig(t, tmpDir, "http://127.0.0.1:1")
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--auth-flow", "popup", "get", "DEVICE_KEY")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+t.TempDir(), oidc.SSOSecretEnvVar+"=")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatal("Expected sstart to fail with an unknown auth flow")
+	}
+	if !strings.Contains(stderr.String(), "unknown SSO auth flow 'popup'") {
+		t.Errorf("Expected an unknown auth flow error, got: %s", stderr.String())
+	}
+}
@@ -0,0 +1,138 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/oidc"
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// ssoTokenStubProvider returns the SSO access token it receives under the configured key
+type ssoTokenStubProvider struct{}
+
+func init() {
+	provider.Register("sso_token_stub", func() provider.Provider {
+		return &ssoTokenStubProvider{}
+	})
+}
+
+func (p *ssoTokenStubProvider) Name() string {
+	return "sso_token_stub"
+}
+
+func (p *ssoTokenStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	key, _ := config["key"].(string)
+	token, _ := config[secrets.AccessTokenConfigKey].(string)
+	return []provider.KeyValue{{Key: key, Value: token}}, nil
+}
+
+// writeTokenFile stores tokens with the given access token in a token file of the config home
+func writeTokenFile(t *testing.T, configHome, fileName, accessToken string) {
+	t.Helper()
+
+	data, err := json.Marshal(oidc.Tokens{AccessToken: accessToken, Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to marshal tokens: %v", err)
+	}
+	tokenPath := filepath.Join(configHome, oidc.ConfigDirName, fileName)
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		t.Fatalf("Failed to create token directory: %v", err)
+	}
+	if err := os.WriteFile(tokenPath, data, 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+}
+
+// TestE2E_SSO_NamedIdentities tests that providers receive the tokens of the SSO identity named by
+// their 'sso_ref', and the default identity's tokens otherwise
+func TestE2E_SSO_NamedIdentities(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(oidc.SSOSecretEnvVar, "")
+	t.Setenv(oidc.SecretEnvVar("cluster-b"), "")
+
+	writeTokenFile(t, configHome, oidc.TokenFileName, "cluster-a-token")
+	writeTokenFile(t, configHome, "tokens-cluster-b.json", "cluster-b-token")
+
+	cfg := loadMockConfig(t, `
+sso:
+  token_storage: file
+  oidc:
+    default:
+      clientId: cluster-a-client
+      issuer: https://a.example.com
+      scopes: [openid]
+    cluster-b:
+      clientId: cluster-b-client
+      issuer: https://b.example.com
+      scopes: [openid]
+providers:
+  - kind: sso_token_stub
+    id: vault-a
+    key: VAULT_A_TOKEN
+  - kind: sso_token_stub
+    id: vault-b
+    key: VAULT_B_TOKEN
+    sso_ref: cluster-b
+`)
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collected["VAULT_A_TOKEN"] != "cluster-a-token" {
+		t.Errorf("Expected vault-a to receive the default identity's token, got %q", collected["VAULT_A_TOKEN"])
+	}
+	if collected["VAULT_B_TOKEN"] != "cluster-b-token" {
+		t.Errorf("Expected vault-b to receive the cluster-b identity's token, got %q", collected["VAULT_B_TOKEN"])
+	}
+}
+
+// TestE2E_SSO_NamedIdentityOnlyWhenReferenced tests that a named identity is only authenticated when a
+// selected provider references it
+func TestE2E_SSO_NamedIdentityOnlyWhenReferenced(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(oidc.SecretEnvVar("cluster-b"), "")
+
+	cfg := loadMockConfig(t, `
+sso:
+  token_storage: file
+  oidc:
+    cluster-b:
+      clientId: cluster-b-client
+      issuer: http://127.0.0.1:1
+      scopes: [openid]
+providers:
+  - kind: mock
+    values:
+      PLAIN_KEY: plain
+  - kind: sso_token_stub
+    id: vault-b
+    key: VAULT_B_TOKEN
+    sso_ref: cluster-b
+`)
+
+	collected, err := secrets.NewCollector(cfg, secrets.WithRequireSSO(true)).Collect(context.Background(), []string{"mock"})
+	if err != nil {
+		t.Fatalf("Failed to collect secrets without the cluster-b identity: %v", err)
+	}
+	if collected["PLAIN_KEY"] != "plain" {
+		t.Errorf("Expected PLAIN_KEY to be collected, got %v", collected)
+	}
+
+	_, err = secrets.NewCollector(cfg, secrets.WithRequireSSO(true)).Collect(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Expected collecting vault-b to require the cluster-b identity's tokens")
+	}
+	if want := "sso identity 'cluster-b': --require-sso is set but no SSO tokens were found"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error containing %q, got: %v", want, err)
+	}
+}
@@ -0,0 +1,122 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_StrictKeys tests that unmapped source keys are reported in strict mode
+func TestE2E_StrictKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		configYAML  string
+		opts        []secrets.CollectorOption
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "non-strict silently drops unmapped keys",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      NEW_KEY: added-later
+    keys:
+      API_KEY: ==
+`,
+			expected: map[string]string{"API_KEY": "key"},
+		},
+		{
+			name: "per-provider strict_keys reports unmapped keys",
+			configYAML: `
+providers:
+  - kind: mock
+    strict_keys: true
+    values:
+      API_KEY: key
+      NEW_KEY: added-later
+      OTHER_KEY: other
+    keys:
+      API_KEY: ==
+`,
+			expectError: "returned keys not listed in 'keys' (strict keys): NEW_KEY, OTHER_KEY",
+		},
+		{
+			name: "global strict keys option reports unmapped keys",
+			configYAML: `
+providers:
+  - kind: mock
+    values:
+      API_KEY: key
+      NEW_KEY: added-later
+    keys:
+      API_KEY: ==
+`,
+			opts:        []secrets.CollectorOption{secrets.WithStrictKeys(true)},
+			expectError: "strict keys): NEW_KEY",
+		},
+		{
+			name: "strict mode applies mapping when all keys are listed",
+			configYAML: `
+providers:
+  - kind: mock
+    strict_keys: true
+    values:
+      API_KEY: key
+      DB_HOST: localhost
+    keys:
+      API_KEY: ==
+      DB_HOST: DATABASE_HOST
+`,
+			expected: map[string]string{"API_KEY": "key", "DATABASE_HOST": "localhost"},
+		},
+		{
+			name: "strict mode without keys returns everything",
+			configYAML: `
+providers:
+  - kind: mock
+    strict_keys: true
+    values:
+      API_KEY: key
+      NEW_KEY: added-later
+`,
+			expected: map[string]string{"API_KEY": "key", "NEW_KEY": "added-later"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, tt.configYAML)
+
+			collectedSecrets, err := secrets.NewCollector(cfg, tt.opts...).Collect(ctx, nil)
+			if tt.expectError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing '%s', got none", tt.expectError)
+				}
+				if !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			if len(collectedSecrets) != len(tt.expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(tt.expected), len(collectedSecrets), collectedSecrets)
+			}
+			for key, value := range tt.expected {
+				if collectedSecrets[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collectedSecrets[key])
+				}
+			}
+		})
+	}
+}
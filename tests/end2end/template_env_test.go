@@ -0,0 +1,86 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_TemplateProvider_UseEnv tests that 'use_env' exposes the environment as .env, and that
+// templates cannot read it without
+func TestE2E_TemplateProvider_UseEnv(t *testing.T) {
+	t.Setenv("SSTART_TEST_ENVIRONMENT", "staging")
+
+	tests := []struct {
+		name        string
+		dbID        string
+		options     string
+		template    string
+		expectError string
+		expected    string
+	}{
+		{
+			name:     "use_env",
+			options:  "use_env: true",
+			template: "postgres://{{.db.DB_USER}}@db.{{.env.SSTART_TEST_ENVIRONMENT}}.internal/app",
+			expected: "postgres://app@db.staging.internal/app",
+		},
+		{
+			name:     "sandboxed by default",
+			template: "postgres://{{.db.DB_USER}}@db.{{.env.SSTART_TEST_ENVIRONMENT}}.internal/app",
+			expected: "postgres://app@db.<no value>.internal/app",
+		},
+		{
+			name:        "missing variable with missing_key error",
+			options:     "use_env: true\n    missing_key: error",
+			template:    "{{.env.SSTART_TEST_UNSET}}",
+			expectError: "missing environment variable 'SSTART_TEST_UNSET' (missing_key: error)",
+		},
+		{
+			name:        "provider named env",
+			dbID:        "env",
+			options:     "use_env: true",
+			template:    "{{.env.DB_USER}}",
+			expectError: "provider 'env' in 'uses' conflicts with the environment exposed by use_env as .env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbID := tt.dbID
+			if dbID == "" {
+				dbID = "db"
+			}
+			cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: `+dbID+`
+    values:
+      DB_USER: app
+  - kind: template
+    uses: [`+dbID+`]
+    `+tt.options+`
+    templates:
+      DB_URL: '`+tt.template+`'
+`)
+
+			collected, err := secrets.NewCollector(cfg).Collect(context.Background(), []string{dbID, "template"})
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+			if collected["DB_URL"] != tt.expected {
+				t.Errorf("Expected DB_URL '%s', got '%s'", tt.expected, collected["DB_URL"])
+			}
+		})
+	}
+}
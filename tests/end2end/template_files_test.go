@@ -0,0 +1,212 @@
+package end2end
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_TemplateProvider_Files tests that 'files' renders templates into files with their permissions,
+// using the same 'uses' data as 'templates'
+func TestE2E_TemplateProvider_Files(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateFile := filepath.Join(tmpDir, "pg_service.conf.tmpl")
+	if err := os.WriteFile(templateFile, []byte("[app]\nhost={{.db.DB_HOST}}\nuser={{.db.DB_USER}}\n"), 0600); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+	nginxConf := filepath.Join(tmpDir, "nginx.conf")
+	pgService := filepath.Join(tmpDir, "pg_service.conf")
+	// An existing file is replaced
+	if err := os.WriteFile(pgService, []byte("stale"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: db
+    values:
+      DB_HOST: db.internal
+      DB_USER: app
+      API_TOKEN: tok-123
+  - kind: template
+    uses: [db]
+    templates:
+      DB_ADDR: "{{.db.DB_HOST}}:5432"
+    files:
+      `+nginxConf+`: |
+        proxy_set_header Authorization "Bearer {{.db.API_TOKEN}}";
+      `+pgService+`:
+        template_file: `+templateFile+`
+        mode: 0640
+`)
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), []string{"db", "template"})
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collected["DB_ADDR"] != "db.internal:5432" {
+		t.Errorf("Expected DB_ADDR from 'templates', got '%s'", collected["DB_ADDR"])
+	}
+
+	expected := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{nginxConf, "proxy_set_header Authorization \"Bearer tok-123\";\n", 0600},
+		{pgService, "[app]\nhost=db.internal\nuser=app\n", 0640},
+	}
+	for _, want := range expected {
+		data, err := os.ReadFile(want.path)
+		if err != nil {
+			t.Fatalf("Failed to read rendered file: %v", err)
+		}
+		if string(data) != want.content {
+			t.Errorf("File %s: expected %q, got %q", want.path, want.content, data)
+		}
+		info, err := os.Stat(want.path)
+		if err != nil {
+			t.Fatalf("Failed to stat rendered file: %v", err)
+		}
+		if info.Mode().Perm() != want.mode {
+			t.Errorf("File %s: expected mode %o, got %o", want.path, want.mode, info.Mode().Perm())
+		}
+	}
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected only the template and the 2 rendered files, got %d entries", len(entries))
+	}
+}
+
+// TestE2E_TemplateProvider_FilesReadOnly tests that dry runs and commands that only inspect secrets
+// render 'files' without writing them, while 'run' writes them
+func TestE2E_TemplateProvider_FilesReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	nginxConf := filepath.Join(tmpDir, "nginx.conf")
+	if err := os.WriteFile(nginxConf, []byte("original"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+providers:
+  - kind: mock
+    id: db
+    values:
+      API_TOKEN: tok-123
+  - kind: template
+    uses: [db]
+    templates:
+      AUTH_HEADER: "Bearer {{.db.API_TOKEN}}"
+    files:
+      ` + nginxConf + `: |
+        proxy_set_header Authorization "Bearer {{.db.API_TOKEN}}";
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"run", "--dry-run", "--", "true"},
+		{"show"},
+		{"env"},
+		{"get", "AUTH_HEADER"},
+		{"fingerprint"},
+	} {
+		cmd := exec.Command(sstartBinary, append([]string{"--config", configFile}, args...)...)
+		cmd.Dir = tmpDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("sstart %s failed: %v\nOutput: %s", strings.Join(args, " "), err, output)
+		}
+		if data, _ := os.ReadFile(nginxConf); string(data) != "original" {
+			t.Fatalf("Expected sstart %s to leave the file untouched, got %q", strings.Join(args, " "), data)
+		}
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--", "true")
+	cmd.Dir = tmpDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sstart run failed: %v\nOutput: %s", err, output)
+	}
+	if data, _ := os.ReadFile(nginxConf); !strings.Contains(string(data), "Bearer tok-123") {
+		t.Errorf("Expected sstart run to write the file, got %q", data)
+	}
+}
+
+// TestE2E_TemplateProvider_FilesErrors tests that a failing file template leaves every file untouched,
+// and that invalid 'files' configurations are rejected
+func TestE2E_TemplateProvider_FilesErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "a.conf")
+	second := filepath.Join(tmpDir, "b.conf")
+
+	tests := []struct {
+		name        string
+		files       string
+		expectError string
+	}{
+		{
+			name: "missing key",
+			files: `
+      ` + first + `: "user={{.db.DB_USER}}"
+      ` + second + `: "password={{.db.DB_PASSWORD}}"
+    missing_key: error`,
+			expectError: "failed to render file '" + second + "': missing key 'DB_PASSWORD' of provider 'db'",
+		},
+		{
+			name: "template and template_file",
+			files: `
+      ` + first + `:
+        template: "user={{.db.DB_USER}}"
+        template_file: ` + filepath.Join(tmpDir, "a.tmpl"),
+			expectError: "file '" + first + "' requires exactly one of 'template' and 'template_file'",
+		},
+		{
+			name: "invalid mode",
+			files: `
+      ` + first + `:
+        template: "user={{.db.DB_USER}}"
+        mode: "rw-r--r--"`,
+			expectError: "file '" + first + "': invalid mode 'rw-r--r--'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: db
+    values:
+      DB_USER: app
+  - kind: template
+    uses: [db]
+    files:`+tt.files+`
+`)
+
+			_, err := secrets.NewCollector(cfg).Collect(context.Background(), []string{"db", "template"})
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+			}
+			for _, path := range []string{first, second} {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("Expected %s not to be written, got: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package end2end
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_TemplateProvider_Funcs tests the helper functions of the template provider, and that missing
+// references still render as <no value>
+func TestE2E_TemplateProvider_Funcs(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: api_secrets
+    values:
+      API_KEY: sk-live-123
+      ENCODED: aGVsbG8=
+      REGION: EU-West-1
+  - kind: template
+    uses: [api_secrets]
+    templates:
+      API_KEY_B64: "{{ .api_secrets.API_KEY | b64enc }}"
+      DECODED: "{{ .api_secrets.ENCODED | b64dec | upper }}"
+      REGION: "{{ .api_secrets.REGION | lower }}"
+      LOG_LEVEL: '{{ .api_secrets.LOG_LEVEL | default "info" }}'
+      MISSING: "{{ .api_secrets.LOG_LEVEL }}"
+`)
+
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), []string{"api_secrets", "template"})
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		"API_KEY_B64": "c2stbGl2ZS0xMjM=",
+		"DECODED":     "HELLO",
+		"REGION":      "eu-west-1",
+		"LOG_LEVEL":   "info",
+		"MISSING":     "<no value>",
+	}
+	for key, value := range expected {
+		if collected[key] != value {
+			t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collected[key])
+		}
+	}
+}
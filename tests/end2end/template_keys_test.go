@@ -0,0 +1,108 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	_ "github.com/dirathea/sstart/internal/provider/template"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_TemplateProvider_Keys tests that 'keys' and 'exclude_keys' rename and filter template outputs
+// like the keys of any other provider
+func TestE2E_TemplateProvider_Keys(t *testing.T) {
+	const source = `
+providers:
+  - kind: mock
+    id: db
+    values:
+      DB_USER: app
+      DB_HOST: db.internal
+`
+
+	tests := []struct {
+		name        string
+		template    string
+		strictKeys  bool
+		expectError string
+		expected    map[string]string
+	}{
+		{
+			name: "rename and filter",
+			template: `
+    keys:
+      DB_URL: DATABASE_URL
+      DB_ADDR: ==
+`,
+			expected: map[string]string{"DATABASE_URL": "postgres://app@db.internal/app", "DB_ADDR": "db.internal:5432"},
+		},
+		{
+			name: "pattern adding a prefix",
+			template: `
+    keys:
+      DB_*: APP_DB_$1
+`,
+			expected: map[string]string{"APP_DB_URL": "postgres://app@db.internal/app", "APP_DB_ADDR": "db.internal:5432", "APP_DB_DEBUG": "app"},
+		},
+		{
+			name: "exclude keys",
+			template: `
+    exclude_keys: [DB_DEBUG]
+`,
+			expected: map[string]string{"DB_URL": "postgres://app@db.internal/app", "DB_ADDR": "db.internal:5432"},
+		},
+		{
+			name: "strict keys",
+			template: `
+    keys:
+      DB_URL: DATABASE_URL
+`,
+			strictKeys:  true,
+			expectError: "returned keys not listed in 'keys' (strict keys): DB_ADDR, DB_DEBUG",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, source+`
+  - kind: template
+    uses: [db]
+    templates:
+      DB_URL: postgres://{{.db.DB_USER}}@{{.db.DB_HOST}}/app
+      DB_ADDR: "{{.db.DB_HOST}}:5432"
+      DB_DEBUG: "{{.db.DB_USER}}"
+`+strings.TrimPrefix(tt.template, "\n"))
+
+			var opts []secrets.CollectorOption
+			if tt.strictKeys {
+				opts = append(opts, secrets.WithStrictKeys(true))
+			}
+			collected, err := secrets.NewCollector(cfg, opts...).Collect(context.Background(), []string{"db", "template"})
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing '%s', got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to collect secrets: %v", err)
+			}
+
+			// The mock provider's own keys are collected as well
+			expected := map[string]string{"DB_USER": "app", "DB_HOST": "db.internal"}
+			for key, value := range tt.expected {
+				expected[key] = value
+			}
+			if len(collected) != len(expected) {
+				t.Errorf("Expected %d secrets, got %d: %v", len(expected), len(collected), collected)
+			}
+			for key, value := range expected {
+				if collected[key] != value {
+					t.Errorf("Secret '%s': expected '%s', got '%s'", key, value, collected[key])
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/dirathea/sstart/internal/config"
 	_ "github.com/dirathea/sstart/internal/provider/aws"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
 	_ "github.com/dirathea/sstart/internal/provider/template"
 	"github.com/dirathea/sstart/internal/secrets"
 )
@@ -579,3 +580,51 @@ exit 0
 
 	t.Logf("Successfully tested template provider: providers not in 'uses' list resolve to empty values")
 }
+
+// TestE2E_TemplateProvider_Alias tests that templates can reference providers by alias as well as by id
+func TestE2E_TemplateProvider_Alias(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	envFile := filepath.Join(tmpDir, "db.env")
+	if err := os.WriteFile(envFile, []byte("HOST=db.example.com\nPORT=5432\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    id: production_database_credentials
+    alias: db
+    path: %s
+
+  - kind: template
+    uses:
+      - db
+    templates:
+      DB_ADDR: "{{.db.HOST}}:{{.db.PORT}}"
+      DB_ADDR_BY_ID: "{{.production_database_credentials.HOST}}"
+`, envFile)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	if got := collectedSecrets["DB_ADDR"]; got != "db.example.com:5432" {
+		t.Errorf("DB_ADDR: expected 'db.example.com:5432', got '%s'", got)
+	}
+	if got := collectedSecrets["DB_ADDR_BY_ID"]; got != "db.example.com" {
+		t.Errorf("DB_ADDR_BY_ID: expected 'db.example.com', got '%s'", got)
+	}
+}
@@ -178,6 +178,37 @@ func SetupAWSSecret(ctx context.Context, t *testing.T, localstack *LocalStackCon
 	}
 }
 
+// SetupAWSPendingSecretVersion adds a version staged as AWSPENDING to an existing secret, simulating a rotation in progress
+func SetupAWSPendingSecretVersion(ctx context.Context, t *testing.T, localstack *LocalStackContainer, secretName string, secretData map[string]string) {
+	t.Helper()
+
+	secretJSON, err := json.Marshal(secretData)
+	if err != nil {
+		t.Fatalf("Failed to marshal secret data: %v", err)
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx,
+		awsconfig.WithRegion("us-east-1"),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("test", "test", "")),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load AWS config: %v", err)
+	}
+
+	secretsManagerClient := secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+		o.BaseEndpoint = aws.String(localstack.Endpoint)
+	})
+
+	_, err = secretsManagerClient.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:      aws.String(secretName),
+		SecretString:  aws.String(string(secretJSON)),
+		VersionStages: []string{"AWSPENDING"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to put pending secret version in AWS Secrets Manager: %v", err)
+	}
+}
+
 // SetupAWSIAMRoleForJWT creates an IAM role in LocalStack that accepts JWT authentication
 // This is a simplified version for testing with LocalStack
 func SetupAWSIAMRoleForJWT(ctx context.Context, t *testing.T, localstack *LocalStackContainer, roleName string) {
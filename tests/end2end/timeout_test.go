@@ -0,0 +1,198 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/provider"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// hangingStubProvider blocks until hangingStubRelease is closed, ignoring its context
+type hangingStubProvider struct{}
+
+var hangingStubRelease = make(chan struct{})
+
+func init() {
+	provider.Register("hanging_stub", func() provider.Provider {
+		return &hangingStubProvider{}
+	})
+}
+
+func (p *hangingStubProvider) Name() string {
+	return "hanging_stub"
+}
+
+func (p *hangingStubProvider) Fetch(secretContext provider.SecretContext, mapID string, config map[string]interface{}, keys map[string]string) ([]provider.KeyValue, error) {
+	<-hangingStubRelease
+	return []provider.KeyValue{{Key: "HANGING", Value: "released"}}, nil
+}
+
+// TestE2E_ProviderTimeout tests that a provider timeout fails a hanging fetch with an error naming
+// the provider and the elapsed time, and that a provider's own timeout takes precedence over the global one
+func TestE2E_ProviderTimeout(t *testing.T) {
+	t.Run("provider_timeout", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: slow-vault
+    delay: 5s
+    timeout: 100ms
+    values:
+      SLOW: slow-value
+  - kind: mock
+    id: fast
+    values:
+      FAST: fast-value
+`)
+		start := time.Now()
+		_, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the timeout to stop the fetch, took %v", elapsed)
+		}
+		if err == nil || !strings.Contains(err.Error(), "failed to fetch from provider 'slow-vault': timed out after") || !strings.Contains(err.Error(), "(timeout 100ms)") {
+			t.Fatalf("Expected a timeout error naming the provider, got %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the error to wrap context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("global_timeout_ignored_context", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: hanging_stub
+    id: hanging
+`)
+		start := time.Now()
+		_, err := secrets.NewCollector(cfg, secrets.WithTimeout(100*time.Millisecond)).Collect(context.Background(), nil)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected a provider ignoring its context to be abandoned, took %v", elapsed)
+		}
+		if err == nil || !strings.Contains(err.Error(), "failed to fetch from provider 'hanging': timed out after") {
+			t.Fatalf("Expected a timeout error naming the provider, got %v", err)
+		}
+	})
+
+	t.Run("provider_timeout_overrides_global", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: patient
+    delay: 200ms
+    timeout: 5s
+    values:
+      PATIENT: patient-value
+`)
+		collected, err := secrets.NewCollector(cfg, secrets.WithTimeout(50*time.Millisecond)).Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Expected the provider's own timeout to apply, got %v", err)
+		}
+		if collected["PATIENT"] != "patient-value" {
+			t.Errorf("Expected PATIENT=patient-value, got %v", collected)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+		if err := os.WriteFile(configFile, []byte("providers:\n  - kind: mock\n    timeout: soon\n"), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		if _, err := config.Load(configFile); err == nil || !strings.Contains(err.Error(), "invalid timeout format 'soon'") {
+			t.Errorf("Expected an invalid timeout error, got %v", err)
+		}
+	})
+}
+
+// TestE2E_CollectionTimeout_ShorterThanProviderTimeouts tests that the collection timeout aborts slow providers
+// before the command is started, even when the per-provider timeouts are longer
+func TestE2E_CollectionTimeout_ShorterThanProviderTimeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(`
+providers:
+  - kind: mock
+    id: slow-vault
+    delay: 5s
+    timeout: 10s
+    values:
+      SLOW: slow-value
+  - kind: mock
+    id: slow-aws
+    delay: 5s
+    values:
+      OTHER: other-value
+`), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	marker := filepath.Join(tmpDir, "started")
+
+	start := time.Now()
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--provider-timeout", "10s", "--collection-timeout", "300ms", "run", "--", "touch", marker)
+	output, err := cmd.CombinedOutput()
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the collection timeout to abort the slow providers, took %v", elapsed)
+	}
+	if err == nil {
+		t.Fatalf("Expected sstart to fail, output: %s", output)
+	}
+	if !strings.Contains(string(output), "secret collection timed out after") || !strings.Contains(string(output), "(collection timeout 300ms)") {
+		t.Errorf("Expected a collection timeout error, got: %s", output)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("Expected the command not to be started, stat error: %v", err)
+	}
+
+	t.Run("repeated_collections", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: slow
+    delay: 100ms
+    values:
+      SLOW: slow-value
+  - kind: hanging_stub
+    id: hanging
+`)
+		// Like watch mode, collect again after each timeout; a timed-out collection must have stopped
+		// before the next one resets the collector's state
+		collector := secrets.NewCollector(cfg, secrets.WithCollectionTimeout(20*time.Millisecond))
+		for i := 0; i < 5; i++ {
+			start := time.Now()
+			_, err := collector.Collect(context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), "(collection timeout 20ms)") {
+				t.Fatalf("Collection %d: expected a collection timeout error, got %v", i, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Collection %d: expected the timed-out collection to stop promptly, took %v", i, elapsed)
+			}
+		}
+	})
+
+	t.Run("within_timeout", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: mock
+    id: quick
+    delay: 50ms
+    values:
+      QUICK: quick-value
+`)
+		collected, err := secrets.NewCollector(cfg, secrets.WithCollectionTimeout(5*time.Second)).Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Expected the collection to finish in time, got %v", err)
+		}
+		if collected["QUICK"] != "quick-value" {
+			t.Errorf("Expected QUICK=quick-value, got %v", collected)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package end2end
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dirathea/sstart/internal/config"
+	"github.com/dirathea/sstart/internal/keyring"
+	"github.com/dirathea/sstart/internal/oidc"
+	_ "github.com/dirathea/sstart/internal/provider/mock"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// lockedKeyring answers no keyring operation in time, like a locked keyring waiting for an unlock prompt
+type lockedKeyring struct{}
+
+func (lockedKeyring) Get(service, user string) (string, error) {
+	time.Sleep(5 * time.Second)
+	return "", keyring.ErrNotFound
+}
+
+func (lockedKeyring) Set(service, user, password string) error {
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+func (lockedKeyring) Delete(service, user string) error {
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+// TestE2E_SSO_TokenStorageFile tests that with 'sso.token_storage: file' tokens are read from the token file
+// without touching the keyring, so a locked keyring does not delay collection
+func TestE2E_SSO_TokenStorageFile(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(oidc.SSOSecretEnvVar, "")
+	t.Setenv(keyring.TimeoutEnvVar, "2s")
+	t.Cleanup(keyring.SetBackend(lockedKeyring{}))
+
+	tokens, err := json.Marshal(oidc.Tokens{AccessToken: "file-access-token", Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to marshal tokens: %v", err)
+	}
+	tokenPath := filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName)
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		t.Fatalf("Failed to create token directory: %v", err)
+	}
+	if err := os.WriteFile(tokenPath, tokens, 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	cfg := loadMockConfig(t, `
+sso:
+  token_storage: file
+  oidc:
+    clientId: sstart-cli
+    issuer: https://auth.example.com
+    scopes: [openid]
+providers:
+  - kind: mock
+    values:
+      API_KEY: secret
+`)
+
+	started := time.Now()
+	collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed >= 2*time.Second {
+		t.Errorf("Collect took %s, expected the keyring not to be used", elapsed)
+	}
+	if collected["API_KEY"] != "secret" {
+		t.Errorf("Expected API_KEY to be collected, got %v", collected)
+	}
+}
+
+// TestE2E_SSO_TokenStorageInvalid tests that an unknown 'sso.token_storage' is rejected
+func TestE2E_SSO_TokenStorageInvalid(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	configYAML := `
+sso:
+  token_storage: keychain
+providers:
+  - kind: mock
+    values:
+      API_KEY: secret
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err := config.Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid sso.token_storage 'keychain': must be auto, keyring, file or memory") {
+		t.Errorf("Expected an invalid token_storage error, got: %v", err)
+	}
+}
+
+// TestE2E_SSO_TokenStorageFlag tests that --token-storage overrides 'sso.token_storage', here keeping
+// the tokens in memory instead of writing the configured token file
+func TestE2E_SSO_TokenStorageFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	issuer := newDeviceFlowIssuer(t)
+	configFile := writeDeviceFlowConfig(t, tmpDir, issuer.URL)
+	configHome := t.TempDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, sstartBinary, "--config", configFile, "--token-storage", "memory", "--auth-flow", "device", "get", "DEVICE_KEY")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, oidc.SSOSecretEnvVar+"=")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("sstart failed: %v\nStderr: %s", err, stderr.String())
+	}
+
+	if stdout.String() != "device-value" {
+		t.Errorf("Expected 'device-value', got %q", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(configHome, oidc.ConfigDirName, oidc.TokenFileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no token file with --token-storage memory, got stat error %v", err)
+	}
+}
+
+// TestE2E_SSO_TokenStorageFlagInvalid tests that an unknown --token-storage is rejected
+func TestE2E_SSO_TokenStorageFlagInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+	configFile := writeDeviceFlowConfig(t, tmpDir, "http://127.0.0.1:1")
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--token-storage", "keychain", "get", "DEVICE_KEY")
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+t.TempDir(), oidc.SSOSecretEnvVar+"=")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatal("Expected sstart to fail with an unknown token storage")
+	}
+	if !strings.Contains(stderr.String(), "invalid token storage 'keychain'") {
+		t.Errorf("Expected an invalid token storage error, got: %s", stderr.String())
+	}
+}
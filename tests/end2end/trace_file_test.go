@@ -0,0 +1,107 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_TraceFile tests that --trace-file records each key's provider in override order
+// and changes when providers are reordered
+func TestE2E_TraceFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	baseYAML := `
+  - kind: mock
+    id: defaults
+    values:
+      SHARED_URL: https://defaults.example.com
+      DEFAULTS_ONLY: trace-defaults-value
+`
+	overrideYAML := `
+  - kind: mock
+    id: overrides
+    values:
+      SHARED_URL: https://overrides.example.com
+      OVERRIDES_ONLY: trace-overrides-value
+`
+
+	trace := func(t *testing.T, providersYAML string) string {
+		t.Helper()
+		configFile := filepath.Join(tmpDir, ".sstart.yml")
+		if err := os.WriteFile(configFile, []byte("providers:"+providersYAML), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		traceFile := filepath.Join(tmpDir, "trace.txt")
+		cmd := exec.Command(sstartBinary, "--config", configFile, "--trace-file", traceFile, "env")
+		cmd.Dir = tmpDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("sstart env failed: %v\nOutput: %s", err, output)
+		}
+		data, err := os.ReadFile(traceFile)
+		if err != nil {
+			t.Fatalf("Failed to read trace file: %v", err)
+		}
+		if strings.Contains(string(data), "example.com") || strings.Contains(string(data), "-value") {
+			t.Errorf("Trace file must not contain values, got:\n%s", data)
+		}
+		return string(data)
+	}
+
+	first := trace(t, baseYAML+overrideYAML)
+	want := "DEFAULTS_ONLY defaults\n" +
+		"OVERRIDES_ONLY overrides\n" +
+		"SHARED_URL overrides (overrides defaults)\n"
+	if first != want {
+		t.Errorf("Unexpected trace file.\nExpected:\n%s\nGot:\n%s", want, first)
+	}
+
+	// Reordering the providers shifts the source of the shared key
+	second := trace(t, overrideYAML+baseYAML)
+	if !strings.Contains(second, "SHARED_URL defaults (overrides overrides)\n") {
+		t.Errorf("Expected SHARED_URL to come from defaults after reordering, got:\n%s", second)
+	}
+	if first == second {
+		t.Error("Expected the trace file to change when providers are reordered")
+	}
+}
+
+// TestE2E_TraceFile_MergeStrategy tests that merged keys are traced as merged rather than overridden
+func TestE2E_TraceFile_MergeStrategy(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := `
+merge_strategy:
+  ALLOWED_HOSTS: concat
+providers:
+  - kind: mock
+    id: base
+    values:
+      ALLOWED_HOSTS: a.example.com
+  - kind: mock
+    id: extra
+    values:
+      ALLOWED_HOSTS: b.example.com
+`
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	traceFile := filepath.Join(tmpDir, "trace.txt")
+	cmd := exec.Command(sstartBinary, "--config", configFile, "--trace-file", traceFile, "env")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sstart env failed: %v\nOutput: %s", err, output)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+	if string(data) != "ALLOWED_HOSTS extra (merged with base)\n" {
+		t.Errorf("Unexpected trace file:\n%s", data)
+	}
+}
@@ -0,0 +1,111 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_UppercaseKeys tests that uppercase_keys normalizes final key names after key mapping
+func TestE2E_UppercaseKeys(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	envFile := filepath.Join(tmpDir, "test.env")
+	envContent := "db_host=localhost\napi_key=secret-key\nMixed_Case=mixed\n"
+	if err := os.WriteFile(envFile, []byte(envContent), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configYAML := fmt.Sprintf(`
+uppercase_keys: true
+providers:
+  - kind: dotenv
+    path: %s
+    keys:
+      db_host: ==
+      api_key: service_api_key
+      Mixed_Case: ==
+`, envFile)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expectedSecrets := map[string]string{
+		"DB_HOST":         "localhost",
+		"SERVICE_API_KEY": "secret-key",
+		"MIXED_CASE":      "mixed",
+	}
+
+	if len(collectedSecrets) != len(expectedSecrets) {
+		t.Errorf("Expected %d secrets, got %d: %v", len(expectedSecrets), len(collectedSecrets), collectedSecrets)
+	}
+	for key, expectedValue := range expectedSecrets {
+		if actualValue, exists := collectedSecrets[key]; !exists || actualValue != expectedValue {
+			t.Errorf("Secret '%s': expected '%s', got '%s' (exists: %v)", key, expectedValue, actualValue, exists)
+		}
+	}
+}
+
+// TestE2E_UppercaseKeys_Collision tests that keys differing only by case are reported as a collision
+func TestE2E_UppercaseKeys_Collision(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	lowerFile := filepath.Join(tmpDir, "lower.env")
+	if err := os.WriteFile(lowerFile, []byte("db_host=lower\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	upperFile := filepath.Join(tmpDir, "upper.env")
+	if err := os.WriteFile(upperFile, []byte("DB_HOST=upper\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configYAML := fmt.Sprintf(`
+uppercase_keys: true
+providers:
+  - kind: dotenv
+    id: lower
+    path: %s
+  - kind: dotenv
+    id: upper
+    path: %s
+`, lowerFile, upperFile)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	_, err = secrets.NewCollector(cfg).Collect(ctx, nil)
+	if err == nil {
+		t.Fatal("Expected case-collision error, got none")
+	}
+	if !strings.Contains(err.Error(), "'DB_HOST' and 'db_host' collide as 'DB_HOST'") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
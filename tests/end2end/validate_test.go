@@ -0,0 +1,90 @@
+package end2end
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestE2E_Validate tests that 'sstart validate' checks provider kinds, provider options and 'uses'
+// references without fetching secrets, reporting every problem at once
+func TestE2E_Validate(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	validate := func(configYAML string) (string, error) {
+		configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+		if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		cmd := exec.Command(sstartBinary, "--config", configFile, "validate")
+		cmd.Dir = tmpDir
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+
+	// A valid configuration passes, even with a provider that would fail to fetch
+	output, err := validate(`
+providers:
+  - kind: mock
+    id: db
+    fail: true
+    values:
+      DB_USER: admin
+  - kind: dotenv
+    path: does-not-exist.env
+  - kind: template
+    uses: [db]
+    templates:
+      DB_URL: postgres://{{.db.DB_USER}}@localhost/app
+`)
+	if err != nil {
+		t.Fatalf("sstart validate failed: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{"ok    db (mock)", "ok    dotenv (dotenv)", "ok    template (template)", "Configuration is valid (3 providers)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+
+	// Every problem is reported
+	output, err = validate(`
+providers:
+  - kind: mock
+    id: db
+    delay: soon
+  - kind: nonexistent
+    id: legacy
+  - kind: template
+    uses: [db, cache, template]
+    templates:
+      DB_URL: postgres://{{.db.DB_USER}}@localhost/app
+`)
+	if err == nil {
+		t.Fatalf("Expected sstart validate to fail, output: %s", output)
+	}
+	for _, want := range []string{
+		"FAIL  db (mock): invalid mock delay 'soon'",
+		"FAIL  legacy (nonexistent): unknown provider kind: nonexistent",
+		"FAIL  template (template): 'uses' references unknown provider 'cache'",
+		"FAIL  template (template): 'uses' references the provider itself",
+		"validation failed for 3 of 3 providers",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+
+	// Load errors are reported before any provider is checked
+	output, err = validate(`
+providers:
+  - kind: mock
+    valuess:
+      KEY: value
+`)
+	if err == nil || !strings.Contains(output, "did you mean 'values'?") {
+		t.Errorf("Expected load error with suggestion, got err=%v output=%s", err, output)
+	}
+}
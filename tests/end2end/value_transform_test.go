@@ -0,0 +1,139 @@
+package end2end
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dirathea/sstart/internal/config"
+	_ "github.com/dirathea/sstart/internal/provider/dotenv"
+	"github.com/dirathea/sstart/internal/secrets"
+	_ "github.com/dirathea/sstart/internal/transform/gcpkms"
+)
+
+// newKMSDecryptStub serves the Cloud KMS decrypt endpoint for any key, "decrypting" by stripping an "enc:" prefix
+func newKMSDecryptStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, ":decrypt") {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ciphertext, _ := base64.StdEncoding.DecodeString(req.Ciphertext)
+		plaintext, ok := strings.CutPrefix(string(ciphertext), "enc:")
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Decryption failed"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"plaintext": base64.StdEncoding.EncodeToString([]byte(plaintext)),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestE2E_ValueTransform_Config tests parsing of value_transform
+func TestE2E_ValueTransform_Config(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: dotenv
+    path: secrets.env
+    value_transform:
+      type: gcp_kms_decrypt
+      keys: [DB_PASSWORD]
+      key_name: my-key
+      keyring: my-ring
+      location: europe-west1
+      project_id: my-project
+`)
+
+	providerCfg := cfg.Providers[0]
+	if _, ok := providerCfg.Config["value_transform"]; ok {
+		t.Error("Expected value_transform not to be passed to the provider config")
+	}
+	valueTransform := providerCfg.ValueTransform
+	if valueTransform == nil {
+		t.Fatal("Expected value_transform to be parsed")
+	}
+	if valueTransform.Type != "gcp_kms_decrypt" {
+		t.Errorf("Expected type gcp_kms_decrypt, got %s", valueTransform.Type)
+	}
+	if strings.Join(valueTransform.Keys, ",") != "DB_PASSWORD" {
+		t.Errorf("Expected keys [DB_PASSWORD], got %v", valueTransform.Keys)
+	}
+	for key, want := range map[string]string{"key_name": "my-key", "keyring": "my-ring", "location": "europe-west1", "project_id": "my-project"} {
+		if valueTransform.Config[key] != want {
+			t.Errorf("Expected %s=%s, got %v", key, want, valueTransform.Config[key])
+		}
+	}
+	if _, ok := valueTransform.Config["type"]; ok {
+		t.Error("Expected type not to be part of the transform config")
+	}
+
+	configFile := filepath.Join(t.TempDir(), ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte("providers:\n  - kind: dotenv\n    path: x.env\n    value_transform:\n      key_name: k\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if _, err := config.Load(configFile); err == nil || !strings.Contains(err.Error(), "'type'") {
+		t.Errorf("Expected a missing type error, got %v", err)
+	}
+}
+
+// TestE2E_ValueTransform_GCPKMSDecrypt tests decrypting selected dotenv values with Cloud KMS
+func TestE2E_ValueTransform_GCPKMSDecrypt(t *testing.T) {
+	server := newKMSDecryptStub(t)
+	tmpDir := t.TempDir()
+
+	encrypted := base64.StdEncoding.EncodeToString([]byte("enc:kms-plaintext"))
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte(fmt.Sprintf("DB_PASSWORD=%s\nDB_HOST=db.internal\n", encrypted)), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	cfg := loadMockConfig(t, fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+    value_transform:
+      type: gcp_kms_decrypt
+      keys: [DB_PASSWORD]
+      project_id: my-project
+      keyring: my-ring
+      key_name: my-key
+      endpoint: %s
+`, envFile, server.URL))
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["DB_PASSWORD"] != "kms-plaintext" {
+		t.Errorf("Expected DB_PASSWORD to be decrypted, got %q", collectedSecrets["DB_PASSWORD"])
+	}
+	if collectedSecrets["DB_HOST"] != "db.internal" {
+		t.Errorf("Expected DB_HOST to be left as-is, got %q", collectedSecrets["DB_HOST"])
+	}
+
+	// Without 'keys', every value must be ciphertext
+	cfg.Providers[0].ValueTransform.Keys = nil
+	_, err = secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "DB_HOST") {
+		t.Errorf("Expected decrypting DB_HOST to fail, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// newVaultDatabaseStub starts a server answering the database secrets engine creds endpoint for 'role'
+func newVaultDatabaseStub(t *testing.T, mount, role string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "db-test-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/"+mount+"/creds/"+role {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":       mount + "/creds/" + role + "/abc123",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data": map[string]interface{}{
+				"username": "v-token-" + role + "-xyz",
+				"password": "A1a-dynamic-password",
+			},
+		})
+	}))
+}
+
+// TestE2E_Vault_DatabaseCreds tests mapping dynamic database credentials from the database secrets engine
+func TestE2E_Vault_DatabaseCreds(t *testing.T) {
+	server := newVaultDatabaseStub(t, "database", "app-role")
+	defer server.Close()
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    id: db
+    address: `+server.URL+`
+    token: db-test-token
+    engine: database
+    role: app-role
+    keys:
+      username: DB_USER
+      password: DB_PASSWORD
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	if collectedSecrets["DB_USER"] != "v-token-app-role-xyz" {
+		t.Errorf("Expected DB_USER=v-token-app-role-xyz, got '%s'", collectedSecrets["DB_USER"])
+	}
+	if collectedSecrets["DB_PASSWORD"] != "A1a-dynamic-password" {
+		t.Errorf("Expected DB_PASSWORD=A1a-dynamic-password, got '%s'", collectedSecrets["DB_PASSWORD"])
+	}
+	if len(collectedSecrets) != 2 {
+		t.Errorf("Expected only the mapped credentials, got %v", collectedSecrets)
+	}
+}
+
+// TestE2E_Vault_DatabaseCreds_CustomMount tests the database engine on a custom mount without key mappings
+func TestE2E_Vault_DatabaseCreds_CustomMount(t *testing.T) {
+	server := newVaultDatabaseStub(t, "postgres", "readonly")
+	defer server.Close()
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    id: db
+    address: `+server.URL+`
+    token: db-test-token
+    engine: database
+    mount: postgres
+    role: readonly
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+	if collectedSecrets["username"] != "v-token-readonly-xyz" {
+		t.Errorf("Expected username=v-token-readonly-xyz, got %v", collectedSecrets)
+	}
+}
+
+// TestE2E_Vault_DatabaseCreds_RequiresRole tests that the database engine requires a role
+func TestE2E_Vault_DatabaseCreds_RequiresRole(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    id: db
+    address: http://127.0.0.1:1
+    token: db-test-token
+    engine: database
+`)
+
+	if _, err := secrets.NewCollector(cfg).Collect(context.Background(), nil); err == nil {
+		t.Error("Expected an error when 'role' is missing for the database engine")
+	}
+}
@@ -0,0 +1,86 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Vault_KVVersion1 tests that kv_version 1 reads mount/path directly and does not unwrap a 'data' field
+func TestE2E_Vault_KVVersion1(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		if r.Header.Get("X-Vault-Token") != "kv1-test-token" || r.URL.Path != "/v1/legacy/myapp/config" {
+			http.NotFound(w, r)
+			return
+		}
+		// A KV v1 secret that happens to have a field named 'data'
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"API_KEY": "kv1-api-key",
+				"data":    "kv1-data-field",
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: `+server.URL+`
+    token: kv1-test-token
+    mount: legacy
+    path: myapp/config
+    kv_version: 1
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+	collected, err := collector.Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	if collected["API_KEY"] != "kv1-api-key" {
+		t.Errorf("Expected API_KEY=kv1-api-key, got %q", collected["API_KEY"])
+	}
+	if collected["data"] != "kv1-data-field" {
+		t.Errorf("Expected the 'data' field to be kept as a key, got %q", collected["data"])
+	}
+	for _, path := range requested {
+		if strings.Contains(path, "/data/") {
+			t.Errorf("Expected no KV v2 request with kv_version 1, got %s", path)
+		}
+	}
+}
+
+// TestE2E_Vault_KVVersionUnsupported tests that an unsupported kv_version fails with a clear error
+func TestE2E_Vault_KVVersionUnsupported(t *testing.T) {
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: http://127.0.0.1:1
+    token: kv-test-token
+    path: myapp/config
+    kv_version: 3
+`)
+
+	collector := secrets.NewCollector(cfg)
+	defer collector.Close()
+	_, err := collector.Collect(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported kv_version: 3") {
+		t.Fatalf("Expected an unsupported kv_version error, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// newVaultKVStub starts a server answering KV v2 reads under the 'secret' mount from data, keyed by path
+func newVaultKVStub(t *testing.T, data map[string]map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, found := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+		if r.Header.Get("X-Vault-Token") != "kv-test-token" || !found {
+			http.NotFound(w, r)
+			return
+		}
+
+		secretData, ok := data[path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     secretData,
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		})
+	}))
+}
+
+// TestE2E_Vault_PathTemplate tests reading templated paths for each value, namespaced by the value
+func TestE2E_Vault_PathTemplate(t *testing.T) {
+	server := newVaultKVStub(t, map[string]map[string]interface{}{
+		"app1/config":  {"API_KEY": "app1-key", "DB_URL": "postgres://app1"},
+		"app-2/config": {"API_KEY": "app2-key", "DB_URL": "postgres://app2"},
+	})
+	defer server.Close()
+
+	cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: `+server.URL+`
+    token: kv-test-token
+    path_template: "{{.}}/config"
+    for_each: [app1, app-2]
+    keys:
+      API_KEY: ==
+`)
+
+	collectedSecrets, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to collect secrets: %v", err)
+	}
+
+	expected := map[string]string{
+		"APP1_API_KEY":  "app1-key",
+		"APP_2_API_KEY": "app2-key",
+	}
+	if len(collectedSecrets) != len(expected) {
+		t.Errorf("Expected %d secrets, got %v", len(expected), collectedSecrets)
+	}
+	for key, want := range expected {
+		if got := collectedSecrets[key]; got != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got)
+		}
+	}
+}
+
+// TestE2E_Vault_PathTemplate_Validation tests the path_template configuration checks
+func TestE2E_Vault_PathTemplate_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+	}{
+		{name: "missing for_each", fields: `path_template: "{{.}}/config"`},
+		{name: "path and path_template", fields: "path: app1/config\n    path_template: \"{{.}}/config\"\n    for_each: [app1]"},
+		{name: "missing path", fields: "for_each: [app1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: http://127.0.0.1:1
+    token: kv-test-token
+    `+tt.fields+`
+`)
+			if _, err := secrets.NewCollector(cfg).Collect(context.Background(), nil); err == nil {
+				t.Error("Expected a configuration error")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package end2end
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/dirathea/sstart/internal/provider/vault"
+	"github.com/dirathea/sstart/internal/secrets"
+)
+
+// TestE2E_Vault_Paths tests reading several paths with one provider, with prefixes and a keys mapping
+// applied to the merged set
+func TestE2E_Vault_Paths(t *testing.T) {
+	server := newVaultKVStub(t, map[string]map[string]interface{}{
+		"app/db":    {"DB_URL": "postgres://app", "PASSWORD": "db-password"},
+		"app/cache": {"REDIS_URL": "redis://app", "PASSWORD": "cache-password"},
+		"app/api":   {"API_KEY": "api-key"},
+	})
+	defer server.Close()
+
+	t.Run("merged", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: `+server.URL+`
+    token: kv-test-token
+    paths:
+      - app/db
+      - path: app/cache
+        prefix: CACHE_
+      - app/api
+    keys:
+      DB_URL: DATABASE_URL
+      PASSWORD: ==
+      CACHE_PASSWORD: ==
+      REDIS_URL: ==
+`)
+		collected, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Failed to collect secrets: %v", err)
+		}
+
+		expected := map[string]string{
+			"DATABASE_URL":   "postgres://app",
+			"PASSWORD":       "db-password",
+			"CACHE_PASSWORD": "cache-password",
+		}
+		if len(collected) != len(expected) {
+			t.Errorf("Expected %d secrets, got %v", len(expected), collected)
+		}
+		for key, want := range expected {
+			if got := collected[key]; got != want {
+				t.Errorf("Expected %s=%q, got %q", key, want, got)
+			}
+		}
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: `+server.URL+`
+    token: kv-test-token
+    paths: [app/db, app/cache]
+`)
+		_, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "key 'PASSWORD' is read from both vault paths 'app/db' and 'app/cache'") {
+			t.Fatalf("Expected a collision error, got %v", err)
+		}
+	})
+
+	t.Run("path_and_paths", func(t *testing.T) {
+		cfg := loadMockConfig(t, `
+providers:
+  - kind: vault
+    address: `+server.URL+`
+    token: kv-test-token
+    path: app/api
+    paths: [app/db]
+`)
+		_, err := secrets.NewCollector(cfg).Collect(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "only one of 'path', 'paths' or 'path_template'") {
+			t.Fatalf("Expected a configuration error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package end2end
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while a subprocess writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput polls buf until it contains want n times or the timeout elapses
+func waitForOutput(buf *syncBuffer, want string, n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if strings.Count(buf.String(), want) >= n {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+// TestE2E_Run_WatchDebounce tests that a burst of changes within the debounce window restarts the command once
+func TestE2E_Run_WatchDebounce(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("WATCH_TOKEN=v0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--watch", "--watch-debounce", "700ms",
+		"--", "sh", "-c", `echo "started $WATCH_TOKEN"; exec sleep 30`)
+	cmd.Dir = tmpDir
+	var stdout, stderr syncBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start sstart: %v", err)
+	}
+	// Interrupt sstart, which forwards the signal to the command, so both exit
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	if !waitForOutput(&stdout, "started v0", 1, 10*time.Second) {
+		t.Fatalf("Command did not start\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+
+	// Fire a burst of changes, each well within the debounce window of the previous one
+	for i := 1; i <= 5; i++ {
+		if err := os.WriteFile(envFile, []byte(fmt.Sprintf("WATCH_TOKEN=v%d\n", i)), 0600); err != nil {
+			t.Fatalf("Failed to update env file: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if !waitForOutput(&stdout, "started v5", 1, 10*time.Second) {
+		t.Fatalf("Command was not restarted with the final value\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+	// Leave time for any extra restart to show up
+	time.Sleep(1500 * time.Millisecond)
+
+	if starts := strings.Count(stdout.String(), "started"); starts != 2 {
+		t.Errorf("Expected the initial start and one restart, got %d starts\nStdout: %s", starts, stdout.String())
+	}
+	if restarts := strings.Count(stderr.String(), "Secrets changed, restarting command"); restarts != 1 {
+		t.Errorf("Expected one restart notice, got %d\nStderr: %s", restarts, stderr.String())
+	}
+}
+
+// TestE2E_Run_WatchUnchanged tests that a change that leaves the secrets identical does not restart the command
+func TestE2E_Run_WatchUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("WATCH_TOKEN=v0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--watch", "--watch-debounce", "100ms",
+		"--", "sh", "-c", `echo "started $WATCH_TOKEN"; exec sleep 30`)
+	cmd.Dir = tmpDir
+	var stdout, stderr syncBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start sstart: %v", err)
+	}
+	// Interrupt sstart, which forwards the signal to the command, so both exit
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	if !waitForOutput(&stdout, "started v0", 1, 10*time.Second) {
+		t.Fatalf("Command did not start\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+
+	// Rewrite the same secret with a comment added
+	if err := os.WriteFile(envFile, []byte("# unchanged\nWATCH_TOKEN=v0\n"), 0600); err != nil {
+		t.Fatalf("Failed to update env file: %v", err)
+	}
+	time.Sleep(1 * time.Second)
+
+	if starts := strings.Count(stdout.String(), "started"); starts != 1 {
+		t.Errorf("Expected no restart when secrets are unchanged, got %d starts\nStdout: %s", starts, stdout.String())
+	}
+}
+
+// TestE2E_Run_WatchDebounceRequiresWatch tests that --watch-debounce is rejected without watch mode
+func TestE2E_Run_WatchDebounceRequiresWatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	if err := os.WriteFile(configFile, []byte("providers:\n  - kind: mock\n    values:\n      A: b\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--watch-debounce", "1s", "--", "true")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("Expected --watch-debounce without --watch to fail")
+	}
+	if !strings.Contains(string(output), "--watch-debounce requires --watch") {
+		t.Errorf("Expected a flag error, got: %s", output)
+	}
+}
+
+// TestE2E_Run_WatchRestartOn tests that --restart-on only restarts the command when a listed key changes
+func TestE2E_Run_WatchRestartOn(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("DB_PASSWORD=p0\nFEATURE_FLAGS=f0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--watch", "--watch-debounce", "100ms", "--restart-on", "DB_PASSWORD",
+		"--", "sh", "-c", `echo "started $DB_PASSWORD $FEATURE_FLAGS"; exec sleep 30`)
+	cmd.Dir = tmpDir
+	var stdout, stderr syncBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start sstart: %v", err)
+	}
+	// Interrupt sstart, which forwards the signal to the command, so both exit
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	if !waitForOutput(&stdout, "started p0 f0", 1, 10*time.Second) {
+		t.Fatalf("Command did not start\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+
+	// A change to a key that is not listed does not restart the command
+	if err := os.WriteFile(envFile, []byte("DB_PASSWORD=p0\nFEATURE_FLAGS=f1\n"), 0600); err != nil {
+		t.Fatalf("Failed to update env file: %v", err)
+	}
+	time.Sleep(1 * time.Second)
+	if starts := strings.Count(stdout.String(), "started"); starts != 1 {
+		t.Fatalf("Expected no restart when an unlisted key changes, got %d starts\nStdout: %s", starts, stdout.String())
+	}
+
+	// A change to a listed key restarts the command with all current secrets
+	if err := os.WriteFile(envFile, []byte("DB_PASSWORD=p1\nFEATURE_FLAGS=f2\n"), 0600); err != nil {
+		t.Fatalf("Failed to update env file: %v", err)
+	}
+	if !waitForOutput(&stdout, "started p1 f2", 1, 10*time.Second) {
+		t.Fatalf("Command was not restarted when a listed key changed\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+	if restarts := strings.Count(stderr.String(), "Secrets changed, restarting command"); restarts != 1 {
+		t.Errorf("Expected one restart notice, got %d\nStderr: %s", restarts, stderr.String())
+	}
+}
+
+// TestE2E_Run_WatchIntervalWithinDebounce tests that triggers arriving faster than the debounce window,
+// here an interval below it, do not hold back re-collections forever
+func TestE2E_Run_WatchIntervalWithinDebounce(t *testing.T) {
+	tmpDir := t.TempDir()
+	sstartBinary := buildSstart(t, tmpDir)
+
+	envFile := filepath.Join(tmpDir, "secrets.env")
+	if err := os.WriteFile(envFile, []byte("WATCH_TOKEN=v0\n"), 0600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, ".sstart.yml")
+	configYAML := fmt.Sprintf(`
+providers:
+  - kind: dotenv
+    path: %s
+`, envFile)
+	if err := os.WriteFile(configFile, []byte(configYAML), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cmd := exec.Command(sstartBinary, "--config", configFile, "run", "--watch-interval", "100ms", "--watch-debounce", "300ms",
+		"--", "sh", "-c", `echo "started $WATCH_TOKEN"; exec sleep 30`)
+	cmd.Dir = tmpDir
+	var stdout, stderr syncBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start sstart: %v", err)
+	}
+	// Interrupt sstart, which forwards the signal to the command, so both exit
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	if !waitForOutput(&stdout, "started v0", 1, 10*time.Second) {
+		t.Fatalf("Command did not start\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+
+	if err := os.WriteFile(envFile, []byte("WATCH_TOKEN=v1\n"), 0600); err != nil {
+		t.Fatalf("Failed to update env file: %v", err)
+	}
+	if !waitForOutput(&stdout, "started v1", 1, 5*time.Second) {
+		t.Fatalf("Command was not restarted while the interval kept triggering\nStdout: %s\nStderr: %s", stdout.String(), stderr.String())
+	}
+}
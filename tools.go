@@ -1,9 +1,11 @@
 //go:build tools
 // +build tools
 
-// Package tools manages tool dependencies for this project.
-// This file ensures tools are tracked in go.mod even though they're not imported by the main code.
-package tools
+// This file manages tool dependencies for this project.
+// It ensures tools are tracked in go.mod even though they're not imported by the main code.
+// It shares the package name of the library in this directory (see sstart.go).
+
+package sstart
 
 import (
 	_ "gotest.tools/gotestsum"